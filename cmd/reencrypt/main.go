@@ -0,0 +1,66 @@
+// the reencrypt command encrypts the personal data again with the primary
+// key, it must be run after a key rotation before the old key is removed,
+// it also encrypts the plaintext values stored before the encryption
+//
+//	go run ./cmd/reencrypt
+//	go run ./cmd/reencrypt -generate-key
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/env"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+func main() {
+	generate := flag.Bool("generate-key", false, "print a new base64 key for ENCRYPTION_KEYS and exit")
+	batchSize := flag.Int("batch-size", constant.ReencryptBatchSize, "rows read per query")
+	flag.Parse()
+
+	if *generate {
+		k, err := envelope.GenerateKey()
+		if err != nil {
+			log.Fatalf("generate key: %v", err)
+		}
+
+		fmt.Println(k)
+		return
+	}
+
+	env.LoadEnv("")
+
+	keys, err := modules.NewKeyRingFromEnv()
+	if err != nil {
+		log.Fatalf("init encryption: %v", err)
+	}
+
+	client := sqlext.GetInstance(sqlext.Config{
+		Host:     os.Getenv("DB_HOST"),
+		Port:     os.Getenv("DB_PORT"),
+		Username: os.Getenv("DB_USERNAME"),
+		Password: os.Getenv("DB_PASS"),
+		DBName:   os.Getenv("DB_NAME"),
+		SSLMode:  os.Getenv("DB_SSL_MODE"),
+	})
+	defer client.Close()
+
+	columns := []sqlext.EncryptedColumn{postgres.AddressColumn}
+
+	for _, c := range columns {
+		n, err := sqlext.Reencrypt(context.Background(), client.DB(), keys, c, *batchSize)
+		if err != nil {
+			log.Fatalf("reencrypt %s.%s: %v", c.Table, c.Column, err)
+		}
+
+		log.Printf("reencrypted %d values of %s.%s with the key %s", n, c.Table, c.Column, keys.Primary())
+	}
+}
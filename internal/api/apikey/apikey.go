@@ -0,0 +1,35 @@
+package apikey
+
+// APIKey is a key of a machine client acting for the user owning
+// it, only the prefix of the key and a salted hash of its secret
+// are stored, the scopes limit the permissions of the owner
+type APIKey struct {
+	ID     string
+	UserID string
+	Name   string
+	// Prefix is the visible part of the key, ex: bsk_1a2b3c4d5e6f
+	Prefix string
+	Salt   []byte
+	Hash   []byte
+	Scopes []string
+	// ExpiresAt is 0 if the key does not expire
+	ExpiresAt int64
+	// LastUsedAt is 0 if the key was not used
+	LastUsedAt int64
+	// RevokedAt is 0 if the key is not revoked
+	RevokedAt int64
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Active reports if the key authenticates at now
+func (k APIKey) Active(now int64) bool {
+	return k.RevokedAt == 0 && (k.ExpiresAt == 0 || now < k.ExpiresAt)
+}
+
+// Issued is a created or rotated key with its secret
+// the secret is only returned once
+type Issued struct {
+	APIKey
+	Key string
+}
@@ -0,0 +1,8 @@
+package apikey
+
+type CreateDTO struct {
+	Name   string
+	Scopes []string
+	// ExpiresAt is 0 if the key does not expire
+	ExpiresAt int64
+}
@@ -0,0 +1,123 @@
+package mock
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// MemoryStorage is a mock storage
+// can be used to mock the repository
+// for service testing, Roles contains
+// the roles of the owners of the keys
+type MemoryStorage struct {
+	mu    sync.Mutex
+	seq   int
+	keys  []apikey.APIKey
+	Roles map[string][]string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{Roles: make(map[string][]string)}
+}
+
+func (s *MemoryStorage) index(id string) int {
+	return slices.IndexFunc(s.keys, func(k apikey.APIKey) bool { return k.ID == id })
+}
+
+func (s *MemoryStorage) Create(ctx context.Context, k apikey.APIKey) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	k.ID = "key-" + strconv.Itoa(s.seq)
+	s.keys = append(s.keys, k)
+
+	return k.ID, nil
+}
+
+func (s *MemoryStorage) ReadMany(ctx context.Context, userID string) ([]apikey.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := make([]apikey.APIKey, 0)
+	for i := len(s.keys) - 1; i >= 0; i-- {
+		if s.keys[i].UserID == userID {
+			d = append(d, s.keys[i])
+		}
+	}
+
+	return d, nil
+}
+
+func (s *MemoryStorage) ReadOne(ctx context.Context, id string) (apikey.APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(id)
+	if i < 0 {
+		return apikey.APIKey{}, errorext.NewCustomError(http.StatusNotFound, errorext.ErrNotFound)
+	}
+
+	return s.keys[i], nil
+}
+
+func (s *MemoryStorage) ReadByPrefix(ctx context.Context, prefix string) (apikey.APIKey, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.keys, func(k apikey.APIKey) bool { return k.Prefix == prefix })
+	if i < 0 {
+		return apikey.APIKey{}, nil, errorext.NewCustomError(http.StatusNotFound, errorext.ErrNotFound)
+	}
+
+	return s.keys[i], s.Roles[s.keys[i].UserID], nil
+}
+
+func (s *MemoryStorage) UpdateSecret(ctx context.Context, id, prefix string, salt, hash []byte, updatedAt int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(id)
+	if i < 0 || s.keys[i].RevokedAt != 0 {
+		return 0, nil
+	}
+
+	k := &s.keys[i]
+	k.Prefix, k.Salt, k.Hash, k.UpdatedAt = prefix, salt, hash, updatedAt
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) UpdateLastUsed(ctx context.Context, id string, usedAt, notBefore int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(id)
+	if i < 0 || s.keys[i].LastUsedAt > notBefore {
+		return 0, nil
+	}
+
+	s.keys[i].LastUsedAt = usedAt
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) Revoke(ctx context.Context, id string, revokedAt int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := s.index(id)
+	if i < 0 || s.keys[i].RevokedAt != 0 {
+		return 0, nil
+	}
+
+	s.keys[i].RevokedAt, s.keys[i].UpdatedAt = revokedAt, revokedAt
+
+	return 1, nil
+}
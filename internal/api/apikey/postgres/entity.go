@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+// columns contains the columns of the key in the select order
+var columns = []string{"id", "user_id", "name", "prefix", "salt", "key_hash", "scopes", "expires_at", "last_used_at", "revoked_at", "created_at", "updated_at"}
+
+// scanner is implemented by sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// a package private entity clone of the domain entity
+// scopes is a jsonb array, the times are null until set
+type apiKeyEntity struct {
+	id         string
+	userID     string
+	name       string
+	prefix     string
+	salt       []byte
+	hash       []byte
+	scopes     sqlext.JsonStringArray
+	expiresAt  sql.NullInt64
+	lastUsedAt sql.NullInt64
+	revokedAt  sql.NullInt64
+	createdAt  int64
+	updatedAt  int64
+}
+
+func (e *apiKeyEntity) dest() []any {
+	return []any{&e.id, &e.userID, &e.name, &e.prefix, &e.salt, &e.hash, &e.scopes, &e.expiresAt, &e.lastUsedAt, &e.revokedAt, &e.createdAt, &e.updatedAt}
+}
+
+func (e *apiKeyEntity) toDomain() apikey.APIKey {
+	return apikey.APIKey{
+		ID:         e.id,
+		UserID:     e.userID,
+		Name:       e.name,
+		Prefix:     e.prefix,
+		Salt:       e.salt,
+		Hash:       e.hash,
+		Scopes:     e.scopes,
+		ExpiresAt:  e.expiresAt.Int64,
+		LastUsedAt: e.lastUsedAt.Int64,
+		RevokedAt:  e.revokedAt.Int64,
+		CreatedAt:  e.createdAt,
+		UpdatedAt:  e.updatedAt,
+	}
+}
+
+func scanAPIKey(s scanner) (apikey.APIKey, error) {
+	var e apiKeyEntity
+	if err := s.Scan(e.dest()...); err != nil {
+		log.Println("error: ", err)
+		return apikey.APIKey{}, errorext.BuildDBError(err)
+	}
+
+	return e.toDomain(), nil
+}
+
+// scanAPIKeyWithRoles scans a key followed by the comma
+// separated roles of its owner, null without roles
+func scanAPIKeyWithRoles(s scanner) (apikey.APIKey, []string, error) {
+	var (
+		e     apiKeyEntity
+		roles sql.NullString
+	)
+
+	if err := s.Scan(append(e.dest(), &roles)...); err != nil {
+		log.Println("error: ", err)
+		return apikey.APIKey{}, nil, errorext.BuildDBError(err)
+	}
+
+	var r []string
+	if roles.String != "" {
+		r = strings.Split(roles.String, ",")
+	}
+
+	return e.toDomain(), r, nil
+}
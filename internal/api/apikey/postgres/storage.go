@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+const (
+	tableName         = "api_keys"
+	userRoleTableName = "user_roles"
+)
+
+// storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
+type storage struct {
+	db *sql.DB
+}
+
+func NewStorage(db *sql.DB) *storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Create(ctx context.Context, k apikey.APIKey) (string, error) {
+	var lastID string
+
+	scopes, err := json.Marshal(k.Scopes)
+	if err != nil {
+		return lastID, err
+	}
+
+	q := sqlext.BuildInsertQuery(tableName, []string{"user_id", "name", "prefix", "salt", "key_hash", "scopes", "expires_at", "created_at", "updated_at"}, "RETURNING id")
+
+	expiresAt := sql.NullInt64{Int64: k.ExpiresAt, Valid: k.ExpiresAt != 0}
+
+	err = sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, k.UserID, k.Name, k.Prefix, k.Salt, k.Hash, scopes, expiresAt, k.CreatedAt, k.UpdatedAt).Scan(&lastID)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return lastID, errorext.BuildDBError(err)
+	}
+
+	return lastID, nil
+}
+
+func (s *storage) ReadMany(ctx context.Context, userID string) ([]apikey.APIKey, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE user_id = $1 ORDER BY created_at DESC, id", strings.Join(columns, ", "), tableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	d := make([]apikey.APIKey, 0)
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		d = append(d, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println("error: ", err)
+		return nil, errorext.BuildDBError(err)
+	}
+
+	return d, nil
+}
+
+func (s *storage) ReadOne(ctx context.Context, id string) (apikey.APIKey, error) {
+	q := sqlext.BuildSelectQuery(tableName, columns, []string{"id"}, "")
+
+	return scanAPIKey(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, id))
+}
+
+func (s *storage) ReadByPrefix(ctx context.Context, prefix string) (apikey.APIKey, []string, error) {
+	q := fmt.Sprintf(
+		"SELECT k.%s, (SELECT string_agg(r.role, ',' ORDER BY r.role) FROM %s r WHERE r.user_id = k.user_id) FROM %s k WHERE k.prefix = $1",
+		strings.Join(columns, ", k."),
+		userRoleTableName,
+		tableName,
+	)
+
+	return scanAPIKeyWithRoles(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, prefix))
+}
+
+func (s *storage) UpdateSecret(ctx context.Context, id, prefix string, salt, hash []byte, updatedAt int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET prefix = $1, salt = $2, key_hash = $3, updated_at = $4 WHERE id = $5 AND revoked_at IS NULL", tableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, prefix, salt, hash, updatedAt, id)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) UpdateLastUsed(ctx context.Context, id string, usedAt, notBefore int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET last_used_at = $1 WHERE id = $2 AND (last_used_at IS NULL OR last_used_at <= $3)", tableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, usedAt, id, notBefore)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) Revoke(ctx context.Context, id string, revokedAt int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET revoked_at = $1, updated_at = $1 WHERE id = $2 AND revoked_at IS NULL", tableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, revokedAt, id)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"database/sql"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/service"
+)
+
+// Provider contains and initializes the components of the package
+type Provider struct {
+	UseCase    apikey.UseCase
+	Repository apikey.Repository
+}
+
+// New initializes a Provider
+func New(db *sql.DB, opts ...service.Option) Provider {
+	r := postgres.NewStorage(db)
+	u := service.NewService(r, opts...)
+	return Provider{UseCase: u, Repository: r}
+}
@@ -0,0 +1,26 @@
+package apikey
+
+import "context"
+
+// Repository defines the data persistance logic that needs to be implemented
+type Repository interface {
+	Create(ctx context.Context, k APIKey) (string, error)
+
+	// ReadMany reads the keys of the user, the latest first
+	ReadMany(ctx context.Context, userID string) ([]APIKey, error)
+
+	ReadOne(ctx context.Context, id string) (APIKey, error)
+
+	// ReadByPrefix reads the key of the prefix and the roles of its owner
+	ReadByPrefix(ctx context.Context, prefix string) (APIKey, []string, error)
+
+	// UpdateSecret replaces the prefix and the secret of an unrevoked key
+	UpdateSecret(ctx context.Context, id, prefix string, salt, hash []byte, updatedAt int64) (int64, error)
+
+	// UpdateLastUsed stores the use of the key unless a use
+	// after notBefore is stored already
+	UpdateLastUsed(ctx context.Context, id string, usedAt, notBefore int64) (int64, error)
+
+	// Revoke revokes the key if it is not revoked yet
+	Revoke(ctx context.Context, id string, revokedAt int64) (int64, error)
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+var (
+	errInvalidKey    = errorext.NewCustomError(http.StatusUnauthorized, errors.New(constant.InvalidAPIKey))
+	errNotManageable = errorext.NewCustomError(http.StatusForbidden, errors.New(constant.APIKeyNotManageable))
+	errNotFound      = errorext.NewCustomError(http.StatusNotFound, errorext.ErrNotFound)
+)
+
+// service implements the use case of the api keys
+// service contains the business logic as well as calls to the
+// repository to perform db operations
+type service struct {
+	repository apikey.Repository
+	// authorizer lets the managers of the users manage
+	// their keys, nil limits the callers to their own keys
+	authorizer rbac.Authorizer
+	now        func() time.Time
+}
+
+// Option configures the service
+type Option func(*service)
+
+// WithAuthorizer lets the callers granted users:manage read, rotate
+// and revoke the keys of the other users
+func WithAuthorizer(a rbac.Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
+}
+
+// WithTimeFunc sets the clock of the expiry and the last use
+func WithTimeFunc(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
+}
+
+// NewService initializes a new Service
+func NewService(r apikey.Repository, opts ...Option) *service {
+	s := &service{repository: r, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// caller returns the principal managing the keys, a key can't
+// manage the keys so that a leaked key can't mint new ones
+func (s *service) caller(ctx context.Context) (auth.Principal, error) {
+	p, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return p, err
+	}
+
+	if p.Method == auth.MethodAPIKey {
+		return p, errNotManageable
+	}
+
+	return p, nil
+}
+
+// readOwned reads the key of the caller, the keys of the other
+// users are not found unless the caller manages the users
+func (s *service) readOwned(ctx context.Context, id string) (apikey.APIKey, error) {
+	p, err := s.caller(ctx)
+	if err != nil {
+		return apikey.APIKey{}, err
+	}
+
+	k, err := s.repository.ReadOne(ctx, id)
+	if err != nil {
+		return k, errorext.BuildCustomError(err)
+	}
+
+	if k.UserID != p.Subject && (s.authorizer == nil || s.authorizer.Authorize(ctx, role.PermissionUsersManage, "") != nil) {
+		return apikey.APIKey{}, errNotFound
+	}
+
+	return k, nil
+}
+
+// validateScopes checks that the scopes are known permissions
+func validateScopes(scopes []string) error {
+	if len(scopes) > constant.APIKeyMaxScopes {
+		return errorext.NewCustomError(http.StatusBadRequest, fmt.Errorf("a key has at most %d scopes", constant.APIKeyMaxScopes))
+	}
+
+	for _, sc := range scopes {
+		if !slices.Contains(role.Permissions, sc) {
+			return errorext.NewCustomError(http.StatusBadRequest, fmt.Errorf("%s: %s", constant.UnknownScope, sc))
+		}
+	}
+
+	return nil
+}
+
+func (s *service) Create(ctx context.Context, payload apikey.CreateDTO) (apikey.Issued, error) {
+	p, err := s.caller(ctx)
+	if err != nil {
+		return apikey.Issued{}, err
+	}
+
+	err = validateScopes(payload.Scopes)
+	if err != nil {
+		return apikey.Issued{}, err
+	}
+
+	n := s.now().Unix()
+	if payload.ExpiresAt != 0 && payload.ExpiresAt <= n {
+		return apikey.Issued{}, errorext.NewCustomError(http.StatusBadRequest, errors.New("the expiry must be in the future"))
+	}
+
+	g, err := auth.NewAPIKey()
+	if err != nil {
+		return apikey.Issued{}, errorext.BuildCustomError(err)
+	}
+
+	scopes := slices.Clone(payload.Scopes)
+	slices.Sort(scopes)
+
+	k := apikey.APIKey{
+		UserID:    p.Subject,
+		Name:      payload.Name,
+		Prefix:    g.Prefix,
+		Salt:      g.Salt,
+		Hash:      g.Hash,
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: payload.ExpiresAt,
+		CreatedAt: n,
+		UpdatedAt: n,
+	}
+
+	k.ID, err = s.repository.Create(ctx, k)
+	if err != nil {
+		return apikey.Issued{}, errorext.BuildCustomError(err)
+	}
+
+	return apikey.Issued{APIKey: k, Key: g.Key}, nil
+}
+
+func (s *service) ReadMany(ctx context.Context) ([]apikey.APIKey, error) {
+	p, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.repository.ReadMany(ctx, p.Subject)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+func (s *service) ReadOne(ctx context.Context, id string) (apikey.APIKey, error) {
+	return s.readOwned(ctx, id)
+}
+
+func (s *service) Rotate(ctx context.Context, id string) (apikey.Issued, error) {
+	k, err := s.readOwned(ctx, id)
+	if err != nil {
+		return apikey.Issued{}, err
+	}
+
+	if k.RevokedAt != 0 {
+		return apikey.Issued{}, errorext.NewCustomError(http.StatusConflict, errors.New(constant.InvalidAPIKey))
+	}
+
+	g, err := auth.NewAPIKey()
+	if err != nil {
+		return apikey.Issued{}, errorext.BuildCustomError(err)
+	}
+
+	n := s.now().Unix()
+
+	rows, err := s.repository.UpdateSecret(ctx, id, g.Prefix, g.Salt, g.Hash, n)
+	if err != nil {
+		return apikey.Issued{}, errorext.BuildCustomError(err)
+	}
+
+	if rows == 0 {
+		// revoked concurrently
+		return apikey.Issued{}, errorext.NewCustomError(http.StatusConflict, errors.New(constant.InvalidAPIKey))
+	}
+
+	k.Prefix, k.Salt, k.Hash, k.UpdatedAt = g.Prefix, g.Salt, g.Hash, n
+
+	return apikey.Issued{APIKey: k, Key: g.Key}, nil
+}
+
+// Revoke revokes the key, revoking a revoked key returns it unchanged
+func (s *service) Revoke(ctx context.Context, id string) (apikey.APIKey, error) {
+	k, err := s.readOwned(ctx, id)
+	if err != nil {
+		return k, err
+	}
+
+	if k.RevokedAt != 0 {
+		return k, nil
+	}
+
+	n := s.now().Unix()
+
+	_, err = s.repository.Revoke(ctx, id, n)
+	if err != nil {
+		return k, errorext.BuildCustomError(err)
+	}
+
+	k.RevokedAt, k.UpdatedAt = n, n
+
+	return k, nil
+}
+
+func (s *service) Authenticate(ctx context.Context, key string) (auth.Principal, error) {
+	prefix, secret, ok := auth.ParseAPIKey(key)
+	if !ok {
+		return auth.Principal{}, errInvalidKey
+	}
+
+	k, roles, err := s.repository.ReadByPrefix(ctx, prefix)
+	if err != nil {
+		if errorext.ParseCustomError(err).Code() == http.StatusNotFound {
+			return auth.Principal{}, errInvalidKey
+		}
+
+		return auth.Principal{}, errorext.BuildCustomError(err)
+	}
+
+	n := s.now()
+	if !auth.VerifyAPIKeySecret(secret, k.Salt, k.Hash) || !k.Active(n.Unix()) {
+		return auth.Principal{}, errInvalidKey
+	}
+
+	if n.Unix()-k.LastUsedAt >= constant.APIKeyLastUsedInterval {
+		s.touch(ctx, k.ID, n.Unix())
+	}
+
+	p := auth.Principal{
+		Subject: k.UserID,
+		Method:  auth.MethodAPIKey,
+		Scopes:  k.Scopes,
+		Roles:   roles,
+		Claims:  map[string]any{"kid": k.ID},
+	}
+
+	if k.ExpiresAt != 0 {
+		p.ExpiresAt = time.Unix(k.ExpiresAt, 0)
+	}
+
+	return p, nil
+}
+
+// touch stores the last use of the key, a failure is logged
+// the conditional update keeps the concurrent uses to one write
+func (s *service) touch(ctx context.Context, id string, n int64) {
+	_, err := s.repository.UpdateLastUsed(ctx, id, n, n-constant.APIKeyLastUsedInterval)
+	if err != nil {
+		log.Printf("update last use of api key %s returned error: %v", id, err)
+	}
+}
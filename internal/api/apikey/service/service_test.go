@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+// clock is a settable time of the expiry and the last use
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func expectCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	if err == nil || errorext.ParseCustomError(err).Code() != code {
+		t.Fatalf("expected %d, got %v", code, err)
+	}
+}
+
+func TestService(t *testing.T) {
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+	r := mock.NewMemoryStorage()
+	r.Roles["user-1"] = []string{role.Staff}
+
+	policy := rbac.NewPolicy(role.Grants(role.Defaults))
+	s := service.NewService(r, service.WithAuthorizer(policy), service.WithTimeFunc(c.Now))
+
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1", Method: auth.MethodJWT, Roles: []string{role.Staff}})
+	other := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-2", Method: auth.MethodJWT, Roles: []string{role.Customer}})
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin-1", Method: auth.MethodJWT, Roles: []string{role.Admin}})
+
+	issued, err := s.Create(owner, apikey.CreateDTO{Name: "partner", Scopes: []string{role.PermissionProductsRead, role.PermissionProductsRead}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if issued.Key == "" || len(issued.Scopes) != 1 || issued.UserID != "user-1" {
+		t.Fatalf("unexpected key %+v", issued)
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		tests := []struct {
+			name string
+			ctx  context.Context
+			dto  apikey.CreateDTO
+			code int
+		}{
+			{name: "unknown scope", ctx: owner, dto: apikey.CreateDTO{Name: "x", Scopes: []string{"products:all"}}, code: http.StatusBadRequest},
+			{name: "past expiry", ctx: owner, dto: apikey.CreateDTO{Name: "x", Scopes: []string{role.PermissionProductsRead}, ExpiresAt: c.now.Unix()}, code: http.StatusBadRequest},
+			{name: "anonymous", ctx: context.Background(), dto: apikey.CreateDTO{Name: "x", Scopes: []string{role.PermissionProductsRead}}, code: http.StatusUnauthorized},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := s.Create(tc.ctx, tc.dto)
+				expectCode(t, err, tc.code)
+			})
+		}
+	})
+
+	t.Run("Authenticate", func(t *testing.T) {
+		p, err := s.Authenticate(context.Background(), issued.Key)
+		if err != nil {
+			t.Fatalf("authenticate: %v", err)
+		}
+
+		if p.Subject != "user-1" || p.Method != auth.MethodAPIKey || !p.HasRole(role.Staff) || !p.HasScope(role.PermissionProductsRead) {
+			t.Fatalf("unexpected principal %+v", p)
+		}
+
+		k, _ := r.ReadOne(context.Background(), issued.ID)
+		if k.LastUsedAt != c.now.Unix() {
+			t.Errorf("expected the last use to be stored, got %d", k.LastUsedAt)
+		}
+
+		// the key is limited to its scopes, the role grants the write
+		ctx := auth.WithPrincipal(context.Background(), p)
+		if err := policy.Authorize(ctx, role.PermissionProductsRead, ""); err != nil {
+			t.Errorf("expected the scope to be allowed, got %v", err)
+		}
+
+		expectCode(t, policy.Authorize(ctx, role.PermissionProductsWrite, ""), http.StatusForbidden)
+
+		// a key can't manage the keys
+		_, err = s.Create(ctx, apikey.CreateDTO{Name: "x", Scopes: []string{role.PermissionProductsRead}})
+		expectCode(t, err, http.StatusForbidden)
+
+		_, err = s.Authenticate(context.Background(), issued.Key[:len(issued.Key)-1]+"0")
+		expectCode(t, err, http.StatusUnauthorized)
+	})
+
+	t.Run("ownership", func(t *testing.T) {
+		_, err := s.ReadOne(other, issued.ID)
+		expectCode(t, err, http.StatusNotFound)
+
+		if _, err := s.ReadOne(admin, issued.ID); err != nil {
+			t.Errorf("expected the manager to read the key, got %v", err)
+		}
+
+		keys, err := s.ReadMany(other)
+		if err != nil || len(keys) != 0 {
+			t.Errorf("expected no keys, got %v %v", keys, err)
+		}
+	})
+
+	t.Run("Rotate", func(t *testing.T) {
+		rotated, err := s.Rotate(owner, issued.ID)
+		if err != nil {
+			t.Fatalf("rotate: %v", err)
+		}
+
+		if rotated.Key == issued.Key || rotated.Prefix == issued.Prefix || rotated.ID != issued.ID {
+			t.Fatalf("expected a new secret, got %+v", rotated)
+		}
+
+		_, err = s.Authenticate(context.Background(), issued.Key)
+		expectCode(t, err, http.StatusUnauthorized)
+
+		if _, err := s.Authenticate(context.Background(), rotated.Key); err != nil {
+			t.Errorf("expected the rotated key to authenticate, got %v", err)
+		}
+
+		issued = rotated
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		e, err := s.Create(owner, apikey.CreateDTO{Name: "short", Scopes: []string{role.PermissionProductsRead}, ExpiresAt: c.now.Add(time.Hour).Unix()})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		later := service.NewService(r, service.WithTimeFunc(func() time.Time { return c.now.Add(2 * time.Hour) }))
+
+		_, err = later.Authenticate(context.Background(), e.Key)
+		expectCode(t, err, http.StatusUnauthorized)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		k, err := s.Revoke(owner, issued.ID)
+		if err != nil {
+			t.Fatalf("revoke: %v", err)
+		}
+
+		if k.RevokedAt == 0 {
+			t.Fatal("expected the key to be revoked")
+		}
+
+		_, err = s.Authenticate(context.Background(), issued.Key)
+		expectCode(t, err, http.StatusUnauthorized)
+
+		_, err = s.Rotate(owner, issued.ID)
+		expectCode(t, err, http.StatusConflict)
+	})
+}
@@ -0,0 +1,30 @@
+package apikey
+
+import (
+	"context"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+// UseCase manages the keys of the calling user, the keys of
+// other users need the users:manage permission
+type UseCase interface {
+	// Create creates a key of the caller, the scopes are
+	// permissions, see role.Permissions
+	Create(ctx context.Context, payload CreateDTO) (Issued, error)
+
+	// ReadMany reads the keys of the caller
+	ReadMany(ctx context.Context) ([]APIKey, error)
+
+	ReadOne(ctx context.Context, id string) (APIKey, error)
+
+	// Rotate replaces the secret of the key, the old
+	// secret does not authenticate afterwards
+	Rotate(ctx context.Context, id string) (Issued, error)
+
+	Revoke(ctx context.Context, id string) (APIKey, error)
+
+	// Authenticate returns the principal of the key, the subject is
+	// the owner with its roles and the scopes are the ones of the key
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
@@ -1,46 +1,150 @@
 package api
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
 	"os"
 	"time"
 
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/interceptor"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/server"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
-// App contains the configuration and server
+// App contains the configuration and servers
 type App struct {
 	cfg *config
 	srv *server.Server
+	// grpcSrv serves the modules over grpc, nil if disabled
+	grpcSrv *server.GRPCServer
 }
 
 // NewApp creates App
 func NewApp() *App {
 	a := &App{cfg: NewConfig()}
 	a.initServer()
+	a.initGRPCServer()
 	a.configureGracefulShutdown()
 	return a
 }
 
 // initServer initializes the server
 func (a *App) initServer() {
-	a.srv = server.NewServer(
-		":"+os.Getenv("PORT"),
-		a.cfg.router.Mux,
-		server.WithReadTimeout(constant.ServerReadTimeout*time.Second),
-		server.WithReadHeaderTimeout(constant.ServerReadHeaderTimeout*time.Second),
-		server.WithWriteTimeout(constant.ServerWriteTimeout*time.Second),
+	opts := []server.Option{
+		server.WithReadTimeout(constant.ServerReadTimeout * time.Second),
+		server.WithReadHeaderTimeout(constant.ServerReadHeaderTimeout * time.Second),
+		server.WithWriteTimeout(constant.ServerWriteTimeout * time.Second),
+		// the change feeds are ended before the shutdown waits for them
+		server.WithOnShutdown(a.cfg.feed.Close),
+	}
+
+	tlsOpts, err := tlsOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("init tls: %v", err)
+	}
+
+	a.srv = server.NewServer(":"+os.Getenv("PORT"), a.cfg.router.Mux, append(opts, tlsOpts...)...)
+}
+
+// tlsOptionsFromEnv returns the tls options of the server
+// TLS_CERT_FILE and TLS_KEY_FILE enable https, TLS_CLIENT_CA_FILE
+// verifies the client certificates, TLS_CLIENT_AUTH is require
+// to reject the clients without one or optional (default)
+func tlsOptionsFromEnv() ([]server.Option, error) {
+	cert, key, ca := os.Getenv("TLS_CERT_FILE"), os.Getenv("TLS_KEY_FILE"), os.Getenv("TLS_CLIENT_CA_FILE")
+	if cert == "" && key == "" {
+		if ca != "" {
+			return nil, errors.New("TLS_CLIENT_CA_FILE needs TLS_CERT_FILE and TLS_KEY_FILE")
+		}
+
+		return nil, nil
+	}
+
+	if cert == "" || key == "" {
+		return nil, errors.New("https needs TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	opts := []server.Option{server.WithTLS(cert, key)}
+	if ca == "" {
+		return opts, nil
+	}
+
+	var clientAuth tls.ClientAuthType
+
+	switch v := os.Getenv("TLS_CLIENT_AUTH"); v {
+	case "", "optional":
+		clientAuth = tls.VerifyClientCertIfGiven
+	case "require":
+		clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown TLS_CLIENT_AUTH: %s", v)
+	}
+
+	return append(opts, server.WithClientCA(ca, clientAuth)), nil
+}
+
+// initGRPCServer initializes the grpc server on GRPC_PORT
+// and registers the grpc services of the modules, it is
+// disabled if GRPC_PORT is not set
+func (a *App) initGRPCServer() {
+	port := os.Getenv("GRPC_PORT")
+	if port == "" {
+		return
+	}
+
+	unary := []grpc.UnaryServerInterceptor{interceptor.Recovery, interceptor.Logging}
+	stream := []grpc.StreamServerInterceptor{interceptor.StreamRecovery, interceptor.StreamLogging}
+
+	if a.cfg.verifier != nil {
+		// the health checks are not authenticated
+		authn := interceptor.NewAuthenticator(a.cfg.verifier, healthpb.Health_ServiceDesc.ServiceName)
+		unary = append(unary, authn.Unary)
+		stream = append(stream, authn.Stream)
+	}
+
+	a.grpcSrv = server.NewGRPCServer(
+		":"+port,
+		constant.GRPCShutdownTimeout*time.Second,
+		grpc.ChainUnaryInterceptor(append(unary, interceptor.Validation)...),
+		grpc.ChainStreamInterceptor(stream...),
 	)
+
+	if err := a.cfg.registry.RegisterGRPC(a.grpcSrv.Registrar()); err != nil {
+		log.Fatalf("register grpc services: %v", err)
+	}
 }
 
 // configureGracefulShutdown configures graceful shutdown
 func (a *App) configureGracefulShutdown() {
 	a.srv.ConfigureGracefulShutdown(func() {
+		// the grpc calls may use the modules too
+		if a.grpcSrv != nil {
+			a.grpcSrv.Shutdown()
+		}
+
+		// stop the modules before closing the shared db client
+		if err := a.cfg.registry.Stop(context.Background()); err != nil {
+			log.Printf("stop modules: %v", err)
+		}
+
 		a.cfg.dbClient.Close()
 	})
 }
 
 // Start starts the server
 func (a *App) Start() {
+	if err := a.cfg.registry.Start(context.Background()); err != nil {
+		log.Fatalf("start modules: %v", err)
+	}
+
+	if a.grpcSrv != nil {
+		go a.grpcSrv.Start()
+	}
+
 	a.srv.Start()
 }
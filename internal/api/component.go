@@ -1,22 +1,47 @@
 package api
 
 import (
-	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
-	productprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/product/provider"
-	userprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/user/provider"
+	"log"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
 )
 
-// initComponents initializes application components
+// initComponents registers the application modules
+// and mounts their routes
 func initComponents(cfg *config) {
-	productProvider := productprovider.New(cfg.dbClient.DB())
+	cfg.registry = module.NewRegistry()
+
+	pagination, err := modules.PaginationPoliciesFromEnv()
+	if err != nil {
+		log.Fatalf("pagination policies: %v", err)
+	}
+
+	deps := modules.Deps{
+		DB:          cfg.dbClient.DB(),
+		Validater:   cfg.validater,
+		Versioning:  cfg.router.Versioning(),
+		Idempotency: cfg.idempotency,
+		Cache:       modules.CachePoliciesFromEnv(),
+		Mux:         cfg.router.Mux,
+		Pagination:  pagination,
+		Events:      event.NewBus(),
+		Feed:        cfg.feed,
+		Credentials: cfg.credentials,
+		Policy:      cfg.policy,
+		RateLimits:  cfg.rateLimits,
+		Encryptor:   cfg.keyRing,
+	}
 
-	userProvider := userprovider.New(cfg.dbClient.DB())
+	err = modules.Register(cfg.registry, deps)
+	if err != nil {
+		log.Fatalf("register modules: %v", err)
+	}
 
-	initRoutes(
-		cfg.router,
-		[]any{
-			handler.NewProduct(productProvider.UseCase, cfg.validater),
-			handler.NewUser(userProvider.UseCase, cfg.validater),
-		},
-	)
+	err = cfg.registry.Mount(cfg.router.Mux, constant.ApiPattern)
+	if err != nil {
+		log.Fatalf("mount modules: %v", err)
+	}
 }
@@ -1,10 +1,20 @@
 package api
 
 import (
+	"log"
 	"os"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/env"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
@@ -13,17 +23,44 @@ import (
 // config contains the components of the application
 // and configures them as required
 type config struct {
-	dbClient  *sqlext.Client
-	router    *router.Router
-	validater validatorext.Validater
+	dbClient    *sqlext.Client
+	router      *router.Router
+	validater   validatorext.Validater
+	registry    *module.Registry
+	idempotency *idempotency.Idempotency
+	feed        *feed.Broker
+	// verifier verifies the bearer tokens, nil if
+	// the authentication is disabled
+	verifier *auth.Verifier
+	// credentials issue the sessions of the auth routes
+	credentials modules.Credentials
+	// policy authorizes the authenticated callers with
+	// their roles, nil if the authentication is disabled
+	policy *rbac.Policy
+	// clientCertRoles contains the roles of the subjects
+	// of the tls client certificates
+	clientCertRoles map[string][]string
+	// rateLimits limit the requests, nil if disabled
+	rateLimits *modules.RateLimits
+	// cors is the cross origin policy of the routes
+	// and of the websockets of the feeds
+	cors middleware.CORSPolicy
+	// keyRing encrypts the personal data at rest
+	keyRing *envelope.KeyRing
 }
 
 func NewConfig() *config {
 	c := new(config)
 	c.loadEnv()
 	c.initDB()
+	c.initEncryption()
+	c.initAuth()
+	c.initRateLimits()
+	c.initCORS()
 	c.initRouter()
 	c.initValidator()
+	c.initIdempotency()
+	c.initFeed()
 
 	// init components
 	initComponents(c)
@@ -50,9 +87,123 @@ func (c *config) initDB() {
 	c.dbClient = sqlext.GetInstance(opts)
 }
 
+// initEncryption initializes the key ring of the personal data
+func (c *config) initEncryption() {
+	k, err := modules.NewKeyRingFromEnv()
+	if err != nil {
+		log.Fatalf("init encryption: %v", err)
+	}
+
+	c.keyRing = k
+}
+
+// initAuth initializes the verifier of the bearer tokens,
+// the policy of the roles and the signer of the access tokens
+func (c *config) initAuth() {
+	v, err := modules.NewVerifierFromEnv()
+	if err != nil {
+		log.Fatalf("init auth: %v", err)
+	}
+
+	if v == nil {
+		log.Println("the authentication is disabled, AUTH_ENABLED=false")
+	}
+
+	c.verifier = v
+
+	if v != nil {
+		// the role module loads the stored roles on start
+		c.policy = rbac.NewPolicy(role.Grants(role.Defaults))
+	}
+
+	c.credentials, err = modules.NewCredentialsFromEnv()
+	if err != nil {
+		log.Fatalf("init credentials: %v", err)
+	}
+
+	if c.credentials.Signer == nil {
+		log.Println("the auth routes are disabled, they need JWT_SECRET")
+	}
+
+	c.clientCertRoles, err = modules.NewClientCertRolesFromEnv()
+	if err != nil {
+		log.Fatalf("init client certificate roles: %v", err)
+	}
+}
+
+// initRateLimits initializes the rate limits of the requests
+func (c *config) initRateLimits() {
+	l, err := modules.NewRateLimitsFromEnv(c.dbClient.DB())
+	if err != nil {
+		log.Fatalf("init rate limits: %v", err)
+	}
+
+	if l == nil {
+		log.Println("the rate limits are disabled, RATE_LIMIT_ENABLED=false")
+	}
+
+	c.rateLimits = l
+}
+
+// initCORS initializes the cross origin policy
+func (c *config) initCORS() {
+	p, err := modules.NewCORSFromEnv()
+	if err != nil {
+		log.Fatalf("init cors: %v", err)
+	}
+
+	c.cors = p
+}
+
 // initRouter initializes router
 func (c *config) initRouter() {
-	c.router = router.NewRouter()
+	v, err := modules.NewVersioning()
+	if err != nil {
+		log.Fatalf("init versioning: %v", err)
+	}
+
+	opts := []router.Option{router.WithVersioning(v), router.WithTimeoutExempt(modules.IsLongRunning)}
+	if c.rateLimits != nil {
+		opts = append(opts, router.WithIPRateLimit(c.rateLimits.IPMiddleware()))
+	}
+
+	if c.verifier != nil {
+		opts = append(opts, router.WithAuthentication(modules.Authentication(c.verifier, c.dbClient.DB(), c.clientCertRoles)))
+	}
+
+	if c.rateLimits != nil {
+		opts = append(opts, router.WithRateLimit(c.rateLimits.Middleware()))
+	}
+
+	opts = append(opts, router.WithCORS(middleware.CORS(c.cors)))
+
+	if s := modules.NewSecurityHeadersFromEnv(); s != nil {
+		opts = append(opts, router.WithSecurityHeaders(middleware.Secure(*s)))
+	} else {
+		log.Println("the security headers are disabled, SECURITY_HEADERS_ENABLED=false")
+	}
+
+	c.router = router.NewRouter(opts...)
+}
+
+// initIdempotency initializes the idempotency of the create routes
+func (c *config) initIdempotency() {
+	i, err := modules.NewIdempotencyFromEnv(c.dbClient.DB())
+	if err != nil {
+		log.Fatalf("init idempotency: %v", err)
+	}
+
+	c.idempotency = i
+}
+
+// initFeed initializes the broker of the change feeds
+func (c *config) initFeed() {
+	b, err := modules.NewFeedFromEnv(c.dbClient.DB(), c.cors)
+	if err != nil {
+		log.Fatalf("init feed: %v", err)
+	}
+
+	c.feed = b
 }
 
 // initValidator initializes validator
@@ -63,3 +214,7 @@ func (c *config) initValidator() {
 func (c *config) Router() *router.Router {
 	return c.router
 }
+
+func (c *config) Registry() *module.Registry {
+	return c.registry
+}
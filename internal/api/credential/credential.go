@@ -0,0 +1,53 @@
+package credential
+
+// Credential is the email and password of a user
+// the email is stored lower case
+type Credential struct {
+	UserID       string
+	Email        string
+	PasswordHash string
+	// EmailVerifiedAt is the time the email was verified, 0 if it
+	// was not, only a verified email links the external identities
+	EmailVerifiedAt int64
+	CreatedAt       int64
+	UpdatedAt       int64
+}
+
+// Identity links the identity of a user at an external
+// provider to the user, the subject is unique per provider
+type Identity struct {
+	Provider string
+	Subject  string
+	UserID   string
+	// Email is the lower case verified email of the identity
+	Email     string
+	CreatedAt int64
+}
+
+// RefreshToken is a refresh token of a session, only the hash
+// of the token is stored, the tokens rotated from the token of
+// a login share its family
+type RefreshToken struct {
+	ID        string
+	UserID    string
+	FamilyID  string
+	TokenHash []byte
+	ExpiresAt int64
+	// RevokedAt is 0 if the token is not revoked
+	RevokedAt int64
+	// ReplacedBy is the id of the token the token was
+	// rotated to, empty if it was not used
+	ReplacedBy string
+	CreatedAt  int64
+}
+
+// Used reports if the token was rotated or revoked, presenting
+// a used token is a reuse of a possibly stolen token
+func (t RefreshToken) Used() bool {
+	return t.RevokedAt != 0 || t.ReplacedBy != ""
+}
+
+// Expired reports if the token is expired at now
+func (t RefreshToken) Expired(now int64) bool {
+	return t.ExpiresAt <= now
+}
@@ -0,0 +1,37 @@
+package credential
+
+type SignupDTO struct {
+	Name     string
+	Address  *string
+	Email    string
+	Password string
+}
+
+type LoginDTO struct {
+	Email    string
+	Password string
+	// IP is the address of the client, the failed logins are
+	// counted per address too, empty if it is unknown
+	IP string
+}
+
+// ExternalLoginDTO is the identity verified by an
+// external provider, ex: the claims of an ID token
+type ExternalLoginDTO struct {
+	Provider string
+	Subject  string
+	Email    string
+	// EmailVerified reports if the provider verified the email
+	EmailVerified bool
+	Name          string
+}
+
+// Session contains the tokens issued by a signup,
+// a login or a refresh
+type Session struct {
+	UserID       string
+	AccessToken  string
+	RefreshToken string
+	// ExpiresIn is the lifetime of the access token in seconds
+	ExpiresIn int64
+}
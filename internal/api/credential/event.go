@@ -0,0 +1,19 @@
+package credential
+
+// the types of the audit events of the lockouts, the data is a LockoutEvent
+const (
+	EventLocked   = "credential.locked"
+	EventUnlocked = "credential.unlocked"
+)
+
+// LockoutEvent is the data of the lockout events
+type LockoutEvent struct {
+	Key      string
+	Failures int
+	// LockedUntil is the end of the lock of EventLocked
+	LockedUntil int64
+	// IP is the address of the client of the failure locking the key
+	IP string
+	// Actor is the subject of the caller unlocking the key
+	Actor string
+}
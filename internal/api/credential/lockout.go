@@ -0,0 +1,153 @@
+package credential
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// retryAfterKey is the additional data of the lockout errors
+// containing the seconds until the next login is allowed
+const retryAfterKey = "retryAfter"
+
+// the kinds of the keys the failed logins are counted by
+const (
+	// LockoutAccount keys the failures by the lower case email
+	// the unknown emails are counted too
+	LockoutAccount = "account"
+	// LockoutIP keys the failures by the address of the client
+	LockoutIP = "ip"
+)
+
+// Lockout contains the failed logins of a key and its lock
+type Lockout struct {
+	// Key is <kind>:<value>, ex: account:jane@example.com, see LockoutKey
+	Key           string
+	Failures      int
+	LastFailureAt int64
+	// LockedUntil is 0 if the key was not locked
+	LockedUntil int64
+}
+
+// Locked reports if the key is locked at now
+func (l Lockout) Locked(now int64) bool {
+	return l.LockedUntil > now
+}
+
+// LockoutKey returns the key of the value of the kind
+func LockoutKey(kind, value string) string {
+	return kind + ":" + value
+}
+
+// ParseLockoutKey returns the kind and the value of the key
+// ok is false if the kind is unknown or the value is empty
+func ParseLockoutKey(key string) (kind, value string, ok bool) {
+	kind, value, ok = strings.Cut(key, ":")
+	if !ok || value == "" || (kind != LockoutAccount && kind != LockoutIP) {
+		return "", "", false
+	}
+
+	return kind, value, true
+}
+
+// LockoutPolicy limits the failed logins of a key, the zero
+// policy does not limit them
+type LockoutPolicy struct {
+	// Free is the number of the failures without a delay
+	Free int
+	// Delay is the wait after the first failure over Free before
+	// the next login, it doubles with every failure up to MaxDelay,
+	// a MaxDelay below Delay does not double it
+	Delay    time.Duration
+	MaxDelay time.Duration
+	// Threshold is the number of the failures locking the key
+	// for Duration, 0 does not lock it
+	Threshold int
+	Duration  time.Duration
+	// Window is the time the failures are counted for, a failure
+	// after Window without a failure starts a new count, 0 does
+	// not count them
+	Window time.Duration
+}
+
+// DefaultAccountLockout returns the policy of the accounts
+func DefaultAccountLockout() LockoutPolicy {
+	return LockoutPolicy{
+		Free:      constant.LockoutAccountFree,
+		Delay:     constant.LockoutDelay * time.Second,
+		MaxDelay:  constant.LockoutMaxDelay * time.Second,
+		Threshold: constant.LockoutAccountThreshold,
+		Duration:  constant.LockoutDuration * time.Minute,
+		Window:    constant.LockoutWindow * time.Minute,
+	}
+}
+
+// DefaultIPLockout returns the policy of the client addresses, it
+// allows more failures as the clients can share an address, ex: a NAT
+func DefaultIPLockout() LockoutPolicy {
+	return LockoutPolicy{
+		Free:      constant.LockoutIPFree,
+		Delay:     constant.LockoutDelay * time.Second,
+		MaxDelay:  constant.LockoutMaxDelay * time.Second,
+		Threshold: constant.LockoutIPThreshold,
+		Duration:  constant.LockoutDuration * time.Minute,
+		Window:    constant.LockoutWindow * time.Minute,
+	}
+}
+
+// Enabled reports if the policy limits the failures
+func (p LockoutPolicy) Enabled() bool {
+	return p.Window > 0 && (p.Delay > 0 || p.Threshold > 0)
+}
+
+// Wait returns the delay after the failures before the next login
+func (p LockoutPolicy) Wait(failures int) time.Duration {
+	n := failures - p.Free
+	if n <= 0 || p.Delay <= 0 {
+		return 0
+	}
+
+	limit := max(p.MaxDelay, p.Delay)
+
+	d := p.Delay
+	for i := 1; i < n && d < limit; i++ {
+		d *= 2
+	}
+
+	return min(d, limit)
+}
+
+// RetryAt returns the unix time the next login of the key is allowed
+// at, it is at most now if the login is allowed at now
+func (p LockoutPolicy) RetryAt(l Lockout, now int64) int64 {
+	if l.Locked(now) {
+		return l.LockedUntil
+	}
+
+	if l.LastFailureAt+int64(p.Window.Seconds()) <= now {
+		// the failures are not counted anymore
+		return now
+	}
+
+	return l.LastFailureAt + int64(p.Wait(l.Failures).Seconds())
+}
+
+// NewLockoutError returns the 429 error of a delayed or a locked
+// login, the next login is allowed after retryAfter seconds
+func NewLockoutError(msg string, retryAfter int64) error {
+	err := errorext.NewCustomError(http.StatusTooManyRequests, errors.New(msg))
+	err.SetAdditionalErrData(map[string]any{retryAfterKey: retryAfter})
+
+	return err
+}
+
+// RetryAfter returns the seconds until the next login of a lockout
+// error, ok is false for the other errors
+func RetryAfter(err error) (seconds int64, ok bool) {
+	seconds, ok = errorext.ParseCustomError(err).AdditionalErrData()[retryAfterKey].(int64)
+	return seconds, ok
+}
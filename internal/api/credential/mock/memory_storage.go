@@ -0,0 +1,298 @@
+package mock
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// MemoryStorage is a mock storage
+// can be used to mock the repository
+// for service testing, InTx does not
+// roll back the changes of a failed fn
+type MemoryStorage struct {
+	mu          sync.Mutex
+	seq         int
+	credentials map[string]credential.Credential
+	tokens      map[string]*credential.RefreshToken
+	// identities are in the order of their creation
+	identities []credential.Identity
+	lockouts   map[string]credential.Lockout
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		credentials: make(map[string]credential.Credential),
+		tokens:      make(map[string]*credential.RefreshToken),
+		lockouts:    make(map[string]credential.Lockout),
+	}
+}
+
+func (s *MemoryStorage) nextID() string {
+	s.seq++
+	return strconv.Itoa(s.seq)
+}
+
+func (s *MemoryStorage) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (s *MemoryStorage) CreateCredential(ctx context.Context, c credential.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.credentials[c.Email]; ok {
+		return errorext.NewCustomError(http.StatusConflict, errors.New(constant.EmailTaken))
+	}
+
+	s.credentials[c.Email] = c
+
+	return nil
+}
+
+func (s *MemoryStorage) ReadCredential(ctx context.Context, email string) (credential.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.credentials[email]
+	if !ok {
+		return c, errorext.BuildDBError(sql.ErrNoRows)
+	}
+
+	return c, nil
+}
+
+func (s *MemoryStorage) CreateIdentity(ctx context.Context, i credential.Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.identities {
+		if e.Provider == i.Provider && e.Subject == i.Subject {
+			return errorext.NewCustomError(http.StatusConflict, errors.New(constant.IdentityLinked))
+		}
+	}
+
+	s.identities = append(s.identities, i)
+
+	return nil
+}
+
+func (s *MemoryStorage) ReadIdentity(ctx context.Context, provider, subject string) (credential.Identity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.identities {
+		if e.Provider == provider && e.Subject == subject {
+			return e, nil
+		}
+	}
+
+	return credential.Identity{}, errorext.BuildDBError(sql.ErrNoRows)
+}
+
+func (s *MemoryStorage) ReadIdentityByEmail(ctx context.Context, email string) (credential.Identity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.identities {
+		if e.Email == email {
+			return e, nil
+		}
+	}
+
+	return credential.Identity{}, errorext.BuildDBError(sql.ErrNoRows)
+}
+
+func (s *MemoryStorage) UpdatePasswordHash(ctx context.Context, userID, hash string, updatedAt int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, c := range s.credentials {
+		if c.UserID == userID {
+			c.PasswordHash = hash
+			c.UpdatedAt = updatedAt
+			s.credentials[k] = c
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (s *MemoryStorage) CreateRefreshToken(ctx context.Context, t credential.RefreshToken) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t.ID = s.nextID()
+	s.tokens[t.ID] = &t
+
+	return t.ID, nil
+}
+
+func (s *MemoryStorage) ReadRefreshToken(ctx context.Context, hash []byte) (credential.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tokens {
+		if string(t.TokenHash) == string(hash) {
+			return *t, nil
+		}
+	}
+
+	return credential.RefreshToken{}, errorext.BuildDBError(sql.ErrNoRows)
+}
+
+func (s *MemoryStorage) ReplaceRefreshToken(ctx context.Context, id, replacedBy string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[id]
+	if !ok || t.Used() {
+		return 0, nil
+	}
+
+	t.ReplacedBy = replacedBy
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) RevokeFamily(ctx context.Context, familyID string, revokedAt int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, t := range s.tokens {
+		if t.FamilyID == familyID && t.RevokedAt == 0 {
+			t.RevokedAt = revokedAt
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (s *MemoryStorage) ReadLockouts(ctx context.Context, keys ...string) ([]credential.Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var d []credential.Lockout
+	for _, k := range keys {
+		if l, ok := s.lockouts[k]; ok {
+			d = append(d, l)
+		}
+	}
+
+	return d, nil
+}
+
+func (s *MemoryStorage) ReadLocked(ctx context.Context, now int64) ([]credential.Lockout, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var d []credential.Lockout
+	for _, l := range s.lockouts {
+		if l.Locked(now) {
+			d = append(d, l)
+		}
+	}
+
+	slices.SortFunc(d, func(a, b credential.Lockout) int {
+		return cmp.Or(cmp.Compare(a.LockedUntil, b.LockedUntil), strings.Compare(a.Key, b.Key))
+	})
+
+	return d, nil
+}
+
+func (s *MemoryStorage) ReserveAttempt(ctx context.Context, seen credential.Lockout, at, resetBefore int64) (credential.Lockout, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lockouts[seen.Key]
+	if ok != (seen.LastFailureAt > 0) || (ok && l != seen) {
+		return credential.Lockout{}, false, nil
+	}
+
+	l.Key = seen.Key
+
+	if l.LastFailureAt < resetBefore {
+		l.Failures = 0
+	}
+
+	l.Failures++
+	l.LastFailureAt = at
+	s.lockouts[l.Key] = l
+
+	return l, true, nil
+}
+
+func (s *MemoryStorage) ReleaseAttempt(ctx context.Context, key string, lockedUntil int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lockouts[key]
+	if !ok {
+		return 0, nil
+	}
+
+	l.Failures = max(l.Failures-1, 0)
+	if l.LockedUntil == lockedUntil {
+		l.LockedUntil = 0
+	}
+
+	s.lockouts[key] = l
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) Lock(ctx context.Context, key string, until, now int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.lockouts[key]
+	if !ok || l.Locked(now) {
+		return 0, nil
+	}
+
+	l.LockedUntil = until
+	s.lockouts[key] = l
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) DeleteLockout(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lockouts[key]; !ok {
+		return 0, nil
+	}
+
+	delete(s.lockouts, key)
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) DeleteExpiredLockouts(ctx context.Context, before, now int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for k, l := range s.lockouts {
+		if l.LastFailureAt < before && !l.Locked(now) {
+			delete(s.lockouts, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
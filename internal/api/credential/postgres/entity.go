@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// credentialColumns contains the columns of the credential in the select order
+var credentialColumns = []string{"user_id", "email", "password_hash", "email_verified_at", "created_at", "updated_at"}
+
+// tokenColumns contains the columns of the refresh token in the select order
+var tokenColumns = []string{"id", "user_id", "family_id", "token_hash", "expires_at", "revoked_at", "replaced_by", "created_at"}
+
+// identityColumns contains the columns of the identity in the select order
+var identityColumns = []string{"provider", "subject", "user_id", "email", "created_at"}
+
+// lockoutColumns contains the columns of the lockout in the select order
+var lockoutColumns = []string{"key", "failures", "last_failure_at", "locked_until"}
+
+// scanner is implemented by sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// a package private entity clone of the domain entity
+// revoked_at and replaced_by are null until the token is used
+type refreshTokenEntity struct {
+	id         string
+	userID     string
+	familyID   string
+	tokenHash  []byte
+	expiresAt  int64
+	revokedAt  sql.NullInt64
+	replacedBy sql.NullString
+	createdAt  int64
+}
+
+func scanCredential(s scanner) (credential.Credential, error) {
+	var c credential.Credential
+	if err := s.Scan(&c.UserID, &c.Email, &c.PasswordHash, &c.EmailVerifiedAt, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		log.Println("error: ", err)
+		return c, errorext.BuildDBError(err)
+	}
+
+	return c, nil
+}
+
+func scanIdentity(s scanner) (credential.Identity, error) {
+	var i credential.Identity
+	if err := s.Scan(&i.Provider, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt); err != nil {
+		log.Println("error: ", err)
+		return i, errorext.BuildDBError(err)
+	}
+
+	return i, nil
+}
+
+func scanRefreshToken(s scanner) (credential.RefreshToken, error) {
+	var e refreshTokenEntity
+	if err := s.Scan(&e.id, &e.userID, &e.familyID, &e.tokenHash, &e.expiresAt, &e.revokedAt, &e.replacedBy, &e.createdAt); err != nil {
+		log.Println("error: ", err)
+		return credential.RefreshToken{}, errorext.BuildDBError(err)
+	}
+
+	return credential.RefreshToken{
+		ID:         e.id,
+		UserID:     e.userID,
+		FamilyID:   e.familyID,
+		TokenHash:  e.tokenHash,
+		ExpiresAt:  e.expiresAt,
+		RevokedAt:  e.revokedAt.Int64,
+		ReplacedBy: e.replacedBy.String,
+		CreatedAt:  e.createdAt,
+	}, nil
+}
+
+func scanLockout(s scanner) (credential.Lockout, error) {
+	var l credential.Lockout
+	if err := s.Scan(&l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil); err != nil {
+		log.Println("error: ", err)
+		return l, errorext.BuildDBError(err)
+	}
+
+	return l, nil
+}
@@ -0,0 +1,268 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+const (
+	credentialTableName = "credentials"
+	tokenTableName      = "refresh_tokens"
+	identityTableName   = "external_identities"
+	lockoutTableName    = "login_lockouts"
+)
+
+// storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
+type storage struct {
+	db *sql.DB
+}
+
+func NewStorage(db *sql.DB) *storage {
+	return &storage{db: db}
+}
+
+func (s *storage) InTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return sqlext.RunInTx(ctx, s.db, fn)
+}
+
+func (s *storage) CreateCredential(ctx context.Context, c credential.Credential) error {
+	q := sqlext.BuildInsertQuery(credentialTableName, credentialColumns, "")
+
+	_, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, c.UserID, c.Email, c.PasswordHash, c.EmailVerifiedAt, c.CreatedAt, c.UpdatedAt)
+	if err != nil {
+		log.Printf("err: %v", err)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == errorext.SQLCodeUniqueViolation {
+			return errorext.NewCustomError(http.StatusConflict, errors.New(constant.EmailTaken))
+		}
+
+		return errorext.BuildDBError(err)
+	}
+
+	return nil
+}
+
+func (s *storage) ReadCredential(ctx context.Context, email string) (credential.Credential, error) {
+	q := sqlext.BuildSelectQuery(credentialTableName, credentialColumns, []string{"email"}, "")
+
+	return scanCredential(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, email))
+}
+
+func (s *storage) CreateIdentity(ctx context.Context, i credential.Identity) error {
+	q := sqlext.BuildInsertQuery(identityTableName, identityColumns, "")
+
+	_, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, i.Provider, i.Subject, i.UserID, i.Email, i.CreatedAt)
+	if err != nil {
+		log.Printf("err: %v", err)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == errorext.SQLCodeUniqueViolation {
+			return errorext.NewCustomError(http.StatusConflict, errors.New(constant.IdentityLinked))
+		}
+
+		return errorext.BuildDBError(err)
+	}
+
+	return nil
+}
+
+func (s *storage) ReadIdentity(ctx context.Context, provider, subject string) (credential.Identity, error) {
+	q := sqlext.BuildSelectQuery(identityTableName, identityColumns, []string{"provider", "subject"}, "")
+
+	return scanIdentity(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, provider, subject))
+}
+
+func (s *storage) ReadIdentityByEmail(ctx context.Context, email string) (credential.Identity, error) {
+	q := sqlext.BuildSelectQuery(identityTableName, identityColumns, []string{"email"}, "ORDER BY created_at LIMIT 1")
+
+	return scanIdentity(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, email))
+}
+
+func (s *storage) UpdatePasswordHash(ctx context.Context, userID, hash string, updatedAt int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET password_hash = $1, updated_at = $2 WHERE user_id = $3", credentialTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, hash, updatedAt, userID)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) CreateRefreshToken(ctx context.Context, t credential.RefreshToken) (string, error) {
+	var lastID string
+
+	q := sqlext.BuildInsertQuery(tokenTableName, []string{"user_id", "family_id", "token_hash", "expires_at", "created_at"}, "RETURNING id")
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, t.CreatedAt).Scan(&lastID)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return lastID, errorext.BuildDBError(err)
+	}
+
+	return lastID, nil
+}
+
+func (s *storage) ReadRefreshToken(ctx context.Context, hash []byte) (credential.RefreshToken, error) {
+	q := sqlext.BuildSelectQuery(tokenTableName, tokenColumns, []string{"token_hash"}, "")
+
+	return scanRefreshToken(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, hash))
+}
+
+// ReplaceRefreshToken only updates an unused token so that one of
+// the concurrent rotations of a token wins
+func (s *storage) ReplaceRefreshToken(ctx context.Context, id, replacedBy string) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET replaced_by = $1 WHERE id = $2 AND replaced_by IS NULL AND revoked_at IS NULL", tokenTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, replacedBy, id)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) RevokeFamily(ctx context.Context, familyID string, revokedAt int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL", tokenTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, revokedAt, familyID)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) ReadLockouts(ctx context.Context, keys ...string) ([]credential.Lockout, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE key = ANY($1)", strings.Join(lockoutColumns, ", "), lockoutTableName)
+
+	return s.readLockouts(ctx, q, keys)
+}
+
+func (s *storage) ReadLocked(ctx context.Context, now int64) ([]credential.Lockout, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE locked_until > $1 ORDER BY locked_until", strings.Join(lockoutColumns, ", "), lockoutTableName)
+
+	return s.readLockouts(ctx, q, now)
+}
+
+func (s *storage) readLockouts(ctx context.Context, q string, args ...any) ([]credential.Lockout, error) {
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	var d []credential.Lockout
+	for rows.Next() {
+		l, err := scanLockout(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		d = append(d, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	return d, nil
+}
+
+// ReserveAttempt counts the attempt in one statement which only
+// matches the seen lockout so that the concurrent attempts of a key
+// can't pass the same check, a key without a lockout is inserted
+func (s *storage) ReserveAttempt(ctx context.Context, seen credential.Lockout, at, resetBefore int64) (credential.Lockout, bool, error) {
+	var row *sql.Row
+
+	if seen.LastFailureAt == 0 {
+		q := fmt.Sprintf(`INSERT INTO %s (key, failures, last_failure_at) VALUES ($1, 1, $2)
+ON CONFLICT (key) DO NOTHING
+RETURNING %s`, lockoutTableName, strings.Join(lockoutColumns, ", "))
+
+		row = sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, seen.Key, at)
+	} else {
+		q := fmt.Sprintf(`UPDATE %s SET
+failures = CASE WHEN last_failure_at < $3 THEN 1 ELSE failures + 1 END,
+last_failure_at = $2
+WHERE key = $1 AND failures = $4 AND last_failure_at = $5 AND locked_until = $6
+RETURNING %s`, lockoutTableName, strings.Join(lockoutColumns, ", "))
+
+		row = sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, seen.Key, at, resetBefore, seen.Failures, seen.LastFailureAt, seen.LockedUntil)
+	}
+
+	var l credential.Lockout
+	if err := row.Scan(&l.Key, &l.Failures, &l.LastFailureAt, &l.LockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return l, false, nil
+		}
+
+		return l, false, errorext.BuildDBError(err)
+	}
+
+	return l, true, nil
+}
+
+// ReleaseAttempt only unlocks the key if the lock is of the attempt
+func (s *storage) ReleaseAttempt(ctx context.Context, key string, lockedUntil int64) (int64, error) {
+	q := fmt.Sprintf(`UPDATE %s SET
+failures = GREATEST(failures - 1, 0),
+locked_until = CASE WHEN locked_until = $2 THEN 0 ELSE locked_until END
+WHERE key = $1`, lockoutTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, key, lockedUntil)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+// Lock only locks an unlocked key so that one of the
+// concurrent failures reaching the threshold locks it
+func (s *storage) Lock(ctx context.Context, key string, until, now int64) (int64, error) {
+	q := fmt.Sprintf("UPDATE %s SET locked_until = $1 WHERE key = $2 AND locked_until <= $3", lockoutTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, until, key, now)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) DeleteLockout(ctx context.Context, key string) (int64, error) {
+	q := fmt.Sprintf("DELETE FROM %s WHERE key = $1", lockoutTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, key)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) DeleteExpiredLockouts(ctx context.Context, before, now int64) (int64, error) {
+	q := fmt.Sprintf("DELETE FROM %s WHERE last_failure_at < $1 AND locked_until <= $2", lockoutTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, before, now)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
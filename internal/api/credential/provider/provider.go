@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"database/sql"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+// Provider contains and initializes the components of the package
+type Provider struct {
+	UseCase    credential.UseCase
+	Repository credential.Repository
+}
+
+// New initializes a Provider, the users are created with users
+// and the access tokens are issued by signer
+func New(db *sql.DB, users user.Repository, h *auth.PasswordHasher, signer *auth.Signer, opts ...service.Option) Provider {
+	r := postgres.NewStorage(db)
+	u := service.NewService(r, users, h, signer, opts...)
+	return Provider{UseCase: u, Repository: r}
+}
@@ -0,0 +1,72 @@
+package credential
+
+import "context"
+
+// Repository defines the data persistance logic that needs to be implemented
+type Repository interface {
+	// InTx runs fn in a transaction, the repositories
+	// called with the ctx of fn join it
+	InTx(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// CreateCredential creates the credential of a user, a
+	// registered email is a conflict
+	CreateCredential(ctx context.Context, c Credential) error
+
+	// ReadCredential reads the credential of the lower case email
+	ReadCredential(ctx context.Context, email string) (Credential, error)
+
+	// CreateIdentity links an external identity to its user, a
+	// linked identity is a conflict
+	CreateIdentity(ctx context.Context, i Identity) error
+
+	// ReadIdentity reads the identity of the subject at the provider
+	ReadIdentity(ctx context.Context, provider, subject string) (Identity, error)
+
+	// ReadIdentityByEmail reads the first linked identity
+	// of the lower case email at any provider
+	ReadIdentityByEmail(ctx context.Context, email string) (Identity, error)
+
+	UpdatePasswordHash(ctx context.Context, userID, hash string, updatedAt int64) (int64, error)
+
+	CreateRefreshToken(ctx context.Context, t RefreshToken) (string, error)
+
+	// ReadRefreshToken reads the refresh token of the hash
+	ReadRefreshToken(ctx context.Context, hash []byte) (RefreshToken, error)
+
+	// ReplaceRefreshToken marks the token replaced by the token of
+	// replacedBy if it is not used yet, it returns the rows affected
+	// so 0 means that the token was used concurrently
+	ReplaceRefreshToken(ctx context.Context, id, replacedBy string) (int64, error)
+
+	// RevokeFamily revokes the unrevoked tokens of the family
+	RevokeFamily(ctx context.Context, familyID string, revokedAt int64) (int64, error)
+
+	// ReadLockouts reads the lockouts of the keys, the keys
+	// without a failure are missing
+	ReadLockouts(ctx context.Context, keys ...string) ([]Lockout, error)
+
+	// ReadLocked reads the lockouts locked at now
+	ReadLocked(ctx context.Context, now int64) ([]Lockout, error)
+
+	// ReserveAttempt counts a login attempt of the key at before its
+	// verification if the lockout of the key is still seen, a seen
+	// lockout without LastFailureAt is missing, the failures before
+	// resetBefore are not counted, it returns the lockout, ok is
+	// false if a concurrent attempt changed it since it was seen
+	ReserveAttempt(ctx context.Context, seen Lockout, at, resetBefore int64) (l Lockout, ok bool, err error)
+
+	// ReleaseAttempt takes back a successful attempt of the key
+	// and its lock if the attempt locked the key until lockedUntil
+	ReleaseAttempt(ctx context.Context, key string, lockedUntil int64) (int64, error)
+
+	// Lock locks the key until, it returns the rows affected
+	// so 0 means that the key was locked at now already
+	Lock(ctx context.Context, key string, until, now int64) (int64, error)
+
+	// DeleteLockout removes the failures and the lock of the key
+	DeleteLockout(ctx context.Context, key string) (int64, error)
+
+	// DeleteExpiredLockouts removes the lockouts unlocked at now
+	// without a failure since before
+	DeleteExpiredLockouts(ctx context.Context, before, now int64) (int64, error)
+}
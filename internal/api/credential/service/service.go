@@ -0,0 +1,821 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+)
+
+var (
+	errInvalidCredentials = errorext.NewCustomError(http.StatusUnauthorized, errors.New(constant.InvalidCredentials))
+	errInvalidToken       = errorext.NewCustomError(http.StatusUnauthorized, errors.New(constant.InvalidRefreshToken))
+	errTokenReused        = errorext.NewCustomError(http.StatusUnauthorized, errors.New(constant.RefreshTokenReused))
+	errSignerMissing      = errorext.NewCustomError(http.StatusServiceUnavailable, errors.New(constant.TokenIssuanceDisabled))
+	errUnverifiedEmail    = errorext.NewCustomError(http.StatusForbidden, errors.New(constant.UnverifiedEmail))
+	errEmailRegistered    = errorext.NewCustomError(http.StatusConflict, errors.New(constant.EmailRegistered))
+	errIdentityLinked     = errorext.NewCustomError(http.StatusConflict, errors.New(constant.IdentityLinked))
+	errInvalidLockoutKey  = errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.InvalidLockoutKey))
+	errLockoutNotFound    = errorext.NewCustomError(http.StatusNotFound, errors.New(constant.LockoutNotFound))
+)
+
+// service implements the use case of the credentials
+// service contains the business logic as well as calls to the
+// repository to perform db operations
+type service struct {
+	repository credential.Repository
+	users      user.Repository
+	hasher     *auth.PasswordHasher
+	// signer issues the access tokens, nil disables the use case
+	signer *auth.Signer
+	// roles puts the roles of the users into the access
+	// tokens, nil issues the tokens without roles
+	roles      role.Repository
+	refreshTTL time.Duration
+	now        func() time.Time
+	// dummyHash is verified for the unknown emails so that
+	// a login takes as long for them as for the known ones
+	dummyHash string
+	// accountLockout and ipLockout limit the failed logins
+	// per account and per client address
+	accountLockout credential.LockoutPolicy
+	ipLockout      credential.LockoutPolicy
+	// publisher publishes the audit events of the
+	// lockouts, nil disables the events
+	publisher event.Publisher
+}
+
+// Option configures the service
+type Option func(*service)
+
+// WithRefreshTTL sets the lifetime of the refresh tokens
+func WithRefreshTTL(d time.Duration) Option {
+	return func(s *service) {
+		s.refreshTTL = d
+	}
+}
+
+// WithTimeFunc sets the clock of the refresh tokens
+func WithTimeFunc(now func() time.Time) Option {
+	return func(s *service) {
+		s.now = now
+	}
+}
+
+// WithRoles sets the repository of the roles of the users
+// the signup assigns role.Customer to the new users
+func WithRoles(r role.Repository) Option {
+	return func(s *service) {
+		s.roles = r
+	}
+}
+
+// WithLockout sets the policies of the failed logins per account
+// and per client address, a zero policy disables the limit
+func WithLockout(account, ip credential.LockoutPolicy) Option {
+	return func(s *service) {
+		s.accountLockout = account
+		s.ipLockout = ip
+	}
+}
+
+// WithPublisher publishes the audit events of the lockouts with p
+func WithPublisher(p event.Publisher) Option {
+	return func(s *service) {
+		s.publisher = p
+	}
+}
+
+// NewService initializes a new Service
+func NewService(r credential.Repository, users user.Repository, h *auth.PasswordHasher, signer *auth.Signer, opts ...Option) *service {
+	s := &service{
+		repository:     r,
+		users:          users,
+		hasher:         h,
+		signer:         signer,
+		refreshTTL:     constant.RefreshTokenTTL * time.Hour,
+		now:            time.Now,
+		accountLockout: credential.DefaultAccountLockout(),
+		ipLockout:      credential.DefaultIPLockout(),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	dummy, err := h.Hash(uuid.NewString())
+	if err != nil {
+		log.Printf("hash returned error: %v", err)
+	}
+
+	s.dummyHash = dummy
+
+	return s
+}
+
+// normalizeEmail returns the stored form of the email
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// validatePassword checks the length of the password in characters
+func validatePassword(password string) error {
+	n := utf8.RuneCountInString(password)
+	if n < constant.PasswordMinLength || n > constant.PasswordMaxLength {
+		return errorext.NewCustomError(
+			http.StatusBadRequest,
+			fmt.Errorf("the password must have %d to %d characters", constant.PasswordMinLength, constant.PasswordMaxLength),
+		)
+	}
+
+	return nil
+}
+
+// Signup creates the user, its credential and the first
+// refresh token of the session in one transaction
+func (s *service) Signup(ctx context.Context, payload credential.SignupDTO) (credential.Session, error) {
+	if s.signer == nil {
+		return credential.Session{}, errSignerMissing
+	}
+
+	err := validatePassword(payload.Password)
+	if err != nil {
+		return credential.Session{}, err
+	}
+
+	hash, err := s.hasher.Hash(payload.Password)
+	if err != nil {
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	var session issuedSession
+
+	err = s.repository.InTx(ctx, func(ctx context.Context) error {
+		n := s.now().Unix()
+
+		id, err := s.users.Create(ctx, user.CreateDTO{Name: payload.Name, Address: payload.Address, CreatedAt: n, UpdatedAt: n})
+		if err != nil {
+			return err
+		}
+
+		err = s.repository.CreateCredential(ctx, credential.Credential{
+			UserID:       id,
+			Email:        normalizeEmail(payload.Email),
+			PasswordHash: hash,
+			CreatedAt:    n,
+			UpdatedAt:    n,
+		})
+		if err != nil {
+			return err
+		}
+
+		if s.roles != nil {
+			err = s.roles.AssignRole(ctx, id, role.Customer, n)
+			if err != nil {
+				return err
+			}
+		}
+
+		session, err = s.issue(ctx, id, uuid.NewString())
+
+		return err
+	})
+	if err != nil {
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	return session.Session, nil
+}
+
+// Login verifies the password and starts a new token family, the
+// logins of a locked account or address and the logins before the
+// delay after the last failure are rejected without a verification,
+// the attempt is counted as a failure before the verification so that
+// the concurrent logins can't pass the check together
+func (s *service) Login(ctx context.Context, payload credential.LoginDTO) (credential.Session, error) {
+	if s.signer == nil {
+		return credential.Session{}, errSignerMissing
+	}
+
+	email := normalizeEmail(payload.Email)
+
+	attempts, err := s.reserveAttempts(ctx, s.lockoutKeys(email, payload.IP))
+	if err != nil {
+		return credential.Session{}, err
+	}
+
+	c, err := s.repository.ReadCredential(ctx, email)
+	if err != nil {
+		if errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+			return credential.Session{}, errorext.BuildCustomError(err)
+		}
+
+		// spend the time of a verification, the response
+		// time does not tell if the email is registered
+		_, _, _ = s.hasher.Verify(payload.Password, s.dummyHash)
+
+		s.publishLocks(ctx, attempts, payload.IP)
+
+		return credential.Session{}, errInvalidCredentials
+	}
+
+	ok, rehash, err := s.hasher.Verify(payload.Password, c.PasswordHash)
+	if err != nil {
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	if !ok {
+		s.publishLocks(ctx, attempts, payload.IP)
+		return credential.Session{}, errInvalidCredentials
+	}
+
+	s.releaseAttempts(ctx, attempts)
+
+	if rehash {
+		s.rehash(ctx, c.UserID, payload.Password)
+	}
+
+	session, err := s.issue(ctx, c.UserID, uuid.NewString())
+	if err != nil {
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	return session.Session, nil
+}
+
+// lockoutKey is a key the failed logins are counted by with its policy
+type lockoutKey struct {
+	key    string
+	policy credential.LockoutPolicy
+}
+
+// lockoutKeys returns the keys of the account and of the
+// address of the client whose policies are enabled
+func (s *service) lockoutKeys(email, ip string) []lockoutKey {
+	var keys []lockoutKey
+
+	if s.accountLockout.Enabled() {
+		keys = append(keys, lockoutKey{key: credential.LockoutKey(credential.LockoutAccount, email), policy: s.accountLockout})
+	}
+
+	if ip != "" && s.ipLockout.Enabled() {
+		keys = append(keys, lockoutKey{key: credential.LockoutKey(credential.LockoutIP, ip), policy: s.ipLockout})
+	}
+
+	return keys
+}
+
+// attempt is a login attempt counted for a key before the verification
+type attempt struct {
+	lockoutKey
+	// failures are the failures of the key with the attempt
+	failures int
+	// lockedUntil is the lock of the key by the attempt, 0 if
+	// the attempt did not reach the threshold of the policy
+	lockedUntil int64
+}
+
+// reserveAttempts checks the lockouts of the keys and counts the attempt
+// for them, it fails with a 429 error if a key is locked or the delay
+// after its last failure has not passed, the error carries the seconds
+// until the login is allowed, the attempts reaching the threshold lock
+// their key, the check is repeated if a concurrent attempt counted first
+func (s *service) reserveAttempts(ctx context.Context, keys []lockoutKey) ([]attempt, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	for range constant.LockoutReserveTries {
+		lockouts, err := s.checkLockouts(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+
+		attempts, ok, err := s.reserve(ctx, keys, lockouts)
+		if err != nil {
+			return nil, errorext.BuildCustomError(err)
+		}
+
+		if ok {
+			return attempts, nil
+		}
+	}
+
+	// the key is contended, ex: a burst of logins
+	return nil, credential.NewLockoutError(constant.LoginDelayed, 1)
+}
+
+// checkLockouts returns the lockouts of the keys, the keys without a
+// lockout included, it fails with the 429 error of reserveAttempts
+func (s *service) checkLockouts(ctx context.Context, keys []lockoutKey) ([]credential.Lockout, error) {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.key
+	}
+
+	read, err := s.repository.ReadLockouts(ctx, names...)
+	if err != nil {
+		return nil, errorext.BuildCustomError(err)
+	}
+
+	now := s.now().Unix()
+	retryAt, locked := now, false
+
+	lockouts := make([]credential.Lockout, len(keys))
+	for i, k := range keys {
+		lockouts[i] = credential.Lockout{Key: k.key}
+
+		for _, l := range read {
+			if k.key != l.Key {
+				continue
+			}
+
+			lockouts[i] = l
+
+			if t := k.policy.RetryAt(l, now); t > retryAt {
+				retryAt = t
+			}
+
+			locked = locked || l.Locked(now)
+		}
+	}
+
+	if retryAt <= now {
+		return lockouts, nil
+	}
+
+	msg := constant.LoginDelayed
+	if locked {
+		msg = constant.LoginLocked
+	}
+
+	return nil, credential.NewLockoutError(msg, retryAt-now)
+}
+
+// reserve counts the attempt for the keys if their lockouts are still
+// the checked ones, ok is false if a concurrent attempt changed one,
+// the attempts counted before are taken back then
+func (s *service) reserve(ctx context.Context, keys []lockoutKey, lockouts []credential.Lockout) ([]attempt, bool, error) {
+	n := s.now()
+	attempts := make([]attempt, 0, len(keys))
+
+	for i, k := range keys {
+		l, ok, err := s.repository.ReserveAttempt(ctx, lockouts[i], n.Unix(), n.Add(-k.policy.Window).Unix())
+		if err != nil || !ok {
+			for _, a := range attempts {
+				if _, err := s.repository.ReleaseAttempt(ctx, a.key, a.lockedUntil); err != nil {
+					log.Printf("release login attempt of %s returned error: %v", a.key, err)
+				}
+			}
+
+			return nil, false, err
+		}
+
+		a := attempt{lockoutKey: k, failures: l.Failures}
+
+		if k.policy.Threshold > 0 && l.Failures >= k.policy.Threshold {
+			until := n.Add(k.policy.Duration).Unix()
+
+			// the attempt is verified still, the key is
+			// unlocked again if it succeeds, see releaseAttempts
+			rows, err := s.repository.Lock(ctx, k.key, until, n.Unix())
+			if err != nil {
+				log.Printf("lock %s returned error: %v", k.key, err)
+			}
+
+			if rows == 1 {
+				a.lockedUntil = until
+			}
+		}
+
+		attempts = append(attempts, a)
+	}
+
+	return attempts, true, nil
+}
+
+// publishLocks publishes EventLocked of the keys locked
+// by the attempts of a failed login
+func (s *service) publishLocks(ctx context.Context, attempts []attempt, ip string) {
+	for _, a := range attempts {
+		if a.lockedUntil != 0 {
+			s.publish(ctx, credential.EventLocked, credential.LockoutEvent{Key: a.key, Failures: a.failures, LockedUntil: a.lockedUntil, IP: ip})
+		}
+	}
+}
+
+// releaseAttempts takes back the attempts of a successful login, the
+// failures of the account are removed, the failures of the address are
+// kept as they can be of other accounts, a failure is logged
+func (s *service) releaseAttempts(ctx context.Context, attempts []attempt) {
+	for _, a := range attempts {
+		var err error
+
+		if kind, _, _ := credential.ParseLockoutKey(a.key); kind == credential.LockoutAccount {
+			_, err = s.repository.DeleteLockout(ctx, a.key)
+		} else {
+			_, err = s.repository.ReleaseAttempt(ctx, a.key, a.lockedUntil)
+		}
+
+		if err != nil {
+			log.Printf("release login attempt of %s returned error: %v", a.key, err)
+		}
+	}
+}
+
+// ReadLocked reads the keys locked now
+func (s *service) ReadLocked(ctx context.Context) ([]credential.Lockout, error) {
+	d, err := s.repository.ReadLocked(ctx, s.now().Unix())
+	if err != nil {
+		return nil, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+// Unlock removes the failures and the lock of the key and
+// publishes EventUnlocked with the caller as the actor
+func (s *service) Unlock(ctx context.Context, key string) error {
+	kind, value, ok := credential.ParseLockoutKey(key)
+	if !ok {
+		return errInvalidLockoutKey
+	}
+
+	if kind == credential.LockoutAccount {
+		key = credential.LockoutKey(kind, normalizeEmail(value))
+	}
+
+	n, err := s.repository.DeleteLockout(ctx, key)
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	if n == 0 {
+		return errLockoutNotFound
+	}
+
+	p, _ := auth.PrincipalFrom(ctx)
+	s.publish(ctx, credential.EventUnlocked, credential.LockoutEvent{Key: key, Actor: p.Subject})
+
+	return nil
+}
+
+// DeleteExpiredLockouts removes the unlocked lockouts without
+// a failure in the longer window of the policies
+func (s *service) DeleteExpiredLockouts(ctx context.Context) (int64, error) {
+	now := s.now()
+
+	n, err := s.repository.DeleteExpiredLockouts(ctx, now.Add(-max(s.accountLockout.Window, s.ipLockout.Window)).Unix(), now.Unix())
+	if err != nil {
+		return 0, errorext.BuildCustomError(err)
+	}
+
+	return n, nil
+}
+
+// publish publishes the audit event of a lockout, the handlers run
+// in the context of the login, a failed handler is logged
+func (s *service) publish(ctx context.Context, eventType string, e credential.LockoutEvent) {
+	if s.publisher == nil {
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, event.New(eventType, e)); err != nil {
+		log.Printf("publish %s returned error: %v", eventType, err)
+	}
+}
+
+// LoginExternal starts a new token family of the user of the
+// identity, an unknown identity is linked in one transaction to
+// the user of the verified password credential or of another
+// identity of its email, otherwise a user is created, only a
+// verified email is trusted for the linking since the provider
+// asserts it, an unverified password credential of the email
+// is a conflict, its user links the identity, see LinkExternal
+func (s *service) LoginExternal(ctx context.Context, payload credential.ExternalLoginDTO) (credential.Session, error) {
+	if s.signer == nil {
+		return credential.Session{}, errSignerMissing
+	}
+
+	var session issuedSession
+
+	err := s.repository.InTx(ctx, func(ctx context.Context) error {
+		userID, err := s.linkIdentity(ctx, payload)
+		if err != nil {
+			return err
+		}
+
+		session, err = s.issue(ctx, userID, uuid.NewString())
+
+		return err
+	})
+	if err != nil {
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	return session.Session, nil
+}
+
+// linkIdentity returns the id of the user of the identity
+// linking the identity if it is unknown
+func (s *service) linkIdentity(ctx context.Context, payload credential.ExternalLoginDTO) (string, error) {
+	i, err := s.repository.ReadIdentity(ctx, payload.Provider, payload.Subject)
+	if err == nil {
+		return i.UserID, nil
+	}
+
+	if errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+		return "", err
+	}
+
+	email := normalizeEmail(payload.Email)
+	if email == "" || !payload.EmailVerified {
+		return "", errUnverifiedEmail
+	}
+
+	userID, err := s.userOfEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	n := s.now().Unix()
+
+	if userID == "" {
+		name := payload.Name
+		if name == "" {
+			name, _, _ = strings.Cut(email, "@")
+		}
+
+		userID, err = s.users.Create(ctx, user.CreateDTO{Name: name, CreatedAt: n, UpdatedAt: n})
+		if err != nil {
+			return "", err
+		}
+
+		if s.roles != nil {
+			err = s.roles.AssignRole(ctx, userID, role.Customer, n)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	err = s.repository.CreateIdentity(ctx, credential.Identity{
+		Provider:  payload.Provider,
+		Subject:   payload.Subject,
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: n,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return userID, nil
+}
+
+// LinkExternal links the identity to the user, the identity
+// of another user is a conflict, the email of the identity is
+// kept only if it is verified as it links the later logins
+func (s *service) LinkExternal(ctx context.Context, userID string, payload credential.ExternalLoginDTO) error {
+	i, err := s.repository.ReadIdentity(ctx, payload.Provider, payload.Subject)
+	if err == nil {
+		if i.UserID != userID {
+			return errIdentityLinked
+		}
+
+		return nil
+	}
+
+	if errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+		return errorext.BuildCustomError(err)
+	}
+
+	var email string
+	if payload.EmailVerified {
+		email = normalizeEmail(payload.Email)
+	}
+
+	err = s.repository.CreateIdentity(ctx, credential.Identity{
+		Provider:  payload.Provider,
+		Subject:   payload.Subject,
+		UserID:    userID,
+		Email:     email,
+		CreatedAt: s.now().Unix(),
+	})
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	return nil
+}
+
+// userOfEmail returns the id of the user of the password credential
+// or of a linked identity of the email, empty if there is none
+// the email of the credential must be verified, anyone can sign up
+// with an email so the identity would be linked to its account
+func (s *service) userOfEmail(ctx context.Context, email string) (string, error) {
+	c, err := s.repository.ReadCredential(ctx, email)
+	if err == nil {
+		if c.EmailVerifiedAt == 0 {
+			return "", errEmailRegistered
+		}
+
+		return c.UserID, nil
+	}
+
+	if errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+		return "", err
+	}
+
+	i, err := s.repository.ReadIdentityByEmail(ctx, email)
+	if err == nil {
+		return i.UserID, nil
+	}
+
+	if errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+		return "", err
+	}
+
+	return "", nil
+}
+
+// rehash stores a hash of the password with the current parameters
+// a failure is logged, the old hash is still valid
+func (s *service) rehash(ctx context.Context, userID, password string) {
+	hash, err := s.hasher.Hash(password)
+	if err == nil {
+		_, err = s.repository.UpdatePasswordHash(ctx, userID, hash, s.now().Unix())
+	}
+
+	if err != nil {
+		log.Printf("rehash password of %s returned error: %v", userID, err)
+	}
+}
+
+// Refresh replaces the refresh token with a new one of the same
+// family, presenting a used token revokes the family so that both
+// the thief and the owner of a stolen token have to log in again
+func (s *service) Refresh(ctx context.Context, refreshToken string) (credential.Session, error) {
+	if s.signer == nil {
+		return credential.Session{}, errSignerMissing
+	}
+
+	t, err := s.readRefreshToken(ctx, refreshToken)
+	if err != nil {
+		return credential.Session{}, err
+	}
+
+	if t.Used() {
+		s.revoke(ctx, t.FamilyID)
+		return credential.Session{}, errTokenReused
+	}
+
+	if t.Expired(s.now().Unix()) {
+		return credential.Session{}, errInvalidToken
+	}
+
+	var session issuedSession
+
+	err = s.repository.InTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		session, err = s.issue(ctx, t.UserID, t.FamilyID)
+		if err != nil {
+			return err
+		}
+
+		n, err := s.repository.ReplaceRefreshToken(ctx, t.ID, session.refreshTokenID)
+		if err != nil {
+			return err
+		}
+
+		if n == 0 {
+			// a concurrent refresh used the token
+			return errTokenReused
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errTokenReused) {
+			s.revoke(ctx, t.FamilyID)
+		}
+
+		return credential.Session{}, errorext.BuildCustomError(err)
+	}
+
+	return session.Session, nil
+}
+
+// Logout revokes the family of the refresh token
+// an unknown or used token is not an error
+func (s *service) Logout(ctx context.Context, refreshToken string) error {
+	t, err := s.readRefreshToken(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, errInvalidToken) {
+			return nil
+		}
+
+		return err
+	}
+
+	_, err = s.repository.RevokeFamily(ctx, t.FamilyID, s.now().Unix())
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	return nil
+}
+
+// readRefreshToken reads the stored token of the token
+func (s *service) readRefreshToken(ctx context.Context, refreshToken string) (credential.RefreshToken, error) {
+	if refreshToken == "" {
+		return credential.RefreshToken{}, errInvalidToken
+	}
+
+	t, err := s.repository.ReadRefreshToken(ctx, auth.HashToken(refreshToken))
+	if err != nil {
+		if errorext.ParseCustomError(err).Code() == http.StatusNotFound {
+			return t, errInvalidToken
+		}
+
+		return t, errorext.BuildCustomError(err)
+	}
+
+	return t, nil
+}
+
+// revoke revokes the family of a reused token, a failure is logged
+func (s *service) revoke(ctx context.Context, familyID string) {
+	_, err := s.repository.RevokeFamily(ctx, familyID, s.now().Unix())
+	if err != nil {
+		log.Printf("revoke token family %s returned error: %v", familyID, err)
+	}
+}
+
+// issuedSession is a session with the id of its refresh token
+type issuedSession struct {
+	credential.Session
+	refreshTokenID string
+}
+
+// issue signs an access token with the current roles of the
+// user and stores a refresh token of the family
+func (s *service) issue(ctx context.Context, userID, familyID string) (issuedSession, error) {
+	claims := auth.Claims{Subject: userID}
+
+	if s.roles != nil {
+		roles, err := s.roles.ReadUserRoles(ctx, userID)
+		if err != nil {
+			return issuedSession{}, err
+		}
+
+		claims.Roles = roles
+	}
+
+	access, _, err := s.signer.Sign(claims)
+	if err != nil {
+		return issuedSession{}, err
+	}
+
+	refresh, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return issuedSession{}, err
+	}
+
+	n := s.now()
+
+	id, err := s.repository.CreateRefreshToken(ctx, credential.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hash,
+		ExpiresAt: n.Add(s.refreshTTL).Unix(),
+		CreatedAt: n.Unix(),
+	})
+	if err != nil {
+		return issuedSession{}, err
+	}
+
+	return issuedSession{
+		Session: credential.Session{
+			UserID:       userID,
+			AccessToken:  access,
+			RefreshToken: refresh,
+			ExpiresIn:    int64(s.signer.TTL().Seconds()),
+		},
+		refreshTokenID: id,
+	}, nil
+}
@@ -0,0 +1,629 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	rolemock "github.com/tanveerprottoy/backend-structure-go/internal/api/role/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	usermock "github.com/tanveerprottoy/backend-structure-go/internal/api/user/mock"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+)
+
+var (
+	secret = []byte("0123456789abcdef0123456789abcdef")
+	params = auth.PasswordParams{Memory: 64, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+)
+
+// users numbers the created users, the user mock
+// stores all of them with an empty id
+type users struct {
+	*usermock.MemoryStorage
+	seq int
+}
+
+func (u *users) Create(ctx context.Context, payload user.CreateDTO, args ...any) (string, error) {
+	u.seq++
+	return "user-" + strconv.Itoa(u.seq), nil
+}
+
+// clock is a settable time of the refresh tokens
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newService(r credential.Repository, h *auth.PasswordHasher, c *clock) credential.UseCase {
+	return service.NewService(
+		r,
+		&users{MemoryStorage: usermock.NewMemoryStorage()},
+		h,
+		auth.NewSigner(secret),
+		service.WithRefreshTTL(time.Hour),
+		service.WithTimeFunc(c.Now),
+	)
+}
+
+func expectCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	if err == nil || errorext.ParseCustomError(err).Code() != code {
+		t.Fatalf("expected %d, got %v", code, err)
+	}
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Now()}
+	r := mock.NewMemoryStorage()
+	s := newService(r, auth.NewPasswordHasher(params), c)
+
+	signup, err := s.Signup(ctx, credential.SignupDTO{Name: "jane", Email: " Jane@Example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+
+	t.Run("Signup", func(t *testing.T) {
+		p, err := auth.NewVerifier(auth.HMACKey(secret)).Verify(ctx, signup.AccessToken)
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+
+		if p.Subject != signup.UserID || signup.RefreshToken == "" || signup.ExpiresIn <= 0 {
+			t.Errorf("unexpected session %+v of %+v", signup, p)
+		}
+
+		tests := []struct {
+			name string
+			dto  credential.SignupDTO
+			code int
+		}{
+			{name: "registered email", dto: credential.SignupDTO{Name: "jane", Email: "jane@example.com", Password: "correct horse"}, code: http.StatusConflict},
+			{name: "short password", dto: credential.SignupDTO{Name: "john", Email: "john@example.com", Password: "short"}, code: http.StatusBadRequest},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := s.Signup(ctx, tc.dto)
+				expectCode(t, err, tc.code)
+			})
+		}
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		tests := []struct {
+			name string
+			dto  credential.LoginDTO
+			code int
+		}{
+			{name: "valid", dto: credential.LoginDTO{Email: "JANE@example.com", Password: "correct horse"}},
+			{name: "wrong password", dto: credential.LoginDTO{Email: "jane@example.com", Password: "battery staple"}, code: http.StatusUnauthorized},
+			{name: "unknown email", dto: credential.LoginDTO{Email: "john@example.com", Password: "correct horse"}, code: http.StatusUnauthorized},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				e, err := s.Login(ctx, tc.dto)
+				if tc.code != 0 {
+					expectCode(t, err, tc.code)
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				if e.UserID != signup.UserID {
+					t.Errorf("expected user %s, got %s", signup.UserID, e.UserID)
+				}
+			})
+		}
+	})
+
+	t.Run("Login rehash", func(t *testing.T) {
+		stronger := params
+		stronger.Time = 2
+
+		_, err := newService(r, auth.NewPasswordHasher(stronger), c).Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+
+		e, _ := r.ReadCredential(ctx, "jane@example.com")
+		if !strings.Contains(e.PasswordHash, "t=2") {
+			t.Errorf("expected the hash to be upgraded, got %s", e.PasswordHash)
+		}
+
+		// the old parameters still verify the upgraded hash
+		_, err = s.Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		rotated, err := s.Refresh(ctx, signup.RefreshToken)
+		if err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+
+		if rotated.RefreshToken == signup.RefreshToken || rotated.UserID != signup.UserID {
+			t.Fatalf("expected a new refresh token, got %+v", rotated)
+		}
+
+		// the replay of the rotated token revokes the family
+		_, err = s.Refresh(ctx, signup.RefreshToken)
+		expectCode(t, err, http.StatusUnauthorized)
+
+		_, err = s.Refresh(ctx, rotated.RefreshToken)
+		expectCode(t, err, http.StatusUnauthorized)
+
+		_, err = s.Refresh(ctx, "unknown")
+		expectCode(t, err, http.StatusUnauthorized)
+	})
+
+	t.Run("Refresh expired", func(t *testing.T) {
+		e, err := s.Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+
+		later := &clock{now: c.now.Add(2 * time.Hour)}
+
+		_, err = newService(r, auth.NewPasswordHasher(params), later).Refresh(ctx, e.RefreshToken)
+		expectCode(t, err, http.StatusUnauthorized)
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		e, err := s.Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+
+		rotated, err := s.Refresh(ctx, e.RefreshToken)
+		if err != nil {
+			t.Fatalf("refresh: %v", err)
+		}
+
+		// the logout with the first token of the family ends the session
+		if err := s.Logout(ctx, e.RefreshToken); err != nil {
+			t.Fatalf("logout: %v", err)
+		}
+
+		_, err = s.Refresh(ctx, rotated.RefreshToken)
+		expectCode(t, err, http.StatusUnauthorized)
+
+		if err := s.Logout(ctx, "unknown"); err != nil {
+			t.Errorf("expected no error for an unknown token, got %v", err)
+		}
+	})
+
+	t.Run("LoginExternal", func(t *testing.T) {
+		// an account of the email of the identity was signed
+		// up by anyone, its email is verified by another flow
+		err := r.CreateCredential(ctx, credential.Credential{UserID: "verified", Email: "bob@example.com", EmailVerifiedAt: c.now.Unix()})
+		if err != nil {
+			t.Fatalf("create credential: %v", err)
+		}
+
+		// the cases run in order, a case links
+		// the identity the next ones log in with
+		tests := []struct {
+			name string
+			dto  credential.ExternalLoginDTO
+			// user is the case whose user is expected, empty is a new user
+			user string
+			code int
+		}{
+			{name: "unverified credential", dto: credential.ExternalLoginDTO{Provider: "google", Subject: "g1", Email: "JANE@example.com", EmailVerified: true}, code: http.StatusConflict},
+			{name: "linked by the verified credential", dto: credential.ExternalLoginDTO{Provider: "google", Subject: "g2", Email: "Bob@example.com", EmailVerified: true}, user: "verified"},
+			{name: "linked identity", dto: credential.ExternalLoginDTO{Provider: "google", Subject: "g2", Email: "bob@other.com"}, user: "verified"},
+			{name: "new user", dto: credential.ExternalLoginDTO{Provider: "github", Subject: "h1", Email: "ann@example.com", EmailVerified: true, Name: "ann"}},
+			{name: "linked by another identity", dto: credential.ExternalLoginDTO{Provider: "company", Subject: "c1", Email: "Ann@Example.com", EmailVerified: true}, user: "new user"},
+			{name: "unverified email", dto: credential.ExternalLoginDTO{Provider: "company", Subject: "c2", Email: "jane@example.com"}, code: http.StatusForbidden},
+			{name: "missing email", dto: credential.ExternalLoginDTO{Provider: "company", Subject: "c3", EmailVerified: true}, code: http.StatusForbidden},
+		}
+
+		userIDs := map[string]string{"signup": signup.UserID, "verified": "verified"}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				e, err := s.LoginExternal(ctx, tc.dto)
+				if tc.code != 0 {
+					expectCode(t, err, tc.code)
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				userIDs[tc.name] = e.UserID
+
+				if tc.user == "" {
+					if e.UserID == signup.UserID {
+						t.Errorf("expected a new user, got %s", e.UserID)
+					}
+
+					return
+				}
+
+				if e.UserID != userIDs[tc.user] {
+					t.Errorf("expected user %s, got %s", userIDs[tc.user], e.UserID)
+				}
+			})
+		}
+
+		if _, err := r.ReadIdentity(ctx, "company", "c2"); errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+			t.Errorf("expected the unverified identity not to be linked, got %v", err)
+		}
+
+		// the identity of the unverified credential
+		// is not linked to its user nor to a new user
+		if _, err := r.ReadIdentity(ctx, "google", "g1"); errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+			t.Errorf("expected the identity of the unverified credential not to be linked, got %v", err)
+		}
+	})
+
+	t.Run("LinkExternal", func(t *testing.T) {
+		g1 := credential.ExternalLoginDTO{Provider: "google", Subject: "g1", Email: "jane@example.com", EmailVerified: true}
+
+		// the user of the unverified credential links the identity
+		if err := s.LinkExternal(ctx, signup.UserID, g1); err != nil {
+			t.Fatalf("link: %v", err)
+		}
+
+		// linking it again is a no-op
+		if err := s.LinkExternal(ctx, signup.UserID, g1); err != nil {
+			t.Errorf("expected no error linking again, got %v", err)
+		}
+
+		e, err := s.LoginExternal(ctx, g1)
+		if err != nil || e.UserID != signup.UserID {
+			t.Errorf("expected the login of user %s, got %+v %v", signup.UserID, e, err)
+		}
+
+		// the identity of another user
+		expectCode(t, s.LinkExternal(ctx, "verified", g1), http.StatusConflict)
+
+		// an unverified email does not link the later logins
+		err = s.LinkExternal(ctx, signup.UserID, credential.ExternalLoginDTO{Provider: "company", Subject: "c9", Email: "eve@example.com"})
+		if err != nil {
+			t.Fatalf("link: %v", err)
+		}
+
+		if _, err := r.ReadIdentityByEmail(ctx, "eve@example.com"); errorext.ParseCustomError(err).Code() != http.StatusNotFound {
+			t.Errorf("expected the unverified email not to be kept, got %v", err)
+		}
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		u := service.NewService(
+			mock.NewMemoryStorage(),
+			&users{MemoryStorage: usermock.NewMemoryStorage()},
+			auth.NewPasswordHasher(params),
+			auth.NewSigner(secret),
+			service.WithRoles(rolemock.NewMemoryStorage()),
+		)
+
+		e, err := u.Signup(ctx, credential.SignupDTO{Name: "john", Email: "john@example.com", Password: "correct horse"})
+		if err != nil {
+			t.Fatalf("signup: %v", err)
+		}
+
+		p, err := auth.NewVerifier(auth.HMACKey(secret)).Verify(ctx, e.AccessToken)
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+
+		if !p.HasRole(role.Customer) {
+			t.Errorf("expected the %s role, got %v", role.Customer, p.Roles)
+		}
+	})
+
+	t.Run("signer missing", func(t *testing.T) {
+		u := service.NewService(r, &users{MemoryStorage: usermock.NewMemoryStorage()}, auth.NewPasswordHasher(params), nil)
+
+		_, err := u.Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "correct horse"})
+		expectCode(t, err, http.StatusServiceUnavailable)
+	})
+}
+
+func TestLockout(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+
+	var events []event.Event
+
+	bus := event.NewBus()
+	bus.Subscribe(func(ctx context.Context, e event.Event) error {
+		events = append(events, e)
+		return nil
+	})
+
+	s := service.NewService(
+		mock.NewMemoryStorage(),
+		&users{MemoryStorage: usermock.NewMemoryStorage()},
+		auth.NewPasswordHasher(params),
+		auth.NewSigner(secret),
+		service.WithTimeFunc(c.Now),
+		service.WithPublisher(bus),
+		service.WithLockout(
+			credential.LockoutPolicy{Free: 1, Delay: time.Second, MaxDelay: 2 * time.Second, Threshold: 4, Duration: 10 * time.Minute, Window: time.Hour},
+			credential.LockoutPolicy{Threshold: 6, Duration: 10 * time.Minute, Window: time.Hour},
+		),
+	)
+
+	if _, err := s.Signup(ctx, credential.SignupDTO{Name: "jane", Email: "jane@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+
+	login := func(email, password, ip string) error {
+		_, err := s.Login(ctx, credential.LoginDTO{Email: email, Password: password, IP: ip})
+		return err
+	}
+
+	expectRetryAfter := func(t *testing.T, err error, seconds int64) {
+		t.Helper()
+
+		expectCode(t, err, http.StatusTooManyRequests)
+
+		if got, ok := credential.RetryAfter(err); !ok || got != seconds {
+			t.Errorf("expected a retry after %d seconds, got %d", seconds, got)
+		}
+	}
+
+	t.Run("account", func(t *testing.T) {
+		// the cases run in order, wait is the time passing before the login
+		tests := []struct {
+			name     string
+			wait     time.Duration
+			password string
+			code     int
+			retry    int64
+		}{
+			{name: "free failure", password: "wrong", code: http.StatusUnauthorized},
+			{name: "last free failure", password: "wrong", code: http.StatusUnauthorized},
+			{name: "delayed", password: "wrong", code: http.StatusTooManyRequests, retry: 1},
+			{name: "after the delay", wait: time.Second, password: "wrong", code: http.StatusUnauthorized},
+			{name: "doubled delay", wait: time.Second, password: "correct horse", code: http.StatusTooManyRequests, retry: 1},
+			{name: "locking failure", wait: time.Second, password: "wrong", code: http.StatusUnauthorized},
+			{name: "locked", wait: time.Minute, password: "correct horse", code: http.StatusTooManyRequests, retry: 540},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				c.now = c.now.Add(tc.wait)
+
+				err := login("Jane@Example.com", tc.password, "10.0.0.1")
+				if tc.retry != 0 {
+					expectRetryAfter(t, err, tc.retry)
+					return
+				}
+
+				expectCode(t, err, tc.code)
+			})
+		}
+
+		if len(events) != 1 || events[0].Type != credential.EventLocked {
+			t.Fatalf("expected a %s event, got %+v", credential.EventLocked, events)
+		}
+
+		if e := events[0].Data.(credential.LockoutEvent); e.Key != "account:jane@example.com" || e.Failures != 4 || e.IP != "10.0.0.1" {
+			t.Errorf("unexpected event %+v", e)
+		}
+
+		d, err := s.ReadLocked(ctx)
+		if err != nil {
+			t.Fatalf("read locked: %v", err)
+		}
+
+		if len(d) != 1 || d[0].Key != "account:jane@example.com" {
+			t.Errorf("expected the account to be locked, got %+v", d)
+		}
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		expectCode(t, s.Unlock(ctx, "user:jane@example.com"), http.StatusBadRequest)
+		expectCode(t, s.Unlock(ctx, "ip:10.0.0.9"), http.StatusNotFound)
+
+		admin := auth.WithPrincipal(ctx, auth.Principal{Subject: "admin-1"})
+		if err := s.Unlock(admin, "account:JANE@example.com"); err != nil {
+			t.Fatalf("unlock: %v", err)
+		}
+
+		if e := events[len(events)-1]; e.Type != credential.EventUnlocked || e.Data.(credential.LockoutEvent).Actor != "admin-1" {
+			t.Errorf("expected a %s event of admin-1, got %+v", credential.EventUnlocked, e)
+		}
+
+		if err := login("jane@example.com", "correct horse", "10.0.0.1"); err != nil {
+			t.Errorf("expected no error after the unlock, got %v", err)
+		}
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		// the address keeps its 4 failures after the login, the
+		// unknown emails are counted for the address too
+		for _, email := range []string{"ann@example.com", "bob@example.com"} {
+			expectCode(t, login(email, "wrong", "10.0.0.1"), http.StatusUnauthorized)
+		}
+
+		if e := events[len(events)-1]; e.Type != credential.EventLocked || e.Data.(credential.LockoutEvent).Key != "ip:10.0.0.1" {
+			t.Errorf("expected a %s event of the address, got %+v", credential.EventLocked, e)
+		}
+
+		expectRetryAfter(t, login("jane@example.com", "correct horse", "10.0.0.1"), 600)
+
+		if err := login("jane@example.com", "correct horse", "10.0.0.2"); err != nil {
+			t.Errorf("expected no error from another address, got %v", err)
+		}
+
+		// the lock expires
+		c.now = c.now.Add(10 * time.Minute)
+
+		if err := login("jane@example.com", "correct horse", "10.0.0.1"); err != nil {
+			t.Errorf("expected no error after the lock, got %v", err)
+		}
+	})
+
+	t.Run("DeleteExpiredLockouts", func(t *testing.T) {
+		n, err := s.DeleteExpiredLockouts(ctx)
+		if err != nil || n != 0 {
+			t.Fatalf("expected no lockout in the window to be deleted, got %d, %v", n, err)
+		}
+
+		c.now = c.now.Add(time.Hour)
+
+		// the lockouts of the address and of the unknown emails
+		n, err = s.DeleteExpiredLockouts(ctx)
+		if err != nil || n != 3 {
+			t.Errorf("expected 3 deleted lockouts, got %d, %v", n, err)
+		}
+	})
+}
+
+// heldCredentials holds the reads of the credentials until release is
+// closed so that the concurrent logins are verified at the same time
+type heldCredentials struct {
+	*mock.MemoryStorage
+	release chan struct{}
+}
+
+func (r *heldCredentials) ReadCredential(ctx context.Context, email string) (credential.Credential, error) {
+	<-r.release
+	return r.MemoryStorage.ReadCredential(ctx, email)
+}
+
+// TestLockoutConcurrent checks the concurrent logins can't
+// verify more passwords than the threshold of the account
+func TestLockoutConcurrent(t *testing.T) {
+	ctx := context.Background()
+	c := &clock{now: time.Unix(1_700_000_000, 0)}
+
+	var (
+		mu     sync.Mutex
+		events []event.Event
+	)
+
+	bus := event.NewBus()
+	bus.Subscribe(func(ctx context.Context, e event.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		events = append(events, e)
+		return nil
+	})
+
+	r := &heldCredentials{MemoryStorage: mock.NewMemoryStorage(), release: make(chan struct{})}
+	close(r.release)
+
+	s := service.NewService(
+		r,
+		&users{MemoryStorage: usermock.NewMemoryStorage()},
+		auth.NewPasswordHasher(params),
+		auth.NewSigner(secret),
+		service.WithTimeFunc(c.Now),
+		service.WithPublisher(bus),
+		service.WithLockout(
+			credential.LockoutPolicy{Threshold: 3, Duration: 10 * time.Minute, Window: time.Hour},
+			credential.LockoutPolicy{},
+		),
+	)
+
+	if _, err := s.Signup(ctx, credential.SignupDTO{Name: "jane", Email: "jane@example.com", Password: "correct horse"}); err != nil {
+		t.Fatalf("signup: %v", err)
+	}
+
+	// the logins passing the lockouts wait for the others
+	r.release = make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() { close(r.release) })
+
+	var (
+		wg       sync.WaitGroup
+		verified atomic.Int64
+	)
+
+	for range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := s.Login(ctx, credential.LoginDTO{Email: "jane@example.com", Password: "wrong", IP: "10.0.0.1"})
+			if err == nil {
+				t.Error("expected the wrong password to fail")
+				return
+			}
+
+			switch code := errorext.ParseCustomError(err).Code(); code {
+			case http.StatusUnauthorized:
+				verified.Add(1)
+			case http.StatusTooManyRequests:
+			default:
+				t.Errorf("unexpected error %v", err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// the contended attempts can be delayed before the threshold
+	n := verified.Load()
+	if n == 0 || n > 3 {
+		t.Fatalf("expected at most the threshold of 3 verified passwords, got %d", n)
+	}
+
+	if n < 3 {
+		return
+	}
+
+	d, err := s.ReadLocked(ctx)
+	if err != nil {
+		t.Fatalf("read locked: %v", err)
+	}
+
+	if len(d) != 1 || d[0].Failures != 3 {
+		t.Errorf("expected the account to be locked after 3 failures, got %+v", d)
+	}
+
+	if len(events) != 1 || events[0].Type != credential.EventLocked {
+		t.Errorf("expected one %s event, got %+v", credential.EventLocked, events)
+	}
+}
+
+func TestLockoutPolicyWait(t *testing.T) {
+	p := credential.LockoutPolicy{Free: 2, Delay: time.Second, MaxDelay: 5 * time.Second}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 5, want: 4 * time.Second},
+		{failures: 6, want: 5 * time.Second},
+		{failures: 60, want: 5 * time.Second},
+	}
+
+	for _, tc := range tests {
+		if got := p.Wait(tc.failures); got != tc.want {
+			t.Errorf("Wait(%d) = %v, want %v", tc.failures, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package credential
+
+import "context"
+
+type UseCase interface {
+	// Signup creates the user and its credential
+	// and starts a session of the user
+	Signup(ctx context.Context, payload SignupDTO) (Session, error)
+
+	// Login verifies the credential and starts a session, the
+	// password is rehashed if the hash parameters were changed,
+	// the failed logins of the account and of the address of the
+	// client delay the next logins and lock them, see LockoutPolicy
+	Login(ctx context.Context, payload LoginDTO) (Session, error)
+
+	// LoginExternal starts a session of the user of an identity
+	// verified by an external provider, an unknown identity is
+	// linked to the user of its verified email or to a new user
+	LoginExternal(ctx context.Context, payload ExternalLoginDTO) (Session, error)
+
+	// LinkExternal links an identity verified by an external
+	// provider to the user, the caller authenticates the user
+	// before the login at the provider
+	LinkExternal(ctx context.Context, userID string, payload ExternalLoginDTO) error
+
+	// Refresh rotates the refresh token of a session, a reused
+	// token revokes the whole session
+	Refresh(ctx context.Context, refreshToken string) (Session, error)
+
+	// Logout revokes the session of the refresh token
+	Logout(ctx context.Context, refreshToken string) error
+
+	// ReadLocked reads the locked accounts and addresses
+	ReadLocked(ctx context.Context) ([]Lockout, error)
+
+	// Unlock removes the failures and the lock of the key
+	// of an account or an address, see LockoutKey
+	Unlock(ctx context.Context, key string) error
+
+	// DeleteExpiredLockouts removes the lockouts whose failures
+	// are not counted anymore, it returns the number removed
+	DeleteExpiredLockouts(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,163 @@
+// package complexity computes the cost of the graphql operations
+// so that the expensive queries are rejected before they run
+package complexity
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// Analyzer computes the cost of the operations of a schema
+// every field costs one, the fields below a list are multiplied
+// by its size, the limit of the page argument of an ancestor
+// or the default list size, at most the max list size
+type Analyzer struct {
+	schema      *ast.Schema
+	listSize    int
+	maxListSize int
+}
+
+// Option configures an Analyzer
+type Option func(*Analyzer)
+
+// WithMaxListSize sets the largest list size, the page limits above
+// it are reduced to it as the resolvers do, default constant.PageMaxLimit
+func WithMaxListSize(n int) Option {
+	return func(a *Analyzer) {
+		a.maxListSize = n
+	}
+}
+
+// New initializes an Analyzer of the schema definition
+func New(sdl string, listSize int, opts ...Option) (*Analyzer, error) {
+	s, err := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+	if err != nil {
+		return nil, err
+	}
+
+	a := &Analyzer{schema: s, listSize: listSize, maxListSize: constant.PageMaxLimit}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a, nil
+}
+
+// Cost returns the cost of the operation of the query
+// a query which can't be analyzed returns an error, it must
+// be rejected as its cost is unknown
+func (a *Analyzer) Cost(query, operationName string, variables map[string]any) (int, error) {
+	doc, errs := gqlparser.LoadQuery(a.schema, query)
+	if len(errs) > 0 {
+		return 0, errs
+	}
+
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		return 0, errors.New(constant.UnknownOperation)
+	}
+
+	return a.selectionCost(op.SelectionSet, variables, 0), nil
+}
+
+// selectionCost returns the cost of the selections
+// limit is the page limit of the nearest ancestor
+// the costs saturate at math.MaxInt instead of overflowing
+func (a *Analyzer) selectionCost(set ast.SelectionSet, vars map[string]any, limit int) int {
+	cost := 0
+
+	for _, s := range set {
+		switch s := s.(type) {
+		case *ast.Field:
+			cost = add(cost, a.fieldCost(s, vars, limit))
+		case *ast.InlineFragment:
+			cost = add(cost, a.selectionCost(s.SelectionSet, vars, limit))
+		case *ast.FragmentSpread:
+			if s.Definition != nil {
+				cost = add(cost, a.selectionCost(s.Definition.SelectionSet, vars, limit))
+			}
+		}
+	}
+
+	return cost
+}
+
+func (a *Analyzer) fieldCost(f *ast.Field, vars map[string]any, limit int) int {
+	if f.Definition == nil || f.Name == "__typename" {
+		return 0
+	}
+
+	// a page argument sizes the lists below the field
+	if l := pageLimit(f.ArgumentMap(vars)); l > 0 {
+		limit = l
+	}
+
+	if f.Definition.Type.Elem == nil {
+		return add(1, a.selectionCost(f.SelectionSet, vars, limit))
+	}
+
+	size := limit
+	if size == 0 {
+		size = a.listSize
+	}
+
+	// the resolvers don't return more than the max
+	if a.maxListSize > 0 {
+		size = min(size, a.maxListSize)
+	}
+
+	// the limit applies to the first list only
+	return add(1, mul(size, a.selectionCost(f.SelectionSet, vars, 0)))
+}
+
+// add returns the sum of the non negative costs, math.MaxInt if it overflows
+func add(x, y int) int {
+	if x > math.MaxInt-y {
+		return math.MaxInt
+	}
+
+	return x + y
+}
+
+// mul returns the product of the non negative costs, math.MaxInt if it overflows
+func mul(x, y int) int {
+	if x != 0 && y > math.MaxInt/x {
+		return math.MaxInt
+	}
+
+	return x * y
+}
+
+// pageLimit returns the limit of the page argument, zero if not set
+func pageLimit(args map[string]any) int {
+	page, ok := args["page"].(map[string]any)
+	if !ok {
+		return 0
+	}
+
+	switch v := page["limit"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		// the conversion of a float out of the range is undefined
+		if v >= math.MaxInt {
+			return math.MaxInt
+		}
+
+		return int(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return int(n)
+	}
+
+	return 0
+}
@@ -0,0 +1,120 @@
+package complexity_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/complexity"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/resolver"
+)
+
+func TestCost(t *testing.T) {
+	a, err := complexity.New(resolver.Schema, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]any
+		want          int
+		wantErr       bool
+	}{
+		{
+			name:  "object fields",
+			query: `{ product(id: "1") { id name __typename } }`,
+			want:  3,
+		},
+		{
+			name:  "list without a limit",
+			query: `{ products { items { id name } } }`,
+			want:  1 + 1 + 20*2,
+		},
+		{
+			name:  "list with the page limit",
+			query: `{ products(page: {limit: 5}) { items { id name } meta { limit } } }`,
+			want:  1 + 1 + 5*2 + 1 + 1,
+		},
+		{
+			name:  "page limit above the max",
+			query: `{ products(page: {limit: 2147483647}) { items { id name } } }`,
+			want:  1 + 1 + 100*2,
+		},
+		{
+			name:      "page limit of a variable",
+			query:     `query ($page: PageInput) { products(page: $page) { items { id name } } }`,
+			variables: map[string]any{"page": map[string]any{"limit": float64(3)}},
+			want:      1 + 1 + 3*2,
+		},
+		{
+			name:  "fragments",
+			query: `{ products { items { ...f } } } fragment f on Product { id name }`,
+			want:  1 + 1 + 20*2,
+		},
+		{
+			name:          "operation of the name",
+			query:         `query a { product(id: "1") { id } } query b { products { items { id } } }`,
+			operationName: "b",
+			want:          1 + 1 + 20,
+		},
+		{
+			name:    "invalid query",
+			query:   `{ product { unknown } }`,
+			wantErr: true,
+		},
+		{
+			name:    "syntax error",
+			query:   `{ products { items { id } }`,
+			wantErr: true,
+		},
+		{
+			name:          "unknown operation",
+			query:         `query a { product(id: "1") { id } }`,
+			operationName: "b",
+			wantErr:       true,
+		},
+		{
+			name:    "ambiguous operation",
+			query:   `query a { product(id: "1") { id } } query b { products { items { id } } }`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := a.Cost(tt.query, tt.operationName, tt.variables)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Cost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Cost() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCostLargeLimit checks the cost of the limits out of the range
+// of an int is the one of the max list size, it doesn't overflow
+func TestCostLargeLimit(t *testing.T) {
+	a, err := complexity.New(resolver.Schema, 20, complexity.WithMaxListSize(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := `query ($page: PageInput) { a: products(page: $page) { items { id name } } b: users(page: $page) { items { id } } }`
+
+	for _, limit := range []any{float64(1e300), json.Number("99999999999999999999"), math.MaxInt} {
+		got, err := a.Cost(query, "", map[string]any{"page": map[string]any{"limit": limit}})
+		if err != nil {
+			t.Fatalf("Cost(%v) error = %v", limit, err)
+		}
+
+		if want := (1 + 1 + 50*2) + (1 + 1 + 50); got != want {
+			t.Errorf("Cost(%v) = %d, want %d", limit, got, want)
+		}
+	}
+}
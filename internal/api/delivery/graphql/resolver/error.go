@@ -0,0 +1,61 @@
+package resolver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/dataloader"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// Error is an error of a resolver, its extensions carry the code
+// of the errorext.CustomError, ex: {"code": 404, "status": "NOT_FOUND"}
+type Error struct {
+	err *errorext.CustomError
+}
+
+// newError converts the error of a use case to an Error
+func newError(err error) *Error {
+	if errors.Is(err, dataloader.ErrNotFound) {
+		err = errorext.NewCustomError(http.StatusNotFound, errorext.ErrNotFound)
+	}
+
+	return &Error{err: errorext.ParseCustomError(err)}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Extensions implements the extensions of the graphql errors
+func (e *Error) Extensions() map[string]any {
+	return map[string]any{
+		"code":   e.err.Code(),
+		"status": StatusName(e.err.Code()),
+	}
+}
+
+// StatusName returns the status text of the code in
+// screaming snake case, ex: 404 is NOT_FOUND
+func StatusName(code int) string {
+	s := strings.ToUpper(http.StatusText(code))
+
+	return strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(s)
+}
+
+// required returns a validation error if the value is empty
+func required(name, v string) error {
+	if strings.TrimSpace(v) != "" {
+		return nil
+	}
+
+	return errorext.NewCustomError(
+		http.StatusBadRequest,
+		errorext.ValidationError{Name: name, Message: name + " required"},
+	)
+}
@@ -0,0 +1,77 @@
+package resolver
+
+import (
+	"context"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/dataloader"
+)
+
+type loadersKey struct{}
+
+// loaders batch the reads by id of a request into
+// one ReadByIDs call of the use cases
+type loaders struct {
+	products *dataloader.Loader[string, product.Product]
+	users    *dataloader.Loader[string, user.User]
+}
+
+// WithLoaders returns a copy of ctx carrying the loaders of a request
+// the loaders cache their results, a ctx must not outlive the request
+func (r *Resolver) WithLoaders(ctx context.Context) context.Context {
+	l := &loaders{
+		products: dataloader.New(
+			r.loadProducts,
+			dataloader.WithWait(constant.GraphQLBatchWait*time.Millisecond),
+			dataloader.WithMaxBatch(constant.GraphQLMaxBatch),
+		),
+		users: dataloader.New(
+			r.loadUsers,
+			dataloader.WithWait(constant.GraphQLBatchWait*time.Millisecond),
+			dataloader.WithMaxBatch(constant.GraphQLMaxBatch),
+		),
+	}
+
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders of ctx, the resolver
+// initializes new ones if the ctx is missing them
+func (r *Resolver) loadersFrom(ctx context.Context) *loaders {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l
+	}
+
+	return r.WithLoaders(ctx).Value(loadersKey{}).(*loaders)
+}
+
+func (r *Resolver) loadProducts(ctx context.Context, ids []string) (map[string]product.Product, error) {
+	d, err := r.products.ReadByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]product.Product, len(d))
+	for _, p := range d {
+		m[p.ID] = p
+	}
+
+	return m, nil
+}
+
+func (r *Resolver) loadUsers(ctx context.Context, ids []string) (map[string]user.User, error) {
+	d, err := r.users.ReadByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]user.User, len(d))
+	for _, u := range d {
+		m[u.ID] = u
+	}
+
+	return m, nil
+}
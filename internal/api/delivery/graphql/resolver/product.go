@@ -0,0 +1,141 @@
+package resolver
+
+import (
+	"context"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+)
+
+// productResolver resolves the Product type
+type productResolver struct {
+	p product.Product
+}
+
+func (r *productResolver) ID() graphql.ID {
+	return graphql.ID(r.p.ID)
+}
+
+func (r *productResolver) Name() string {
+	return r.p.Name
+}
+
+func (r *productResolver) Description() *string {
+	return r.p.Description
+}
+
+func (r *productResolver) IsArchived() bool {
+	return r.p.IsArchived
+}
+
+func (r *productResolver) CreatedAt() Int64 {
+	return Int64(r.p.CreatedAt)
+}
+
+func (r *productResolver) UpdatedAt() Int64 {
+	return Int64(r.p.UpdatedAt)
+}
+
+// productPageResolver resolves the ProductPage type
+type productPageResolver struct {
+	items []*productResolver
+	meta  *pageMeta
+}
+
+func (r *productPageResolver) Items() []*productResolver {
+	return r.items
+}
+
+func (r *productPageResolver) Meta() *pageMeta {
+	return r.meta
+}
+
+type createProductInput struct {
+	Name        string
+	Description *string
+}
+
+type updateProductInput struct {
+	Name        string
+	Description *string
+	IsArchived  *bool
+}
+
+// Product reads the product through the loader of the request
+// so that the product fields of a query are read in one batch
+func (r *Resolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	p, err := r.loadersFrom(ctx).products.Load(ctx, string(args.ID))
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &productResolver{p: p}, nil
+}
+
+func (r *Resolver) Products(ctx context.Context, args struct {
+	Page   *pageArgs
+	Filter *filterArgs
+}) (*productPageResolver, error) {
+	page := args.Page.resolve(r.productPagination)
+
+	a := args.Filter.args()
+	d, err := r.products.ReadMany(ctx, page.Limit, page.Number, a...)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	total, err := r.products.Count(ctx, r.productPagination, a...)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	items := make([]*productResolver, 0, len(d))
+	for _, p := range d {
+		items = append(items, &productResolver{p: p})
+	}
+
+	return &productPageResolver{items: items, meta: newPageMeta(page, total)}, nil
+}
+
+func (r *Resolver) CreateProduct(ctx context.Context, args struct{ Input createProductInput }) (*productResolver, error) {
+	if err := required("name", args.Input.Name); err != nil {
+		return nil, newError(err)
+	}
+
+	p, err := r.products.Create(ctx, product.CreateDTO{Name: args.Input.Name, Description: args.Input.Description})
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &productResolver{p: p}, nil
+}
+
+func (r *Resolver) UpdateProduct(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updateProductInput
+}) (*productResolver, error) {
+	if err := required("name", args.Input.Name); err != nil {
+		return nil, newError(err)
+	}
+
+	payload := product.UpdateDTO{Name: args.Input.Name, Description: args.Input.Description}
+	if args.Input.IsArchived != nil {
+		payload.IsArchived = *args.Input.IsArchived
+	}
+
+	p, err := r.products.Update(ctx, string(args.ID), payload)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &productResolver{p: p}, nil
+}
+
+func (r *Resolver) DeleteProduct(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	p, err := r.products.Delete(ctx, string(args.ID))
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &productResolver{p: p}, nil
+}
@@ -0,0 +1,133 @@
+// package resolver contains the graphql schema of the resources
+// and its resolvers, they map the operations onto the use cases
+package resolver
+
+import (
+	_ "embed"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+// Schema is the schema definition of the graphql endpoint
+//
+//go:embed schema.graphql
+var Schema string
+
+// Option configures a Resolver
+type Option func(*Resolver)
+
+// WithProductPagination sets the pagination policy of the products query
+func WithProductPagination(p pagination.Policy) Option {
+	return func(r *Resolver) {
+		r.productPagination = p
+	}
+}
+
+// WithUserPagination sets the pagination policy of the users query
+func WithUserPagination(p pagination.Policy) Option {
+	return func(r *Resolver) {
+		r.userPagination = p
+	}
+}
+
+// Resolver is the root resolver of the queries and the mutations
+type Resolver struct {
+	products          product.UseCase
+	users             user.UseCase
+	productPagination pagination.Policy
+	userPagination    pagination.Policy
+}
+
+// New initializes a new Resolver of the use cases
+func New(products product.UseCase, users user.UseCase, opts ...Option) *Resolver {
+	r := &Resolver{
+		products:          products,
+		users:             users,
+		productPagination: pagination.DefaultPolicy(),
+		userPagination:    pagination.DefaultPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// MaxLimit returns the largest page limit of the list queries
+func (r *Resolver) MaxLimit() int {
+	return max(r.productPagination.MaxLimit, r.userPagination.MaxLimit)
+}
+
+// pageArgs is the page argument of the list queries
+type pageArgs struct {
+	Limit *int32
+	Page  *int32
+}
+
+// resolve returns the page of the policy, the missing
+// values fall back to the defaults of the policy
+func (a *pageArgs) resolve(p pagination.Policy) pagination.Page {
+	var limit, number int
+	if a != nil {
+		if a.Limit != nil {
+			limit = int(*a.Limit)
+		}
+
+		if a.Page != nil {
+			number = int(*a.Page)
+		}
+	}
+
+	return p.Page(limit, number)
+}
+
+// filterArgs is the filter argument of the list queries
+type filterArgs struct {
+	IsArchived *bool
+}
+
+// args returns the args of the reads of the use cases
+func (a *filterArgs) args() []any {
+	if a == nil || a.IsArchived == nil {
+		return []any{false}
+	}
+
+	return []any{*a.IsArchived}
+}
+
+// pageMeta resolves the PageMeta type
+type pageMeta struct {
+	page pagination.Page
+	meta pagination.Meta
+}
+
+func newPageMeta(page pagination.Page, total pagination.Total) *pageMeta {
+	return &pageMeta{page: page, meta: pagination.NewMeta(page, total)}
+}
+
+func (m *pageMeta) Limit() int32 {
+	return int32(m.page.Limit)
+}
+
+func (m *pageMeta) Page() int32 {
+	return int32(m.page.Number)
+}
+
+func (m *pageMeta) TotalItems() Int64 {
+	return Int64(m.meta.TotalItems)
+}
+
+func (m *pageMeta) TotalPages() int32 {
+	return int32(m.meta.TotalPages)
+}
+
+func (m *pageMeta) HasNext() bool {
+	return m.meta.HasNext
+}
+
+func (m *pageMeta) Estimated() bool {
+	return m.meta.Estimated
+}
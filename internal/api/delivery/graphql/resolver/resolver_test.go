@@ -0,0 +1,228 @@
+package resolver_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/resolver"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+// fakeUseCase serves the products of its map
+type fakeUseCase struct {
+	m map[string]product.Product
+
+	mu sync.Mutex
+	// batches records the ids of the ReadByIDs calls
+	batches [][]string
+}
+
+func (u *fakeUseCase) Create(ctx context.Context, payload product.CreateDTO) (product.Product, error) {
+	p := product.Product{ID: payload.Name, Name: payload.Name, Description: payload.Description, CreatedAt: 1, UpdatedAt: 1}
+	u.m[p.ID] = p
+
+	return p, nil
+}
+
+func (u *fakeUseCase) Upsert(ctx context.Context, payload product.CreateDTO) (product.Product, bool, error) {
+	p, err := u.Create(ctx, payload)
+	return p, true, err
+}
+
+func (u *fakeUseCase) ReadMany(ctx context.Context, limit, page int, args ...any) ([]product.Product, error) {
+	var d []product.Product
+	for _, p := range u.m {
+		d = append(d, p)
+	}
+
+	return d, nil
+}
+
+func (u *fakeUseCase) Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error) {
+	return pagination.Total{Items: int64(len(u.m))}, nil
+}
+
+func (u *fakeUseCase) Stream(ctx context.Context, args ...any) iter.Seq2[product.Product, error] {
+	return nil
+}
+
+func (u *fakeUseCase) ReadOne(ctx context.Context, id string) (product.Product, error) {
+	p, ok := u.m[id]
+	if !ok {
+		return p, errorext.NewCustomError(http.StatusNotFound, errors.New(constant.NotFound))
+	}
+
+	return p, nil
+}
+
+func (u *fakeUseCase) ReadByIDs(ctx context.Context, ids []string) ([]product.Product, error) {
+	u.mu.Lock()
+	u.batches = append(u.batches, slices.Sorted(slices.Values(ids)))
+	u.mu.Unlock()
+
+	var d []product.Product
+	for _, id := range ids {
+		if p, ok := u.m[id]; ok {
+			d = append(d, p)
+		}
+	}
+
+	return d, nil
+}
+
+func (u *fakeUseCase) Update(ctx context.Context, id string, payload product.UpdateDTO) (product.Product, error) {
+	p, err := u.ReadOne(ctx, id)
+	if err != nil {
+		return p, err
+	}
+
+	p.Name = payload.Name
+	p.IsArchived = payload.IsArchived
+	u.m[id] = p
+
+	return p, nil
+}
+
+func (u *fakeUseCase) Delete(ctx context.Context, id string) (product.Product, error) {
+	return u.ReadOne(ctx, id)
+}
+
+func (u *fakeUseCase) Purge(ctx context.Context, id string) error {
+	_, err := u.ReadOne(ctx, id)
+	return err
+}
+
+func newFakeUseCase() *fakeUseCase {
+	return &fakeUseCase{m: map[string]product.Product{
+		"1": {ID: "1", Name: "one", CreatedAt: 1, UpdatedAt: 2},
+		"2": {ID: "2", Name: "two", CreatedAt: 3, UpdatedAt: 4},
+	}}
+}
+
+func exec(t *testing.T, r *resolver.Resolver, query string) (map[string]any, []map[string]any) {
+	t.Helper()
+
+	s := graphql.MustParseSchema(resolver.Schema, r, graphql.UseStringDescriptions(), graphql.MaxDepth(3))
+	res := s.Exec(r.WithLoaders(context.Background()), query, "", nil)
+
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var body struct {
+		Data   map[string]any   `json:"data"`
+		Errors []map[string]any `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatal(err)
+	}
+
+	return body.Data, body.Errors
+}
+
+func TestResolver(t *testing.T) {
+	t.Run("batches the reads by id", func(t *testing.T) {
+		u := newFakeUseCase()
+		r := resolver.New(u, nil)
+
+		data, errs := exec(t, r, `{ a: product(id: "1") { name } b: product(id: "2") { name } c: product(id: "1") { createdAt } }`)
+		if len(errs) > 0 {
+			t.Fatalf("errors = %v", errs)
+		}
+
+		if data["a"].(map[string]any)["name"] != "one" || data["b"].(map[string]any)["name"] != "two" {
+			t.Errorf("data = %v", data)
+		}
+
+		if data["c"].(map[string]any)["createdAt"] != float64(1) {
+			t.Errorf("createdAt = %v, want 1", data["c"])
+		}
+
+		if len(u.batches) != 1 || !slices.Equal(u.batches[0], []string{"1", "2"}) {
+			t.Errorf("batches = %v, want [[1 2]]", u.batches)
+		}
+	})
+
+	t.Run("lists with the page of the policy", func(t *testing.T) {
+		r := resolver.New(newFakeUseCase(), nil, resolver.WithProductPagination(pagination.Policy{DefaultLimit: 1, MaxLimit: 5}))
+
+		data, errs := exec(t, r, `{ products(page: {limit: 50}) { items { id } meta { limit page totalItems totalPages hasNext } } }`)
+		if len(errs) > 0 {
+			t.Fatalf("errors = %v", errs)
+		}
+
+		meta := data["products"].(map[string]any)["meta"].(map[string]any)
+		want := map[string]any{"limit": float64(5), "page": float64(1), "totalItems": float64(2), "totalPages": float64(1), "hasNext": false}
+		for k, v := range want {
+			if meta[k] != v {
+				t.Errorf("meta[%s] = %v, want %v", k, meta[k], v)
+			}
+		}
+	})
+
+	errTests := []struct {
+		name   string
+		query  string
+		code   float64
+		status string
+	}{
+		{
+			name:   "not found carries the code",
+			query:  `{ product(id: "9") { id } }`,
+			code:   http.StatusNotFound,
+			status: "NOT_FOUND",
+		},
+		{
+			name:   "use case error carries the code",
+			query:  `mutation { updateProduct(id: "9", input: {name: "x"}) { id } }`,
+			code:   http.StatusNotFound,
+			status: "NOT_FOUND",
+		},
+		{
+			name:   "validation error",
+			query:  `mutation { createProduct(input: {name: " "}) { id } }`,
+			code:   http.StatusBadRequest,
+			status: "BAD_REQUEST",
+		},
+	}
+
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, errs := exec(t, resolver.New(newFakeUseCase(), nil), tt.query)
+			if len(errs) != 1 {
+				t.Fatalf("errors = %v, want one", errs)
+			}
+
+			ext, _ := errs[0]["extensions"].(map[string]any)
+			if ext["code"] != tt.code || ext["status"] != tt.status {
+				t.Errorf("extensions = %v, want code %v status %s", ext, tt.code, tt.status)
+			}
+		})
+	}
+
+	t.Run("rejects the deep queries", func(t *testing.T) {
+		r := resolver.New(newFakeUseCase(), nil)
+
+		_, errs := exec(t, r, `{ products { items { id } } }`)
+		if len(errs) > 0 {
+			t.Fatalf("errors = %v", errs)
+		}
+
+		_, errs = exec(t, r, `{ __schema { types { fields { name } } } }`)
+		if len(errs) == 0 || !strings.Contains(errs[0]["message"].(string), "exceeds max depth") {
+			t.Errorf("errors = %v, want the depth error", errs)
+		}
+	})
+}
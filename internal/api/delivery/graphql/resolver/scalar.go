@@ -0,0 +1,41 @@
+package resolver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Int64 implements the Int64 scalar, the Int of graphql is 32 bit
+type Int64 int64
+
+func (Int64) ImplementsGraphQLType(name string) bool {
+	return name == "Int64"
+}
+
+// UnmarshalGraphQL accepts the numbers and the numeric strings
+func (i *Int64) UnmarshalGraphQL(input any) error {
+	switch v := input.(type) {
+	case int32:
+		*i = Int64(v)
+	case int64:
+		*i = Int64(v)
+	case float64:
+		*i = Int64(v)
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		*i = Int64(n)
+	default:
+		return fmt.Errorf("wrong type for Int64: %T", input)
+	}
+
+	return nil
+}
+
+func (i Int64) MarshalJSON() ([]byte, error) {
+	return json.Marshal(int64(i))
+}
@@ -0,0 +1,141 @@
+package resolver
+
+import (
+	"context"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+)
+
+// userResolver resolves the User type
+type userResolver struct {
+	u user.User
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return graphql.ID(r.u.ID)
+}
+
+func (r *userResolver) Name() string {
+	return r.u.Name
+}
+
+func (r *userResolver) Address() *string {
+	return r.u.Address
+}
+
+func (r *userResolver) IsArchived() bool {
+	return r.u.IsArchived
+}
+
+func (r *userResolver) CreatedAt() Int64 {
+	return Int64(r.u.CreatedAt)
+}
+
+func (r *userResolver) UpdatedAt() Int64 {
+	return Int64(r.u.UpdatedAt)
+}
+
+// userPageResolver resolves the UserPage type
+type userPageResolver struct {
+	items []*userResolver
+	meta  *pageMeta
+}
+
+func (r *userPageResolver) Items() []*userResolver {
+	return r.items
+}
+
+func (r *userPageResolver) Meta() *pageMeta {
+	return r.meta
+}
+
+type createUserInput struct {
+	Name    string
+	Address *string
+}
+
+type updateUserInput struct {
+	Name       string
+	Address    *string
+	IsArchived *bool
+}
+
+// User reads the user through the loader of the request
+// so that the user fields of a query are read in one batch
+func (r *Resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	u, err := r.loadersFrom(ctx).users.Load(ctx, string(args.ID))
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &userResolver{u: u}, nil
+}
+
+func (r *Resolver) Users(ctx context.Context, args struct {
+	Page   *pageArgs
+	Filter *filterArgs
+}) (*userPageResolver, error) {
+	page := args.Page.resolve(r.userPagination)
+
+	a := args.Filter.args()
+	d, err := r.users.ReadMany(ctx, page.Limit, page.Number, a...)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	total, err := r.users.Count(ctx, r.userPagination, a...)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	items := make([]*userResolver, 0, len(d))
+	for _, u := range d {
+		items = append(items, &userResolver{u: u})
+	}
+
+	return &userPageResolver{items: items, meta: newPageMeta(page, total)}, nil
+}
+
+func (r *Resolver) CreateUser(ctx context.Context, args struct{ Input createUserInput }) (*userResolver, error) {
+	if err := required("name", args.Input.Name); err != nil {
+		return nil, newError(err)
+	}
+
+	u, err := r.users.Create(ctx, user.CreateDTO{Name: args.Input.Name, Address: args.Input.Address})
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &userResolver{u: u}, nil
+}
+
+func (r *Resolver) UpdateUser(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updateUserInput
+}) (*userResolver, error) {
+	if err := required("name", args.Input.Name); err != nil {
+		return nil, newError(err)
+	}
+
+	payload := user.UpdateDTO{Name: args.Input.Name, Address: args.Input.Address}
+	if args.Input.IsArchived != nil {
+		payload.IsArchived = *args.Input.IsArchived
+	}
+
+	u, err := r.users.Update(ctx, string(args.ID), payload)
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &userResolver{u: u}, nil
+}
+
+func (r *Resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	u, err := r.users.Delete(ctx, string(args.ID))
+	if err != nil {
+		return nil, newError(err)
+	}
+
+	return &userResolver{u: u}, nil
+}
@@ -0,0 +1,115 @@
+// package handler contains the grpc services of the resources
+// they map the messages of package pb onto the use cases
+package handler
+
+import (
+	"context"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+// Product implements pb.ProductServiceServer
+type Product struct {
+	pb.UnimplementedProductServiceServer
+	useCase product.UseCase
+	// pagination is the policy of the list method
+	pagination pagination.Policy
+}
+
+// NewProduct initializes a new Product service
+func NewProduct(u product.UseCase, opts ...Option) *Product {
+	o := newOptions(opts)
+
+	return &Product{useCase: u, pagination: o.pagination}
+}
+
+func (h *Product) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
+	d, err := h.useCase.Create(ctx, product.CreateDTO{Name: req.GetName(), Description: req.Description})
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toProduct(d), nil
+}
+
+func (h *Product) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	page := h.pagination.Page(int(req.GetLimit()), int(req.GetPage()))
+
+	args := []any{req.GetIsArchived()}
+	d, err := h.useCase.ReadMany(ctx, page.Limit, page.Number, args...)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	total, err := h.useCase.Count(ctx, h.pagination, args...)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	items := make([]*pb.Product, 0, len(d))
+	for _, p := range d {
+		items = append(items, toProduct(p))
+	}
+
+	return &pb.ListProductsResponse{Items: items, Meta: toPageMeta(page, total)}, nil
+}
+
+func (h *Product) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	d, err := h.useCase.ReadOne(ctx, req.GetId())
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toProduct(d), nil
+}
+
+func (h *Product) UpdateProduct(ctx context.Context, req *pb.UpdateProductRequest) (*pb.Product, error) {
+	payload := product.UpdateDTO{
+		Name:        req.GetName(),
+		Description: req.Description,
+		IsArchived:  req.GetIsArchived(),
+	}
+
+	d, err := h.useCase.Update(ctx, req.GetId(), payload)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toProduct(d), nil
+}
+
+func (h *Product) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.Product, error) {
+	d, err := h.useCase.Delete(ctx, req.GetId())
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toProduct(d), nil
+}
+
+func toProduct(p product.Product) *pb.Product {
+	return &pb.Product{
+		Id:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		IsArchived:  p.IsArchived,
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+// toPageMeta describes the page as the meta of the http list responses
+func toPageMeta(page pagination.Page, total pagination.Total) *pb.PageMeta {
+	meta := pagination.NewMeta(page, total)
+
+	return &pb.PageMeta{
+		Limit:      int32(page.Limit),
+		Page:       int32(page.Number),
+		TotalItems: meta.TotalItems,
+		TotalPages: int32(meta.TotalPages),
+		HasNext:    meta.HasNext,
+		Estimated:  meta.Estimated,
+	}
+}
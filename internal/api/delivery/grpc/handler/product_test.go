@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/interceptor"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeUseCase serves the products of its map
+type fakeUseCase struct {
+	m map[string]product.Product
+	// page records the page of the last ReadMany
+	page [2]int
+}
+
+func (u *fakeUseCase) Create(ctx context.Context, payload product.CreateDTO) (product.Product, error) {
+	p := product.Product{ID: payload.Name, Name: payload.Name, Description: payload.Description, CreatedAt: 1, UpdatedAt: 1}
+	u.m[p.ID] = p
+
+	return p, nil
+}
+
+func (u *fakeUseCase) Upsert(ctx context.Context, payload product.CreateDTO) (product.Product, bool, error) {
+	p, err := u.Create(ctx, payload)
+	return p, true, err
+}
+
+func (u *fakeUseCase) ReadMany(ctx context.Context, limit, page int, args ...any) ([]product.Product, error) {
+	u.page = [2]int{limit, page}
+
+	var d []product.Product
+	for _, p := range u.m {
+		d = append(d, p)
+	}
+
+	return d, nil
+}
+
+func (u *fakeUseCase) Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error) {
+	return pagination.Total{Items: int64(len(u.m))}, nil
+}
+
+func (u *fakeUseCase) Stream(ctx context.Context, args ...any) iter.Seq2[product.Product, error] {
+	return nil
+}
+
+func (u *fakeUseCase) ReadOne(ctx context.Context, id string) (product.Product, error) {
+	if id == "panic" {
+		panic("read one")
+	}
+
+	p, ok := u.m[id]
+	if !ok {
+		return p, errorext.NewCustomError(http.StatusNotFound, errors.New(constant.NotFound))
+	}
+
+	return p, nil
+}
+
+func (u *fakeUseCase) ReadByIDs(ctx context.Context, ids []string) ([]product.Product, error) {
+	var d []product.Product
+	for _, id := range ids {
+		if p, ok := u.m[id]; ok {
+			d = append(d, p)
+		}
+	}
+
+	return d, nil
+}
+
+func (u *fakeUseCase) Update(ctx context.Context, id string, payload product.UpdateDTO) (product.Product, error) {
+	p, err := u.ReadOne(ctx, id)
+	if err != nil {
+		return p, err
+	}
+
+	p.Name = payload.Name
+	u.m[id] = p
+
+	return p, nil
+}
+
+func (u *fakeUseCase) Delete(ctx context.Context, id string) (product.Product, error) {
+	p, err := u.ReadOne(ctx, id)
+	if err != nil {
+		return p, err
+	}
+
+	p.IsArchived = true
+	u.m[id] = p
+
+	return p, nil
+}
+
+func (u *fakeUseCase) Purge(ctx context.Context, id string) error {
+	if _, err := u.ReadOne(ctx, id); err != nil {
+		return err
+	}
+
+	delete(u.m, id)
+
+	return nil
+}
+
+// newClient serves the product service over an in memory listener
+// with the interceptors of the app
+func newClient(t *testing.T, u product.UseCase, opts ...handler.Option) pb.ProductServiceClient {
+	lis := bufconn.Listen(1 << 20)
+
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptor.Recovery, interceptor.Logging, interceptor.Validation))
+	pb.RegisterProductServiceServer(s, handler.NewProduct(u, opts...))
+
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewProductServiceClient(conn)
+}
+
+func TestProduct(t *testing.T) {
+	u := &fakeUseCase{m: make(map[string]product.Product)}
+	c := newClient(t, u, handler.WithPagination(pagination.Policy{DefaultLimit: 5, MaxLimit: 20}))
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		description := "description 1"
+
+		created, err := c.CreateProduct(ctx, &pb.CreateProductRequest{Name: "name 1", Description: &description})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		p, err := c.GetProduct(ctx, &pb.GetProductRequest{Id: created.GetId()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p.GetName() != "name 1" || p.GetDescription() != description {
+			t.Errorf("unexpected product %v", p)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		res, err := c.ListProducts(ctx, &pb.ListProductsRequest{Limit: 50, Page: 0})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.page != [2]int{20, 1} {
+			t.Errorf("expected the page of the policy, got %v", u.page)
+		}
+
+		if len(res.GetItems()) != 1 || res.GetMeta().GetTotalItems() != 1 || res.GetMeta().GetLimit() != 20 {
+			t.Errorf("unexpected response %v", res)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			call     func() error
+			expected codes.Code
+		}{
+			{
+				name: "not found",
+				call: func() error {
+					_, err := c.GetProduct(ctx, &pb.GetProductRequest{Id: "missing"})
+					return err
+				},
+				expected: codes.NotFound,
+			},
+			{
+				name: "invalid",
+				call: func() error {
+					_, err := c.UpdateProduct(ctx, &pb.UpdateProductRequest{Id: "name 1"})
+					return err
+				},
+				expected: codes.InvalidArgument,
+			},
+			{
+				name: "panic",
+				call: func() error {
+					_, err := c.GetProduct(ctx, &pb.GetProductRequest{Id: "panic"})
+					return err
+				},
+				expected: codes.Internal,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				if code := status.Code(tc.call()); code != tc.expected {
+					t.Errorf("expected %s, got %s", tc.expected, code)
+				}
+			})
+		}
+	})
+}
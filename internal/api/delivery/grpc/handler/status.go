@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusCodes maps the http status codes of the
+// custom errors to the grpc status codes
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusInternalServerError:   codes.Internal,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// Code returns the grpc status code of the http status code
+// the unmapped client errors are FailedPrecondition and
+// the unmapped server errors are Internal
+func Code(httpCode int) codes.Code {
+	if c, ok := statusCodes[httpCode]; ok {
+		return c
+	}
+
+	if httpCode >= http.StatusBadRequest && httpCode < http.StatusInternalServerError {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
+
+// Error converts the error of a use case to a grpc status error
+// with the code of its errorext.CustomError, the errors of the
+// context keep their meaning
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	e := errorext.ParseCustomError(err)
+
+	return status.Error(Code(e.Code()), e.Error())
+}
@@ -0,0 +1,44 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/handler"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{name: "bad request", err: errorext.NewCustomError(http.StatusBadRequest, errors.New("bad")), expected: codes.InvalidArgument},
+		{name: "not found", err: errorext.NewCustomError(http.StatusNotFound, errors.New("missing")), expected: codes.NotFound},
+		{name: "conflict", err: errorext.NewCustomError(http.StatusConflict, errors.New("conflict")), expected: codes.AlreadyExists},
+		{name: "wrapped", err: fmt.Errorf("read: %w", errorext.NewCustomError(http.StatusNotFound, errors.New("missing"))), expected: codes.NotFound},
+		{name: "unmapped client error", err: errorext.NewCustomError(http.StatusTeapot, errors.New("tea")), expected: codes.FailedPrecondition},
+		{name: "plain error", err: errors.New("boom"), expected: codes.Internal},
+		{name: "canceled", err: context.Canceled, expected: codes.Canceled},
+		{name: "deadline", err: fmt.Errorf("query: %w", context.DeadlineExceeded), expected: codes.DeadlineExceeded},
+		{name: "status kept", err: status.Error(codes.Unavailable, "down"), expected: codes.Unavailable},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := status.Code(handler.Error(tc.err)); code != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, code)
+			}
+		})
+	}
+
+	if err := handler.Error(nil); err != nil {
+		t.Errorf("expected nil, got %v", err)
+	}
+}
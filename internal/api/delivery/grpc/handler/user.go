@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+// User implements pb.UserServiceServer
+type User struct {
+	pb.UnimplementedUserServiceServer
+	useCase user.UseCase
+	// pagination is the policy of the list method
+	pagination pagination.Policy
+}
+
+// NewUser initializes a new User service
+func NewUser(u user.UseCase, opts ...Option) *User {
+	o := newOptions(opts)
+
+	return &User{useCase: u, pagination: o.pagination}
+}
+
+func (h *User) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.User, error) {
+	d, err := h.useCase.Create(ctx, user.CreateDTO{Name: req.GetName(), Address: req.Address})
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toUser(d), nil
+}
+
+func (h *User) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	page := h.pagination.Page(int(req.GetLimit()), int(req.GetPage()))
+
+	args := []any{req.GetIsArchived()}
+	d, err := h.useCase.ReadMany(ctx, page.Limit, page.Number, args...)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	total, err := h.useCase.Count(ctx, h.pagination, args...)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	items := make([]*pb.User, 0, len(d))
+	for _, u := range d {
+		items = append(items, toUser(u))
+	}
+
+	return &pb.ListUsersResponse{Items: items, Meta: toPageMeta(page, total)}, nil
+}
+
+func (h *User) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	d, err := h.useCase.ReadOne(ctx, req.GetId())
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toUser(d), nil
+}
+
+func (h *User) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.User, error) {
+	payload := user.UpdateDTO{
+		Name:       req.GetName(),
+		Address:    req.Address,
+		IsArchived: req.GetIsArchived(),
+	}
+
+	d, err := h.useCase.Update(ctx, req.GetId(), payload)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toUser(d), nil
+}
+
+func (h *User) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest) (*pb.User, error) {
+	d, err := h.useCase.Delete(ctx, req.GetId())
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	return toUser(d), nil
+}
+
+func toUser(u user.User) *pb.User {
+	return &pb.User{
+		Id:         u.ID,
+		Name:       u.Name,
+		Address:    u.Address,
+		IsArchived: u.IsArchived,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}
+}
@@ -0,0 +1,99 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Authenticator authenticates the calls with the bearer token of
+// the authorization metadata and stores the principal in the context
+// as the http middleware does, the methods of the public services,
+// ex: grpc.health.v1.Health, pass as anonymous
+type Authenticator struct {
+	verifier *auth.Verifier
+	public   []string
+}
+
+// NewAuthenticator initializes an Authenticator of the verifier
+func NewAuthenticator(v *auth.Verifier, publicServices ...string) *Authenticator {
+	return &Authenticator{verifier: v, public: publicServices}
+}
+
+// Unary authenticates the unary calls
+func (a *Authenticator) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+// Stream authenticates the streaming calls
+func (a *Authenticator) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate returns ctx carrying the principal of the call
+// the calls without a valid token fail with Unauthenticated
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if a.isPublic(fullMethod) {
+		return ctx, nil
+	}
+
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, constant.MissingCredentials)
+	}
+
+	p, err := a.verifier.Verify(ctx, token)
+	if err != nil {
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return auth.WithPrincipal(ctx, p), nil
+}
+
+// isPublic reports if the method, /package.Service/Method,
+// belongs to a public service
+func (a *Authenticator) isPublic(fullMethod string) bool {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+
+	return slices.Contains(a.public, service)
+}
+
+// bearerToken returns the token of the authorization metadata
+func bearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(strings.ToLower(constant.HeaderAuthorization)) {
+		scheme, token, ok := strings.Cut(v, " ")
+		if ok && strings.EqualFold(scheme, constant.AuthSchemeBearer) && token != "" {
+			return strings.TrimSpace(token), true
+		}
+	}
+
+	return "", false
+}
+
+// serverStream overrides the context of a stream
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,66 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/interceptor"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthenticator(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub": "user-1",
+		"exp": time.Now().Add(time.Minute).Unix(),
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := interceptor.NewAuthenticator(auth.NewVerifier(auth.HMACKey(secret)), "grpc.health.v1.Health")
+
+	// next responds with the subject of the principal
+	next := func(ctx context.Context, req any) (any, error) {
+		p, _ := auth.PrincipalFrom(ctx)
+		return p.Subject, nil
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		code          codes.Code
+		subject       string
+	}{
+		{name: "valid token", method: "/api.v1.ProductService/GetProduct", authorization: "Bearer " + token, code: codes.OK, subject: "user-1"},
+		{name: "missing token", method: "/api.v1.ProductService/GetProduct", code: codes.Unauthenticated},
+		{name: "invalid token", method: "/api.v1.ProductService/GetProduct", authorization: "Bearer x.y.z", code: codes.Unauthenticated},
+		{name: "public service", method: "/grpc.health.v1.Health/Check", code: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.authorization != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.authorization))
+			}
+
+			res, err := a.Unary(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, next)
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("code = %s, want %s", code, tt.code)
+			}
+
+			if err == nil && res != tt.subject {
+				t.Errorf("subject = %v, want %q", res, tt.subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+// package interceptor contains the interceptors of the grpc server
+// they are chained in the order: recovery, logging, authentication, validation
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validator is implemented by the request messages which have rules
+type validator interface {
+	Validate() error
+}
+
+// Recovery converts a panic of a handler to an Internal error
+// the panic and its stack are logged, the server keeps running
+func Recovery(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("grpc %s panic: %v\n%s", info.FullMethod, p, debug.Stack())
+			err = status.Error(codes.Internal, constant.InternalServerError)
+		}
+	}()
+
+	return handler(ctx, req)
+}
+
+// StreamRecovery is Recovery of the streaming methods
+func StreamRecovery(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("grpc %s panic: %v\n%s", info.FullMethod, p, debug.Stack())
+			err = status.Error(codes.Internal, constant.InternalServerError)
+		}
+	}()
+
+	return handler(srv, ss)
+}
+
+// Logging logs the method, the status code and the duration of a call
+func Logging(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	res, err := handler(ctx, req)
+	log.Printf("grpc %s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+
+	return res, err
+}
+
+// StreamLogging is Logging of the streaming methods
+func StreamLogging(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	err := handler(srv, ss)
+	log.Printf("grpc %s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+
+	return err
+}
+
+// Validation validates the requests implementing Validate
+// an invalid request fails with InvalidArgument and the
+// fields are reported as BadRequest details
+func Validation(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if v, ok := req.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, invalidArgument(err)
+		}
+	}
+
+	return handler(ctx, req)
+}
+
+// invalidArgument builds the status of the validation error
+// the message lists the violations as the http error response
+func invalidArgument(err error) error {
+	errs := []error{err}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = j.Unwrap()
+	}
+
+	br := &errdetails.BadRequest{}
+	msgs := make([]string, 0, len(errs))
+
+	for _, e := range errs {
+		v := errorext.ValidationError{Message: e.Error()}
+		errors.As(e, &v)
+
+		msgs = append(msgs, v.Message)
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{Field: v.Name, Description: v.Message})
+	}
+
+	st := status.New(codes.InvalidArgument, strings.Join(msgs, "; "))
+	if d, err := st.WithDetails(br); err == nil {
+		return d.Err()
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,79 @@
+package interceptor_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/interceptor"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var info = &grpc.UnaryServerInfo{FullMethod: "/api.v1.ProductService/UpdateProduct"}
+
+func TestValidation(t *testing.T) {
+	called := false
+	next := func(ctx context.Context, req any) (any, error) {
+		called = true
+		return req, nil
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		called = false
+
+		_, err := interceptor.Validation(context.Background(), &pb.UpdateProductRequest{}, info, next)
+
+		st := status.Convert(err)
+		if st.Code() != codes.InvalidArgument || called {
+			t.Fatalf("expected InvalidArgument without the call, got %v called %t", err, called)
+		}
+
+		if st.Message() != "id required; name required" {
+			t.Errorf("unexpected message %q", st.Message())
+		}
+
+		var fields []string
+		for _, d := range st.Details() {
+			if br, ok := d.(*errdetails.BadRequest); ok {
+				for _, v := range br.GetFieldViolations() {
+					fields = append(fields, v.GetField())
+				}
+			}
+		}
+
+		if len(fields) != 2 || fields[0] != "id" || fields[1] != "name" {
+			t.Errorf("expected the id and name violations, got %v", fields)
+		}
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		called = false
+
+		_, err := interceptor.Validation(context.Background(), &pb.UpdateProductRequest{Id: "1", Name: "name"}, info, next)
+		if err != nil || !called {
+			t.Errorf("expected the call, got %v called %t", err, called)
+		}
+	})
+
+	t.Run("no rules", func(t *testing.T) {
+		called = false
+
+		_, err := interceptor.Validation(context.Background(), &pb.ListProductsRequest{}, info, next)
+		if err != nil || !called {
+			t.Errorf("expected the call, got %v called %t", err, called)
+		}
+	})
+}
+
+func TestRecovery(t *testing.T) {
+	_, err := interceptor.Recovery(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal, got %v", err)
+	}
+}
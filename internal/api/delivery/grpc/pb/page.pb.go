@@ -0,0 +1,172 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: api/v1/page.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PageMeta describes a page of a list, see pagination.Meta
+type PageMeta struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// limit is the effective number of items per page
+	Limit      int32 `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Page       int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
+	TotalItems int64 `protobuf:"varint,3,opt,name=total_items,json=totalItems,proto3" json:"total_items,omitempty"`
+	TotalPages int32 `protobuf:"varint,4,opt,name=total_pages,json=totalPages,proto3" json:"total_pages,omitempty"`
+	HasNext    bool  `protobuf:"varint,5,opt,name=has_next,json=hasNext,proto3" json:"has_next,omitempty"`
+	// estimated is set when total_items is the planner estimate
+	Estimated     bool `protobuf:"varint,6,opt,name=estimated,proto3" json:"estimated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageMeta) Reset() {
+	*x = PageMeta{}
+	mi := &file_api_v1_page_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageMeta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageMeta) ProtoMessage() {}
+
+func (x *PageMeta) ProtoReflect() protoreflect.Message {
+	mi := &file_api_v1_page_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageMeta.ProtoReflect.Descriptor instead.
+func (*PageMeta) Descriptor() ([]byte, []int) {
+	return file_api_v1_page_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PageMeta) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PageMeta) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PageMeta) GetTotalItems() int64 {
+	if x != nil {
+		return x.TotalItems
+	}
+	return 0
+}
+
+func (x *PageMeta) GetTotalPages() int32 {
+	if x != nil {
+		return x.TotalPages
+	}
+	return 0
+}
+
+func (x *PageMeta) GetHasNext() bool {
+	if x != nil {
+		return x.HasNext
+	}
+	return false
+}
+
+func (x *PageMeta) GetEstimated() bool {
+	if x != nil {
+		return x.Estimated
+	}
+	return false
+}
+
+var File_api_v1_page_proto protoreflect.FileDescriptor
+
+const file_api_v1_page_proto_rawDesc = "" +
+	"\n" +
+	"\x11api/v1/page.proto\x12\x06api.v1\"\xaf\x01\n" +
+	"\bPageMeta\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x1f\n" +
+	"\vtotal_items\x18\x03 \x01(\x03R\n" +
+	"totalItems\x12\x1f\n" +
+	"\vtotal_pages\x18\x04 \x01(\x05R\n" +
+	"totalPages\x12\x19\n" +
+	"\bhas_next\x18\x05 \x01(\bR\ahasNext\x12\x1c\n" +
+	"\testimated\x18\x06 \x01(\bR\testimatedBQZOgithub.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb;pbb\x06proto3"
+
+var (
+	file_api_v1_page_proto_rawDescOnce sync.Once
+	file_api_v1_page_proto_rawDescData []byte
+)
+
+func file_api_v1_page_proto_rawDescGZIP() []byte {
+	file_api_v1_page_proto_rawDescOnce.Do(func() {
+		file_api_v1_page_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_api_v1_page_proto_rawDesc), len(file_api_v1_page_proto_rawDesc)))
+	})
+	return file_api_v1_page_proto_rawDescData
+}
+
+var file_api_v1_page_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_api_v1_page_proto_goTypes = []any{
+	(*PageMeta)(nil), // 0: api.v1.PageMeta
+}
+var file_api_v1_page_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_api_v1_page_proto_init() }
+func file_api_v1_page_proto_init() {
+	if File_api_v1_page_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_v1_page_proto_rawDesc), len(file_api_v1_page_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_api_v1_page_proto_goTypes,
+		DependencyIndexes: file_api_v1_page_proto_depIdxs,
+		MessageInfos:      file_api_v1_page_proto_msgTypes,
+	}.Build()
+	File_api_v1_page_proto = out.File
+	file_api_v1_page_proto_goTypes = nil
+	file_api_v1_page_proto_depIdxs = nil
+}
@@ -0,0 +1,52 @@
+package pb
+
+import (
+	"errors"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// the requests are validated by the validation interceptor
+// with the rules of the http dtos
+
+func (r *CreateProductRequest) Validate() error {
+	return required("name", r.GetName())
+}
+
+func (r *GetProductRequest) Validate() error {
+	return required("id", r.GetId())
+}
+
+func (r *UpdateProductRequest) Validate() error {
+	return errors.Join(required("id", r.GetId()), required("name", r.GetName()))
+}
+
+func (r *DeleteProductRequest) Validate() error {
+	return required("id", r.GetId())
+}
+
+func (r *CreateUserRequest) Validate() error {
+	return required("name", r.GetName())
+}
+
+func (r *GetUserRequest) Validate() error {
+	return required("id", r.GetId())
+}
+
+func (r *UpdateUserRequest) Validate() error {
+	return errors.Join(required("id", r.GetId()), required("name", r.GetName()))
+}
+
+func (r *DeleteUserRequest) Validate() error {
+	return required("id", r.GetId())
+}
+
+// required returns the error of a missing field
+// in the form of validatorext
+func required(name, v string) error {
+	if v == "" {
+		return errorext.ValidationError{Name: name, Message: name + " required"}
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+// package apidoc generates the OpenAPI document of the http api
+// from the registered chi routes and the delivery dtos
+// it also serves the committed document and a docs page
+package apidoc
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/openapi"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// SpecFile is the name of the committed document
+const SpecFile = "openapi.json"
+
+// the names of the security schemes of the bearer tokens and the api keys
+const (
+	bearerScheme = "bearerAuth"
+	apiKeyScheme = "apiKeyAuth"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// assets are the vendored swagger ui files of the docs page
+//
+//go:embed assets/swagger-ui-bundle.js assets/swagger-ui.css
+var assets embed.FS
+
+// DocsContentSecurityPolicy is the content security policy of the docs
+// page, it loads the vendored swagger ui and runs the inline script
+var DocsContentSecurityPolicy = "default-src 'none'; script-src 'self' '" + inlineScriptHash(docs) +
+	"'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+var inlineScriptRegex = regexp.MustCompile(`(?s)<script>(.*?)</script>`)
+
+var pathParamRegex = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Spec returns the committed OpenAPI document
+func Spec() []byte {
+	return spec
+}
+
+// SpecHandler serves the committed OpenAPI document
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(spec)
+}
+
+// DocsHandler serves the api docs page which renders
+// the document served by SpecHandler
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(docs)
+}
+
+// AssetsHandler serves the swagger ui files of the docs page, it
+// must be mounted under the docs pattern, ex: /api/docs/swagger-ui.css
+func AssetsHandler(prefix string) http.Handler {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err)
+	}
+
+	return http.StripPrefix(prefix, http.FileServer(http.FS(sub)))
+}
+
+// inlineScriptHash returns the csp hash source of the inline script of the page
+func inlineScriptHash(page []byte) string {
+	m := inlineScriptRegex.FindSubmatch(page)
+	if m == nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(m[1])
+
+	return "sha256-" + base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Generate builds the OpenAPI document by walking the routes
+// the routes must be the fully mounted application routes
+// so that the paths are complete
+func Generate(routes chi.Routes) (*openapi.Document, error) {
+	d := openapi.NewDocument(
+		openapi.Info{
+			Title:       "backend-structure-go api",
+			Description: "http api of the backend-structure-go service",
+			Version:     "1.0.0",
+		},
+	)
+
+	// the documented routes are authenticated, the public ones are excluded
+	d.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		bearerScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		apiKeyScheme: {Type: "http", Scheme: constant.AuthSchemeAPIKey, Description: "Authorization: ApiKey <key>, the key is limited to its scopes"},
+	}
+	d.Security = []openapi.SecurityRequirement{{bearerScheme: {}}, {apiKeyScheme: {}}}
+
+	tags := make(map[string]struct{})
+
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := normalizePath(route)
+
+		if _, ok := excluded[path]; ok {
+			return nil
+		}
+
+		item, ok := d.Paths[path]
+		if !ok {
+			item = &openapi.PathItem{}
+			d.Paths[path] = item
+		}
+
+		op := buildOperation(d, method, path)
+		if !item.SetOperation(method, op) {
+			// methods like OPTIONS, HEAD are not documented
+			return nil
+		}
+
+		for _, t := range op.Tags {
+			tags[t] = struct{}{}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for t := range tags {
+		d.Tags = append(d.Tags, openapi.Tag{Name: t})
+	}
+
+	sort.Slice(d.Tags, func(i, j int) bool { return d.Tags[i].Name < d.Tags[j].Name })
+
+	return d, nil
+}
+
+// Marshal encodes the document in the committed format
+func Marshal(d *openapi.Document) ([]byte, error) {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(b, '\n'), nil
+}
+
+// normalizePath converts a chi route pattern to an OpenAPI path
+func normalizePath(route string) string {
+	// remove the wildcard of mounted sub routers
+	route = strings.TrimSuffix(route, "/*")
+
+	if len(route) > 1 {
+		route = strings.TrimSuffix(route, "/")
+	}
+
+	// drop the regex part of chi url params {id:[0-9]+}
+	return pathParamRegex.ReplaceAllString(route, "{$1}")
+}
+
+func buildOperation(d *openapi.Document, method, path string) *openapi.Operation {
+	meta, ok := operations[method+" "+path]
+	if !ok {
+		meta = operation{status: http.StatusOK}
+	}
+
+	op := &openapi.Operation{
+		OperationID: meta.id,
+		Summary:     meta.summary,
+		Tags:        meta.tags,
+		Responses:   make(map[string]*openapi.Response),
+	}
+
+	for _, m := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, openapi.Parameter{
+			Name:     m[1],
+			In:       "path",
+			Required: true,
+			Schema:   &openapi.Schema{Type: "string"},
+		})
+	}
+
+	op.Parameters = append(op.Parameters, meta.query...)
+	op.Parameters = append(op.Parameters, meta.headers...)
+
+	if meta.conditional {
+		op.Parameters = append(op.Parameters, ifNoneMatchHeader)
+		if meta.lastModified {
+			op.Parameters = append(op.Parameters, ifModifiedSinceHeader)
+		}
+
+		op.Responses[strconv.Itoa(http.StatusNotModified)] = &openapi.Response{Description: http.StatusText(http.StatusNotModified)}
+	}
+
+	switch {
+	case meta.upload:
+		item := d.SchemaFor(meta.request)
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content: map[string]openapi.MediaType{
+				"text/csv":             {Schema: &openapi.Schema{Type: "string"}},
+				"application/x-ndjson": {Schema: item},
+				"multipart/form-data": {Schema: &openapi.Schema{
+					Type: "object",
+					Properties: map[string]*openapi.Schema{
+						constant.ImportFormField: {Type: "string", Format: "binary"},
+					},
+				}},
+			},
+		}
+	case meta.request != nil:
+		op.RequestBody = &openapi.RequestBody{
+			Required: true,
+			Content:  openapi.JSONContent(d.SchemaFor(meta.request)),
+		}
+	}
+
+	success := &openapi.Response{Description: http.StatusText(meta.status)}
+	switch {
+	case meta.events:
+		success.Content = map[string]openapi.MediaType{
+			"text/event-stream": {Schema: d.SchemaFor(meta.response)},
+		}
+	case meta.stream:
+		success.Content = map[string]openapi.MediaType{
+			"application/x-ndjson": {Schema: d.SchemaFor(meta.response)},
+			"text/csv":             {Schema: &openapi.Schema{Type: "string"}},
+		}
+	case meta.response != nil:
+		success.Content = openapi.JSONContent(d.SchemaFor(meta.response))
+	}
+
+	op.Responses[strconv.Itoa(meta.status)] = success
+
+	if meta.events {
+		op.Responses[strconv.Itoa(http.StatusSwitchingProtocols)] = &openapi.Response{
+			Description: "WebSocket of the entries as JSON text messages",
+		}
+	}
+
+	if meta.upload {
+		op.Responses[strconv.Itoa(http.StatusAccepted)] = &openapi.Response{
+			Description: http.StatusText(http.StatusAccepted),
+			Content:     success.Content,
+		}
+	}
+
+	errResponse := meta.errorResponse
+	if errResponse == nil {
+		errResponse = response.ErrorResponse{}
+	}
+
+	errSchema := d.SchemaFor(errResponse)
+	for _, code := range meta.errors {
+		op.Responses[strconv.Itoa(code)] = &openapi.Response{
+			Description: http.StatusText(code),
+			Content:     openapi.JSONContent(errSchema),
+		}
+	}
+
+	// the rate limit middleware responds with a problem, RFC 9457
+	op.Responses[strconv.Itoa(http.StatusTooManyRequests)] = &openapi.Response{
+		Description: http.StatusText(http.StatusTooManyRequests),
+		Content: map[string]openapi.MediaType{
+			response.ContentTypeProblem: {Schema: d.SchemaFor(response.Problem{})},
+		},
+	}
+
+	if meta.public {
+		op.Security = &[]openapi.SecurityRequirement{}
+		return op
+	}
+
+	// the authentication middleware responds before the handler
+	op.Responses[strconv.Itoa(http.StatusUnauthorized)] = &openapi.Response{
+		Description: http.StatusText(http.StatusUnauthorized),
+		Content:     openapi.JSONContent(d.SchemaFor(response.ErrorResponse{})),
+	}
+
+	// the authorization middleware responds with a problem, RFC 9457
+	op.Responses[strconv.Itoa(http.StatusForbidden)] = &openapi.Response{
+		Description: http.StatusText(http.StatusForbidden),
+		Content: map[string]openapi.MediaType{
+			response.ContentTypeProblem: {Schema: d.SchemaFor(response.Problem{})},
+		},
+	}
+
+	return op
+}
+
+// queryParam is a helper to build an optional query parameter
+func queryParam(name, typ, description string) openapi.Parameter {
+	return openapi.Parameter{
+		Name:        name,
+		In:          "query",
+		Description: description,
+		Schema:      &openapi.Schema{Type: typ},
+	}
+}
+
+// excluded contains the paths which are not part of the document
+var excluded = map[string]struct{}{
+	constant.ApiPattern + constant.OpenAPIPattern: {},
+	constant.ApiPattern + constant.DocsPattern:    {},
+}
+
+// operationKey builds the key of the operations map
+func operationKey(method, path string) string {
+	return fmt.Sprintf("%s %s", method, path)
+}
@@ -0,0 +1,232 @@
+package apidoc_test
+
+import (
+	"bytes"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/apidoc"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+)
+
+// update regenerates the committed document
+// go test ./internal/api/delivery/http/apidoc -update
+var update = flag.Bool("update", false, "update the committed openapi document")
+
+// buildRouter mounts the application modules, the handlers
+// are never called so their dependencies are not required
+func buildRouter(t *testing.T, opts ...router.Option) *router.Router {
+	v, err := modules.NewVersioning()
+	if err != nil {
+		t.Fatalf("init versioning: %v", err)
+	}
+
+	r := router.NewRouter(append(opts, router.WithVersioning(v))...)
+
+	reg := module.NewRegistry()
+
+	if err := modules.Register(reg, modules.Deps{Versioning: v, Feed: feed.NewBroker(feed.NewMemoryBuffer(1))}); err != nil {
+		t.Fatalf("register modules: %v", err)
+	}
+
+	if err := reg.Mount(r.Mux, constant.ApiPattern); err != nil {
+		t.Fatalf("mount modules: %v", err)
+	}
+
+	return r
+}
+
+// TestSpec fails when the committed document drifts from the code
+func TestSpec(t *testing.T) {
+	d, err := apidoc.Generate(buildRouter(t).Mux)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	got, err := apidoc.Marshal(d)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(apidoc.SpecFile, got, 0o644); err != nil {
+			t.Fatalf("write %s: %v", apidoc.SpecFile, err)
+		}
+
+		return
+	}
+
+	if !bytes.Equal(got, apidoc.Spec()) {
+		t.Errorf("%s is out of date, run: go test ./internal/api/delivery/http/apidoc -update", apidoc.SpecFile)
+	}
+}
+
+func TestSpecPaths(t *testing.T) {
+	d, err := apidoc.Generate(buildRouter(t).Mux)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		path   string
+		method string
+	}{
+		{name: "create product", path: "/api/v1/products", method: "post"},
+		{name: "read one product", path: "/api/v1/products/{id}", method: "get"},
+		{name: "delete user", path: "/api/v1/users/{id}", method: "delete"},
+		{name: "purge user", path: "/api/v1/users/{id}/purge", method: "delete"},
+		{name: "read one product v2", path: "/api/v2/products/{id}", method: "get"},
+		{name: "versions", path: "/api/versions", method: "get"},
+		{name: "batch", path: "/api/batch", method: "post"},
+		{name: "graphql", path: "/api/graphql", method: "post"},
+		{name: "export users", path: "/api/v1/users:export", method: "get"},
+		{name: "import products report", path: "/api/v1/products:import/{jobId}/report", method: "get"},
+		{name: "product events", path: "/api/v1/products/events", method: "get"},
+		{name: "webhook deliveries", path: "/api/v1/webhooks/{id}/deliveries", method: "get"},
+		{name: "redeliver webhook", path: "/api/v1/webhooks/{id}/deliveries/{deliveryId}/redeliver", method: "post"},
+		{name: "signup", path: "/api/v1/auth/signup", method: "post"},
+		{name: "refresh", path: "/api/v1/auth/refresh", method: "post"},
+		{name: "oidc callback", path: "/api/v1/auth/oidc/{provider}/callback", method: "get"},
+		{name: "unlock", path: "/api/v1/auth/lockouts/{key}", method: "delete"},
+		{name: "roles", path: "/api/v1/roles", method: "get"},
+		{name: "user roles", path: "/api/v1/users/{id}/roles", method: "get"},
+		{name: "remove user role", path: "/api/v1/users/{id}/roles/{role}", method: "delete"},
+		{name: "create api key", path: "/api/v1/api-keys", method: "post"},
+		{name: "rotate api key", path: "/api/v1/api-keys/{id}/rotate", method: "post"},
+		{name: "docs excluded", path: "/api/docs", method: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			item, ok := d.Paths[tc.path]
+			if tc.method == "" {
+				if ok {
+					t.Errorf("expected path %s to be excluded", tc.path)
+				}
+				return
+			}
+
+			if !ok {
+				t.Fatalf("expected path %s", tc.path)
+			}
+
+			var found bool
+			switch tc.method {
+			case "get":
+				found = item.Get != nil
+			case "post":
+				found = item.Post != nil
+			case "delete":
+				found = item.Delete != nil
+			}
+
+			if !found {
+				t.Errorf("expected %s operation for %s", tc.method, tc.path)
+			}
+		})
+	}
+}
+
+// TestDocs checks the docs page is served with the policy allowing its scripts
+func TestDocs(t *testing.T) {
+	r := buildRouter(t)
+
+	w := httptest.NewRecorder()
+	r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, constant.ApiPattern+constant.DocsPattern, nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200", w.Code)
+	}
+
+	csp := w.Header().Get(constant.HeaderContentSecurityPolicy)
+	if csp != apidoc.DocsContentSecurityPolicy {
+		t.Errorf("Content-Security-Policy = %q, want the docs policy", csp)
+	}
+
+	if !strings.Contains(csp, "'sha256-") {
+		t.Errorf("Content-Security-Policy = %q, want the hash of the inline script", csp)
+	}
+
+	// the swagger ui is vendored, no source may be an external host
+	for _, directive := range strings.Split(csp, ";") {
+		for _, src := range strings.Fields(directive)[1:] {
+			if !strings.HasPrefix(src, "'") && src != "data:" {
+				t.Errorf("Content-Security-Policy %q allows the external source %s", csp, src)
+			}
+		}
+	}
+}
+
+// TestDocsAssets checks the vendored swagger ui is served next to the page
+func TestDocsAssets(t *testing.T) {
+	r := buildRouter(t)
+
+	tests := []struct {
+		file        string
+		code        int
+		contentType string
+	}{
+		{file: "/swagger-ui-bundle.js", code: http.StatusOK, contentType: "text/javascript"},
+		{file: "/swagger-ui.css", code: http.StatusOK, contentType: "text/css"},
+		{file: "/NOTICE", code: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.file, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, constant.ApiPattern+constant.DocsPattern+tc.file, nil))
+
+			if w.Code != tc.code {
+				t.Fatalf("code = %d, want %d", w.Code, tc.code)
+			}
+
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tc.contentType) {
+				t.Errorf("Content-Type = %q, want %s", ct, tc.contentType)
+			}
+		})
+	}
+}
+
+// TestDocsPublic checks the docs and their assets are
+// served without a token when the authentication is enabled
+func TestDocsPublic(t *testing.T) {
+	t.Setenv("JWT_SECRET", strings.Repeat("s", 32))
+
+	v, err := modules.NewVerifierFromEnv()
+	if err != nil {
+		t.Fatalf("init verifier: %v", err)
+	}
+
+	r := buildRouter(t, router.WithAuthentication(modules.Authentication(v, nil, nil)))
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{path: constant.ApiPattern + constant.OpenAPIPattern, code: http.StatusOK},
+		{path: constant.ApiPattern + constant.DocsPattern, code: http.StatusOK},
+		{path: constant.ApiPattern + constant.DocsPattern + "/swagger-ui-bundle.js", code: http.StatusOK},
+		{path: constant.ApiPattern + constant.DocsPattern + "/swagger-ui.css", code: http.StatusOK},
+		{path: constant.ApiPattern + constant.V1 + constant.ProductsPattern, code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if w.Code != tc.code {
+				t.Errorf("code = %d, want %d", w.Code, tc.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,674 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/batch"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/bulk"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/openapi"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+)
+
+// operation contains the documentation of a route
+// which can't be derived from the route itself
+type operation struct {
+	id      string
+	summary string
+	tags    []string
+	query   []openapi.Parameter
+	headers []openapi.Parameter
+	request any
+	// conditional documents If-None-Match and 304, lastModified also
+	// documents If-Modified-Since, the lists have no Last-Modified
+	conditional  bool
+	lastModified bool
+	// stream documents response as the item of an NDJSON or CSV stream
+	stream bool
+	// events documents response as the entry of a Server-Sent Events
+	// stream and the 101 response of the WebSocket upgrade
+	events bool
+	// upload documents request as the item of a CSV, NDJSON or
+	// multipart upload and the 202 response of an asynchronous job
+	upload   bool
+	response any
+	status   int
+	errors   []int
+	// errorResponse documents the errors instead of response.ErrorResponse
+	errorResponse any
+	// public documents the operation without authentication
+	// see modules.IsPublic
+	public bool
+}
+
+const (
+	productsPath   = constant.ApiPattern + constant.V1 + constant.ProductsPattern
+	productsV2Path = constant.ApiPattern + constant.V2 + constant.ProductsPattern
+	usersPath      = constant.ApiPattern + constant.V1 + constant.UsersPattern
+	exportPath     = constant.ExportSuffix
+	importPath     = constant.ImportSuffix
+	jobPath        = "/{" + constant.ParamJobId + "}"
+	versionsPath   = constant.ApiPattern + constant.VersionsPattern
+	batchPath      = constant.ApiPattern + constant.BatchPattern
+	graphQLPath    = constant.ApiPattern + constant.GraphQLPattern
+	authPath       = constant.ApiPattern + constant.V1 + constant.AuthPattern
+	idPath         = "/{" + constant.ParamId + "}"
+	webhooksPath   = constant.ApiPattern + constant.V1 + constant.WebhooksPattern
+	deliveriesPath = constant.DeliveriesPattern
+	redeliverPath  = constant.DeliveriesPattern + "/{" + constant.ParamDeliveryId + "}" + constant.RedeliverPattern
+	rolesPath      = constant.ApiPattern + constant.V1 + constant.RolesPattern
+	userRolesPath  = usersPath + idPath + constant.RolesPattern
+	rolePath       = "/{" + constant.ParamRole + "}"
+	purgePath      = idPath + constant.PurgePattern
+	apiKeysPath    = constant.ApiPattern + constant.V1 + constant.APIKeysPattern
+	oidcPath       = authPath + constant.OIDCPattern + "/{" + constant.ParamProvider + "}"
+	lockoutsPath   = authPath + constant.LockoutsPattern
+	keyPath        = "/{" + constant.ParamKey + "}"
+)
+
+// readManyQuery contains the query parameters of the list routes
+var readManyQuery = []openapi.Parameter{
+	queryParam(constant.ParamLimit, "integer", "number of items per page, reduced to the maximum of the pagination policy"),
+	queryParam(constant.ParamPage, "integer", "page number starting from 1"),
+	queryParam(constant.ParamIsArchived, "boolean", "filter by archived state"),
+	fieldsParam,
+	expandParam,
+}
+
+// pageQuery contains the query parameters of the list routes without filters
+var pageQuery = []openapi.Parameter{
+	queryParam(constant.ParamLimit, "integer", "number of items per page, reduced to the maximum of the pagination policy"),
+	queryParam(constant.ParamPage, "integer", "page number starting from 1"),
+}
+
+// eventsQuery contains the query parameters of the change feeds
+var eventsQuery = []openapi.Parameter{
+	queryParam(constant.ParamTypes, "string", "comma separated event types, all if not set, 400 if unknown"),
+	queryParam(constant.ParamIds, "string", "comma separated ids of the entities, all if not set"),
+	queryParam(constant.ParamLastEventId, "integer", "resume after the entry, for the WebSocket clients which can't set Last-Event-ID"),
+}
+
+// eventsHeaders contains the headers of the change feeds
+var eventsHeaders = []openapi.Parameter{
+	{
+		Name:        constant.HeaderLastEventId,
+		In:          "header",
+		Description: "resume after the entry, a reset event is sent if entries were evicted",
+		Schema:      &openapi.Schema{Type: "string"},
+	},
+}
+
+// readOneQuery contains the query parameters of the read routes
+var readOneQuery = []openapi.Parameter{fieldsParam, expandParam}
+
+var (
+	fieldsParam = queryParam(constant.ParamFields, "string", "comma separated fields of the response, all if not set, 400 if unknown")
+	expandParam = queryParam(constant.ParamExpand, "string", "comma separated relations to embed, 400 if unknown")
+)
+
+// exportQuery contains the query parameters of the export routes
+var exportQuery = []openapi.Parameter{
+	queryParam(constant.ParamFormat, "string", "ndjson (default) or csv, overrides the Accept header"),
+	queryParam(constant.ParamIsArchived, "boolean", "filter by archived state, all if not set"),
+}
+
+// importQuery contains the query parameters of the import route
+var importQuery = []openapi.Parameter{
+	queryParam(constant.ParamFormat, "string", "csv or ndjson, detected from the media type or the file extension if not set"),
+	queryParam(constant.ParamMapping, "string", "column mapping as source:field pairs, ex: title:name,details:description"),
+	queryParam(constant.ParamDryRun, "boolean", "validate the rows without storing them"),
+	queryParam(constant.ParamAsync, "boolean", "process as a job and respond with 202, implied for large uploads"),
+}
+
+// reportQuery contains the query parameters of the import report route
+var reportQuery = []openapi.Parameter{
+	queryParam(constant.ParamFormat, "string", "ndjson (default) or csv, overrides the Accept header"),
+}
+
+// callbackQuery contains the query parameters the provider redirects with
+var callbackQuery = []openapi.Parameter{
+	queryParam("code", "string", "authorization code of the login"),
+	queryParam("state", "string", "state of the login, it must match the state cookie"),
+	queryParam("error", "string", "error of a failed login, ex: access_denied"),
+}
+
+// createHeaders contains the headers of the create routes
+var createHeaders = []openapi.Parameter{
+	{
+		Name:        constant.HeaderIdempotencyKey,
+		In:          "header",
+		Description: "retries with the same key get the stored response",
+		Schema:      &openapi.Schema{Type: "string"},
+	},
+}
+
+// the headers of the conditional GET routes
+var (
+	ifNoneMatchHeader = openapi.Parameter{
+		Name:        constant.HeaderIfNoneMatch,
+		In:          "header",
+		Description: "304 if the ETag matches",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	ifModifiedSinceHeader = openapi.Parameter{
+		Name:        constant.HeaderIfModifiedSince,
+		In:          "header",
+		Description: "304 if not modified since, ignored with If-None-Match",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+)
+
+// authErrors contains the errors of the session routes
+var authErrors = []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusRequestEntityTooLarge, http.StatusInternalServerError, http.StatusServiceUnavailable}
+
+// createErrors contains the errors of the create routes
+var createErrors = []int{http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError}
+
+// updateErrors contains the errors of the update routes
+var updateErrors = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusRequestEntityTooLarge, http.StatusInternalServerError}
+
+// productUpdateErrors contains the errors of the product update
+// routes, 409 if another product has the name
+var productUpdateErrors = []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError}
+
+// operations contains the documentation of the routes
+// keyed by "<METHOD> <path>"
+var operations = map[string]operation{
+	// versions
+	operationKey(http.MethodGet, versionsPath): {
+		id:       "readVersions",
+		summary:  "List the api versions and their usage",
+		tags:     []string{"versions"},
+		response: response.Response[[]router.VersionUsage]{},
+		status:   http.StatusOK,
+	},
+
+	// batch
+	operationKey(http.MethodPost, batchPath): {
+		id:       "batch",
+		summary:  "Dispatch many requests in one round trip",
+		tags:     []string{"batch"},
+		request:  batch.Batch{},
+		response: response.Response[batch.Result]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+
+	// graphql
+	operationKey(http.MethodPost, graphQLPath): {
+		id:            "graphql",
+		summary:       "Run a graphql query or mutation over the products and users",
+		tags:          []string{"graphql"},
+		request:       dto.GraphQLRequest{},
+		response:      dto.GraphQLResponse{},
+		status:        http.StatusOK,
+		errors:        []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge},
+		errorResponse: dto.GraphQLResponse{},
+	},
+
+	// auth
+	operationKey(http.MethodPost, authPath+constant.SignupPattern): {
+		id:       "signup",
+		summary:  "Create a user with an email and a password and log it in",
+		tags:     []string{"auth"},
+		request:  dto.Signup{},
+		response: response.Response[dto.TokenEntity]{},
+		status:   http.StatusCreated,
+		errors:   []int{http.StatusBadRequest, http.StatusConflict, http.StatusRequestEntityTooLarge, http.StatusInternalServerError, http.StatusServiceUnavailable},
+		public:   true,
+	},
+	operationKey(http.MethodPost, authPath+constant.LoginPattern): {
+		id:       "login",
+		summary:  "Log in with an email and a password, the failed logins delay and lock the next ones with 429 and Retry-After",
+		tags:     []string{"auth"},
+		request:  dto.Login{},
+		response: response.Response[dto.TokenEntity]{},
+		status:   http.StatusOK,
+		errors:   authErrors,
+		public:   true,
+	},
+	operationKey(http.MethodPost, authPath+constant.RefreshPattern): {
+		id:       "refresh",
+		summary:  "Exchange a refresh token for new tokens, a reused token revokes the session",
+		tags:     []string{"auth"},
+		request:  dto.RefreshToken{},
+		response: response.Response[dto.TokenEntity]{},
+		status:   http.StatusOK,
+		errors:   authErrors,
+		public:   true,
+	},
+	operationKey(http.MethodPost, authPath+constant.LogoutPattern): {
+		id:      "logout",
+		summary: "Revoke the session of a refresh token",
+		tags:    []string{"auth"},
+		request: dto.RefreshToken{},
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+		public:  true,
+	},
+	operationKey(http.MethodGet, lockoutsPath): {
+		id:       "readLockouts",
+		summary:  "List the accounts and the client addresses locked by the failed logins",
+		tags:     []string{"auth"},
+		response: response.Response[[]dto.LockoutEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, lockoutsPath+keyPath): {
+		id:      "unlock",
+		summary: "Remove the failed logins and the lock of an account or a client address, the key is account:email or ip:address",
+		tags:    []string{"auth"},
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, oidcPath): {
+		id:      "authorizeOIDC",
+		summary: "Redirect to the OpenID Connect provider to log in, the login state is set in a cookie",
+		tags:    []string{"auth"},
+		status:  http.StatusFound,
+		errors:  []int{http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway},
+		public:  true,
+	},
+	operationKey(http.MethodPost, oidcPath+constant.LinkPattern): {
+		id:       "linkOIDC",
+		summary:  "Start a login at the OpenID Connect provider which links the identity to the logged in user, the user agent navigates to the url, the login state is set in a cookie",
+		tags:     []string{"auth"},
+		response: response.Response[dto.AuthorizationEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusUnauthorized, http.StatusNotFound, http.StatusInternalServerError, http.StatusBadGateway},
+	},
+	operationKey(http.MethodGet, oidcPath+constant.CallbackPattern): {
+		id:       "callbackOIDC",
+		summary:  "Complete the login at the OpenID Connect provider, the identity is linked to the user of its verified email, a login started by linkOIDC links it to the logged in user and responds with 204",
+		tags:     []string{"auth"},
+		query:    callbackQuery,
+		response: response.Response[dto.TokenEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable},
+		public:   true,
+	},
+
+	// products
+	operationKey(http.MethodPost, productsPath): {
+		id:       "createProduct",
+		summary:  "Create a product",
+		tags:     []string{"products"},
+		headers:  createHeaders,
+		request:  dto.CreateProduct{},
+		response: response.Response[dto.ProductEntity]{},
+		status:   http.StatusCreated,
+		errors:   createErrors,
+	},
+	operationKey(http.MethodGet, productsPath): {
+		id:          "readManyProducts",
+		conditional: true,
+		summary:     "List products",
+		tags:        []string{"products"},
+		query:       readManyQuery,
+		response:    response.Response[response.ReadManyResponse[dto.ProductEntity]]{},
+		status:      http.StatusOK,
+		errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, productsPath+idPath): {
+		id:           "readOneProduct",
+		conditional:  true,
+		lastModified: true,
+		summary:      "Get a product",
+		tags:         []string{"products"},
+		query:        readOneQuery,
+		response:     response.Response[dto.ProductEntity]{},
+		status:       http.StatusOK,
+		errors:       []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPut, productsPath+idPath): {
+		id:       "updateProduct",
+		summary:  "Update a product",
+		tags:     []string{"products"},
+		request:  dto.UpdateProduct{},
+		response: response.Response[dto.ProductEntity]{},
+		status:   http.StatusOK,
+		errors:   productUpdateErrors,
+	},
+	operationKey(http.MethodDelete, productsPath+idPath): {
+		id:       "deleteProduct",
+		summary:  "Archive a product",
+		tags:     []string{"products"},
+		response: response.Response[dto.ProductEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, productsPath+purgePath): {
+		id:      "purgeProduct",
+		summary: "Remove a product for good, admins only",
+		tags:    []string{"products"},
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, productsPath+exportPath): {
+		id:       "exportProducts",
+		summary:  "Export all products",
+		tags:     []string{"products"},
+		query:    exportQuery,
+		response: dto.ProductEntity{},
+		stream:   true,
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPost, productsPath+importPath): {
+		id:       "importProducts",
+		summary:  "Import products from a CSV or NDJSON upload, upserted by name",
+		tags:     []string{"products"},
+		query:    importQuery,
+		request:  dto.CreateProduct{},
+		upload:   true,
+		response: response.Response[bulk.JobView]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, productsPath+constant.EventsPattern): {
+		id:       "streamProductEvents",
+		summary:  "Stream the changes of the products over Server-Sent Events or WebSocket",
+		tags:     []string{"products"},
+		query:    eventsQuery,
+		headers:  eventsHeaders,
+		response: feed.Entry{},
+		events:   true,
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusServiceUnavailable},
+	},
+	operationKey(http.MethodGet, productsPath+importPath+jobPath): {
+		id:       "readProductImport",
+		summary:  "Get the state and progress of an import",
+		tags:     []string{"products"},
+		response: response.Response[bulk.JobView]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusNotFound},
+	},
+	operationKey(http.MethodGet, productsPath+importPath+jobPath+constant.ReportPattern): {
+		id:       "readProductImportReport",
+		summary:  "Download the row report of a finished import",
+		tags:     []string{"products"},
+		query:    reportQuery,
+		response: bulk.RowResult{},
+		stream:   true,
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict},
+	},
+
+	// products v2
+	operationKey(http.MethodPost, productsV2Path): {
+		id:       "createProductV2",
+		summary:  "Create a product",
+		tags:     []string{"products"},
+		headers:  createHeaders,
+		request:  dto.CreateProduct{},
+		response: response.Response[dto.ProductEntityV2]{},
+		status:   http.StatusCreated,
+		errors:   createErrors,
+	},
+	operationKey(http.MethodGet, productsV2Path): {
+		id:          "readManyProductsV2",
+		conditional: true,
+		summary:     "List products",
+		tags:        []string{"products"},
+		query:       readManyQuery,
+		response:    response.Response[response.ReadManyResponse[dto.ProductEntityV2]]{},
+		status:      http.StatusOK,
+		errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, productsV2Path+idPath): {
+		id:           "readOneProductV2",
+		conditional:  true,
+		lastModified: true,
+		summary:      "Get a product",
+		tags:         []string{"products"},
+		query:        readOneQuery,
+		response:     response.Response[dto.ProductEntityV2]{},
+		status:       http.StatusOK,
+		errors:       []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPut, productsV2Path+idPath): {
+		id:       "updateProductV2",
+		summary:  "Update a product",
+		tags:     []string{"products"},
+		request:  dto.UpdateProduct{},
+		response: response.Response[dto.ProductEntityV2]{},
+		status:   http.StatusOK,
+		errors:   productUpdateErrors,
+	},
+	operationKey(http.MethodDelete, productsV2Path+idPath): {
+		id:       "deleteProductV2",
+		summary:  "Archive a product",
+		tags:     []string{"products"},
+		response: response.Response[dto.ProductEntityV2]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, productsV2Path+purgePath): {
+		id:      "purgeProductV2",
+		summary: "Remove a product for good, admins only",
+		tags:    []string{"products"},
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, productsV2Path+exportPath): {
+		id:       "exportProductsV2",
+		summary:  "Export all products",
+		tags:     []string{"products"},
+		query:    exportQuery,
+		response: dto.ProductEntityV2{},
+		stream:   true,
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+
+	// users
+	operationKey(http.MethodPost, usersPath): {
+		id:       "createUser",
+		summary:  "Create a user",
+		tags:     []string{"users"},
+		headers:  createHeaders,
+		request:  dto.CreateUser{},
+		response: response.Response[dto.UserEntity]{},
+		status:   http.StatusCreated,
+		errors:   createErrors,
+	},
+	operationKey(http.MethodGet, usersPath): {
+		id:          "readManyUsers",
+		conditional: true,
+		summary:     "List users",
+		tags:        []string{"users"},
+		query:       readManyQuery,
+		response:    response.Response[response.ReadManyResponse[dto.UserEntity]]{},
+		status:      http.StatusOK,
+		errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, usersPath+idPath): {
+		id:           "readOneUser",
+		conditional:  true,
+		lastModified: true,
+		summary:      "Get a user",
+		tags:         []string{"users"},
+		query:        readOneQuery,
+		response:     response.Response[dto.UserEntity]{},
+		status:       http.StatusOK,
+		errors:       []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPut, usersPath+idPath): {
+		id:       "updateUser",
+		summary:  "Update a user",
+		tags:     []string{"users"},
+		request:  dto.UpdateUser{},
+		response: response.Response[dto.UserEntity]{},
+		status:   http.StatusOK,
+		errors:   updateErrors,
+	},
+	operationKey(http.MethodDelete, usersPath+idPath): {
+		id:       "deleteUser",
+		summary:  "Archive a user",
+		tags:     []string{"users"},
+		response: response.Response[dto.UserEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, usersPath+purgePath): {
+		id:      "purgeUser",
+		summary: "Remove a user for good with its credentials, sessions, identities, api keys and roles, admins only",
+		tags:    []string{"users"},
+		status:  http.StatusNoContent,
+		errors:  []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, usersPath+exportPath): {
+		id:       "exportUsers",
+		summary:  "Export all users",
+		tags:     []string{"users"},
+		query:    exportQuery,
+		response: dto.UserEntity{},
+		stream:   true,
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+
+	// roles
+	operationKey(http.MethodGet, rolesPath): {
+		id:       "readRoles",
+		summary:  "List the roles with their grants",
+		tags:     []string{"roles"},
+		response: response.Response[[]dto.RoleEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, userRolesPath): {
+		id:       "readUserRoles",
+		summary:  "List the roles of a user",
+		tags:     []string{"roles"},
+		response: response.Response[dto.UserRolesEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPut, userRolesPath+rolePath): {
+		id:       "assignUserRole",
+		summary:  "Assign a role to a user, it applies to the tokens issued afterwards",
+		tags:     []string{"roles"},
+		response: response.Response[dto.UserRolesEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, userRolesPath+rolePath): {
+		id:       "removeUserRole",
+		summary:  "Remove a role of a user",
+		tags:     []string{"roles"},
+		response: response.Response[dto.UserRolesEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+
+	// api keys
+	operationKey(http.MethodPost, apiKeysPath): {
+		id:       "createAPIKey",
+		summary:  "Create an api key of the caller, the key is only returned once",
+		tags:     []string{"api keys"},
+		request:  dto.CreateAPIKey{},
+		response: response.Response[dto.IssuedAPIKeyEntity]{},
+		status:   http.StatusCreated,
+		errors:   []int{http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, apiKeysPath): {
+		id:       "readAPIKeys",
+		summary:  "List the api keys of the caller",
+		tags:     []string{"api keys"},
+		response: response.Response[[]dto.APIKeyEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, apiKeysPath+idPath): {
+		id:       "readAPIKey",
+		summary:  "Get an api key",
+		tags:     []string{"api keys"},
+		response: response.Response[dto.APIKeyEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodDelete, apiKeysPath+idPath): {
+		id:       "revokeAPIKey",
+		summary:  "Revoke an api key",
+		tags:     []string{"api keys"},
+		response: response.Response[dto.APIKeyEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPost, apiKeysPath+idPath+constant.RotatePattern): {
+		id:       "rotateAPIKey",
+		summary:  "Replace the secret of an api key, the old key stops working",
+		tags:     []string{"api keys"},
+		response: response.Response[dto.IssuedAPIKeyEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+	},
+
+	// webhooks
+	operationKey(http.MethodPost, webhooksPath): {
+		id:       "createWebhook",
+		summary:  "Subscribe an endpoint to events, the response has the signing secret",
+		tags:     []string{"webhooks"},
+		headers:  createHeaders,
+		request:  dto.CreateWebhook{},
+		response: response.Response[dto.CreatedWebhookEntity]{},
+		status:   http.StatusCreated,
+		errors:   createErrors,
+	},
+	operationKey(http.MethodGet, webhooksPath): {
+		id:       "readManyWebhooks",
+		summary:  "List webhook subscriptions",
+		tags:     []string{"webhooks"},
+		query:    pageQuery,
+		response: response.Response[response.ReadManyResponse[dto.WebhookEntity]]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, webhooksPath+idPath): {
+		id:       "readOneWebhook",
+		summary:  "Get a webhook subscription",
+		tags:     []string{"webhooks"},
+		response: response.Response[dto.WebhookEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPut, webhooksPath+idPath): {
+		id:       "updateWebhook",
+		summary:  "Update a webhook subscription, enabled re-enables it",
+		tags:     []string{"webhooks"},
+		request:  dto.UpdateWebhook{},
+		response: response.Response[dto.WebhookEntity]{},
+		status:   http.StatusOK,
+		errors:   updateErrors,
+	},
+	operationKey(http.MethodDelete, webhooksPath+idPath): {
+		id:       "deleteWebhook",
+		summary:  "Delete a webhook subscription and its deliveries",
+		tags:     []string{"webhooks"},
+		response: response.Response[dto.WebhookEntity]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodGet, webhooksPath+idPath+deliveriesPath): {
+		id:       "readWebhookDeliveries",
+		summary:  "List the deliveries of a webhook subscription, latest first",
+		tags:     []string{"webhooks"},
+		query:    pageQuery,
+		response: response.Response[response.ReadManyResponse[dto.DeliveryEntity]]{},
+		status:   http.StatusOK,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError},
+	},
+	operationKey(http.MethodPost, webhooksPath+idPath+redeliverPath): {
+		id:       "redeliverWebhook",
+		summary:  "Send the payload of a delivery again",
+		tags:     []string{"webhooks"},
+		response: response.Response[dto.DeliveryEntity]{},
+		status:   http.StatusAccepted,
+		errors:   []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError},
+	},
+}
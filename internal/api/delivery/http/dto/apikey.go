@@ -0,0 +1,66 @@
+package dto
+
+import "github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+
+type CreateAPIKey struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+	// ExpiresAt is the unix time the key expires at, 0 never
+	ExpiresAt int64 `json:"expiresAt" validate:"omitempty,gt=0"`
+}
+
+func (k *CreateAPIKey) ToDomainDTO() apikey.CreateDTO {
+	return apikey.CreateDTO{
+		Name:      k.Name,
+		Scopes:    k.Scopes,
+		ExpiresAt: k.ExpiresAt,
+	}
+}
+
+// APIKeyEntity is the key without its secret, the prefix
+// identifies the key in the listings, the times are 0 if unset
+type APIKeyEntity struct {
+	ID         string   `json:"id"`
+	UserID     string   `json:"userId"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  int64    `json:"expiresAt"`
+	LastUsedAt int64    `json:"lastUsedAt"`
+	RevokedAt  int64    `json:"revokedAt"`
+	CreatedAt  int64    `json:"createdAt"`
+	UpdatedAt  int64    `json:"updatedAt"`
+}
+
+// IssuedAPIKeyEntity is the response of the create and the
+// rotate requests, the key is only returned once
+type IssuedAPIKeyEntity struct {
+	APIKeyEntity
+	Key string `json:"key"`
+}
+
+// helper function to convert to dto entity from domain entity
+func ToAPIKeyEntity(k apikey.APIKey) *APIKeyEntity {
+	scopes := k.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+
+	return &APIKeyEntity{
+		ID:         k.ID,
+		UserID:     k.UserID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+		UpdatedAt:  k.UpdatedAt,
+	}
+}
+
+// helper function to convert to dto entity from domain entity
+func ToIssuedAPIKeyEntity(k apikey.Issued) *IssuedAPIKeyEntity {
+	return &IssuedAPIKeyEntity{APIKeyEntity: *ToAPIKeyEntity(k.APIKey), Key: k.Key}
+}
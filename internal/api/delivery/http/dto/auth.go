@@ -0,0 +1,85 @@
+package dto
+
+import (
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+type Signup struct {
+	Name     string  `json:"name" validate:"required"`
+	Address  *string `json:"address" validate:"omitempty"`
+	Email    string  `json:"email" validate:"required,email,max=320"`
+	Password string  `json:"password" validate:"required,min=8,max=128"`
+}
+
+func (s *Signup) ToDomainDTO() credential.SignupDTO {
+	return credential.SignupDTO{
+		Name:     s.Name,
+		Address:  s.Address,
+		Email:    s.Email,
+		Password: s.Password,
+	}
+}
+
+type Login struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (l *Login) ToDomainDTO() credential.LoginDTO {
+	return credential.LoginDTO{
+		Email:    l.Email,
+		Password: l.Password,
+	}
+}
+
+// RefreshToken is the body of the refresh and the logout requests
+type RefreshToken struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// TokenEntity contains the tokens of a session
+// the access token is sent as the bearer token
+type TokenEntity struct {
+	UserID       string `json:"userId"`
+	AccessToken  string `json:"accessToken"`
+	TokenType    string `json:"tokenType"`
+	ExpiresIn    int64  `json:"expiresIn"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// AuthorizationEntity contains the url of the authorization endpoint
+// of a provider the user agent navigates to, see handler.OIDC.Link
+type AuthorizationEntity struct {
+	URL string `json:"url"`
+}
+
+// helper function to convert to dto entity from domain entity
+func ToTokenEntity(s credential.Session) *TokenEntity {
+	return &TokenEntity{
+		UserID:       s.UserID,
+		AccessToken:  s.AccessToken,
+		TokenType:    constant.AuthSchemeBearer,
+		ExpiresIn:    s.ExpiresIn,
+		RefreshToken: s.RefreshToken,
+	}
+}
+
+// LockoutEntity contains the failed logins of an account
+// or an address and the end of its lock
+type LockoutEntity struct {
+	Key           string `json:"key"`
+	Failures      int    `json:"failures"`
+	LastFailureAt int64  `json:"lastFailureAt"`
+	LockedUntil   int64  `json:"lockedUntil"`
+}
+
+// helper function to convert to dto entity from domain entity
+func ToLockoutEntity(l credential.Lockout) *LockoutEntity {
+	return &LockoutEntity{
+		Key:           l.Key,
+		Failures:      l.Failures,
+		LastFailureAt: l.LastFailureAt,
+		LockedUntil:   l.LockedUntil,
+	}
+}
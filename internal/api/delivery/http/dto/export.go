@@ -0,0 +1,54 @@
+package dto
+
+import "strconv"
+
+// ProductCSVHeader is the header of the products CSV export
+var ProductCSVHeader = []string{"id", "name", "description", "isArchived", "createdAt", "updatedAt"}
+
+// UserCSVHeader is the header of the users CSV export
+// the address is named description as in the json
+var UserCSVHeader = []string{"id", "name", "description", "isArchived", "createdAt", "updatedAt"}
+
+// CSVRecord implements response.CSVRecord
+func (p ProductEntity) CSVRecord() []string {
+	return []string{
+		p.ID,
+		p.Name,
+		stringOrEmpty(p.Description),
+		strconv.FormatBool(p.IsArchived),
+		strconv.FormatInt(p.CreatedAt, 10),
+		strconv.FormatInt(p.UpdatedAt, 10),
+	}
+}
+
+// CSVRecord implements response.CSVRecord
+func (p ProductEntityV2) CSVRecord() []string {
+	return []string{
+		p.ID,
+		p.Name,
+		stringOrEmpty(p.Description),
+		strconv.FormatBool(p.IsArchived),
+		p.CreatedAt,
+		p.UpdatedAt,
+	}
+}
+
+// CSVRecord implements response.CSVRecord
+func (u UserEntity) CSVRecord() []string {
+	return []string{
+		u.ID,
+		u.Name,
+		stringOrEmpty(u.Address),
+		strconv.FormatBool(u.IsArchived),
+		strconv.FormatInt(u.CreatedAt, 10),
+		strconv.FormatInt(u.UpdatedAt, 10),
+	}
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,23 @@
+package dto
+
+// GraphQLRequest is the body of a graphql request
+type GraphQLRequest struct {
+	Query         string         `json:"query" validate:"required"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse documents the body of a graphql response
+// the handler responds with graphql.Response of the same shape
+type GraphQLResponse struct {
+	Data   map[string]any `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError documents an error of a graphql response, the
+// extensions carry the code and the status, ex: 404 and NOT_FOUND
+type GraphQLError struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
@@ -1,6 +1,11 @@
 package dto
 
-import "github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+import (
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
+)
 
 type CreateProduct struct {
 	Name        string  `json:"name" validate:"required"`
@@ -28,6 +33,12 @@ func (p *UpdateProduct) ToDomainDTO() product.UpdateDTO {
 	}
 }
 
+// ProductFields contains the selectable fields and the expandable
+// relations of the products, the versions share the field names
+var ProductFields = fieldset.Whitelist{
+	Fields: []string{"id", "name", "description", "isArchived", "createdAt", "updatedAt"},
+}
+
 type ProductEntity struct {
 	ID          string  `json:"id"`
 	Name        string  `json:"name"`
@@ -62,10 +73,33 @@ func ToProductEntity(p product.Product) *ProductEntity {
 
 // helper function to convert to dto entity slice from domain entity slice
 func ToProductEntities(products []product.Product) []ProductEntity {
-	entityDTOs := make([]ProductEntity, len(products))
+	entityDTOs := make([]ProductEntity, 0, len(products))
 	for _, p := range products {
 		entityDTOs = append(entityDTOs, *ToProductEntity(p))
 	}
 
 	return entityDTOs
 }
+
+// ProductEntityV2 is the v2 response entity
+// the timestamps are RFC 3339 strings instead of unix seconds
+type ProductEntityV2 struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	IsArchived  bool    `json:"isArchived"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+// helper function to convert to v2 dto entity from domain entity
+func ToProductEntityV2(p product.Product) *ProductEntityV2 {
+	return &ProductEntityV2{
+		ID:          p.ID,
+		Name:        p.Name,
+		Description: p.Description,
+		IsArchived:  p.IsArchived,
+		CreatedAt:   time.Unix(p.CreatedAt, 0).UTC().Format(time.RFC3339),
+		UpdatedAt:   time.Unix(p.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,45 @@
+package dto
+
+import "github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+
+// GrantEntity is a permission of a role, own limits
+// it to the resources of the caller
+type GrantEntity struct {
+	Permission string `json:"permission"`
+	Own        bool   `json:"own"`
+}
+
+type RoleEntity struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Grants      []GrantEntity `json:"grants"`
+}
+
+// UserRolesEntity contains the names of the roles of a user
+type UserRolesEntity struct {
+	UserID string   `json:"userId"`
+	Roles  []string `json:"roles"`
+}
+
+// helper function to convert to dto entity from domain entity
+func ToRoleEntity(r role.Role) *RoleEntity {
+	grants := make([]GrantEntity, 0, len(r.Grants))
+	for _, g := range r.Grants {
+		grants = append(grants, GrantEntity{Permission: g.Permission, Own: g.Own})
+	}
+
+	return &RoleEntity{
+		Name:        r.Name,
+		Description: r.Description,
+		Grants:      grants,
+	}
+}
+
+// helper function to convert to dto entity from domain entity
+func ToUserRolesEntity(userID string, roles []string) *UserRolesEntity {
+	if roles == nil {
+		roles = []string{}
+	}
+
+	return &UserRolesEntity{UserID: userID, Roles: roles}
+}
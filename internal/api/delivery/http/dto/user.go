@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
 )
 
 type CreateUser struct {
@@ -32,6 +33,12 @@ func (u *UpdateUser) ToDomainDTO() user.UpdateDTO {
 	}
 }
 
+// UserFields contains the selectable fields and the
+// expandable relations of the users
+var UserFields = fieldset.Whitelist{
+	Fields: []string{"id", "name", "description", "isArchived", "createdAt", "updatedAt"},
+}
+
 // UserEntityAlias is a custom type to avoid infinite recursion in MarshalJSON
 // As UserEntityAlias itself doesn't have MarshalJSON implemented,
 // it doesn't infinitely recurse
@@ -83,7 +90,7 @@ func ToUserEntity(u user.User) *UserEntity {
 
 // helper function to convert to dto entity slice from domain entity slice
 func ToUserEntities(users []user.User) []UserEntity {
-	entityDTOs := make([]UserEntity, len(users))
+	entityDTOs := make([]UserEntity, 0, len(users))
 	for _, u := range users {
 		entityDTOs = append(entityDTOs, *ToUserEntity(u))
 	}
@@ -0,0 +1,103 @@
+package dto
+
+import (
+	"encoding/json"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+)
+
+type CreateWebhook struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+	// Secret is generated if it is empty
+	Secret string `json:"secret" validate:"omitempty,min=16"`
+}
+
+func (w *CreateWebhook) ToDomainDTO() webhook.CreateDTO {
+	return webhook.CreateDTO{
+		URL:    w.URL,
+		Events: w.Events,
+		Secret: w.Secret,
+	}
+}
+
+type UpdateWebhook struct {
+	URL     string   `json:"url" validate:"required,url"`
+	Events  []string `json:"events" validate:"required,min=1,dive,required"`
+	Enabled bool     `json:"enabled" validate:"boolean"`
+}
+
+func (w *UpdateWebhook) ToDomainDTO() webhook.UpdateDTO {
+	return webhook.UpdateDTO{
+		URL:     w.URL,
+		Events:  w.Events,
+		Enabled: w.Enabled,
+	}
+}
+
+// WebhookEntity is the subscription without its secret
+type WebhookEntity struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Enabled   bool     `json:"enabled"`
+	Failures  int      `json:"failures"`
+	CreatedAt int64    `json:"createdAt"`
+	UpdatedAt int64    `json:"updatedAt"`
+}
+
+// CreatedWebhookEntity is the response of the create request
+// the secret is only returned once
+type CreatedWebhookEntity struct {
+	WebhookEntity
+	Secret string `json:"secret"`
+}
+
+// helper function to convert to dto entity from domain entity
+func ToWebhookEntity(s webhook.Subscription) *WebhookEntity {
+	return &WebhookEntity{
+		ID:        s.ID,
+		URL:       s.URL,
+		Events:    s.Events,
+		Enabled:   s.Enabled,
+		Failures:  s.Failures,
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+	}
+}
+
+type DeliveryEntity struct {
+	ID     string `json:"id"`
+	Event  string `json:"event"`
+	Status string `json:"status"`
+	// Payload is the body sent to the endpoint
+	Payload        json.RawMessage `json:"payload"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	NextAttemptAt  int64           `json:"nextAttemptAt,omitempty"`
+	CreatedAt      int64           `json:"createdAt"`
+	UpdatedAt      int64           `json:"updatedAt"`
+}
+
+// helper function to convert to dto entity from domain entity
+// the next attempt is only set for a pending delivery
+func ToDeliveryEntity(d webhook.Delivery) *DeliveryEntity {
+	e := &DeliveryEntity{
+		ID:             d.ID,
+		Event:          d.Event,
+		Status:         string(d.Status),
+		Payload:        d.Payload,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus,
+		Error:          d.Error,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+
+	if d.Status == webhook.StatusPending {
+		e.NextAttemptAt = d.NextAttemptAt
+	}
+
+	return e
+}
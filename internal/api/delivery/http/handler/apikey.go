@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/apikey"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
+)
+
+// APIKey handles the requests of the api keys of the caller
+type APIKey struct {
+	useCase   apikey.UseCase
+	validater validatorext.Validater
+}
+
+// NewAPIKey initializes a new Handler
+func NewAPIKey(u apikey.UseCase, v validatorext.Validater) *APIKey {
+	return &APIKey{useCase: u, validater: v}
+}
+
+// Create handles the create request, the response
+// carries the key, it is not returned again
+func (h *APIKey) Create(w http.ResponseWriter, r *http.Request) {
+	var v dto.CreateAPIKey
+	err := httpext.DecodeJSON(w, r, &v)
+	if err != nil {
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	errs := h.validater.Validate(&v)
+	if errs != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorMultiple, errs))
+		return
+	}
+
+	d, err := h.useCase.Create(r.Context(), v.ToDomainDTO())
+	h.respondIssued(w, http.StatusCreated, d, err)
+}
+
+// ReadMany handles the list request of the keys of the caller
+func (h *APIKey) ReadMany(w http.ResponseWriter, r *http.Request) {
+	d, err := h.useCase.ReadMany(r.Context())
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, dto.ToAPIKeyEntity(e))
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(i))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+func (h *APIKey) ReadOne(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.ReadOne(r.Context(), id)
+	h.respond(w, d, err)
+}
+
+// Rotate handles the rotate request, the response carries the
+// new key and the old key does not authenticate afterwards
+func (h *APIKey) Rotate(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.Rotate(r.Context(), id)
+	h.respondIssued(w, http.StatusOK, d, err)
+}
+
+// Revoke handles the delete request, the key is kept revoked
+func (h *APIKey) Revoke(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.Revoke(r.Context(), id)
+	h.respond(w, d, err)
+}
+
+// respond responds with the key or the error
+func (h *APIKey) respond(w http.ResponseWriter, d apikey.APIKey, err error) {
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(dto.ToAPIKeyEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// respondIssued responds with the issued key, it must not be cached
+func (h *APIKey) respondIssued(w http.ResponseWriter, code int, d apikey.Issued, err error) {
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.Header().Set(constant.HeaderCacheControl, "no-store")
+
+	_, err = response.Respond(w, code, response.NewResponse(dto.ToIssuedAPIKeyEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
+)
+
+// Auth handles the signup, the login and the
+// session requests of the credentials and the
+// lockouts of the failed logins
+type Auth struct {
+	useCase   credential.UseCase
+	validater validatorext.Validater
+	// proxies are trusted to forward the address of the
+	// client, the failed logins are counted per address
+	proxies middleware.TrustedProxies
+}
+
+// NewAuth initializes a new Handler
+func NewAuth(u credential.UseCase, v validatorext.Validater, proxies middleware.TrustedProxies) *Auth {
+	return &Auth{useCase: u, validater: v, proxies: proxies}
+}
+
+// decode decodes and validates the body into v
+// it responds with the error and returns false if it fails
+func (h *Auth) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := httpext.DecodeJSON(w, r, v)
+	if err != nil {
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return false
+	}
+
+	errs := h.validater.Validate(v)
+	if errs != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorMultiple, errs))
+		return false
+	}
+
+	return true
+}
+
+// respondSession responds with the tokens, they must not be cached
+func respondSession(w http.ResponseWriter, code int, s credential.Session, err error) {
+	if err != nil {
+		if seconds, ok := credential.RetryAfter(err); ok {
+			w.Header().Set(constant.HeaderRetryAfter, strconv.FormatInt(seconds, 10))
+		}
+
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.Header().Set(constant.HeaderCacheControl, "no-store")
+
+	_, err = response.Respond(w, code, response.NewResponse(dto.ToTokenEntity(s)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// Signup handles the signup request, the user is logged in
+func (h *Auth) Signup(w http.ResponseWriter, r *http.Request) {
+	var v dto.Signup
+	if !h.decode(w, r, &v) {
+		return
+	}
+
+	s, err := h.useCase.Signup(r.Context(), v.ToDomainDTO())
+	respondSession(w, http.StatusCreated, s, err)
+}
+
+// Login handles the login request, a delayed or a locked
+// login responds with 429 and Retry-After
+func (h *Auth) Login(w http.ResponseWriter, r *http.Request) {
+	var v dto.Login
+	if !h.decode(w, r, &v) {
+		return
+	}
+
+	p := v.ToDomainDTO()
+	p.IP = h.proxies.ClientIP(r)
+
+	s, err := h.useCase.Login(r.Context(), p)
+	respondSession(w, http.StatusOK, s, err)
+}
+
+// Refresh handles the refresh request, the refresh token
+// of the request can't be used again
+func (h *Auth) Refresh(w http.ResponseWriter, r *http.Request) {
+	var v dto.RefreshToken
+	if !h.decode(w, r, &v) {
+		return
+	}
+
+	s, err := h.useCase.Refresh(r.Context(), v.RefreshToken)
+	respondSession(w, http.StatusOK, s, err)
+}
+
+// Logout handles the logout request, the issued access
+// tokens stay valid until they expire
+func (h *Auth) Logout(w http.ResponseWriter, r *http.Request) {
+	var v dto.RefreshToken
+	if !h.decode(w, r, &v) {
+		return
+	}
+
+	err := h.useCase.Logout(r.Context(), v.RefreshToken)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ReadLockouts handles the list request of the locked
+// accounts and addresses
+func (h *Auth) ReadLockouts(w http.ResponseWriter, r *http.Request) {
+	d, err := h.useCase.ReadLocked(r.Context())
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, dto.ToLockoutEntity(e))
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(i))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// Unlock handles the unlock request of an account or an address
+func (h *Auth) Unlock(w http.ResponseWriter, r *http.Request) {
+	err := h.useCase.Unlock(r.Context(), httpext.GetURLParam(r, constant.ParamKey))
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Feed streams the changes of a resource over
+// Server-Sent Events or WebSocket
+type Feed struct {
+	broker *feed.Broker
+	// types contains the event types of the resource
+	types []string
+}
+
+// NewFeed initializes a new Handler
+func NewFeed(b *feed.Broker, types []string) *Feed {
+	return &Feed{broker: b, types: types}
+}
+
+// Stream subscribes to the changes matching the types and ids query
+// parameters, a client resumes with the Last-Event-ID header or the
+// lastEventId query parameter, a WebSocket upgrade request is served
+// as a WebSocket and the others as Server-Sent Events
+func (h *Feed) Stream(w http.ResponseWriter, r *http.Request) {
+	f, err := feed.ParseFilter(r, h.types)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	after, resume, err := feed.LastEventID(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	s, err := h.broker.Subscribe(r.Context(), f, after, resume)
+	if err != nil {
+		code := http.StatusInternalServerError
+		if errors.Is(err, feed.ErrClosed) {
+			code = http.StatusServiceUnavailable
+		}
+
+		response.RespondError(w, code, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	if feed.IsWebSocket(r) {
+		feed.ServeWebSocket(w, r, h.broker, s)
+		return
+	}
+
+	feed.ServeSSE(w, r, h.broker, s)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/complexity"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/resolver"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/must"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// GraphQL serves the graphql operations over http
+// the depth and the cost of an operation are limited
+// before it runs, see package complexity
+type GraphQL struct {
+	resolver *resolver.Resolver
+	schema   *graphql.Schema
+	analyzer *complexity.Analyzer
+}
+
+// NewGraphQL initializes a new Handler
+func NewGraphQL(r *resolver.Resolver) *GraphQL {
+	return &GraphQL{
+		resolver: r,
+		schema: graphql.MustParseSchema(
+			resolver.Schema,
+			r,
+			graphql.UseStringDescriptions(),
+			graphql.MaxDepth(constant.GraphQLMaxDepth),
+		),
+		analyzer: must.Must(complexity.New(resolver.Schema, constant.GraphQLListSize, complexity.WithMaxListSize(r.MaxLimit()))),
+	}
+}
+
+// Serve handles the graphql post request
+// the executed operations respond with 200, the errors of the
+// resolvers carry the code of the use cases in their extensions
+func (h *GraphQL) Serve(w http.ResponseWriter, r *http.Request) {
+	var v dto.GraphQLRequest
+	err := httpext.DecodeJSON(w, r, &v)
+	if err != nil {
+		respondGraphQLError(w, httpext.BodyErrorCode(err), err)
+		return
+	}
+
+	if v.Query == "" {
+		respondGraphQLError(w, http.StatusBadRequest, errors.New(constant.QueryRequired))
+		return
+	}
+
+	// a query the analyzer can't parse is rejected as its cost is unknown
+	cost, err := h.analyzer.Cost(v.Query, v.OperationName, v.Variables)
+	if err != nil {
+		respondGraphQLError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if cost > constant.GraphQLMaxComplexity {
+		respondGraphQLError(w, http.StatusBadRequest, errors.New(constant.QueryTooComplex))
+		return
+	}
+
+	// the loaders batch the reads by id of the operation
+	ctx := h.resolver.WithLoaders(r.Context())
+
+	res := h.schema.Exec(ctx, v.Query, v.OperationName, v.Variables)
+
+	_, err = response.Respond(w, http.StatusOK, res)
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// respondGraphQLError responds with the error in the errors of a
+// graphql response so that the clients parse one shape
+func respondGraphQLError(w http.ResponseWriter, code int, err error) {
+	res := graphql.Response{
+		Errors: []*gqlerrors.QueryError{{
+			Message:    err.Error(),
+			Extensions: map[string]any{"code": code, "status": resolver.StatusName(code)},
+		}},
+	}
+
+	_, err = response.Respond(w, code, res)
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/oidc"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// oidcCookiePath limits the state cookie to the oidc routes
+const oidcCookiePath = constant.ApiPattern + constant.V1 + constant.AuthPattern + constant.OIDCPattern
+
+// OIDC handles the logins with the external OpenID Connect
+// providers, the login secrets are kept by the user agent in
+// a cookie sealed with the state key between the redirect to
+// the provider and the callback
+type OIDC struct {
+	useCase   credential.UseCase
+	providers map[string]*oidc.Provider
+	stateKey  []byte
+}
+
+// NewOIDC initializes a new Handler of the providers
+func NewOIDC(u credential.UseCase, stateKey []byte, providers ...*oidc.Provider) *OIDC {
+	h := &OIDC{useCase: u, providers: make(map[string]*oidc.Provider), stateKey: stateKey}
+	for _, p := range providers {
+		h.providers[p.Name()] = p
+	}
+
+	return h
+}
+
+func (h *OIDC) respondError(w http.ResponseWriter, code int, err error) {
+	response.RespondError(w, code, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+}
+
+// provider returns the provider of the path, it responds
+// with 404 and returns nil for an unknown one
+func (h *OIDC) provider(w http.ResponseWriter, r *http.Request) *oidc.Provider {
+	p, ok := h.providers[httpext.GetURLParam(r, constant.ParamProvider)]
+	if !ok {
+		h.respondError(w, http.StatusNotFound, errors.New(constant.UnknownIdentityProvider))
+		return nil
+	}
+
+	return p
+}
+
+// Authorize starts a login, it redirects to the
+// authorization endpoint of the provider
+func (h *OIDC) Authorize(w http.ResponseWriter, r *http.Request) {
+	p := h.provider(w, r)
+	if p == nil {
+		return
+	}
+
+	u, ok := h.authorize(w, r, p, "")
+	if !ok {
+		return
+	}
+
+	http.Redirect(w, r, u, http.StatusFound)
+}
+
+// Link starts a login which links the identity to the logged in
+// user, it responds with the url of the authorization endpoint
+// of the provider the user agent navigates to, the callback
+// links the identity instead of logging in
+func (h *OIDC) Link(w http.ResponseWriter, r *http.Request) {
+	p := h.provider(w, r)
+	if p == nil {
+		return
+	}
+
+	// a user logged in, not an api key or a client certificate
+	principal, ok := auth.FromRequest(r)
+	if !ok || principal.Method != auth.MethodJWT {
+		h.respondError(w, http.StatusUnauthorized, errors.New(constant.MissingCredentials))
+		return
+	}
+
+	u, ok := h.authorize(w, r, p, principal.Subject)
+	if !ok {
+		return
+	}
+
+	_, err := response.Respond(w, http.StatusOK, response.NewResponse(dto.AuthorizationEntity{URL: u}))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// authorize sets the state cookie of a login at the provider and
+// returns the url of its authorization endpoint, linkUser is the
+// user the identity is linked to, it responds to the errors
+func (h *OIDC) authorize(w http.ResponseWriter, r *http.Request, p *oidc.Provider, linkUser string) (string, bool) {
+	a, err := oidc.NewAuthorization(p.Name(), time.Now())
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, errorext.ErrInternalServer)
+		return "", false
+	}
+
+	a.LinkUser = linkUser
+
+	u, err := p.AuthCodeURL(r.Context(), a)
+	if err != nil {
+		log.Printf("oidc authorize returned error: %v", err)
+		h.respondError(w, http.StatusBadGateway, errors.New(constant.OIDCLoginFailed))
+		return "", false
+	}
+
+	sealed, err := oidc.Seal(h.stateKey, a)
+	if err != nil {
+		h.respondError(w, http.StatusInternalServerError, errorext.ErrInternalServer)
+		return "", false
+	}
+
+	// Lax so that the cookie is sent with the redirect of the provider
+	http.SetCookie(w, &http.Cookie{
+		Name:     constant.OIDCStateCookie,
+		Value:    sealed,
+		Path:     oidcCookiePath,
+		MaxAge:   constant.OIDCStateTTL * 60,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.Header().Set(constant.HeaderCacheControl, "no-store")
+
+	return u, true
+}
+
+// Callback completes a login, the code of the provider is
+// exchanged for the ID token and the user of its identity
+// is logged in, the identity of a login started by Link is
+// linked to its user instead, the state cookie is cleared
+func (h *OIDC) Callback(w http.ResponseWriter, r *http.Request) {
+	p := h.provider(w, r)
+	if p == nil {
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     constant.OIDCStateCookie,
+		Path:     oidcCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	q := r.URL.Query()
+
+	// the provider redirects with an error, ex: access_denied
+	if e := q.Get("error"); e != "" {
+		log.Printf("oidc callback of %s returned error: %s", p.Name(), e)
+		h.respondError(w, http.StatusUnauthorized, errors.New(constant.OIDCLoginFailed))
+		return
+	}
+
+	c, err := r.Cookie(constant.OIDCStateCookie)
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, oidc.ErrInvalidState)
+		return
+	}
+
+	a, err := oidc.Open(h.stateKey, c.Value, p.Name(), q.Get("state"), time.Now())
+	if err != nil {
+		h.respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	code := q.Get("code")
+	if code == "" {
+		h.respondError(w, http.StatusBadRequest, errors.New(constant.OIDCLoginFailed))
+		return
+	}
+
+	id, err := p.Exchange(r.Context(), code, a)
+	if err != nil {
+		log.Printf("oidc exchange returned error: %v", err)
+
+		status := http.StatusBadGateway
+		if errors.Is(err, oidc.ErrLoginFailed) {
+			status = http.StatusUnauthorized
+		}
+
+		h.respondError(w, status, errors.New(constant.OIDCLoginFailed))
+		return
+	}
+
+	payload := credential.ExternalLoginDTO{
+		Provider:      p.Name(),
+		Subject:       id.Subject,
+		Email:         id.Email,
+		EmailVerified: id.EmailVerified,
+		Name:          id.Name,
+	}
+
+	if a.LinkUser != "" {
+		err := h.useCase.LinkExternal(r.Context(), a.LinkUser, payload)
+		if err != nil {
+			err := errorext.ParseCustomError(err)
+			h.respondError(w, err.Code(), err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s, err := h.useCase.LoginExternal(r.Context(), payload)
+	respondSession(w, http.StatusOK, s, err)
+}
@@ -0,0 +1,26 @@
+package handler
+
+import "github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+
+// Option configures a resource handler
+type Option func(*options)
+
+type options struct {
+	pagination pagination.Policy
+}
+
+// WithPagination sets the pagination policy of the list route
+func WithPagination(p pagination.Policy) Option {
+	return func(o *options) {
+		o.pagination = p
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{pagination: pagination.DefaultPolicy()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
@@ -2,14 +2,18 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
 )
@@ -21,20 +25,48 @@ import (
 type Product struct {
 	useCase   product.UseCase
 	validater validatorext.Validater
+	// pagination is the policy of the list route
+	pagination pagination.Policy
+	// fields is the whitelist of the sparse fieldsets
+	fields fieldset.Whitelist
+	// toEntity converts the domain entity to the
+	// response entity of the api version
+	toEntity func(product.Product) any
 }
 
 // NewProduct initializes a new Handler
-func NewProduct(u product.UseCase, v validatorext.Validater) *Product {
-	return &Product{useCase: u, validater: v}
+func NewProduct(u product.UseCase, v validatorext.Validater, opts ...Option) *Product {
+	o := newOptions(opts)
+
+	return &Product{
+		useCase:    u,
+		validater:  v,
+		pagination: o.pagination,
+		fields:     dto.ProductFields,
+		toEntity:   func(p product.Product) any { return dto.ToProductEntity(p) },
+	}
+}
+
+// NewProductV2 initializes a new v2 Handler
+// v2 responds with dto.ProductEntityV2
+func NewProductV2(u product.UseCase, v validatorext.Validater, opts ...Option) *Product {
+	o := newOptions(opts)
+
+	return &Product{
+		useCase:    u,
+		validater:  v,
+		pagination: o.pagination,
+		fields:     dto.ProductFields,
+		toEntity:   func(p product.Product) any { return dto.ToProductEntityV2(p) },
+	}
 }
 
 // Create handles entity create post request
 func (h *Product) Create(w http.ResponseWriter, r *http.Request) {
 	var v dto.CreateProduct
-	err := httpext.ParseRequestBody(r.Body, &v)
+	err := httpext.DecodeJSON(w, r, &v)
 	if err != nil {
-		err = errorext.ParseJSONError(err)
-		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
@@ -56,7 +88,7 @@ func (h *Product) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// convert to dto entity
-	p := dto.ToProductEntity(d)
+	p := h.toEntity(d)
 
 	_, err = response.Respond(w, http.StatusCreated, response.NewResponse(p))
 	if err != nil {
@@ -64,33 +96,25 @@ func (h *Product) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ReadMany handles the list request
+// the page is resolved with the pagination policy and
+// described with the totals and the Link header
 func (h *Product) ReadMany(w http.ResponseWriter, r *http.Request) {
-	// temp
-	response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New("bad request")}))
-	return
-
-	/* limit := 10
-	page := 1
-	var err error
-
-	limitStr := httpext.GetQueryParam(r, constant.ParamLimit)
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, limitStr)}))
-			return
-		}
+	page, err := h.pagination.Resolve(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
 	}
 
-	pageStr := httpext.GetQueryParam(r, constant.ParamPage)
-	if pageStr != "" {
-		page, err = strconv.Atoi(pageStr)
-		if err != nil {
-			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, pageStr)}))
-			return
-		}
+	sel, err := h.fields.Parse(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
 	}
 
+	// the storage selects the columns of the fieldset
+	ctx := fieldset.WithContext(r.Context(), sel)
+
 	var isArchived = false
 	isArchivedStr := httpext.GetQueryParam(r, constant.ParamIsArchived)
 	if isArchivedStr == "true" {
@@ -98,28 +122,76 @@ func (h *Product) ReadMany(w http.ResponseWriter, r *http.Request) {
 	}
 
 	args := []any{isArchived}
-	d, err := h.useCase.ReadMany(r.Context(), limit, page, args...)
+	d, err := h.useCase.ReadMany(ctx, page.Limit, page.Number, args...)
 	if err != nil {
 		err := errorext.ParseCustomError(err)
 		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
-	// convert to dto entities
-	i := dto.ToProductEntities(d)
+	total, err := h.useCase.Count(r.Context(), h.pagination, args...)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
 
-	res := response.ReadManyResponse[dto.ProductEntity]{
-		Items: i,
-		Limit: limit,
-		Page:  page,
+	// convert to dto entities of the version
+	items := make([]any, 0, len(d))
+	for _, p := range d {
+		items = append(items, fieldset.Project(h.toEntity(p), sel))
 	}
 
-	_, err = response.Respond(w, http.StatusOK, response.NewResponse(&res))
+	meta := pagination.NewMeta(page, total)
+	pagination.SetLinks(w, r, page, meta)
+
+	// no Last-Modified, the latest update of the items does not change
+	// when an item is deleted or filtered out, the ETag validates the list
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(response.NewReadManyResponse(items, page, meta)))
 	if err != nil {
 		log.Printf("response.Respond returned error: %v", err)
-	} */
+	}
+}
+
+// Export streams all the products matching the isArchived
+// query parameter as NDJSON or CSV, see response.Stream
+func (h *Product) Export(w http.ResponseWriter, r *http.Request) {
+	format, err := response.NegotiateStreamFormat(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	// no filter unless the parameter is set
+	var isArchived any
+	if v := httpext.GetQueryParam(r, constant.ParamIsArchived); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, v)}))
+			return
+		}
+
+		isArchived = b
+	}
+
+	// convert to dto entities as they are read
+	seq := func(yield func(any, error) bool) {
+		for d, err := range h.useCase.Stream(r.Context(), isArchived) {
+			if !yield(h.toEntity(d), err) {
+				return
+			}
+		}
+	}
+
+	err = response.Stream(w, format, "products", dto.ProductCSVHeader, seq)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+	}
 }
 
+// ReadOne handles the read request of an entity
+// the fields query parameter selects the fields of the response
 func (h *Product) ReadOne(w http.ResponseWriter, r *http.Request) {
 	id := httpext.GetURLParam(r, constant.ParamId)
 	if id == "" {
@@ -127,7 +199,13 @@ func (h *Product) ReadOne(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	d, err := h.useCase.ReadOne(r.Context(), id)
+	sel, err := h.fields.Parse(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	d, err := h.useCase.ReadOne(fieldset.WithContext(r.Context(), sel), id)
 	if err != nil {
 		err := errorext.ParseCustomError(err)
 		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
@@ -135,7 +213,9 @@ func (h *Product) ReadOne(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// convert to dto entity
-	p := dto.ToProductEntity(d)
+	p := fieldset.Project(h.toEntity(d), sel)
+
+	response.SetLastModified(w, d.UpdatedAt)
 
 	_, err = response.Respond(w, http.StatusOK, response.NewResponse(p))
 	if err != nil {
@@ -152,10 +232,9 @@ func (h *Product) Update(w http.ResponseWriter, r *http.Request) {
 
 	// parse the request body
 	var v dto.UpdateProduct
-	err := httpext.ParseRequestBody(r.Body, &v)
+	err := httpext.DecodeJSON(w, r, &v)
 	if err != nil {
-		err = errorext.ParseJSONError(err)
-		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
@@ -177,7 +256,7 @@ func (h *Product) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// convert to dto entity
-	p := dto.ToProductEntity(d)
+	p := h.toEntity(d)
 
 	_, err = response.Respond(w, http.StatusOK, response.NewResponse(p))
 	if err != nil {
@@ -200,10 +279,28 @@ func (h *Product) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// convert to dto entity
-	p := dto.ToProductEntity(d)
+	p := h.toEntity(d)
 
 	_, err = response.Respond(w, http.StatusOK, response.NewResponse(p))
 	if err != nil {
 		log.Printf("response.Respond returned error: %v", err)
 	}
 }
+
+// Purge handles the purge request, the entity is removed for good
+func (h *Product) Purge(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	err := h.useCase.Purge(r.Context(), id)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/bulk"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
+)
+
+// ProductImport handles the bulk import requests
+// the rows are validated as dto.CreateProduct and
+// upserted by their name
+type ProductImport struct {
+	useCase   product.UseCase
+	validater validatorext.Validater
+	jobs      *bulk.Jobs
+}
+
+// NewProductImport initializes a new Handler
+func NewProductImport(u product.UseCase, v validatorext.Validater, jobs *bulk.Jobs) *ProductImport {
+	return &ProductImport{useCase: u, validater: v, jobs: jobs}
+}
+
+// Import handles the CSV or NDJSON upload
+// the uploads up to constant.ImportSyncMaxBytes are processed in the
+// request and respond with the finished job, larger uploads or
+// async=true respond with 202 and the job is polled at the Location
+func (h *ProductImport) Import(w http.ResponseWriter, r *http.Request) {
+	mapping, err := bulk.ParseMapping(httpext.GetQueryParam(r, constant.ParamMapping))
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	dryRun, err := parseBoolParam(r, constant.ParamDryRun)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	async, err := parseBoolParam(r, constant.ParamAsync)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	// the upload can take longer than the server read timeout
+	// not every writer supports the deadline, ex: httptest
+	_ = http.NewResponseController(w).SetReadDeadline(time.Now().Add(constant.ImportReadTimeout * time.Second))
+	r.Body = http.MaxBytesReader(w, r.Body, constant.ImportMaxBytes)
+
+	upload, err := bulk.ReadUpload(r)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+
+	format, err := bulk.DetectFormat(httpext.GetQueryParam(r, constant.ParamFormat), upload.ContentType, upload.Filename)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	src := bulk.Source{
+		Body:    io.NopCloser(upload.Body),
+		Size:    r.ContentLength,
+		Format:  format,
+		Mapping: mapping,
+	}
+
+	async = async || r.ContentLength < 0 || r.ContentLength > constant.ImportSyncMaxBytes
+	if async {
+		// the job outlives the request body
+		src.Body, src.Size, err = bulk.Spool(upload.Body)
+		if err != nil {
+			respondUploadError(w, err)
+			return
+		}
+	}
+
+	// the job runs with a context of its own, the upserts
+	// are authorized with the principal of the upload
+	var principal *auth.Principal
+	if p, ok := auth.FromRequest(r); ok {
+		principal = &p
+	}
+
+	job := bulk.Run(h.jobs, src, dryRun, h.process(dryRun, principal))
+
+	w.Header().Set("Location", r.URL.Path+"/"+job.ID())
+
+	status := http.StatusAccepted
+	if !async {
+		<-job.Done()
+
+		if bulk.IsTooLarge(job.Err()) {
+			respondUploadError(w, job.Err())
+			return
+		}
+
+		status = http.StatusOK
+	}
+
+	_, err = response.Respond(w, status, response.NewResponse(job.View()))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// process validates the row and upserts it unless it is a dry run
+// principal is nil for the anonymous uploads
+func (h *ProductImport) process(dryRun bool, principal *auth.Principal) bulk.Process[dto.CreateProduct] {
+	return func(ctx context.Context, v dto.CreateProduct) bulk.RowResult {
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, *principal)
+		}
+
+		errs := h.validater.Validate(&v)
+		if errs != nil {
+			return bulk.Invalid(errs...)
+		}
+
+		if dryRun {
+			return bulk.RowResult{Status: bulk.RowValid}
+		}
+
+		d, created, err := h.useCase.Upsert(ctx, v.ToDomainDTO())
+		if err != nil {
+			return bulk.Failed(errorext.ParseCustomError(err))
+		}
+
+		res := bulk.RowResult{Status: bulk.RowUpdated, ID: d.ID}
+		if created {
+			res.Status = bulk.RowCreated
+		}
+
+		return res
+	}
+}
+
+// ReadJob responds with the state and progress of the job
+func (h *ProductImport) ReadJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := response.Respond(w, http.StatusOK, response.NewResponse(job.View()))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// ReadReport streams the row report of the finished job
+// as NDJSON or CSV, see response.NegotiateStreamFormat
+func (h *ProductImport) ReadReport(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.job(w, r)
+	if !ok {
+		return
+	}
+
+	if !job.Finished() {
+		response.RespondError(w, http.StatusConflict, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.ImportInProgress)}))
+		return
+	}
+
+	format, err := response.NegotiateStreamFormat(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	seq := func(yield func(bulk.RowResult, error) bool) {
+		for _, v := range job.Results() {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+
+	err = response.Stream(w, format, "import-"+job.ID(), bulk.ReportCSVHeader, seq)
+	if err != nil {
+		response.RespondError(w, http.StatusInternalServerError, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+	}
+}
+
+// job returns the job of the path parameter
+// it responds with 404 if there is none
+func (h *ProductImport) job(w http.ResponseWriter, r *http.Request) (*bulk.Job, bool) {
+	job, ok := h.jobs.Get(httpext.GetURLParam(r, constant.ParamJobId))
+	if !ok {
+		response.RespondError(w, http.StatusNotFound, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.ImportJobNotFound)}))
+	}
+
+	return job, ok
+}
+
+// parseBoolParam parses the optional boolean query parameter
+func parseBoolParam(r *http.Request, name string) (bool, error) {
+	v := httpext.GetQueryParam(r, name)
+	if v == "" {
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %s", constant.InvalidQueryParam, v)
+	}
+
+	return b, nil
+}
+
+// respondUploadError responds with 413 if the upload exceeded
+// the size limit and with 400 otherwise
+func respondUploadError(w http.ResponseWriter, err error) {
+	if bulk.IsTooLarge(err) {
+		response.RespondError(w, http.StatusRequestEntityTooLarge, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.ImportTooLarge)}))
+		return
+	}
+
+	response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Role handles the requests of the roles and
+// the role assignments of the users
+type Role struct {
+	useCase role.UseCase
+}
+
+// NewRole initializes a new Handler
+func NewRole(u role.UseCase) *Role {
+	return &Role{useCase: u}
+}
+
+// ReadMany handles the list request of the roles with their grants
+func (h *Role) ReadMany(w http.ResponseWriter, r *http.Request) {
+	d, err := h.useCase.ReadMany(r.Context())
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, dto.ToRoleEntity(e))
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(i))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// ReadUserRoles handles the read request of the roles of a user
+func (h *Role) ReadUserRoles(w http.ResponseWriter, r *http.Request) {
+	h.respondUserRoles(w, r, func(ctx context.Context, userID, _ string) ([]string, error) {
+		return h.useCase.ReadUserRoles(ctx, userID)
+	})
+}
+
+// Assign handles the assign request of a role, assigning
+// an assigned role responds with the unchanged roles
+func (h *Role) Assign(w http.ResponseWriter, r *http.Request) {
+	h.respondUserRoles(w, r, h.useCase.Assign)
+}
+
+// Remove handles the remove request of a role
+func (h *Role) Remove(w http.ResponseWriter, r *http.Request) {
+	h.respondUserRoles(w, r, h.useCase.Remove)
+}
+
+// respondUserRoles calls fn with the path params and
+// responds with the roles of the user it returns
+func (h *Role) respondUserRoles(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, userID, role string) ([]string, error)) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := fn(r.Context(), id, httpext.GetURLParam(r, constant.ParamRole))
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(dto.ToUserRolesEntity(id, d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
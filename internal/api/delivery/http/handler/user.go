@@ -11,7 +11,9 @@ import (
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
 )
@@ -20,21 +22,26 @@ import (
 type User struct {
 	useCase   user.UseCase
 	validater validatorext.Validater
+	// pagination is the policy of the list route
+	pagination pagination.Policy
+	// fields is the whitelist of the sparse fieldsets
+	fields fieldset.Whitelist
 }
 
 // NewUser initializes a new Handler
-func NewUser(u user.UseCase, v validatorext.Validater) *User {
-	return &User{useCase: u, validater: v}
+func NewUser(u user.UseCase, v validatorext.Validater, opts ...Option) *User {
+	o := newOptions(opts)
+
+	return &User{useCase: u, validater: v, pagination: o.pagination, fields: dto.UserFields}
 }
 
 // Create handles entity create post request
 func (u *User) Create(w http.ResponseWriter, r *http.Request) {
 	var v dto.CreateUser
 	// parse the request body
-	err := httpext.ParseRequestBody(r.Body, &v)
+	err := httpext.DecodeJSON(w, r, &v)
 	if err != nil {
-		err = errorext.ParseJSONError(err)
-		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
@@ -64,29 +71,25 @@ func (u *User) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ReadMany handles the list request
+// the page is resolved with the pagination policy and
+// described with the totals and the Link header
 func (u *User) ReadMany(w http.ResponseWriter, r *http.Request) {
-	limit := 10
-	page := 1
-	var err error
-
-	limitStr := httpext.GetQueryParam(r, constant.ParamLimit)
-	if limitStr != "" {
-		limit, err = strconv.Atoi(limitStr)
-		if err != nil {
-			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, limitStr)}))
-			return
-		}
+	page, err := u.pagination.Resolve(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
 	}
 
-	pageStr := httpext.GetQueryParam(r, constant.ParamPage)
-	if pageStr != "" {
-		page, err = strconv.Atoi(pageStr)
-		if err != nil {
-			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, pageStr)}))
-			return
-		}
+	sel, err := u.fields.Parse(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
 	}
 
+	// the storage selects the columns of the fieldset
+	ctx := fieldset.WithContext(r.Context(), sel)
+
 	var isArchived = false
 	isArchivedStr := httpext.GetQueryParam(r, constant.ParamIsArchived)
 	if isArchivedStr == "true" {
@@ -94,28 +97,76 @@ func (u *User) ReadMany(w http.ResponseWriter, r *http.Request) {
 	}
 
 	args := []any{isArchived}
-	d, err := u.useCase.ReadMany(r.Context(), limit, page, args...)
+	d, err := u.useCase.ReadMany(ctx, page.Limit, page.Number, args...)
 	if err != nil {
 		err := errorext.ParseCustomError(err)
 		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
-	// convert to dto entities
-	i := dto.ToUserEntities(d)
+	total, err := u.useCase.Count(r.Context(), u.pagination, args...)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
 
-	res := response.ReadManyResponse[dto.UserEntity]{
-		Items: i,
-		Limit: limit,
-		Page:  page,
+	// convert to dto entities
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, fieldset.Project(dto.ToUserEntity(e), sel))
 	}
 
-	_, err = response.Respond(w, http.StatusOK, response.NewResponse(&res))
+	meta := pagination.NewMeta(page, total)
+	pagination.SetLinks(w, r, page, meta)
+
+	// no Last-Modified, the latest update of the items does not change
+	// when an item is deleted or filtered out, the ETag validates the list
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(response.NewReadManyResponse(i, page, meta)))
 	if err != nil {
 		log.Printf("response.Respond returned error: %v", err)
 	}
 }
 
+// Export streams all the users matching the isArchived
+// query parameter as NDJSON or CSV, see response.Stream
+func (u *User) Export(w http.ResponseWriter, r *http.Request) {
+	format, err := response.NegotiateStreamFormat(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	// no filter unless the parameter is set
+	var isArchived any
+	if v := httpext.GetQueryParam(r, constant.ParamIsArchived); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.InvalidQueryParam, v)}))
+			return
+		}
+
+		isArchived = b
+	}
+
+	// convert to dto entities as they are read
+	seq := func(yield func(any, error) bool) {
+		for d, err := range u.useCase.Stream(r.Context(), isArchived) {
+			if !yield(dto.ToUserEntity(d), err) {
+				return
+			}
+		}
+	}
+
+	err = response.Stream(w, format, "users", dto.UserCSVHeader, seq)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+	}
+}
+
+// ReadOne handles the read request of an entity
+// the fields query parameter selects the fields of the response
 func (u *User) ReadOne(w http.ResponseWriter, r *http.Request) {
 	id := httpext.GetURLParam(r, constant.ParamId)
 	if id == "" {
@@ -123,7 +174,13 @@ func (u *User) ReadOne(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	d, err := u.useCase.ReadOne(r.Context(), id)
+	sel, err := u.fields.Parse(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	d, err := u.useCase.ReadOne(fieldset.WithContext(r.Context(), sel), id)
 	if err != nil {
 		err := errorext.ParseCustomError(err)
 		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
@@ -131,7 +188,9 @@ func (u *User) ReadOne(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// convert to dto entity
-	p := dto.ToUserEntity(d)
+	p := fieldset.Project(dto.ToUserEntity(d), sel)
+
+	response.SetLastModified(w, d.UpdatedAt)
 
 	_, err = response.Respond(w, http.StatusOK, response.NewResponse(p))
 	if err != nil {
@@ -148,10 +207,9 @@ func (u *User) Update(w http.ResponseWriter, r *http.Request) {
 
 	// parse the request body
 	var v dto.UpdateUser
-	err := httpext.ParseRequestBody(r.Body, &v)
+	err := httpext.DecodeJSON(w, r, &v)
 	if err != nil {
-		err = errorext.ParseJSONError(err)
-		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
 		return
 	}
 
@@ -203,3 +261,21 @@ func (u *User) Delete(w http.ResponseWriter, r *http.Request) {
 		log.Printf("response.Respond returned error: %v", err)
 	}
 }
+
+// Purge handles the purge request, the entity is removed for good
+func (u *User) Purge(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	err := u.useCase.Purge(r.Context(), id)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,240 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
+)
+
+// Webhook handles the requests of the webhook subscriptions
+// and their delivery logs
+type Webhook struct {
+	useCase   webhook.UseCase
+	validater validatorext.Validater
+	// pagination is the policy of the list routes
+	pagination pagination.Policy
+}
+
+// NewWebhook initializes a new Handler
+func NewWebhook(u webhook.UseCase, v validatorext.Validater, opts ...Option) *Webhook {
+	o := newOptions(opts)
+
+	return &Webhook{useCase: u, validater: v, pagination: o.pagination}
+}
+
+// Create handles the subscription create request
+// the response carries the secret, it is not returned again
+func (h *Webhook) Create(w http.ResponseWriter, r *http.Request) {
+	var v dto.CreateWebhook
+	err := httpext.DecodeJSON(w, r, &v)
+	if err != nil {
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	errs := h.validater.Validate(&v)
+	if errs != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorMultiple, errs))
+		return
+	}
+
+	d, err := h.useCase.Create(r.Context(), v.ToDomainDTO())
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	p := dto.CreatedWebhookEntity{WebhookEntity: *dto.ToWebhookEntity(d), Secret: d.Secret}
+
+	_, err = response.Respond(w, http.StatusCreated, response.NewResponse(p))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// ReadMany handles the list request of the subscriptions
+func (h *Webhook) ReadMany(w http.ResponseWriter, r *http.Request) {
+	page, err := h.pagination.Resolve(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	d, err := h.useCase.ReadMany(r.Context(), page.Limit, page.Number)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	total, err := h.useCase.Count(r.Context(), h.pagination)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, dto.ToWebhookEntity(e))
+	}
+
+	meta := pagination.NewMeta(page, total)
+	pagination.SetLinks(w, r, page, meta)
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(response.NewReadManyResponse(i, page, meta)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+func (h *Webhook) ReadOne(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.ReadOne(r.Context(), id)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(dto.ToWebhookEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// Update replaces the url and the events of the subscription
+// enabled true re-enables a subscription disabled after failures
+func (h *Webhook) Update(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	var v dto.UpdateWebhook
+	err := httpext.DecodeJSON(w, r, &v)
+	if err != nil {
+		response.RespondError(w, httpext.BodyErrorCode(err), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	errs := h.validater.Validate(&v)
+	if errs != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorMultiple, errs))
+		return
+	}
+
+	d, err := h.useCase.Update(r.Context(), id, v.ToDomainDTO())
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(dto.ToWebhookEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+func (h *Webhook) Delete(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.Delete(r.Context(), id)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(dto.ToWebhookEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// ReadDeliveries handles the list request of the delivery log
+// of a subscription, the latest deliveries first
+func (h *Webhook) ReadDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	if id == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	page, err := h.pagination.Resolve(r)
+	if err != nil {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	d, err := h.useCase.ReadDeliveries(r.Context(), id, page.Limit, page.Number)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	total, err := h.useCase.CountDeliveries(r.Context(), id)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	i := make([]any, 0, len(d))
+	for _, e := range d {
+		i = append(i, dto.ToDeliveryEntity(e))
+	}
+
+	meta := pagination.NewMeta(page, total)
+	pagination.SetLinks(w, r, page, meta)
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(response.NewReadManyResponse(i, page, meta)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// Redeliver enqueues a new delivery of the payload of a delivery
+// it responds 202 with the new delivery, it is sent asynchronously
+func (h *Webhook) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id := httpext.GetURLParam(r, constant.ParamId)
+	deliveryID := httpext.GetURLParam(r, constant.ParamDeliveryId)
+	if id == "" || deliveryID == "" {
+		response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.MissingRequiredPathParam)}))
+		return
+	}
+
+	d, err := h.useCase.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		err := errorext.ParseCustomError(err)
+		response.RespondError(w, err.Code(), response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusAccepted, response.NewResponse(dto.ToDeliveryEntity(d)))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
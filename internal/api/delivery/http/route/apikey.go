@@ -0,0 +1,24 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// APIKey builds the routes of the api key handler, the
+// keys belong to the caller so the use case authorizes them
+// the create route is not idempotent, a replay would store
+// the key in clear with the response
+func APIKey(handler *handler.APIKey) chi.Router {
+	r := chi.NewRouter()
+	r.Post("/", handler.Create)
+	r.Get("/", handler.ReadMany)
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", handler.ReadOne)
+		r.Delete("/", handler.Revoke)
+		r.Post(constant.RotatePattern, handler.Rotate)
+	})
+	return r
+}
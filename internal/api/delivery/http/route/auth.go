@@ -0,0 +1,32 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// Auth builds the routes of the auth handler and of the
+// logins with the OpenID Connect providers, they are
+// limited with LimitAuth as they can guess the credentials,
+// the lockouts are managed with PermissionUsersManage
+func Auth(handler *handler.Auth, oidc *handler.OIDC, opts Options) chi.Router {
+	r := chi.NewRouter()
+	r.Use(opts.Limit(LimitAuth))
+	r.Post(constant.SignupPattern, handler.Signup)
+	r.Post(constant.LoginPattern, handler.Login)
+	r.Post(constant.RefreshPattern, handler.Refresh)
+	r.Post(constant.LogoutPattern, handler.Logout)
+	r.Get(constant.OIDCPattern+"/{"+constant.ParamProvider+"}", oidc.Authorize)
+	r.Get(constant.OIDCPattern+"/{"+constant.ParamProvider+"}"+constant.CallbackPattern, oidc.Callback)
+	// authenticated, the logged in user links an identity
+	r.Post(constant.OIDCPattern+"/{"+constant.ParamProvider+"}"+constant.LinkPattern, oidc.Link)
+
+	r.Group(func(r chi.Router) {
+		r.Use(opts.Require(role.PermissionUsersManage, nil))
+		r.Get(constant.LockoutsPattern, handler.ReadLockouts)
+		r.Delete(constant.LockoutsPattern+"/{"+constant.ParamKey+"}", handler.Unlock)
+	})
+	return r
+}
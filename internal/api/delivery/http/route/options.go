@@ -0,0 +1,68 @@
+package route
+
+import (
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	middlewarext "github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+// the names of the per route rate limits
+const (
+	LimitAuth   = "auth"
+	LimitImport = "import"
+)
+
+// CachePolicies contains the Cache-Control policies of the read routes
+// an empty policy leaves the header unset
+type CachePolicies struct {
+	ReadOne  string
+	ReadMany string
+}
+
+// Options contains the per route middlewares and policies
+type Options struct {
+	// Create contains the middlewares of the create route
+	Create []func(http.Handler) http.Handler
+	Cache  CachePolicies
+	// Authorizer authorizes the requests of the routes
+	// nil disables the checks
+	Authorizer rbac.Authorizer
+	// RateLimits contains the rate limit middlewares of the
+	// routes keyed by the limit name, ex: LimitAuth, they
+	// apply on top of the global limit
+	RateLimits map[string]func(http.Handler) http.Handler
+}
+
+// Require returns the middleware checking the permission
+// see middlewarext.Require, it passes the requests through
+// when the checks are disabled
+func (o Options) Require(permission string, owner func(*http.Request) string) func(http.Handler) http.Handler {
+	if o.Authorizer == nil {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return middlewarext.Require(o.Authorizer, permission, owner)
+}
+
+// Limit returns the rate limit middleware of the name, it
+// passes the requests through when the limit is not set
+func (o Options) Limit(name string) func(http.Handler) http.Handler {
+	mw, ok := o.RateLimits[name]
+	if !ok {
+		return func(next http.Handler) http.Handler {
+			return next
+		}
+	}
+
+	return mw
+}
+
+// ownerParam returns the id of the requested user as its owner
+func ownerParam(r *http.Request) string {
+	return httpext.GetURLParam(r, constant.ParamId)
+}
@@ -3,17 +3,25 @@ package route
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	middlewarext "github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
 )
 
-func Product(handler *handler.Product) chi.Router {
+// Product builds the routes of the product handler
+func Product(handler *handler.Product, opts Options) chi.Router {
+	read := opts.Require(role.PermissionProductsRead, nil)
+	write := opts.Require(role.PermissionProductsWrite, nil)
+
 	r := chi.NewRouter()
-	r.Post("/", handler.Create)
-	r.Get("/", handler.ReadMany)
+	r.With(write).With(opts.Create...).Post("/", handler.Create)
+	r.With(read, middlewarext.CacheControl(opts.Cache.ReadMany), middlewarext.Conditional).Get("/", handler.ReadMany)
 
 	r.Route("/{id}", func(r chi.Router) {
-		r.Get("/", handler.ReadOne)
-		r.Put("/", handler.Update)
-		r.Delete("/", handler.Delete)
+		r.With(read, middlewarext.CacheControl(opts.Cache.ReadOne), middlewarext.Conditional).Get("/", handler.ReadOne)
+		r.With(write).Put("/", handler.Update)
+		r.With(opts.Require(role.PermissionProductsDelete, nil)).Delete("/", handler.Delete)
+		r.With(opts.Require(role.PermissionProductsPurge, nil)).Delete(constant.PurgePattern, handler.Purge)
 	})
 	return r
 }
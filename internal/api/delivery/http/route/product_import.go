@@ -0,0 +1,22 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// ProductImport builds the routes of the product import handler
+// the imports create and update the products
+func ProductImport(handler *handler.ProductImport, opts Options) chi.Router {
+	r := chi.NewRouter()
+	r.Use(opts.Require(role.PermissionProductsWrite, nil))
+	r.With(opts.Limit(LimitImport)).Post("/", handler.Import)
+
+	r.Route("/{"+constant.ParamJobId+"}", func(r chi.Router) {
+		r.Get("/", handler.ReadJob)
+		r.Get(constant.ReportPattern, handler.ReadReport)
+	})
+	return r
+}
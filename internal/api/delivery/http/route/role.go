@@ -0,0 +1,30 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// Role builds the routes of the roles
+func Role(handler *handler.Role, opts Options) chi.Router {
+	r := chi.NewRouter()
+	r.With(opts.Require(role.PermissionUsersManage, nil)).Get("/", handler.ReadMany)
+	return r
+}
+
+// UserRoles builds the routes of the roles of a user, they are
+// mounted under the path of the user, the users read their own
+// roles so the use case authorizes the read route
+func UserRoles(handler *handler.Role, opts Options) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", handler.ReadUserRoles)
+
+	r.Group(func(r chi.Router) {
+		r.Use(opts.Require(role.PermissionUsersManage, nil))
+		r.Put("/{"+constant.ParamRole+"}", handler.Assign)
+		r.Delete("/{"+constant.ParamRole+"}", handler.Remove)
+	})
+	return r
+}
@@ -3,17 +3,23 @@ package route
 import (
 	"github.com/go-chi/chi/v5"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	middlewarext "github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
 )
 
-func User(handler *handler.User) chi.Router {
+// User builds the routes of the user handler, the
+// users with an own grant read and edit themselves
+func User(handler *handler.User, opts Options) chi.Router {
 	r := chi.NewRouter()
-	r.Post("/", handler.Create)
-	r.Get("/", handler.ReadMany)
+	r.With(opts.Require(role.PermissionUsersWrite, nil)).With(opts.Create...).Post("/", handler.Create)
+	r.With(opts.Require(role.PermissionUsersRead, nil), middlewarext.CacheControl(opts.Cache.ReadMany), middlewarext.Conditional).Get("/", handler.ReadMany)
 
 	r.Route("/{id}", func(r chi.Router) {
-		r.Get("/", handler.ReadOne)
-		r.Put("/", handler.Update)
-		r.Delete("/", handler.Delete)
+		r.With(opts.Require(role.PermissionUsersRead, ownerParam), middlewarext.CacheControl(opts.Cache.ReadOne), middlewarext.Conditional).Get("/", handler.ReadOne)
+		r.With(opts.Require(role.PermissionUsersWrite, ownerParam)).Put("/", handler.Update)
+		r.With(opts.Require(role.PermissionUsersDelete, ownerParam)).Delete("/", handler.Delete)
+		r.With(opts.Require(role.PermissionUsersPurge, nil)).Delete(constant.PurgePattern, handler.Purge)
 	})
 	return r
 }
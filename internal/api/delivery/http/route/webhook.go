@@ -0,0 +1,29 @@
+package route
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// Webhook builds the routes of the webhook handler
+func Webhook(handler *handler.Webhook, opts Options) chi.Router {
+	r := chi.NewRouter()
+
+	r.Group(func(r chi.Router) {
+		r.Use(opts.Require(role.PermissionWebhooksManage, nil))
+
+		r.With(opts.Create...).Post("/", handler.Create)
+		r.Get("/", handler.ReadMany)
+
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", handler.ReadOne)
+			r.Put("/", handler.Update)
+			r.Delete("/", handler.Delete)
+			r.Get(constant.DeliveriesPattern, handler.ReadDeliveries)
+			r.Post(constant.DeliveriesPattern+"/{"+constant.ParamDeliveryId+"}"+constant.RedeliverPattern, handler.Redeliver)
+		})
+	})
+	return r
+}
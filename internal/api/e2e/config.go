@@ -2,8 +2,15 @@ package e2e
 
 import (
 	"database/sql"
+	"log"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
 )
@@ -11,15 +18,22 @@ import (
 // config contains the components of the application
 // and configures them as required
 type config struct {
-	db        *sql.DB
-	router    *router.Router
-	validater validatorext.Validater
+	db          *sql.DB
+	router      *router.Router
+	validater   validatorext.Validater
+	registry    *module.Registry
+	idempotency *idempotency.Idempotency
+	feed        *feed.Broker
+	keyRing     *envelope.KeyRing
 }
 
 func NewConfig(db *sql.DB) *config {
 	c := &config{db: db}
+	c.initEncryption()
 	c.initRouter()
 	c.initValidator()
+	c.initIdempotency()
+	c.initFeed()
 
 	// Initialize components
 	initComponents(c)
@@ -27,9 +41,45 @@ func NewConfig(db *sql.DB) *config {
 	return c
 }
 
+// initEncryption initializes the key ring of the personal data
+func (c *config) initEncryption() {
+	k, err := modules.NewKeyRingFromEnv()
+	if err != nil {
+		log.Fatalf("init encryption: %v", err)
+	}
+
+	c.keyRing = k
+}
+
 // initRouter initializes router
+// the e2e routes are not authenticated, the tests call them without tokens
 func (c *config) initRouter() {
-	c.router = router.NewRouter()
+	v, err := modules.NewVersioning()
+	if err != nil {
+		log.Fatalf("init versioning: %v", err)
+	}
+
+	c.router = router.NewRouter(router.WithVersioning(v), router.WithTimeoutExempt(modules.IsLongRunning))
+}
+
+// initIdempotency initializes the idempotency of the create routes
+func (c *config) initIdempotency() {
+	i, err := modules.NewIdempotencyFromEnv(c.db)
+	if err != nil {
+		log.Fatalf("init idempotency: %v", err)
+	}
+
+	c.idempotency = i
+}
+
+// initFeed initializes the broker of the change feeds
+func (c *config) initFeed() {
+	b, err := modules.NewFeedFromEnv(c.db, middleware.DefaultCORSPolicy())
+	if err != nil {
+		log.Fatalf("init feed: %v", err)
+	}
+
+	c.feed = b
 }
 
 // initValidator initializes validator
@@ -40,3 +90,7 @@ func (c *config) initValidator() {
 func (c *config) Router() *router.Router {
 	return c.router
 }
+
+func (c *config) Registry() *module.Registry {
+	return c.registry
+}
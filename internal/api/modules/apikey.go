@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	apikeyprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/provider"
+	apikeyservice "github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// APIKey is the module of the api keys of the machine
+// clients, the keys authenticate the requests with the
+// authenticator of Authentication
+type APIKey struct {
+	handler *handler.APIKey
+}
+
+// NewAPIKey initializes the api key module
+func NewAPIKey(deps Deps) *APIKey {
+	var opts []apikeyservice.Option
+	if a := deps.authorizer(); a != nil {
+		opts = append(opts, apikeyservice.WithAuthorizer(a))
+	}
+
+	p := apikeyprovider.New(deps.DB, opts...)
+
+	return &APIKey{
+		handler: handler.NewAPIKey(p.UseCase, deps.Validater),
+	}
+}
+
+func (m *APIKey) Name() module.ID {
+	return NameAPIKey
+}
+
+func (m *APIKey) Version() string {
+	return constant.V1
+}
+
+func (m *APIKey) Dependencies() []module.ID {
+	return []module.ID{NameUser, NameRole}
+}
+
+func (m *APIKey) Routes(r chi.Router) {
+	r.Mount(constant.APIKeysPattern, route.APIKey(m.handler))
+}
+
+func (m *APIKey) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *APIKey) Stop(ctx context.Context) error {
+	return nil
+}
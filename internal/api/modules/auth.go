@@ -0,0 +1,484 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	apikeyprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/apikey/provider"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/credential"
+	credentialprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/credential/provider"
+	credentialservice "github.com/tanveerprottoy/backend-structure-go/internal/api/credential/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/oidc"
+)
+
+// the minimum length of the HS256 secret, RFC 7518 3.2
+const jwtSecretMinBytes = 32
+
+// publicRoutes contains the routes served without authentication
+// keyed by the method and the path, a new public route needs one
+// entry here
+var publicRoutes = map[string]struct{}{
+	http.MethodGet + " " + constant.ApiPattern + constant.OpenAPIPattern: {},
+	http.MethodGet + " " + constant.ApiPattern + constant.DocsPattern:    {},
+	http.MethodPost + " " + authPath + constant.SignupPattern:            {},
+	http.MethodPost + " " + authPath + constant.LoginPattern:             {},
+	http.MethodPost + " " + authPath + constant.RefreshPattern:           {},
+	http.MethodPost + " " + authPath + constant.LogoutPattern:            {},
+}
+
+// publicGetPrefixes contains the path prefixes of the public
+// GET routes of a path parameter, ex: the oidc provider, and
+// of the files, ex: the assets of the docs
+var publicGetPrefixes = []string{
+	authPath + constant.OIDCPattern + "/",
+	constant.ApiPattern + constant.DocsPattern + "/",
+}
+
+// authPath is the path of the auth routes
+const authPath = constant.ApiPattern + constant.V1 + constant.AuthPattern
+
+// IsPublic reports if the request is served by a public route
+func IsPublic(r *http.Request) bool {
+	_, ok := publicRoutes[r.Method+" "+r.URL.Path]
+	if ok || r.Method != http.MethodGet {
+		return ok
+	}
+
+	for _, p := range publicGetPrefixes {
+		if strings.HasPrefix(r.URL.Path, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authentication returns the middleware authenticating the
+// requests with the bearer tokens verified by v, the api
+// keys stored in db and the tls client certificates, certRoles
+// contains the roles of the certificate subjects, the streams
+// also take a short lived token of the query for the browsers
+func Authentication(v *auth.Verifier, db *sql.DB, certRoles map[string][]string) func(http.Handler) http.Handler {
+	keys := apikeyprovider.New(db)
+
+	return middleware.Authenticate(
+		IsPublic,
+		middleware.BearerJWT(v),
+		middleware.APIKey(keys.UseCase),
+		middleware.QueryJWT(v, IsStream, constant.QueryTokenTTL*time.Minute),
+		middleware.ClientCert(certRoles),
+	)
+}
+
+// NewClientCertRolesFromEnv returns the roles of the subjects of the
+// tls client certificates, TLS_CLIENT_ROLES contains the comma
+// separated subject:role pairs, ex: billing:staff,reports:admin
+func NewClientCertRolesFromEnv() (map[string][]string, error) {
+	roles := make(map[string][]string)
+
+	for _, v := range splitList(os.Getenv("TLS_CLIENT_ROLES")) {
+		subject, role, ok := strings.Cut(v, ":")
+		if !ok || subject == "" || role == "" {
+			return nil, fmt.Errorf("TLS_CLIENT_ROLES: invalid entry %q, it must be subject:role", v)
+		}
+
+		roles[subject] = append(roles[subject], role)
+	}
+
+	return roles, nil
+}
+
+// NewVerifierFromEnv initializes the verifier of the bearer tokens
+// JWT_SECRET is the HS256 secret, JWT_JWKS_FILE or JWT_JWKS_URL is the
+// JSON Web Key Set of the RS256 and ES256 keys, JWKS_CACHE_TTL sets the
+// time the set is cached for, ex: 10m
+// JWT_ISSUER, JWT_AUDIENCE (comma separated) and JWT_CLOCK_SKEW, ex: 30s,
+// configure the validation of the claims
+// AUTH_ENABLED=false disables the authentication, the verifier is nil
+func NewVerifierFromEnv() (*auth.Verifier, error) {
+	if os.Getenv("AUTH_ENABLED") == "false" {
+		return nil, nil
+	}
+
+	var (
+		keys       auth.KeySets
+		algorithms []string
+		jwksOpts   []auth.JWKSOption
+		opts       []auth.VerifierOption
+	)
+
+	if v := os.Getenv("JWKS_CACHE_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWKS_CACHE_TTL: %w", err)
+		}
+
+		jwksOpts = append(jwksOpts, auth.WithTTL(ttl))
+	}
+
+	if v := os.Getenv("JWT_SECRET"); v != "" {
+		if len(v) < jwtSecretMinBytes {
+			return nil, fmt.Errorf("JWT_SECRET must be at least %d bytes", jwtSecretMinBytes)
+		}
+
+		keys = append(keys, auth.HMACKey(v))
+		algorithms = append(algorithms, auth.AlgHS256)
+	}
+
+	jwksFile, jwksURL := os.Getenv("JWT_JWKS_FILE"), os.Getenv("JWT_JWKS_URL")
+	switch {
+	case jwksFile != "" && jwksURL != "":
+		return nil, errors.New("JWT_JWKS_FILE and JWT_JWKS_URL are exclusive")
+	case jwksFile != "":
+		keys = append(keys, auth.NewJWKSFile(jwksFile, jwksOpts...))
+	case jwksURL != "":
+		keys = append(keys, auth.NewJWKSURL(jwksURL, jwksOpts...))
+	}
+
+	if jwksFile != "" || jwksURL != "" {
+		algorithms = append(algorithms, auth.AlgRS256, auth.AlgES256)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("the authentication needs JWT_SECRET, JWT_JWKS_FILE or JWT_JWKS_URL, AUTH_ENABLED=false disables it")
+	}
+
+	opts = append(opts, auth.WithAlgorithms(algorithms...))
+
+	if v := os.Getenv("JWT_ISSUER"); v != "" {
+		opts = append(opts, auth.WithIssuer(v))
+	}
+
+	if v := os.Getenv("JWT_AUDIENCE"); v != "" {
+		opts = append(opts, auth.WithAudience(strings.Split(strings.ReplaceAll(v, " ", ""), ",")...))
+	}
+
+	if v := os.Getenv("JWT_CLOCK_SKEW"); v != "" {
+		skew, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parse JWT_CLOCK_SKEW: %w", err)
+		}
+
+		opts = append(opts, auth.WithClockSkew(skew))
+	}
+
+	return auth.NewVerifier(keys, opts...), nil
+}
+
+// Credentials contains the components of the sessions
+// of the users logging in with a password
+type Credentials struct {
+	// Signer issues the access tokens, nil disables the auth routes
+	Signer *auth.Signer
+	// Hasher hashes the passwords, nil is the default parameters
+	Hasher *auth.PasswordHasher
+	// RefreshTTL is the lifetime of the refresh tokens, 0 is the default
+	RefreshTTL time.Duration
+	// Providers are the OpenID Connect providers of the external logins
+	Providers []*oidc.Provider
+	// StateKey seals the state cookies of the external logins
+	StateKey []byte
+	// AccountLockout and IPLockout limit the failed logins per
+	// account and per client address, zero policies disable them
+	AccountLockout credential.LockoutPolicy
+	IPLockout      credential.LockoutPolicy
+	// TrustedProxies forward the address of the client of the logins
+	TrustedProxies middleware.TrustedProxies
+}
+
+// NewCredentialsFromEnv initializes the credentials, the access tokens
+// are signed with JWT_SECRET and carry JWT_ISSUER and JWT_AUDIENCE so
+// that the verifier accepts them, the signer is nil without JWT_SECRET
+// ACCESS_TOKEN_TTL and REFRESH_TOKEN_TTL set the lifetimes, ex: 15m and
+// 720h, PASSWORD_HASH_PARAMS sets the argon2id parameters of the new
+// hashes, ex: "m=19456,t=2,p=1", the old hashes are upgraded on login
+func NewCredentialsFromEnv() (Credentials, error) {
+	var c Credentials
+
+	params, err := auth.ParsePasswordParams(os.Getenv("PASSWORD_HASH_PARAMS"))
+	if err != nil {
+		return c, fmt.Errorf("PASSWORD_HASH_PARAMS: %w", err)
+	}
+
+	c.Hasher = auth.NewPasswordHasher(params)
+
+	c.Providers, c.StateKey, err = newOIDCProvidersFromEnv()
+	if err != nil {
+		return c, err
+	}
+
+	c.AccountLockout, c.IPLockout, err = newLockoutsFromEnv()
+	if err != nil {
+		return c, err
+	}
+
+	c.TrustedProxies, err = middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return c, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	if v := os.Getenv("REFRESH_TOKEN_TTL"); v != "" {
+		c.RefreshTTL, err = time.ParseDuration(v)
+		if err != nil {
+			return c, fmt.Errorf("parse REFRESH_TOKEN_TTL: %w", err)
+		}
+	}
+
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return c, nil
+	}
+
+	if len(secret) < jwtSecretMinBytes {
+		return c, fmt.Errorf("JWT_SECRET must be at least %d bytes", jwtSecretMinBytes)
+	}
+
+	var opts []auth.SignerOption
+
+	if v := os.Getenv("ACCESS_TOKEN_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return c, fmt.Errorf("parse ACCESS_TOKEN_TTL: %w", err)
+		}
+
+		opts = append(opts, auth.WithTokenTTL(ttl))
+	}
+
+	if v := os.Getenv("JWT_ISSUER"); v != "" {
+		opts = append(opts, auth.WithTokenIssuer(v))
+	}
+
+	if v := os.Getenv("JWT_AUDIENCE"); v != "" {
+		opts = append(opts, auth.WithTokenAudience(strings.Split(strings.ReplaceAll(v, " ", ""), ",")...))
+	}
+
+	c.Signer = auth.NewSigner([]byte(secret), opts...)
+
+	return c, nil
+}
+
+// newLockoutsFromEnv returns the policies of the failed logins per account
+// and per client address, LOGIN_LOCKOUT_ENABLED=false disables them
+// LOGIN_LOCKOUT_THRESHOLD and LOGIN_LOCKOUT_IP_THRESHOLD set the failures
+// locking an account and an address, 0 does not lock them, ex: 10 and 100
+// LOGIN_LOCKOUT_DURATION sets the time they are locked for, ex: 15m
+func newLockoutsFromEnv() (account, ip credential.LockoutPolicy, err error) {
+	if os.Getenv("LOGIN_LOCKOUT_ENABLED") == "false" {
+		return account, ip, nil
+	}
+
+	account, ip = credential.DefaultAccountLockout(), credential.DefaultIPLockout()
+
+	if v := os.Getenv("LOGIN_LOCKOUT_THRESHOLD"); v != "" {
+		account.Threshold, err = strconv.Atoi(v)
+		if err != nil || account.Threshold < 0 {
+			return account, ip, fmt.Errorf("invalid LOGIN_LOCKOUT_THRESHOLD: %s", v)
+		}
+	}
+
+	if v := os.Getenv("LOGIN_LOCKOUT_IP_THRESHOLD"); v != "" {
+		ip.Threshold, err = strconv.Atoi(v)
+		if err != nil || ip.Threshold < 0 {
+			return account, ip, fmt.Errorf("invalid LOGIN_LOCKOUT_IP_THRESHOLD: %s", v)
+		}
+	}
+
+	if v := os.Getenv("LOGIN_LOCKOUT_DURATION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return account, ip, fmt.Errorf("invalid LOGIN_LOCKOUT_DURATION: %s", v)
+		}
+
+		account.Duration, ip.Duration = d, d
+	}
+
+	return account, ip, nil
+}
+
+// oidcNamePattern matches the names of the providers, they are path segments
+var oidcNamePattern = regexp.MustCompile(`^[a-z0-9-]+$`)
+
+// newOIDCProvidersFromEnv initializes the OpenID Connect providers of
+// OIDC_PROVIDERS, a comma separated list of names, ex: google,company
+// each of them is configured with OIDC_<NAME>_ISSUER, _CLIENT_ID,
+// _CLIENT_SECRET, _REDIRECT_URL and the optional _SCOPES, space
+// separated, the name is upper case with "-" replaced by "_"
+// OIDC_STATE_SECRET seals the state cookies of the logins
+func newOIDCProvidersFromEnv() ([]*oidc.Provider, []byte, error) {
+	v := strings.ReplaceAll(os.Getenv("OIDC_PROVIDERS"), " ", "")
+	if v == "" {
+		return nil, nil, nil
+	}
+
+	key := os.Getenv("OIDC_STATE_SECRET")
+	if len(key) < jwtSecretMinBytes {
+		return nil, nil, fmt.Errorf("OIDC_STATE_SECRET must be at least %d bytes", jwtSecretMinBytes)
+	}
+
+	var providers []*oidc.Provider
+
+	for _, name := range strings.Split(v, ",") {
+		if !oidcNamePattern.MatchString(name) {
+			return nil, nil, fmt.Errorf("OIDC_PROVIDERS: invalid name %q", name)
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		c := oidc.Config{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+		}
+
+		if c.Issuer == "" || c.ClientID == "" || c.ClientSecret == "" || c.RedirectURL == "" {
+			return nil, nil, fmt.Errorf("the oidc provider %s needs %sISSUER, %sCLIENT_ID, %sCLIENT_SECRET and %sREDIRECT_URL", name, prefix, prefix, prefix, prefix)
+		}
+
+		if scopes := os.Getenv(prefix + "SCOPES"); scopes != "" {
+			c.Scopes = strings.Fields(scopes)
+		}
+
+		providers = append(providers, oidc.NewProvider(c))
+	}
+
+	return providers, []byte(key), nil
+}
+
+// Auth is the module of the signup, the login and the
+// sessions of the users, the users are created with
+// the repository of the user module and the access
+// tokens carry their roles of the role module
+type Auth struct {
+	handler *handler.Auth
+	oidc    *handler.OIDC
+	routes  route.Options
+	useCase credential.UseCase
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewAuth initializes the auth module
+func NewAuth(u *User, roles *Role, deps Deps) *Auth {
+	c := deps.Credentials
+	if c.Hasher == nil {
+		c.Hasher = auth.NewPasswordHasher(auth.DefaultPasswordParams())
+	}
+
+	opts := []credentialservice.Option{
+		credentialservice.WithRoles(roles.Provider().Repository),
+		credentialservice.WithLockout(c.AccountLockout, c.IPLockout),
+	}
+
+	if deps.Events != nil {
+		opts = append(opts, credentialservice.WithPublisher(deps.Events))
+		deps.Events.Subscribe(audit)
+	}
+
+	if c.RefreshTTL > 0 {
+		opts = append(opts, credentialservice.WithRefreshTTL(c.RefreshTTL))
+	}
+
+	p := credentialprovider.New(deps.DB, u.Provider().Repository, c.Hasher, c.Signer, opts...)
+
+	return &Auth{
+		handler: handler.NewAuth(p.UseCase, deps.Validater, c.TrustedProxies),
+		oidc:    handler.NewOIDC(p.UseCase, c.StateKey, c.Providers...),
+		routes:  deps.routeOptions(),
+		useCase: p.UseCase,
+	}
+}
+
+// audit logs the lockout events, the lockouts and the unlocks
+// of the accounts and the addresses are kept in the logs
+func audit(ctx context.Context, e event.Event) error {
+	l, ok := e.Data.(credential.LockoutEvent)
+	if !ok {
+		return nil
+	}
+
+	b, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("audit %s %s", e.Type, b)
+
+	return nil
+}
+
+func (m *Auth) Name() module.ID {
+	return NameAuth
+}
+
+func (m *Auth) Version() string {
+	return constant.V1
+}
+
+func (m *Auth) Dependencies() []module.ID {
+	return []module.ID{NameUser, NameRole}
+}
+
+func (m *Auth) Routes(r chi.Router) {
+	r.Mount(constant.AuthPattern, route.Auth(m.handler, m.oidc, m.routes))
+}
+
+// Start starts the cleanup of the lockouts not counted anymore
+func (m *Auth) Start(ctx context.Context) error {
+	ctx, m.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		t := time.NewTicker(constant.LockoutCleanupInterval * time.Minute)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := m.useCase.DeleteExpiredLockouts(ctx); err != nil {
+					log.Printf("delete expired lockouts returned error: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the cleanup
+func (m *Auth) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
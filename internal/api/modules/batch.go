@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/batch"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// Batch is the module which dispatches many requests
+// in one round trip, it's not versioned
+type Batch struct {
+	executor *batch.Executor
+}
+
+// NewBatch initializes the batch module
+// the sub requests are dispatched to deps.Mux
+func NewBatch(deps Deps) *Batch {
+	return &Batch{
+		executor: batch.New(deps.Mux, constant.ApiPattern+constant.BatchPattern, batch.WithDB(deps.DB)),
+	}
+}
+
+func (m *Batch) Name() module.ID {
+	return NameBatch
+}
+
+func (m *Batch) Version() string {
+	return ""
+}
+
+func (m *Batch) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Batch) Routes(r chi.Router) {
+	r.Post(constant.BatchPattern, m.executor.Handler)
+}
+
+func (m *Batch) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *Batch) Stop(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,49 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/apidoc"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// Docs is the module which serves the api docs
+// it's not versioned, the routes are mounted under the api pattern
+type Docs struct{}
+
+// NewDocs initializes the docs module
+func NewDocs() *Docs {
+	return &Docs{}
+}
+
+func (m *Docs) Name() module.ID {
+	return NameDocs
+}
+
+func (m *Docs) Version() string {
+	return ""
+}
+
+func (m *Docs) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Docs) Routes(r chi.Router) {
+	r.Get(constant.OpenAPIPattern, apidoc.SpecHandler)
+	// the page loads the swagger ui, its policy
+	// overrides the one of the json responses
+	r.With(middleware.Secure(middleware.SecurityHeaders{ContentSecurityPolicy: apidoc.DocsContentSecurityPolicy})).
+		Get(constant.DocsPattern, apidoc.DocsHandler)
+	r.Get(constant.DocsPattern+"/*", apidoc.AssetsHandler(constant.ApiPattern+constant.DocsPattern).ServeHTTP)
+}
+
+func (m *Docs) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *Docs) Stop(ctx context.Context) error {
+	return nil
+}
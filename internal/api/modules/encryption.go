@@ -0,0 +1,44 @@
+package modules
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+)
+
+// NewKeyRingFromEnv initializes the key ring encrypting the personal data
+// ENCRYPTION_KEYS contains the comma separated id:base64 keys of 32 bytes,
+// ENCRYPTION_KEYS_FILE is a file of them, one per line, ex: a mounted secret
+// ENCRYPTION_PRIMARY_KEY is the id of the key encrypting the new values,
+// the first key by default, the other keys decrypt the values until they
+// are encrypted again, see cmd/reencrypt
+func NewKeyRingFromEnv() (*envelope.KeyRing, error) {
+	v := os.Getenv("ENCRYPTION_KEYS")
+
+	if file := os.Getenv("ENCRYPTION_KEYS_FILE"); file != "" {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read ENCRYPTION_KEYS_FILE: %w", err)
+		}
+
+		v = string(b)
+	}
+
+	ids, keys, err := envelope.ParseKeys(v)
+	if err != nil {
+		return nil, fmt.Errorf("ENCRYPTION_KEYS: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return nil, errors.New("the personal data needs ENCRYPTION_KEYS or ENCRYPTION_KEYS_FILE")
+	}
+
+	primary := os.Getenv("ENCRYPTION_PRIMARY_KEY")
+	if primary == "" {
+		primary = ids[0]
+	}
+
+	return envelope.NewKeyRing(primary, keys)
+}
@@ -0,0 +1,117 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// Feed is the module of the change feeds of the resources
+// it pushes the published events to the connected clients
+type Feed struct {
+	broker   *feed.Broker
+	products *handler.Feed
+	routes   route.Options
+}
+
+// NewFeed initializes the feed module
+func NewFeed(deps Deps) *Feed {
+	m := &Feed{
+		broker:   deps.Feed,
+		products: handler.NewFeed(deps.Feed, product.EventTypes),
+		routes:   deps.routeOptions(),
+	}
+
+	if deps.Events != nil && deps.Feed != nil {
+		deps.Events.Subscribe(m.publish)
+	}
+
+	return m
+}
+
+// publish pushes the event with the response entity of its data
+func (m *Feed) publish(ctx context.Context, e event.Event) error {
+	p, ok := e.Data.(product.Product)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(dto.ToProductEntity(p))
+	if err != nil {
+		return err
+	}
+
+	_, err = m.broker.Publish(ctx, feed.Entry{Type: e.Type, Subject: p.ID, Data: data, OccurredAt: e.OccurredAt.Unix()})
+	if errors.Is(err, feed.ErrClosed) {
+		// shutting down
+		return nil
+	}
+
+	return err
+}
+
+func (m *Feed) Name() module.ID {
+	return NameFeed
+}
+
+func (m *Feed) Version() string {
+	return constant.V1
+}
+
+func (m *Feed) Dependencies() []module.ID {
+	return []module.ID{NameProduct}
+}
+
+func (m *Feed) Routes(r chi.Router) {
+	if m.broker == nil {
+		return
+	}
+
+	r.With(m.routes.Require(role.PermissionProductsRead, nil)).Get(constant.ProductsPattern+constant.EventsPattern, m.products.Stream)
+}
+
+func (m *Feed) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop ends the open streams, the server closes them
+// earlier when it shuts down, see server.WithOnShutdown
+func (m *Feed) Stop(ctx context.Context) error {
+	if m.broker != nil {
+		m.broker.Close()
+	}
+
+	return nil
+}
+
+// NewFeedFromEnv initializes the broker of the change feeds
+// FEED_BUFFER selects the buffer of the resume: memory (default)
+// or postgres which is shared by the instances, the WebSockets
+// are opened by the allowed origins of the cors policy only
+func NewFeedFromEnv(db *sql.DB, cors middleware.CORSPolicy) (*feed.Broker, error) {
+	origins := feed.WithOrigins(cors.AllowedOrigins...)
+
+	switch v := os.Getenv("FEED_BUFFER"); v {
+	case "", "memory":
+		return feed.NewBroker(feed.NewMemoryBuffer(constant.FeedBufferSize), origins), nil
+	case "postgres":
+		return feed.NewBroker(feed.NewPostgresBuffer(db, constant.FeedBufferSize), origins), nil
+	default:
+		return nil, fmt.Errorf("unknown FEED_BUFFER: %s", v)
+	}
+}
@@ -0,0 +1,56 @@
+package modules_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/modules"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+)
+
+// TestFeedOrigins checks the websockets of the feeds are
+// only opened by the allowed origins of the cors policy
+func TestFeedOrigins(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGIN", "https://app.example.com")
+	t.Setenv("FEED_BUFFER", "memory")
+
+	cors, err := modules.NewCORSFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := modules.NewFeedFromEnv(nil, cors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(handler.NewFeed(b, product.EventTypes).Stream))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{name: "allowed", origin: "https://app.example.com", status: http.StatusSwitchingProtocols},
+		{name: "disallowed", origin: "https://evil.example.net", status: http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, res, _ := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), http.Header{"Origin": {tc.origin}})
+			if conn != nil {
+				conn.Close()
+			}
+
+			if res == nil || res.StatusCode != tc.status {
+				t.Errorf("expected %d, got %+v", tc.status, res)
+			}
+		})
+	}
+}
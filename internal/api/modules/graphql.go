@@ -0,0 +1,53 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/graphql/resolver"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// GraphQL is the module of the graphql endpoint, it's not
+// versioned and uses the use cases of the resource modules
+type GraphQL struct {
+	handler *handler.GraphQL
+}
+
+// NewGraphQL initializes the graphql module
+func NewGraphQL(p *Product, u *User, deps Deps) *GraphQL {
+	r := resolver.New(
+		p.Provider().UseCase,
+		u.Provider().UseCase,
+		resolver.WithProductPagination(deps.paginationPolicy(NameProduct)),
+		resolver.WithUserPagination(deps.paginationPolicy(NameUser)),
+	)
+
+	return &GraphQL{handler: handler.NewGraphQL(r)}
+}
+
+func (m *GraphQL) Name() module.ID {
+	return NameGraphQL
+}
+
+func (m *GraphQL) Version() string {
+	return ""
+}
+
+func (m *GraphQL) Dependencies() []module.ID {
+	return []module.ID{NameProduct, NameUser}
+}
+
+func (m *GraphQL) Routes(r chi.Router) {
+	r.Post(constant.GraphQLPattern, m.handler.Serve)
+}
+
+func (m *GraphQL) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *GraphQL) Stop(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,127 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// Idempotency is the module which removes the expired
+// idempotency records, it has no routes
+type Idempotency struct {
+	idempotency *idempotency.Idempotency
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewIdempotency initializes the idempotency module
+func NewIdempotency(deps Deps) *Idempotency {
+	return &Idempotency{idempotency: deps.Idempotency}
+}
+
+func (m *Idempotency) Name() module.ID {
+	return NameIdempotency
+}
+
+func (m *Idempotency) Version() string {
+	return ""
+}
+
+func (m *Idempotency) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Idempotency) Routes(r chi.Router) {}
+
+// Start starts the cleanup of the expired records
+func (m *Idempotency) Start(ctx context.Context) error {
+	if m.idempotency == nil {
+		return nil
+	}
+
+	ctx, m.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		t := time.NewTicker(constant.IdempotencyCleanupInterval * time.Minute)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := m.idempotency.Store().DeleteExpired(ctx); err != nil {
+					log.Printf("idempotency delete expired returned error: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the cleanup
+func (m *Idempotency) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// NewIdempotencyFromEnv initializes the idempotency of the create routes
+// IDEMPOTENCY_STORE selects the store: postgres (default) or memory
+// IDEMPOTENCY_TTL sets the time the records are kept for, ex: 24h
+// the keys of the anonymous callers are scoped by the client address
+// resolved with TRUSTED_PROXIES
+func NewIdempotencyFromEnv(db *sql.DB) (*idempotency.Idempotency, error) {
+	proxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	opts := []idempotency.Option{
+		idempotency.WithScope(idempotency.ScopeByPrincipal(idempotency.ScopeByClientIP(proxies))),
+	}
+
+	if v := os.Getenv("IDEMPOTENCY_TTL"); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("parse IDEMPOTENCY_TTL: %w", err)
+		}
+
+		opts = append(opts, idempotency.WithTTL(ttl))
+	}
+
+	var store idempotency.Store
+
+	switch v := os.Getenv("IDEMPOTENCY_STORE"); v {
+	case "", "postgres":
+		store = idempotency.NewPostgresStore(db)
+	case "memory":
+		store = idempotency.NewMemoryStore()
+	default:
+		return nil, fmt.Errorf("unknown IDEMPOTENCY_STORE: %s", v)
+	}
+
+	return idempotency.New(store, opts...), nil
+}
@@ -0,0 +1,223 @@
+// package modules contains the modules of the application
+// the app and the e2e config build from the same registration
+package modules
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
+)
+
+// module ids
+const (
+	NameDocs        module.ID = "docs"
+	NameVersions              = "versions"
+	NameBatch                 = "batch"
+	NameIdempotency           = "idempotency"
+	NameProduct               = "product"
+	NameProductV2             = "product-v2"
+	NameUser                  = "user"
+	NameWebhook               = "webhook"
+	NameFeed                  = "feed"
+	NameGraphQL               = "graphql"
+	NameAuth                  = "auth"
+	NameRole                  = "role"
+	NameAPIKey                = "api-key"
+	NameRateLimit             = "rate-limit"
+)
+
+// compile time checks of the module implementations
+var (
+	_ module.Module = (*Docs)(nil)
+	_ module.Module = (*Versions)(nil)
+	_ module.Module = (*Batch)(nil)
+	_ module.Module = (*Idempotency)(nil)
+	_ module.Module = (*Product)(nil)
+	_ module.Module = (*ProductV2)(nil)
+	_ module.Module = (*User)(nil)
+	_ module.Module = (*Webhook)(nil)
+	_ module.Module = (*Feed)(nil)
+	_ module.Module = (*GraphQL)(nil)
+	_ module.Module = (*Auth)(nil)
+	_ module.Module = (*Role)(nil)
+	_ module.Module = (*APIKey)(nil)
+	_ module.Module = (*RateLimit)(nil)
+
+	_ module.GRPCModule = (*Product)(nil)
+	_ module.GRPCModule = (*User)(nil)
+)
+
+// Deps contains the shared dependencies of the modules
+type Deps struct {
+	DB         *sql.DB
+	Validater  validatorext.Validater
+	Versioning *router.Versioning
+	// Idempotency applies to the create routes, nil disables it
+	Idempotency *idempotency.Idempotency
+	// Cache contains the Cache-Control policies of the read routes
+	Cache route.CachePolicies
+	// Mux is the router the batch requests are dispatched to
+	Mux http.Handler
+	// Pagination contains the pagination policies of the list
+	// routes keyed by the module name, the versions of a resource
+	// share its policy, a missing policy is the default
+	Pagination map[string]pagination.Policy
+	// Events carries the changes of the resources to the
+	// modules reacting to them, nil disables the events
+	Events *event.Bus
+	// Feed pushes the events to the change feeds, nil disables them
+	Feed *feed.Broker
+	// Credentials issue the sessions of the auth routes
+	Credentials Credentials
+	// Policy authorizes the callers of the routes and the
+	// use cases with their roles, nil disables the checks
+	Policy *rbac.Policy
+	// RateLimits limit the routes on top of the global
+	// limit, nil disables them
+	RateLimits *RateLimits
+	// Encryptor encrypts the personal data at rest
+	Encryptor sqlext.Encryptor
+}
+
+// paginationPolicy returns the pagination policy of the module
+func (d Deps) paginationPolicy(name string) pagination.Policy {
+	p, ok := d.Pagination[name]
+	if !ok {
+		p = pagination.DefaultPolicy()
+	}
+
+	return p
+}
+
+// paginationOption returns the handler option of the policy of the module
+func (d Deps) paginationOption(name string) handler.Option {
+	return handler.WithPagination(d.paginationPolicy(name))
+}
+
+// authorizer returns the policy as an rbac.Authorizer, a nil
+// policy is a nil interface so that the checks are disabled
+func (d Deps) authorizer() rbac.Authorizer {
+	if d.Policy == nil {
+		return nil
+	}
+
+	return d.Policy
+}
+
+// routeOptions returns the options of the resource routes
+func (d Deps) routeOptions() route.Options {
+	opts := route.Options{Cache: d.Cache, Authorizer: d.authorizer()}
+
+	if d.Idempotency != nil {
+		opts.Create = append(opts.Create, d.Idempotency.Middleware)
+	}
+
+	if d.RateLimits != nil {
+		opts.RateLimits = d.RateLimits.routeLimits()
+	}
+
+	return opts
+}
+
+// IsLongRunning reports if the request is served by a streaming
+// route, a change feed or uploads an import, they are exempt
+// from the request timeouts
+func IsLongRunning(r *http.Request) bool {
+	if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, constant.ImportSuffix) {
+		return true
+	}
+
+	if IsStream(r) {
+		return true
+	}
+
+	return strings.HasSuffix(r.URL.Path, constant.ExportSuffix)
+}
+
+// IsStream reports if the request opens a change feed stream
+func IsStream(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, constant.EventsPattern)
+}
+
+// CachePoliciesFromEnv returns the cache policies of the read routes
+// CACHE_CONTROL_READ_ONE and CACHE_CONTROL_READ_MANY override the defaults
+// "none" leaves the header unset
+func CachePoliciesFromEnv() route.CachePolicies {
+	return route.CachePolicies{
+		ReadOne:  cachePolicyFromEnv("CACHE_CONTROL_READ_ONE", constant.CacheControlReadOne),
+		ReadMany: cachePolicyFromEnv("CACHE_CONTROL_READ_MANY", constant.CacheControlReadMany),
+	}
+}
+
+// PaginationPoliciesFromEnv returns the pagination policies of the list routes
+// PAGINATION_PRODUCTS and PAGINATION_USERS override the defaults,
+// ex: "limit=20,maxLimit=200,count=estimated,threshold=100000"
+func PaginationPoliciesFromEnv() (map[string]pagination.Policy, error) {
+	keys := map[string]string{
+		NameProduct: "PAGINATION_PRODUCTS",
+		NameUser:    "PAGINATION_USERS",
+	}
+
+	policies := make(map[string]pagination.Policy, len(keys))
+	for name, key := range keys {
+		p, err := pagination.ParsePolicy(os.Getenv(key))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		policies[name] = p
+	}
+
+	return policies, nil
+}
+
+func cachePolicyFromEnv(key, fallback string) string {
+	switch v := os.Getenv(key); v {
+	case "":
+		return fallback
+	case "none":
+		return ""
+	default:
+		return v
+	}
+}
+
+// Register registers the modules of the application
+// a new resource needs one entry here
+func Register(reg *module.Registry, deps Deps) error {
+	product := NewProduct(deps)
+	user := NewUser(deps)
+	roles := NewRole(deps)
+
+	return reg.Register(
+		NewDocs(),
+		NewVersions(deps),
+		NewBatch(deps),
+		NewIdempotency(deps),
+		product,
+		NewProductV2(product, deps),
+		user,
+		NewWebhook(deps),
+		NewFeed(deps),
+		NewGraphQL(product, user, deps),
+		NewAuth(user, roles, deps),
+		roles,
+		NewAPIKey(deps),
+		NewRateLimit(deps),
+	)
+}
@@ -0,0 +1,91 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	grpchandler "github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	productprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/product/provider"
+	productservice "github.com/tanveerprottoy/backend-structure-go/internal/api/product/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/bulk"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"google.golang.org/grpc"
+)
+
+// Product is the module of the products resource
+type Product struct {
+	provider productprovider.Provider
+	handler  *handler.Product
+	imports  *handler.ProductImport
+	service  *grpchandler.Product
+	jobs     *bulk.Jobs
+	routes   route.Options
+}
+
+// NewProduct initializes the product module
+func NewProduct(deps Deps) *Product {
+	var opts []productservice.Option
+	if deps.Events != nil {
+		opts = append(opts, productservice.WithPublisher(deps.Events))
+	}
+
+	if a := deps.authorizer(); a != nil {
+		opts = append(opts, productservice.WithAuthorizer(a))
+	}
+
+	p := productprovider.New(deps.DB, opts...)
+	jobs := bulk.NewJobs(constant.ImportConcurrency, constant.ImportJobTTL*time.Minute)
+
+	return &Product{
+		provider: p,
+		handler:  handler.NewProduct(p.UseCase, deps.Validater, deps.paginationOption(NameProduct)),
+		imports:  handler.NewProductImport(p.UseCase, deps.Validater, jobs),
+		service:  grpchandler.NewProduct(p.UseCase, grpchandler.WithPagination(deps.paginationPolicy(NameProduct))),
+		jobs:     jobs,
+		routes:   deps.routeOptions(),
+	}
+}
+
+func (m *Product) Name() module.ID {
+	return NameProduct
+}
+
+func (m *Product) Version() string {
+	return constant.V1
+}
+
+func (m *Product) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Product) Routes(r chi.Router) {
+	r.Mount(constant.ProductsPattern, route.Product(m.handler, m.routes))
+	r.With(m.routes.Require(role.PermissionProductsRead, nil)).Get(constant.ProductsPattern+constant.ExportSuffix, m.handler.Export)
+	r.Mount(constant.ProductsPattern+constant.ImportSuffix, route.ProductImport(m.imports, m.routes))
+}
+
+// RegisterGRPC registers the grpc service of the products
+func (m *Product) RegisterGRPC(s grpc.ServiceRegistrar) {
+	pb.RegisterProductServiceServer(s, m.service)
+}
+
+func (m *Product) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop cancels the running import jobs
+func (m *Product) Stop(ctx context.Context) error {
+	return m.jobs.Stop(ctx)
+}
+
+// Provider returns the components of the module
+// so that dependent modules can use them
+func (m *Product) Provider() productprovider.Provider {
+	return m.provider
+}
@@ -0,0 +1,52 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// ProductV2 is the v2 module of the products resource
+// it shares the components of the v1 module
+type ProductV2 struct {
+	handler *handler.Product
+	routes  route.Options
+}
+
+// NewProductV2 initializes the v2 product module
+func NewProductV2(p *Product, deps Deps) *ProductV2 {
+	return &ProductV2{
+		handler: handler.NewProductV2(p.Provider().UseCase, deps.Validater, deps.paginationOption(NameProduct)),
+		routes:  deps.routeOptions(),
+	}
+}
+
+func (m *ProductV2) Name() module.ID {
+	return NameProductV2
+}
+
+func (m *ProductV2) Version() string {
+	return constant.V2
+}
+
+func (m *ProductV2) Dependencies() []module.ID {
+	return []module.ID{NameProduct}
+}
+
+func (m *ProductV2) Routes(r chi.Router) {
+	r.Mount(constant.ProductsPattern, route.Product(m.handler, m.routes))
+	r.With(m.routes.Require(role.PermissionProductsRead, nil)).Get(constant.ProductsPattern+constant.ExportSuffix, m.handler.Export)
+}
+
+func (m *ProductV2) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *ProductV2) Stop(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,261 @@
+package modules
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+)
+
+// RateLimits contains the limiters of the requests, the requests
+// are keyed by the api key, the principal or the client address
+type RateLimits struct {
+	Store ratelimit.Store
+	// IP limits all the requests by the client address before
+	// the authentication, nil disables it
+	IP *ratelimit.Limiter
+	// Global limits all the requests, nil disables it
+	Global *ratelimit.Limiter
+	// Routes contains the limiters of the routes keyed
+	// by the limit name, ex: route.LimitAuth
+	Routes map[string]*ratelimit.Limiter
+	Key    middleware.RateLimitKey
+	// IPKey keys the requests by the client address
+	IPKey middleware.RateLimitKey
+}
+
+// IPMiddleware returns the middleware of the limit by
+// the client address, nil if disabled
+func (l *RateLimits) IPMiddleware() func(http.Handler) http.Handler {
+	if l.IP == nil {
+		return nil
+	}
+
+	return middleware.RateLimit(l.IP, l.IPKey)
+}
+
+// Middleware returns the middleware of the global limit, nil if disabled
+func (l *RateLimits) Middleware() func(http.Handler) http.Handler {
+	if l.Global == nil {
+		return nil
+	}
+
+	return middleware.RateLimit(l.Global, l.Key)
+}
+
+// routeLimits returns the middlewares of the route limits
+func (l *RateLimits) routeLimits() map[string]func(http.Handler) http.Handler {
+	m := make(map[string]func(http.Handler) http.Handler, len(l.Routes))
+	for name, lim := range l.Routes {
+		m[name] = middleware.RateLimit(lim, l.Key)
+	}
+
+	return m
+}
+
+// routeLimitNames contains the names of the route limits
+var routeLimitNames = []string{route.LimitAuth, route.LimitImport}
+
+// NewRateLimitsFromEnv initializes the rate limits of the requests
+// RATE_LIMIT_ENABLED=false disables them, the limiters are nil
+// RATE_LIMIT_IP limits the requests by the client address before the
+// authentication, ex: 600/1m, "none" disables it
+// RATE_LIMIT is the global limit, ex: 300/1m, "none" disables it
+// RATE_LIMIT_ROUTES contains the limits of the routes, ex:
+// auth=10/1m,import=5/1h, the auth limit defaults to 10/1m
+// RATE_LIMIT_ALGORITHM selects token-bucket (default) or sliding-window
+// RATE_LIMIT_BURST sets the capacity of the token buckets of RATE_LIMIT
+// RATE_LIMIT_STORE selects the store: memory (default) or postgres
+// TRUSTED_PROXIES contains the networks of the proxies whose
+// X-Forwarded-For is trusted, ex: 10.0.0.0/8
+func NewRateLimitsFromEnv(db *sql.DB) (*RateLimits, error) {
+	if os.Getenv("RATE_LIMIT_ENABLED") == "false" {
+		return nil, nil
+	}
+
+	proxies, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	l := &RateLimits{
+		Routes: make(map[string]*ratelimit.Limiter),
+		Key:    middleware.FirstKey(middleware.KeyByAPIKey(), middleware.KeyByPrincipal(), middleware.KeyByIP(proxies)),
+		IPKey:  middleware.KeyByIP(proxies),
+	}
+
+	switch v := os.Getenv("RATE_LIMIT_STORE"); v {
+	case "", "memory":
+		l.Store = ratelimit.NewMemoryStore()
+	case "postgres":
+		l.Store = ratelimit.NewPostgresStore(db)
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_STORE: %s", v)
+	}
+
+	var a ratelimit.Algorithm
+
+	switch v := os.Getenv("RATE_LIMIT_ALGORITHM"); v {
+	case "", "token-bucket":
+		a = ratelimit.TokenBucket{}
+	case "sliding-window":
+		a = ratelimit.SlidingWindow{}
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_ALGORITHM: %s", v)
+	}
+
+	global := ratelimit.Limit{Requests: constant.RateLimitRequests, Period: constant.RateLimitPeriod * time.Second}
+
+	switch v := os.Getenv("RATE_LIMIT"); v {
+	case "":
+	case "none":
+		global = ratelimit.Limit{}
+	default:
+		global, err = ratelimit.ParseLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT: %w", err)
+		}
+	}
+
+	if v := os.Getenv("RATE_LIMIT_BURST"); v != "" {
+		global.Burst, err = strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("parse RATE_LIMIT_BURST: %w", err)
+		}
+	}
+
+	if global.Requests > 0 {
+		l.Global = ratelimit.New("global", l.Store, a, global)
+	}
+
+	ip := ratelimit.Limit{Requests: constant.RateLimitIPRequests, Period: constant.RateLimitPeriod * time.Second}
+
+	switch v := os.Getenv("RATE_LIMIT_IP"); v {
+	case "":
+	case "none":
+		ip = ratelimit.Limit{}
+	default:
+		ip, err = ratelimit.ParseLimit(v)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_IP: %w", err)
+		}
+	}
+
+	if ip.Requests > 0 {
+		l.IP = ratelimit.New("ip", l.Store, a, ip)
+	}
+
+	limits := map[string]ratelimit.Limit{
+		route.LimitAuth: {Requests: constant.RateLimitAuthRequests, Period: constant.RateLimitPeriod * time.Second},
+	}
+
+	for _, v := range strings.Split(os.Getenv("RATE_LIMIT_ROUTES"), ",") {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(v, "=")
+		name = strings.TrimSpace(name)
+
+		if !ok || !slices.Contains(routeLimitNames, name) {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES: invalid route limit %q, the names are %v", v, routeLimitNames)
+		}
+
+		limits[name], err = ratelimit.ParseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("RATE_LIMIT_ROUTES: %w", err)
+		}
+	}
+
+	for name, limit := range limits {
+		l.Routes[name] = ratelimit.New(name, l.Store, a, limit)
+	}
+
+	return l, nil
+}
+
+// RateLimit is the module which removes the expired
+// states of the rate limits, it has no routes
+type RateLimit struct {
+	limits *RateLimits
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRateLimit initializes the rate limit module
+func NewRateLimit(deps Deps) *RateLimit {
+	return &RateLimit{limits: deps.RateLimits}
+}
+
+func (m *RateLimit) Name() module.ID {
+	return NameRateLimit
+}
+
+func (m *RateLimit) Version() string {
+	return ""
+}
+
+func (m *RateLimit) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *RateLimit) Routes(r chi.Router) {}
+
+// Start starts the cleanup of the expired states
+func (m *RateLimit) Start(ctx context.Context) error {
+	if m.limits == nil {
+		return nil
+	}
+
+	ctx, m.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		t := time.NewTicker(constant.RateLimitCleanupInterval * time.Minute)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				if _, err := m.limits.Store.DeleteExpired(ctx); err != nil {
+					log.Printf("rate limit delete expired returned error: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops the cleanup
+func (m *RateLimit) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,89 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	roleprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/role/provider"
+	roleservice "github.com/tanveerprottoy/backend-structure-go/internal/api/role/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+// Role is the module of the roles and the role assignments
+// of the users, it loads the grants of the stored roles
+// into the policy on start
+type Role struct {
+	provider roleprovider.Provider
+	handler  *handler.Role
+	policy   *rbac.Policy
+	// load is false without a db, the policy keeps role.Defaults
+	load   bool
+	routes route.Options
+}
+
+// NewRole initializes the role module
+func NewRole(deps Deps) *Role {
+	var opts []roleservice.Option
+	if a := deps.authorizer(); a != nil {
+		opts = append(opts, roleservice.WithAuthorizer(a))
+	}
+
+	p := roleprovider.New(deps.DB, opts...)
+
+	return &Role{
+		provider: p,
+		handler:  handler.NewRole(p.UseCase),
+		policy:   deps.Policy,
+		load:     deps.DB != nil,
+		routes:   deps.routeOptions(),
+	}
+}
+
+func (m *Role) Name() module.ID {
+	return NameRole
+}
+
+func (m *Role) Version() string {
+	return constant.V1
+}
+
+func (m *Role) Dependencies() []module.ID {
+	return []module.ID{NameUser}
+}
+
+func (m *Role) Routes(r chi.Router) {
+	r.Mount(constant.RolesPattern, route.Role(m.handler, m.routes))
+	r.Mount(constant.UsersPattern+"/{"+constant.ParamId+"}"+constant.RolesPattern, route.UserRoles(m.handler, m.routes))
+}
+
+// Start loads the grants of the stored roles into the policy
+func (m *Role) Start(ctx context.Context) error {
+	if m.policy == nil || !m.load {
+		return nil
+	}
+
+	roles, err := m.provider.Repository.ReadMany(ctx)
+	if err != nil {
+		return fmt.Errorf("load roles: %w", err)
+	}
+
+	m.policy.Load(role.Grants(roles))
+
+	return nil
+}
+
+func (m *Role) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Provider returns the components of the module
+// so that dependent modules can use them
+func (m *Role) Provider() roleprovider.Provider {
+	return m.provider
+}
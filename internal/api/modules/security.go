@@ -0,0 +1,89 @@
+package modules
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+)
+
+// NewCORSFromEnv initializes the cross origin policy
+// ALLOWED_ORIGIN contains the comma separated origins, ex:
+// https://app.example.com,https://*.example.com, empty or "*"
+// allows all the origins
+// CORS_ALLOW_CREDENTIALS=true lets the clients send the cookies
+// CORS_EXPOSED_HEADERS adds the comma separated headers to the exposed ones
+// CORS_MAX_AGE is the seconds the preflight results are cached for
+func NewCORSFromEnv() (middleware.CORSPolicy, error) {
+	p := middleware.DefaultCORSPolicy()
+
+	if origins := splitList(os.Getenv("ALLOWED_ORIGIN")); len(origins) > 0 {
+		p.AllowedOrigins = origins
+	}
+
+	p.AllowCredentials = os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
+
+	if headers := splitList(os.Getenv("CORS_EXPOSED_HEADERS")); len(headers) > 0 {
+		p.ExposedHeaders = slices.Concat(p.ExposedHeaders, headers)
+	}
+
+	if v := os.Getenv("CORS_MAX_AGE"); v != "" {
+		age, err := strconv.Atoi(v)
+		if err != nil || age < 0 {
+			return p, fmt.Errorf("invalid CORS_MAX_AGE: %s", v)
+		}
+
+		p.MaxAge = age
+	}
+
+	if err := p.Validate(); err != nil {
+		return p, fmt.Errorf("ALLOWED_ORIGIN: %w", err)
+	}
+
+	return p, nil
+}
+
+// NewSecurityHeadersFromEnv initializes the security headers of the responses
+// SECURITY_HEADERS_ENABLED=false disables them, the headers are nil
+// HSTS, CONTENT_SECURITY_POLICY, REFERRER_POLICY and FRAME_OPTIONS
+// replace the default values, "none" leaves the header unset
+func NewSecurityHeadersFromEnv() *middleware.SecurityHeaders {
+	if os.Getenv("SECURITY_HEADERS_ENABLED") == "false" {
+		return nil
+	}
+
+	s := middleware.DefaultSecurityHeaders()
+
+	for key, v := range map[string]*string{
+		"HSTS":                    &s.StrictTransportSecurity,
+		"CONTENT_SECURITY_POLICY": &s.ContentSecurityPolicy,
+		"REFERRER_POLICY":         &s.ReferrerPolicy,
+		"FRAME_OPTIONS":           &s.FrameOptions,
+	} {
+		switch e := os.Getenv(key); e {
+		case "":
+		case "none":
+			*v = ""
+		default:
+			*v = e
+		}
+	}
+
+	return &s
+}
+
+// splitList splits the comma separated values, the empty ones are skipped
+func splitList(s string) []string {
+	var l []string
+
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			l = append(l, v)
+		}
+	}
+
+	return l
+}
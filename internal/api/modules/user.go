@@ -0,0 +1,78 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	grpchandler "github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/grpc/pb"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	userprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/user/provider"
+	userservice "github.com/tanveerprottoy/backend-structure-go/internal/api/user/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"google.golang.org/grpc"
+)
+
+// User is the module of the users resource
+type User struct {
+	provider userprovider.Provider
+	handler  *handler.User
+	service  *grpchandler.User
+	routes   route.Options
+}
+
+// NewUser initializes the user module
+func NewUser(deps Deps) *User {
+	var opts []userservice.Option
+	if a := deps.authorizer(); a != nil {
+		opts = append(opts, userservice.WithAuthorizer(a))
+	}
+
+	p := userprovider.New(deps.DB, deps.Encryptor, opts...)
+
+	return &User{
+		provider: p,
+		handler:  handler.NewUser(p.UseCase, deps.Validater, deps.paginationOption(NameUser)),
+		service:  grpchandler.NewUser(p.UseCase, grpchandler.WithPagination(deps.paginationPolicy(NameUser))),
+		routes:   deps.routeOptions(),
+	}
+}
+
+func (m *User) Name() module.ID {
+	return NameUser
+}
+
+func (m *User) Version() string {
+	return constant.V1
+}
+
+func (m *User) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *User) Routes(r chi.Router) {
+	r.Mount(constant.UsersPattern, route.User(m.handler, m.routes))
+	r.With(m.routes.Require(role.PermissionUsersRead, nil)).Get(constant.UsersPattern+constant.ExportSuffix, m.handler.Export)
+}
+
+// RegisterGRPC registers the grpc service of the users
+func (m *User) RegisterGRPC(s grpc.ServiceRegistrar) {
+	pb.RegisterUserServiceServer(s, m.service)
+}
+
+func (m *User) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *User) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Provider returns the components of the module
+// so that dependent modules can use them
+func (m *User) Provider() userprovider.Provider {
+	return m.provider
+}
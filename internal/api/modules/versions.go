@@ -0,0 +1,109 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+)
+
+// Versions is the module which reports the api versions
+// and their usage, it's not versioned
+type Versions struct {
+	versioning *router.Versioning
+}
+
+// NewVersions initializes the versions module
+func NewVersions(deps Deps) *Versions {
+	return &Versions{versioning: deps.Versioning}
+}
+
+func (m *Versions) Name() module.ID {
+	return NameVersions
+}
+
+func (m *Versions) Version() string {
+	return ""
+}
+
+func (m *Versions) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Versions) Routes(r chi.Router) {
+	r.Get(constant.VersionsPattern, m.versioning.UsageHandler)
+}
+
+func (m *Versions) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *Versions) Stop(ctx context.Context) error {
+	return nil
+}
+
+// NewVersioning initializes the versioning of the api
+// the deprecation of a version is read from the env
+// ex: API_V1_DEPRECATION, API_V1_SUNSET in RFC 3339
+// unversioned requests are served by the latest version of
+// the resource, ex: /api/products by v2 and /api/users by v1
+func NewVersioning() (*router.Versioning, error) {
+	var versions []router.Version
+
+	for _, name := range []string{constant.V1, constant.V2} {
+		v, err := versionFromEnv(name)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return router.NewVersioning(constant.ApiPattern, constant.V2, versions...)
+}
+
+func versionFromEnv(name string) (router.Version, error) {
+	v := router.Version{Name: name}
+
+	prefix := "API_" + strings.ToUpper(strings.TrimPrefix(name, "/")) + "_"
+
+	var err error
+
+	v.Deprecation, err = parseEnvTime(prefix + "DEPRECATION")
+	if err != nil {
+		return v, err
+	}
+
+	v.Sunset, err = parseEnvTime(prefix + "SUNSET")
+	if err != nil {
+		return v, err
+	}
+
+	if v.Deprecated() {
+		v.Link = constant.ApiPattern + constant.DocsPattern
+	}
+
+	return v, nil
+}
+
+// parseEnvTime parses the RFC 3339 time of the env key
+// the zero time is returned if the key is not set
+func parseEnvTime(key string) (time.Time, error) {
+	s := os.Getenv(key)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse %s: %w", key, err)
+	}
+
+	return t, nil
+}
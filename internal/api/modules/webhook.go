@@ -0,0 +1,113 @@
+package modules
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/dto"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/handler"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/delivery/http/route"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	webhookprovider "github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/provider"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+)
+
+// Webhook is the module of the webhook subscriptions
+// it enqueues the deliveries of the published events
+// and dispatches them in the background
+type Webhook struct {
+	provider webhookprovider.Provider
+	handler  *handler.Webhook
+	routes   route.Options
+	// dispatch is false if WEBHOOK_DISPATCH is false, the
+	// deliveries are then sent by the other instances
+	dispatch bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewWebhook initializes the webhook module
+func NewWebhook(deps Deps) *Webhook {
+	p := webhookprovider.New(deps.DB)
+
+	m := &Webhook{
+		provider: p,
+		handler:  handler.NewWebhook(p.UseCase, deps.Validater, deps.paginationOption(NameWebhook)),
+		routes:   deps.routeOptions(),
+		dispatch: os.Getenv("WEBHOOK_DISPATCH") != "false",
+	}
+
+	if deps.Events != nil {
+		deps.Events.Subscribe(m.enqueue)
+	}
+
+	return m
+}
+
+// enqueue creates the deliveries of the event with the
+// response entity of its data
+func (m *Webhook) enqueue(ctx context.Context, e event.Event) error {
+	data := e.Data
+	if p, ok := data.(product.Product); ok {
+		data = dto.ToProductEntity(p)
+	}
+
+	return m.provider.UseCase.Enqueue(ctx, e.Type, data)
+}
+
+func (m *Webhook) Name() module.ID {
+	return NameWebhook
+}
+
+func (m *Webhook) Version() string {
+	return constant.V1
+}
+
+func (m *Webhook) Dependencies() []module.ID {
+	return nil
+}
+
+func (m *Webhook) Routes(r chi.Router) {
+	r.Mount(constant.WebhooksPattern, route.Webhook(m.handler, m.routes))
+}
+
+// Start starts the dispatcher of the deliveries
+func (m *Webhook) Start(ctx context.Context) error {
+	if !m.dispatch {
+		log.Println("webhook dispatch is disabled")
+		return nil
+	}
+
+	ctx, m.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		m.provider.Dispatcher.Run(ctx, constant.WebhookPollInterval*time.Second)
+	}()
+
+	return nil
+}
+
+// Stop stops the dispatcher, the deliveries being sent are
+// cancelled and retried once their lease expires
+func (m *Webhook) Stop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+
+	m.cancel()
+
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,14 @@
+package product
+
+// the types of the events published on the changes
+// of the entities, the data is the changed Product
+const (
+	EventCreated = "product.created"
+	EventUpdated = "product.updated"
+	EventDeleted = "product.deleted"
+	// EventPurged is published when the entity is removed for good
+	EventPurged = "product.purged"
+)
+
+// EventTypes contains the types of the events of the entities
+var EventTypes = []string{EventCreated, EventUpdated, EventDeleted, EventPurged}
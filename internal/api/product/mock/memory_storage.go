@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"iter"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 )
@@ -24,6 +25,18 @@ func (s *MemoryStorage) Create(ctx context.Context, payload product.CreateDTO, a
 	return payload.Name, nil
 }
 
+func (s *MemoryStorage) Upsert(ctx context.Context, payload product.CreateDTO, args ...any) (product.Product, bool, error) {
+	if e, ok := s.m[payload.Name]; ok {
+		e.Description = payload.Description
+		e.UpdatedAt = payload.UpdatedAt
+		return *e, false, nil
+	}
+
+	s.m[payload.Name] = product.NewProduct(payload.Name, payload.Name, payload.Description, payload.CreatedAt, payload.UpdatedAt)
+
+	return *s.m[payload.Name], true, nil
+}
+
 func (s MemoryStorage) ReadMany(ctx context.Context, limit int, offset int, args ...any) ([]product.Product, error) {
 	entities := make([]product.Product, len(s.m))
 
@@ -34,6 +47,47 @@ func (s MemoryStorage) ReadMany(ctx context.Context, limit int, offset int, args
 	return entities, nil
 }
 
+func (s MemoryStorage) ReadByIDs(ctx context.Context, ids []string, args ...any) ([]product.Product, error) {
+	entities := make([]product.Product, 0, len(ids))
+
+	for _, id := range ids {
+		if v, ok := s.m[id]; ok {
+			entities = append(entities, *v)
+		}
+	}
+
+	return entities, nil
+}
+
+func (s MemoryStorage) Count(ctx context.Context, args ...any) (int64, error) {
+	var n int64
+
+	for _, v := range s.m {
+		if len(args) > 0 && args[0] != nil && v.IsArchived != args[0].(bool) {
+			continue
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// Estimate is not known for the memory storage
+func (s MemoryStorage) Estimate(ctx context.Context) (int64, error) {
+	return -1, nil
+}
+
+func (s MemoryStorage) Stream(ctx context.Context, args ...any) iter.Seq2[product.Product, error] {
+	return func(yield func(product.Product, error) bool) {
+		for _, v := range s.m {
+			if !yield(*v, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (s MemoryStorage) ReadOne(ctx context.Context, id string, args ...any) (product.Product, error) {
 	if e, ok := s.m[id]; ok {
 		return *e, nil
@@ -44,7 +98,10 @@ func (s MemoryStorage) ReadOne(ctx context.Context, id string, args ...any) (pro
 
 func (s *MemoryStorage) Update(ctx context.Context, id string, payload product.UpdateDTO, args ...any) (int64, error) {
 	if e, ok := s.m[id]; ok {
+		// the columns written by the postgres storage
 		e.Name = payload.Name
+		e.Description = payload.Description
+		e.UpdatedAt = payload.UpdatedAt
 		s.m[id] = e
 
 		return 1, nil
@@ -65,6 +122,16 @@ func (s *MemoryStorage) Delete(ctx context.Context, id string, args ...any) (int
 	return -1, errors.New("not found")
 }
 
+func (s *MemoryStorage) Purge(ctx context.Context, id string, args ...any) (int64, error) {
+	if _, ok := s.m[id]; ok {
+		delete(s.m, id)
+		return 1, nil
+	}
+
+	// not found return error
+	return -1, errors.New("not found")
+}
+
 func (s *MemoryStorage) Clear() {
 	clear(s.m)
 }
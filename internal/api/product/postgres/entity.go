@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"log"
 
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
 )
 
@@ -36,8 +37,45 @@ func newProductEntity(name string, description *string, createdAt, updatedAt int
 	return &e
 }
 
-func (e *productEntity) scanRow(row *sql.Row) error {
-	if err := row.Scan(&e.id, &e.name, &e.description, &e.isArchived, &e.createdAt, &e.updatedAt); err != nil {
+// columns contains the columns of the entity in the select order
+var columns = []string{"id", "name", "description", "is_archived", "created_at", "updated_at"}
+
+// fieldColumns maps the field names of the api to the columns
+// used to select the columns of a sparse fieldset
+var fieldColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "description",
+	"isArchived":  "is_archived",
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+}
+
+// dest returns the scan destinations of the columns
+func (e *productEntity) dest(columns []string) []any {
+	d := make([]any, len(columns))
+	for i, c := range columns {
+		switch c {
+		case "id":
+			d[i] = &e.id
+		case "name":
+			d[i] = &e.name
+		case "description":
+			d[i] = &e.description
+		case "is_archived":
+			d[i] = &e.isArchived
+		case "created_at":
+			d[i] = &e.createdAt
+		case "updated_at":
+			d[i] = &e.updatedAt
+		}
+	}
+
+	return d
+}
+
+func (e *productEntity) scanRow(row *sql.Row, columns []string) error {
+	if err := row.Scan(e.dest(columns)...); err != nil {
 		log.Println("error: ", err)
 		return errorext.BuildDBError(err)
 	}
@@ -45,7 +83,7 @@ func (e *productEntity) scanRow(row *sql.Row) error {
 	return nil
 }
 
-func (e *productEntity) scanRows(rows *sql.Rows) ([]productEntity, error) {
+func (e *productEntity) scanRows(rows *sql.Rows, columns []string) ([]productEntity, error) {
 	d := []productEntity{}
 
 	// Loop through rows, using Scan to assign column data to struct fields.
@@ -53,7 +91,7 @@ func (e *productEntity) scanRows(rows *sql.Rows) ([]productEntity, error) {
 		var p productEntity
 
 		// fmt.Printf("Pointer: %p\n", &e)
-		if err := rows.Scan(&p.id, &p.name, &p.description, &p.isArchived, &p.createdAt, &p.updatedAt); err != nil {
+		if err := rows.Scan(p.dest(columns)...); err != nil {
 			log.Println("error: ", err)
 			return nil, errorext.BuildDBError(err)
 		}
@@ -69,3 +107,22 @@ func (e *productEntity) scanRows(rows *sql.Rows) ([]productEntity, error) {
 
 	return d, nil
 }
+
+// scanProduct scans the current row to the domain entity
+// used by the streaming read
+func scanProduct(rows *sql.Rows) (product.Product, error) {
+	var p productEntity
+	if err := rows.Scan(&p.id, &p.name, &p.description, &p.isArchived, &p.createdAt, &p.updatedAt); err != nil {
+		log.Println("error: ", err)
+		return product.Product{}, errorext.BuildDBError(err)
+	}
+
+	return product.Product{
+		ID:          p.id,
+		Name:        p.name,
+		Description: &p.description.String,
+		IsArchived:  p.isArchived,
+		CreatedAt:   p.createdAt,
+		UpdatedAt:   p.updatedAt,
+	}, nil
+}
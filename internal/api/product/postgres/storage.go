@@ -3,17 +3,25 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"iter"
 	"log"
+	"net/http"
+	"strings"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
 )
 
 const tableName = "products"
 
 // storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
 type storage struct {
 	db *sql.DB
 }
@@ -22,6 +30,16 @@ func NewStorage(db *sql.DB) *storage {
 	return &storage{db: db}
 }
 
+// buildError maps the violation of the unique name to 409
+func buildError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == errorext.SQLCodeUniqueViolation {
+		return errorext.NewCustomError(http.StatusConflict, errors.New(constant.ProductNameTaken))
+	}
+
+	return errorext.BuildDBError(err)
+}
+
 func (s *storage) Create(ctx context.Context, payload product.CreateDTO, args ...any) (string, error) {
 	var lastID string
 
@@ -29,28 +47,67 @@ func (s *storage) Create(ctx context.Context, payload product.CreateDTO, args ..
 	q := sqlext.BuildInsertQuery(tableName, []string{"name", "description", "created_at", "updated_at"}, "RETURNING id")
 
 	// execute the query
-	row := s.db.QueryRowContext(ctx, q, payload.Name, payload.Description, payload.CreatedAt, payload.UpdatedAt)
+	row := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, payload.Name, payload.Description, payload.CreatedAt, payload.UpdatedAt)
 	err := row.Err()
 	if err != nil {
 		log.Printf("err: %v", err)
-		err := errorext.BuildDBError(err)
+		err := buildError(err)
 		return lastID, err
 	}
 
 	err = row.Scan(&lastID)
 	if err != nil {
 		log.Printf("err: %v", err)
-		err := errorext.BuildDBError(err)
+		err := buildError(err)
 		return lastID, err
 	}
 
 	return lastID, nil
 }
 
+// Upsert inserts the entity or updates the description of
+// the entity with the same name, name is the unique natural key
+// the stored entity is returned, created_at is kept on an update
+func (s *storage) Upsert(ctx context.Context, payload product.CreateDTO, args ...any) (product.Product, bool, error) {
+	var (
+		e       productEntity
+		created bool
+	)
+
+	// xmax is zero for a freshly inserted row
+	q := sqlext.BuildInsertQuery(
+		tableName,
+		[]string{"name", "description", "created_at", "updated_at"},
+		"ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = EXCLUDED.updated_at RETURNING "+strings.Join(columns, ", ")+", (xmax = 0)",
+	)
+
+	row := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, payload.Name, payload.Description, payload.CreatedAt, payload.UpdatedAt)
+
+	err := row.Scan(append(e.dest(columns), &created)...)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return product.Product{}, created, errorext.BuildDBError(err)
+	}
+
+	// convert postgres entity to domain entity
+	return product.Product{
+		ID:          e.id,
+		Name:        e.name,
+		Description: &e.description.String,
+		IsArchived:  e.isArchived,
+		CreatedAt:   e.createdAt,
+		UpdatedAt:   e.updatedAt,
+	}, created, nil
+}
+
+// ReadMany reads a page of the entities matching the args
+// only the columns of the fieldset of the context are selected
 func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any) ([]product.Product, error) {
 	d := make([]product.Product, 0)
 
-	q := fmt.Sprintf("SELECT id, name, description, is_archived, created_at, updated_at FROM %s", tableName)
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
+
+	q := fmt.Sprintf("SELECT %s FROM %s", strings.Join(projections, ", "), tableName)
 	vals := make([]any, 0)
 
 	if len(args) > 0 && args[0] != nil {
@@ -61,7 +118,7 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 	q += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(vals)+1, len(vals)+2)
 	vals = append(vals, limit, offset)
 
-	rows, err := s.db.QueryContext(ctx, q, vals...)
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, vals...)
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return d, err
@@ -71,7 +128,7 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 
 	// scan the rows
 	entity := &productEntity{}
-	products, err := entity.scanRows(rows)
+	products, err := entity.scanRows(rows, projections)
 	if err != nil {
 		return d, err
 	}
@@ -91,12 +148,98 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 	return d, nil
 }
 
+// ReadByIDs reads the entities of the ids in one query
+// only the columns of the fieldset of the context are selected
+func (s *storage) ReadByIDs(ctx context.Context, ids []string, args ...any) ([]product.Product, error) {
+	d := make([]product.Product, 0, len(ids))
+
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
+
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE id = ANY($1)", strings.Join(projections, ", "), tableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, ids)
+	if err != nil {
+		err := errorext.BuildDBError(err)
+		return d, err
+	}
+
+	defer rows.Close()
+
+	// scan the rows
+	entity := &productEntity{}
+	products, err := entity.scanRows(rows, projections)
+	if err != nil {
+		return d, err
+	}
+
+	// convert postgres entity to domain entity
+	for _, p := range products {
+		d = append(d, product.Product{
+			ID:          p.id,
+			Name:        p.name,
+			Description: &p.description.String,
+			IsArchived:  p.isArchived,
+			CreatedAt:   p.createdAt,
+			UpdatedAt:   p.updatedAt,
+		})
+	}
+
+	return d, nil
+}
+
+// Count counts the entities matching the args
+func (s *storage) Count(ctx context.Context, args ...any) (int64, error) {
+	var n int64
+
+	q := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	vals := make([]any, 0)
+
+	if len(args) > 0 && args[0] != nil {
+		q += " WHERE is_archived = $1"
+		vals = append(vals, args[0].(bool))
+	}
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, vals...).Scan(&n)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+// Estimate returns the estimated number of entities, see sqlext.Estimate
+func (s *storage) Estimate(ctx context.Context) (int64, error) {
+	n, err := sqlext.Estimate(ctx, sqlext.Conn(ctx, s.db), tableName)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+func (s *storage) Stream(ctx context.Context, args ...any) iter.Seq2[product.Product, error] {
+	q := fmt.Sprintf("SELECT id, name, description, is_archived, created_at, updated_at FROM %s", tableName)
+	vals := make([]any, 0)
+
+	if len(args) > 0 && args[0] != nil {
+		q += " WHERE is_archived = $1"
+		vals = append(vals, args[0].(bool))
+	}
+
+	// stable order so the export can be compared
+	q += " ORDER BY created_at, id"
+
+	return sqlext.Stream(ctx, s.db, q, constant.StreamBatchSize, scanProduct, vals...)
+}
+
+// ReadOne reads the entity of the id
+// only the columns of the fieldset of the context are selected
 func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (product.Product, error) {
-	projections := []string{"id", "name", "description", "is_archived", "created_at", "updated_at"}
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
 
 	q := sqlext.BuildSelectQuery(tableName, projections, []string{"id"}, "LIMIT $2")
 
-	row := s.db.QueryRowContext(ctx, q, id, 1)
+	row := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, id, 1)
 	err := row.Err()
 	if err != nil {
 		err := errorext.BuildDBError(err)
@@ -104,7 +247,7 @@ func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (product.
 	}
 
 	entity := &productEntity{}
-	err = entity.scanRow(row)
+	err = entity.scanRow(row, projections)
 	if err != nil {
 		return product.Product{}, err
 	}
@@ -123,9 +266,9 @@ func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (product.
 func (s *storage) Update(ctx context.Context, id string, payload product.UpdateDTO, args ...any) (int64, error) {
 	q := sqlext.BuildUpdateQuery(tableName, []string{"name", "description", "updated_at"}, []string{"id"}, "")
 
-	res, err := s.db.ExecContext(ctx, q, payload.Name, payload.Description, payload.UpdatedAt, id)
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, payload.Name, payload.Description, payload.UpdatedAt, id)
 	if err != nil {
-		err := errorext.BuildDBError(err)
+		err := buildError(err)
 		return -1, err
 	}
 
@@ -135,7 +278,19 @@ func (s *storage) Update(ctx context.Context, id string, payload product.UpdateD
 func (s *storage) Delete(ctx context.Context, id string, args ...any) (int64, error) {
 	q := sqlext.BuildUpdateQuery(tableName, []string{"is_archived", "updated_at"}, []string{"id"}, "")
 
-	res, err := s.db.ExecContext(ctx, q, true, args[0].(int64), id)
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, true, args[0].(int64), id)
+	if err != nil {
+		err := errorext.BuildDBError(err)
+		return -1, err
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) Purge(ctx context.Context, id string, args ...any) (int64, error) {
+	q := sqlext.BuildDeleteQuery(tableName, []string{"id"}, "")
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, id)
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return -1, err
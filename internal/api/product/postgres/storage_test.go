@@ -2,17 +2,20 @@ package postgres_test
 
 import (
 	"context"
+	"net/http"
 	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product/postgres"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
 )
 
 func TestStorage(t *testing.T) {
@@ -272,3 +275,48 @@ func TestStorage(t *testing.T) {
 		}
 	})
 }
+
+func TestStorageNameConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	s := postgres.NewStorage(db)
+	n := time.Now().Unix()
+	unique := &pgconn.PgError{Code: errorext.SQLCodeUniqueViolation}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO products`)).WillReturnError(unique)
+
+	_, err = s.Create(context.Background(), product.CreateDTO{Name: "taken", CreatedAt: n, UpdatedAt: n})
+	assert.Equal(t, http.StatusConflict, errorext.ParseCustomError(err).Code())
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE products SET name = $1`)).WillReturnError(unique)
+
+	_, err = s.Update(context.Background(), constant.FakeUUID, product.UpdateDTO{Name: "taken", UpdatedAt: n})
+	assert.Equal(t, http.StatusConflict, errorext.ParseCustomError(err).Code())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStorageUpsert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	s := postgres.NewStorage(db)
+	n := time.Now().Unix()
+	dto := product.CreateDTO{Name: "existing", CreatedAt: n, UpdatedAt: n}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ON CONFLICT (name) DO UPDATE SET description = EXCLUDED.description, updated_at = EXCLUDED.updated_at RETURNING id, name, description, is_archived, created_at, updated_at, (xmax = 0)`)).
+		WithArgs(dto.Name, dto.Description, dto.CreatedAt, dto.UpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "is_archived", "created_at", "updated_at", "created"}).
+			AddRow(constant.FakeUUID, dto.Name, nil, false, n-3600, n, false))
+
+	p, created, err := s.Upsert(context.Background(), dto)
+	assert.NoError(t, err)
+	assert.False(t, created)
+	// the created at of the stored entity, not the one of the dto
+	assert.Equal(t, n-3600, p.CreatedAt)
+	assert.Equal(t, constant.FakeUUID, p.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
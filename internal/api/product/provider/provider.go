@@ -14,9 +14,9 @@ type Provider struct {
 	Repository product.Repository
 }
 
-// New initializes a Provider
-func New(db *sql.DB) Provider {
+// New initializes a Provider, opts configure the service
+func New(db *sql.DB, opts ...service.Option) Provider {
 	r := postgres.NewStorage(db)
-	u := service.NewService(r)
+	u := service.NewService(r, opts...)
 	return Provider{UseCase: u, Repository: r}
 }
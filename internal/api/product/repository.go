@@ -2,17 +2,41 @@ package product
 
 import (
 	"context"
+	"iter"
 )
 
 // Repository defines the data persistance logic that needs to be implemented
 type Repository interface {
 	Create(ctx context.Context, payload CreateDTO, args ...any) (string, error)
 
+	// Upsert creates the entity or updates the entity with the same name
+	// and returns the stored entity, created is false if an existing
+	// entity was updated
+	Upsert(ctx context.Context, payload CreateDTO, args ...any) (p Product, created bool, err error)
+
 	ReadMany(ctx context.Context, limit, offset int, args ...any) ([]Product, error)
 
+	// ReadByIDs reads the entities of the ids in one query
+	// the missing ids are skipped, the order is not kept
+	ReadByIDs(ctx context.Context, ids []string, args ...any) ([]Product, error)
+
+	// Count counts the entities matching the args
+	Count(ctx context.Context, args ...any) (int64, error)
+
+	// Estimate returns the estimated number of entities of the
+	// table, negative if it is not known
+	Estimate(ctx context.Context) (int64, error)
+
+	// Stream reads all the entities matching the args
+	// without holding them in memory
+	Stream(ctx context.Context, args ...any) iter.Seq2[Product, error]
+
 	ReadOne(ctx context.Context, id string, args ...any) (Product, error)
 
 	Update(ctx context.Context, id string, payload UpdateDTO, args ...any) (int64, error)
 
 	Delete(ctx context.Context, id string, args ...any) (int64, error)
+
+	// Purge removes the entity, Delete archives it
+	Purge(ctx context.Context, id string, args ...any) (int64, error)
 }
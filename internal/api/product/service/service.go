@@ -3,13 +3,18 @@ package service
 import (
 	"context"
 	"errors"
+	"iter"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
-	"github.com/tanveerprottoy/backend-structure-go/pkg/util"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
 )
 
 // service implements the use case of the product
@@ -17,11 +22,60 @@ import (
 // repository to perform db operations
 type service struct {
 	repository product.Repository
+	// publisher publishes the changes, nil disables the events
+	publisher event.Publisher
+	// authorizer authorizes the callers, nil disables the checks
+	authorizer rbac.Authorizer
+}
+
+// Option configures the service
+type Option func(*service)
+
+// WithPublisher publishes the events of the changes with p
+func WithPublisher(p event.Publisher) Option {
+	return func(s *service) {
+		s.publisher = p
+	}
+}
+
+// WithAuthorizer checks the products permissions of the callers
+func WithAuthorizer(a rbac.Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
 }
 
 // NewService initializes a new Service
-func NewService(r product.Repository) *service {
-	return &service{repository: r}
+func NewService(r product.Repository, opts ...Option) *service {
+	s := &service{repository: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// publish publishes the change of the entity after a successful write
+// the handlers run in the context of the write, a failed handler is
+// logged and does not fail the write
+func (s *service) publish(ctx context.Context, eventType string, p product.Product) {
+	if s.publisher == nil {
+		return
+	}
+
+	if err := s.publisher.Publish(ctx, event.New(eventType, p)); err != nil {
+		log.Printf("publish %s returned error: %v", eventType, err)
+	}
+}
+
+// authorize checks the permission of the caller of ctx
+// the products have no owner
+func (s *service) authorize(ctx context.Context, permission string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	return s.authorizer.Authorize(ctx, permission, "")
 }
 
 // readOneInternal fetches one entity from db
@@ -36,6 +90,11 @@ func (s *service) readOneInternal(ctx context.Context, id string) (product.Produ
 
 // create defines the business logic for create post request
 func (s *service) Create(ctx context.Context, payload product.CreateDTO) (product.Product, error) {
+	err := s.authorize(ctx, role.PermissionProductsWrite)
+	if err != nil {
+		return product.Product{}, err
+	}
+
 	// build entity
 	n := time.Now().Unix()
 
@@ -47,17 +106,54 @@ func (s *service) Create(ctx context.Context, payload product.CreateDTO) (produc
 		return product.Product{}, errorext.BuildCustomError(err)
 	}
 
-	return *product.NewProduct(
+	p := *product.NewProduct(
 		l,
 		payload.Name,
 		payload.Description,
 		payload.CreatedAt,
 		payload.UpdatedAt,
-	), nil
+	)
+
+	s.publish(ctx, product.EventCreated, p)
+
+	return p, nil
+}
+
+// Upsert defines the business logic of the import
+// the entity is created or updated by its name
+func (s *service) Upsert(ctx context.Context, payload product.CreateDTO) (product.Product, bool, error) {
+	err := s.authorize(ctx, role.PermissionProductsWrite)
+	if err != nil {
+		return product.Product{}, false, err
+	}
+
+	n := time.Now().Unix()
+
+	payload.CreatedAt = n
+	payload.UpdatedAt = n
+
+	// the stored entity keeps its created at on an update
+	p, created, err := s.repository.Upsert(ctx, payload)
+	if err != nil {
+		return product.Product{}, false, errorext.BuildCustomError(err)
+	}
+
+	if created {
+		s.publish(ctx, product.EventCreated, p)
+	} else {
+		s.publish(ctx, product.EventUpdated, p)
+	}
+
+	return p, created, nil
 }
 
 func (s *service) ReadMany(ctx context.Context, limit, page int, args ...any) ([]product.Product, error) {
-	offset := util.CalculateOffset(limit, page)
+	err := s.authorize(ctx, role.PermissionProductsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	offset := pagination.Page{Limit: limit, Number: max(page, 1)}.Offset()
 
 	d, err := s.repository.ReadMany(ctx, limit, offset, args...)
 	if err != nil {
@@ -67,7 +163,53 @@ func (s *service) ReadMany(ctx context.Context, limit, page int, args ...any) ([
 	return d, nil
 }
 
+// Count returns the total of the entities matching the args
+func (s *service) Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error) {
+	err := s.authorize(ctx, role.PermissionProductsRead)
+	if err != nil {
+		return pagination.Total{}, err
+	}
+
+	t, err := policy.Total(
+		ctx,
+		func(ctx context.Context) (int64, error) { return s.repository.Count(ctx, args...) },
+		s.repository.Estimate,
+	)
+	if err != nil {
+		return t, errorext.BuildCustomError(err)
+	}
+
+	return t, nil
+}
+
+// Stream reads all the entities matching the args
+// the errors are converted to custom errors
+func (s *service) Stream(ctx context.Context, args ...any) iter.Seq2[product.Product, error] {
+	return func(yield func(product.Product, error) bool) {
+		if err := s.authorize(ctx, role.PermissionProductsRead); err != nil {
+			yield(product.Product{}, err)
+			return
+		}
+
+		for e, err := range s.repository.Stream(ctx, args...) {
+			if err != nil {
+				yield(e, errorext.BuildCustomError(err))
+				return
+			}
+
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (s *service) ReadOne(ctx context.Context, id string) (product.Product, error) {
+	err := s.authorize(ctx, role.PermissionProductsRead)
+	if err != nil {
+		return product.Product{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
@@ -76,25 +218,65 @@ func (s *service) ReadOne(ctx context.Context, id string) (product.Product, erro
 	return e, nil
 }
 
+// ReadByIDs reads the entities of the ids, an empty ids
+// is answered without calling the repository
+func (s *service) ReadByIDs(ctx context.Context, ids []string) ([]product.Product, error) {
+	err := s.authorize(ctx, role.PermissionProductsRead)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return []product.Product{}, nil
+	}
+
+	d, err := s.repository.ReadByIDs(ctx, ids)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
 func (s *service) Update(ctx context.Context, id string, payload product.UpdateDTO) (product.Product, error) {
+	err := s.authorize(ctx, role.PermissionProductsWrite)
+	if err != nil {
+		return product.Product{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
 	}
 
+	payload.UpdatedAt = time.Now().Unix()
+
 	rowCount, err := s.repository.Update(ctx, id, payload)
 	if err != nil {
 		return e, errorext.BuildCustomError(err)
 	}
 
 	if rowCount > 0 {
-		return e, nil
+		// the stored entity, the update writes only some of the fields
+		u, err := s.readOneInternal(ctx, id)
+		if err != nil {
+			return e, err
+		}
+
+		s.publish(ctx, product.EventUpdated, u)
+
+		return u, nil
 	}
 
 	return e, errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
 }
 
 func (s *service) Delete(ctx context.Context, id string) (product.Product, error) {
+	err := s.authorize(ctx, role.PermissionProductsDelete)
+	if err != nil {
+		return product.Product{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
@@ -109,8 +291,35 @@ func (s *service) Delete(ctx context.Context, id string) (product.Product, error
 	if rowCount > 0 {
 		e.IsArchived = true
 		e.UpdatedAt = n
+		s.publish(ctx, product.EventDeleted, e)
 		return e, nil
 	}
 
 	return e, errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
 }
+
+// Purge removes the entity for good, Delete archives it
+// the purge is granted to the admins, see role.Defaults
+func (s *service) Purge(ctx context.Context, id string) error {
+	err := s.authorize(ctx, role.PermissionProductsPurge)
+	if err != nil {
+		return err
+	}
+
+	e, err := s.readOneInternal(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := s.repository.Purge(ctx, id)
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	if rowCount > 0 {
+		s.publish(ctx, product.EventPurged, e)
+		return nil
+	}
+
+	return errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
+}
@@ -2,12 +2,18 @@ package service_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product/mock"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product/service"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/event"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
 )
 
 func TestService(t *testing.T) {
@@ -149,6 +155,31 @@ func TestService(t *testing.T) {
 		}
 	})
 
+	t.Run("ReadByIDs", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			ids      []string
+			expected int
+		}{
+			{name: "found", ids: []string{insertedIDs[0], insertedIDs[1]}, expected: 2},
+			{name: "missing skipped", ids: []string{insertedIDs[0], constant.FakeUUID}, expected: 1},
+			{name: "empty", ids: nil, expected: 0},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				d, err := s.ReadByIDs(context.Background(), tc.ids)
+				if err != nil {
+					t.Error(err)
+				}
+
+				if len(d) != tc.expected {
+					t.Errorf("expected %d entities, got %d", tc.expected, len(d))
+				}
+			})
+		}
+	})
+
 	t.Run("update", func(t *testing.T) {
 		descrption := "updated description 1"
 
@@ -238,3 +269,80 @@ func TestService(t *testing.T) {
 		}
 	})
 }
+
+func TestServiceUpdateEvent(t *testing.T) {
+	ctx := context.Background()
+	r := mock.NewMemoryStorage()
+
+	var events []event.Event
+
+	bus := event.NewBus()
+	bus.Subscribe(func(ctx context.Context, e event.Event) error {
+		events = append(events, e)
+		return nil
+	})
+
+	s := service.NewService(r, service.WithPublisher(bus))
+
+	c, err := s.Create(ctx, product.CreateDTO{Name: "name 1"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	description := "description 1"
+
+	// the storage does not write is archived
+	u, err := s.Update(ctx, c.ID, product.UpdateDTO{Name: "name 2", Description: &description, IsArchived: true})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	if u.Name != "name 2" || u.IsArchived {
+		t.Errorf("expected the stored entity, got %+v", u)
+	}
+
+	e := events[len(events)-1]
+	if p, ok := e.Data.(product.Product); e.Type != product.EventUpdated || !ok || p.Name != "name 2" || p.IsArchived || p.UpdatedAt == 0 {
+		t.Errorf("expected the %s event of the stored entity, got %+v", product.EventUpdated, e)
+	}
+}
+
+func TestServicePurge(t *testing.T) {
+	r := mock.NewMemoryStorage()
+
+	var events []event.Event
+
+	bus := event.NewBus()
+	bus.Subscribe(func(ctx context.Context, e event.Event) error {
+		events = append(events, e)
+		return nil
+	})
+
+	s := service.NewService(r, service.WithPublisher(bus), service.WithAuthorizer(rbac.NewPolicy(role.Grants(role.Defaults))))
+
+	staff := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "staff-1", Method: auth.MethodJWT, Roles: []string{role.Staff}})
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin-1", Method: auth.MethodJWT, Roles: []string{role.Admin}})
+
+	c, err := s.Create(staff, product.CreateDTO{Name: "name 1"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// the staff archive the products, the admins purge them
+	if err := s.Purge(staff, c.ID); err == nil || errorext.ParseCustomError(err).Code() != http.StatusForbidden {
+		t.Fatalf("expected 403 for the staff, got %v", err)
+	}
+
+	if err := s.Purge(admin, c.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	if _, err := s.ReadOne(admin, c.ID); err == nil {
+		t.Error("expected the purged entity to be removed")
+	}
+
+	e := events[len(events)-1]
+	if p, ok := e.Data.(product.Product); e.Type != product.EventPurged || !ok || p.ID != c.ID {
+		t.Errorf("expected the %s event of the entity, got %+v", product.EventPurged, e)
+	}
+}
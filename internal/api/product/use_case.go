@@ -2,16 +2,38 @@ package product
 
 import (
 	"context"
+	"iter"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
 )
 
 type UseCase interface {
 	Create(ctx context.Context, payload CreateDTO) (Product, error)
 
+	// Upsert creates or updates the entity by its name
+	// created is false if an existing entity was updated
+	Upsert(ctx context.Context, payload CreateDTO) (p Product, created bool, err error)
+
+	// ReadMany reads a page of the entities, limit is
+	// the effective limit of the pagination policy
 	ReadMany(ctx context.Context, limit, page int, args ...any) ([]Product, error)
 
+	// Count returns the total of the entities matching the args
+	// with the count mode of the policy
+	Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error)
+
+	Stream(ctx context.Context, args ...any) iter.Seq2[Product, error]
+
 	ReadOne(ctx context.Context, id string) (Product, error)
 
+	// ReadByIDs reads the entities of the ids, used to batch
+	// the reads of the graphql queries, see dataloader
+	ReadByIDs(ctx context.Context, ids []string) ([]Product, error)
+
 	Update(ctx context.Context, id string, payload UpdateDTO) (Product, error)
 
 	Delete(ctx context.Context, id string) (Product, error)
+
+	// Purge removes the entity for good, an archived one too
+	Purge(ctx context.Context, id string) error
 }
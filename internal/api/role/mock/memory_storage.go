@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// MemoryStorage is a mock storage
+// can be used to mock the repository
+// for service testing, the roles are
+// role.Defaults and any user exists
+type MemoryStorage struct {
+	mu    sync.Mutex
+	users map[string][]string
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{users: make(map[string][]string)}
+}
+
+func (s *MemoryStorage) ReadMany(ctx context.Context) ([]role.Role, error) {
+	return slices.Clone(role.Defaults), nil
+}
+
+func (s *MemoryStorage) ReadUserRoles(ctx context.Context, userID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.users[userID]), nil
+}
+
+func (s *MemoryStorage) AssignRole(ctx context.Context, userID, name string, createdAt int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.ContainsFunc(role.Defaults, func(r role.Role) bool { return r.Name == name }) {
+		return errorext.NewCustomError(http.StatusNotFound, errors.New(constant.UserOrRoleNotFound))
+	}
+
+	if !slices.Contains(s.users[userID], name) {
+		s.users[userID] = append(s.users[userID], name)
+		slices.Sort(s.users[userID])
+	}
+
+	return nil
+}
+
+func (s *MemoryStorage) RemoveRole(ctx context.Context, userID, name string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.Index(s.users[userID], name)
+	if i < 0 {
+		return 0, nil
+	}
+
+	s.users[userID] = slices.Delete(s.users[userID], i, i+1)
+
+	return 1, nil
+}
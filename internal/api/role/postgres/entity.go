@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+// a package private row of the roles joined with their grants
+// the grant is null for a role without grants
+type grantEntity struct {
+	role        string
+	description string
+	permission  sql.NullString
+	own         sql.NullBool
+}
+
+// scanRoles groups the rows ordered by the role into the roles
+func scanRoles(rows *sql.Rows) ([]role.Role, error) {
+	d := make([]role.Role, 0)
+
+	for rows.Next() {
+		var e grantEntity
+		if err := rows.Scan(&e.role, &e.description, &e.permission, &e.own); err != nil {
+			log.Println("error: ", err)
+			return nil, errorext.BuildDBError(err)
+		}
+
+		if len(d) == 0 || d[len(d)-1].Name != e.role {
+			d = append(d, role.Role{Name: e.role, Description: e.description})
+		}
+
+		if e.permission.Valid {
+			r := &d[len(d)-1]
+			r.Grants = append(r.Grants, rbac.Grant{Permission: e.permission.String, Own: e.own.Bool})
+		}
+	}
+
+	// Check for errors from iterating over rows.
+	if err := rows.Err(); err != nil {
+		log.Println("error: ", err)
+		return nil, errorext.BuildDBError(err)
+	}
+
+	return d, nil
+}
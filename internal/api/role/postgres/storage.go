@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+const (
+	tableName           = "roles"
+	permissionTableName = "role_permissions"
+	userRoleTableName   = "user_roles"
+)
+
+// storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
+type storage struct {
+	db *sql.DB
+}
+
+func NewStorage(db *sql.DB) *storage {
+	return &storage{db: db}
+}
+
+func (s *storage) ReadMany(ctx context.Context) ([]role.Role, error) {
+	q := fmt.Sprintf(
+		"SELECT r.name, r.description, p.permission, p.own FROM %s r LEFT JOIN %s p ON p.role = r.name ORDER BY r.name, p.permission",
+		tableName,
+		permissionTableName,
+	)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	return scanRoles(rows)
+}
+
+func (s *storage) ReadUserRoles(ctx context.Context, userID string) ([]string, error) {
+	q := fmt.Sprintf("SELECT role FROM %s WHERE user_id = $1 ORDER BY role", userRoleTableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, userID)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	d := make([]string, 0)
+	for rows.Next() {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			log.Println("error: ", err)
+			return nil, errorext.BuildDBError(err)
+		}
+
+		d = append(d, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Println("error: ", err)
+		return nil, errorext.BuildDBError(err)
+	}
+
+	return d, nil
+}
+
+func (s *storage) AssignRole(ctx context.Context, userID, role string, createdAt int64) error {
+	q := sqlext.BuildInsertQuery(userRoleTableName, []string{"user_id", "role", "created_at"}, "ON CONFLICT DO NOTHING")
+
+	_, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, userID, role, createdAt)
+	if err != nil {
+		log.Printf("err: %v", err)
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == errorext.SQLCodeForeignKeyViolation {
+			return errorext.NewCustomError(http.StatusNotFound, errors.New(constant.UserOrRoleNotFound))
+		}
+
+		return errorext.BuildDBError(err)
+	}
+
+	return nil
+}
+
+func (s *storage) RemoveRole(ctx context.Context, userID, role string) (int64, error) {
+	q := fmt.Sprintf("DELETE FROM %s WHERE user_id = $1 AND role = $2", userRoleTableName)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, userID, role)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
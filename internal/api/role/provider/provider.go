@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"database/sql"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role/service"
+)
+
+// Provider contains and initializes the components of the package
+type Provider struct {
+	UseCase    role.UseCase
+	Repository role.Repository
+}
+
+// New initializes a Provider
+func New(db *sql.DB, opts ...service.Option) Provider {
+	r := postgres.NewStorage(db)
+	u := service.NewService(r, opts...)
+	return Provider{UseCase: u, Repository: r}
+}
@@ -0,0 +1,18 @@
+package role
+
+import "context"
+
+// Repository defines the data persistance logic that needs to be implemented
+type Repository interface {
+	// ReadMany reads the roles with their grants
+	ReadMany(ctx context.Context) ([]Role, error)
+
+	// ReadUserRoles reads the names of the roles of the user
+	ReadUserRoles(ctx context.Context, userID string) ([]string, error)
+
+	// AssignRole assigns the role to the user, assigning a role
+	// twice is not an error, an unknown user or role is not found
+	AssignRole(ctx context.Context, userID, role string, createdAt int64) error
+
+	RemoveRole(ctx context.Context, userID, role string) (int64, error)
+}
@@ -0,0 +1,99 @@
+package role
+
+import "github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+
+// the names of the seeded roles
+const (
+	Admin    = "admin"
+	Staff    = "staff"
+	Customer = "customer"
+)
+
+// the permissions checked by the routes and the use cases
+const (
+	PermissionProductsRead   = "products:read"
+	PermissionProductsWrite  = "products:write"
+	PermissionProductsDelete = "products:delete"
+	// PermissionProductsPurge allows to remove the products for good
+	PermissionProductsPurge = "products:purge"
+	PermissionUsersRead     = "users:read"
+	PermissionUsersWrite    = "users:write"
+	PermissionUsersDelete   = "users:delete"
+	// PermissionUsersPurge allows to remove the users and their data for good
+	PermissionUsersPurge = "users:purge"
+	// PermissionUsersManage allows to read and change the roles of the users
+	PermissionUsersManage    = "users:manage"
+	PermissionWebhooksManage = "webhooks:manage"
+)
+
+// Permissions contains the known permissions, the
+// scopes of the api keys are some of them
+var Permissions = []string{
+	PermissionProductsRead,
+	PermissionProductsWrite,
+	PermissionProductsDelete,
+	PermissionProductsPurge,
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersDelete,
+	PermissionUsersPurge,
+	PermissionUsersManage,
+	PermissionWebhooksManage,
+}
+
+// Role is a named set of grants, see rbac.Grant
+type Role struct {
+	Name        string
+	Description string
+	Grants      []rbac.Grant
+}
+
+// Defaults contains the roles seeded by scripts/db/db.sql
+// the customers and the staff read and edit themselves
+var Defaults = []Role{
+	{
+		Name:        Admin,
+		Description: "manages the users and the resources",
+		Grants: []rbac.Grant{
+			{Permission: PermissionProductsDelete},
+			{Permission: PermissionProductsPurge},
+			{Permission: PermissionProductsRead},
+			{Permission: PermissionProductsWrite},
+			{Permission: PermissionUsersDelete},
+			{Permission: PermissionUsersManage},
+			{Permission: PermissionUsersPurge},
+			{Permission: PermissionUsersRead},
+			{Permission: PermissionUsersWrite},
+			{Permission: PermissionWebhooksManage},
+		},
+	},
+	{
+		Name:        Customer,
+		Description: "reads the products",
+		Grants: []rbac.Grant{
+			{Permission: PermissionProductsRead},
+			{Permission: PermissionUsersRead, Own: true},
+			{Permission: PermissionUsersWrite, Own: true},
+		},
+	},
+	{
+		Name:        Staff,
+		Description: "edits the products",
+		Grants: []rbac.Grant{
+			{Permission: PermissionProductsRead},
+			{Permission: PermissionProductsWrite},
+			{Permission: PermissionUsersRead, Own: true},
+			{Permission: PermissionUsersWrite, Own: true},
+		},
+	},
+}
+
+// Grants returns the grants of the roles keyed by their names
+func Grants(roles []Role) map[string][]rbac.Grant {
+	m := make(map[string][]rbac.Grant, len(roles))
+	for _, r := range roles {
+		m[r.Name] = r.Grants
+	}
+
+	return m
+}
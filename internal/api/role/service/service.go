@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+// service implements the use case of the roles
+// service contains the business logic as well as calls to the
+// repository to perform db operations
+type service struct {
+	repository role.Repository
+	// authorizer authorizes the callers, nil disables the checks
+	authorizer rbac.Authorizer
+}
+
+// Option configures the service
+type Option func(*service)
+
+// WithAuthorizer checks the users:manage permission of the callers, a user
+// with users:read reads its own roles
+func WithAuthorizer(a rbac.Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
+}
+
+// NewService initializes a new Service
+func NewService(r role.Repository, opts ...Option) *service {
+	s := &service{repository: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// authorize checks the permission of the caller of ctx
+func (s *service) authorize(ctx context.Context, permission, owner string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	return s.authorizer.Authorize(ctx, permission, owner)
+}
+
+func (s *service) ReadMany(ctx context.Context) ([]role.Role, error) {
+	err := s.authorize(ctx, role.PermissionUsersManage, "")
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.repository.ReadMany(ctx)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+// ReadUserRoles needs the manage permission or the read
+// permission on the user
+func (s *service) ReadUserRoles(ctx context.Context, userID string) ([]string, error) {
+	err := s.authorize(ctx, role.PermissionUsersManage, "")
+	if err != nil {
+		if err := s.authorize(ctx, role.PermissionUsersRead, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	d, err := s.repository.ReadUserRoles(ctx, userID)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+func (s *service) Assign(ctx context.Context, userID, name string) ([]string, error) {
+	err := s.authorize(ctx, role.PermissionUsersManage, "")
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.AssignRole(ctx, userID, name, time.Now().Unix())
+	if err != nil {
+		return nil, errorext.BuildCustomError(err)
+	}
+
+	return s.ReadUserRoles(ctx, userID)
+}
+
+func (s *service) Remove(ctx context.Context, userID, name string) ([]string, error) {
+	err := s.authorize(ctx, role.PermissionUsersManage, "")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repository.RemoveRole(ctx, userID, name)
+	if err != nil {
+		return nil, errorext.BuildCustomError(err)
+	}
+
+	return s.ReadUserRoles(ctx, userID)
+}
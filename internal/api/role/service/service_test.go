@@ -0,0 +1,72 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+func TestService(t *testing.T) {
+	s := service.NewService(mock.NewMemoryStorage(), service.WithAuthorizer(rbac.NewPolicy(role.Grants(role.Defaults))))
+
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin-1", Roles: []string{role.Admin}})
+	customer := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1", Roles: []string{role.Customer}})
+
+	roles, err := s.Assign(admin, "user-1", role.Staff)
+	if err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+
+	if !slices.Equal(roles, []string{role.Staff}) {
+		t.Fatalf("unexpected roles %v", roles)
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		code int
+	}{
+		{name: "admin reads the roles", call: func() error { _, err := s.ReadMany(admin); return err }},
+		{name: "customer reads the roles", call: func() error { _, err := s.ReadMany(customer); return err }, code: http.StatusForbidden},
+		{name: "customer reads its roles", call: func() error { _, err := s.ReadUserRoles(customer, "user-1"); return err }},
+		{name: "customer reads other roles", call: func() error { _, err := s.ReadUserRoles(customer, "user-2"); return err }, code: http.StatusForbidden},
+		{name: "customer assigns a role", call: func() error { _, err := s.Assign(customer, "user-1", role.Admin); return err }, code: http.StatusForbidden},
+		{name: "unknown role", call: func() error { _, err := s.Assign(admin, "user-1", "guest"); return err }, code: http.StatusNotFound},
+		{name: "anonymous", call: func() error { _, err := s.ReadMany(context.Background()); return err }, code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.call()
+			if tc.code == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || errorext.ParseCustomError(err).Code() != tc.code {
+				t.Errorf("expected %d, got %v", tc.code, err)
+			}
+		})
+	}
+
+	t.Run("Remove", func(t *testing.T) {
+		roles, err := s.Remove(admin, "user-1", role.Staff)
+		if err != nil {
+			t.Fatalf("remove: %v", err)
+		}
+
+		if len(roles) != 0 {
+			t.Errorf("expected no roles, got %v", roles)
+		}
+	})
+}
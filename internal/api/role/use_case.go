@@ -0,0 +1,19 @@
+package role
+
+import "context"
+
+type UseCase interface {
+	// ReadMany reads the roles with their grants
+	ReadMany(ctx context.Context) ([]Role, error)
+
+	// ReadUserRoles reads the roles of the user, the users
+	// can read their own roles
+	ReadUserRoles(ctx context.Context, userID string) ([]string, error)
+
+	// Assign assigns the role to the user and returns its roles
+	// the changes apply to the access tokens issued afterwards
+	Assign(ctx context.Context, userID, role string) ([]string, error)
+
+	// Remove removes the role of the user and returns its roles
+	Remove(ctx context.Context, userID, role string) ([]string, error)
+}
@@ -3,6 +3,7 @@ package mock
 import (
 	"context"
 	"errors"
+	"iter"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 )
@@ -37,6 +38,47 @@ func (s MemoryStorage) ReadMany(ctx context.Context, limit int, offset int, args
 	return entities, nil
 }
 
+func (s MemoryStorage) ReadByIDs(ctx context.Context, ids []string, args ...any) ([]user.User, error) {
+	entities := make([]user.User, 0, len(ids))
+
+	for _, id := range ids {
+		if v, ok := s.m[id]; ok {
+			entities = append(entities, v)
+		}
+	}
+
+	return entities, nil
+}
+
+func (s MemoryStorage) Count(ctx context.Context, args ...any) (int64, error) {
+	var n int64
+
+	for _, v := range s.m {
+		if len(args) > 0 && args[0] != nil && v.IsArchived != args[0].(bool) {
+			continue
+		}
+
+		n++
+	}
+
+	return n, nil
+}
+
+// Estimate is not known for the memory storage
+func (s MemoryStorage) Estimate(ctx context.Context) (int64, error) {
+	return -1, nil
+}
+
+func (s MemoryStorage) Stream(ctx context.Context, args ...any) iter.Seq2[user.User, error] {
+	return func(yield func(user.User, error) bool) {
+		for _, v := range s.m {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (s MemoryStorage) ReadOne(ctx context.Context, id string, args ...any) (user.User, error) {
 	if e, ok := s.m[id]; ok {
 		return e, nil
@@ -68,6 +110,15 @@ func (s *MemoryStorage) Delete(ctx context.Context, id string, args ...any) (int
 	return -1, errors.New("not found")
 }
 
+func (s *MemoryStorage) Purge(ctx context.Context, id string, args ...any) (int64, error) {
+	if _, ok := s.m[id]; ok {
+		delete(s.m, id)
+		return 1, nil
+	}
+	// not found return error
+	return -1, errors.New("not found")
+}
+
 func (s *MemoryStorage) Clear() {
 	clear(s.m)
 }
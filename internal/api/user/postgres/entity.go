@@ -6,43 +6,84 @@ import (
 	"log"
 	"reflect"
 
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
 )
 
+// AddressColumn is the encrypted address of the users
+var AddressColumn = sqlext.EncryptedColumn{Table: tableName, Key: "id", Column: "address"}
+
 // a package private entity clone of the domain entity
 // this type can have db specific data types like here
-// it's sqlext.NullString
+// it's sqlext.EncryptedString
 type userEntity struct {
 	// this struct fields must be exported
 	// so that the reflection can access them
 	// which is done in the method scanMany
-	Id         string         `db:"id"`
-	Name       string         `db:"name"`
-	Address    sql.NullString `db:"address"`
-	IsArchived bool           `db:"is_archived"`
-	CreatedAt  int64          `db:"created_at"`
-	UpdatedAt  int64          `db:"updated_at"`
+	Id         string                 `db:"id"`
+	Name       string                 `db:"name"`
+	Address    sqlext.EncryptedString `db:"address"`
+	IsArchived bool                   `db:"is_archived"`
+	CreatedAt  int64                  `db:"created_at"`
+	UpdatedAt  int64                  `db:"updated_at"`
 }
 
 // this will be used to create db entity from domain entity
-func newUserEntity(name string, address *string, createdAt, updatedAt int64) *userEntity {
+// the address is encrypted with enc for the row of the id
+func newUserEntity(enc sqlext.Encryptor, id, name string, address *string, createdAt, updatedAt int64) *userEntity {
 	// address can be nil in db
-	// check for nil
+	// it's checked by the encrypted string
 	e := userEntity{
+		Id:        id,
 		Name:      name,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}
+	e.Address = sqlext.NewEncryptedString(enc, AddressColumn, &e.Id, address)
+
+	return &e
+}
 
-	if address != nil {
-		e.Address = sql.NullString{String: *address, Valid: true}
+// columns contains the columns of the entity in the select order
+var columns = []string{"id", "name", "address", "is_archived", "created_at", "updated_at"}
+
+// fieldColumns maps the field names of the api to the columns
+// used to select the columns of a sparse fieldset
+var fieldColumns = map[string]string{
+	"id":          "id",
+	"name":        "name",
+	"description": "address",
+	"isArchived":  "is_archived",
+	"createdAt":   "created_at",
+	"updatedAt":   "updated_at",
+}
+
+// dest returns the scan destinations of the columns
+func (e *userEntity) dest(columns []string) []any {
+	d := make([]any, len(columns))
+	for i, c := range columns {
+		switch c {
+		case "id":
+			d[i] = &e.Id
+		case "name":
+			d[i] = &e.Name
+		case "address":
+			d[i] = &e.Address
+		case "is_archived":
+			d[i] = &e.IsArchived
+		case "created_at":
+			d[i] = &e.CreatedAt
+		case "updated_at":
+			d[i] = &e.UpdatedAt
+		}
 	}
 
-	return &e
+	return d
 }
 
-func (e *userEntity) scanRow(row *sql.Row) error {
-	if err := row.Scan(&e.Id, &e.Name, &e.Address, &e.IsArchived, &e.CreatedAt, &e.UpdatedAt); err != nil {
+func (e *userEntity) scanRow(row *sql.Row, columns []string) error {
+	if err := row.Scan(e.dest(columns)...); err != nil {
 		log.Println("error: ", err)
 		return errorext.BuildDBError(err)
 	}
@@ -50,15 +91,18 @@ func (e *userEntity) scanRow(row *sql.Row) error {
 	return nil
 }
 
-func (e *userEntity) scanRows(rows *sql.Rows) ([]userEntity, error) {
+func (e *userEntity) scanRows(rows *sql.Rows, columns []string) ([]userEntity, error) {
 	d := []userEntity{}
 
 	// Loop through rows, using Scan to assign column data to struct fields.
 	for rows.Next() {
-		var p userEntity
+		// the address decrypts with the encryptor of e
+		// for the id of the row
+		p := userEntity{}
+		p.Address = e.Address.WithKey(&p.Id)
 
 		// fmt.Printf("Pointer: %p\n", &e)
-		if err := rows.Scan(&p.Id, &p.Name, &p.Address, &p.IsArchived, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		if err := rows.Scan(p.dest(columns)...); err != nil {
 			log.Println("error: ", err)
 			return nil, errorext.BuildDBError(err)
 		}
@@ -92,6 +136,7 @@ func (e *userEntity) scanMany(rows *sql.Rows) ([]userEntity, error) {
 
 	for rows.Next() {
 		entity := userEntity{}
+		entity.Address = e.Address.WithKey(&entity.Id)
 		pointers := make([]any, len(columnNames))
 		// pointers array's index
 		j := 0
@@ -143,3 +188,23 @@ func (e *userEntity) scanMany(rows *sql.Rows) ([]userEntity, error) {
 
 	return d, nil
 }
+
+// scanUser scans the current row to the domain entity
+// used by the streaming read
+func (e *userEntity) scanUser(rows *sql.Rows) (user.User, error) {
+	u := userEntity{}
+	u.Address = e.Address.WithKey(&u.Id)
+	if err := rows.Scan(&u.Id, &u.Name, &u.Address, &u.IsArchived, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		log.Println("error: ", err)
+		return user.User{}, errorext.BuildDBError(err)
+	}
+
+	return user.User{
+		ID:         u.Id,
+		Name:       u.Name,
+		Address:    &u.Address.String,
+		IsArchived: u.IsArchived,
+		CreatedAt:  u.CreatedAt,
+		UpdatedAt:  u.UpdatedAt,
+	}, nil
+}
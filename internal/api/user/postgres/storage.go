@@ -4,32 +4,48 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"iter"
 	"log"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
 )
 
 const tableName = "users"
 
 // storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
+// the address is stored encrypted with enc
 type storage struct {
-	db *sql.DB
+	db  *sql.DB
+	enc sqlext.Encryptor
 }
 
-func NewStorage(db *sql.DB) *storage {
-	return &storage{db: db}
+func NewStorage(db *sql.DB, enc sqlext.Encryptor) *storage {
+	return &storage{db: db, enc: enc}
+}
+
+// newEntity returns the entity the rows are scanned to
+func (s *storage) newEntity() *userEntity {
+	return newUserEntity(s.enc, "", "", nil, 0, 0)
 }
 
 func (s *storage) Create(ctx context.Context, payload user.CreateDTO, args ...any) (string, error) {
 	var lastID string
 
 	// build insert query
-	q := sqlext.BuildInsertQuery(tableName, []string{"name", "address", "created_at", "updated_at"}, "RETURNING id")
+	q := sqlext.BuildInsertQuery(tableName, []string{"id", "name", "address", "created_at", "updated_at"}, "RETURNING id")
+
+	// execute the query, the id is generated here as
+	// the address is encrypted for the row of the id
+	e := newUserEntity(s.enc, uuid.NewString(), payload.Name, payload.Address, payload.CreatedAt, payload.UpdatedAt)
 
-	// execute the query
-	row := s.db.QueryRowContext(ctx, q, payload.Name, payload.Address, payload.CreatedAt, payload.UpdatedAt)
+	row := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, e.Id, e.Name, e.Address, e.CreatedAt, e.UpdatedAt)
 	err := row.Err()
 	if err != nil {
 		log.Printf("err: %v", err)
@@ -47,10 +63,14 @@ func (s *storage) Create(ctx context.Context, payload user.CreateDTO, args ...an
 	return lastID, nil
 }
 
+// ReadMany reads a page of the entities matching the args
+// only the columns of the fieldset of the context are selected
 func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any) ([]user.User, error) {
 	d := make([]user.User, 0)
 
-	q := fmt.Sprintf("SELECT id, name, address, is_archived, created_at, updated_at FROM %s", tableName)
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
+
+	q := fmt.Sprintf("SELECT %s FROM %s", strings.Join(projections, ", "), tableName)
 	vals := make([]any, 0)
 
 	if len(args) > 0 && args[0] != nil {
@@ -61,7 +81,7 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 	q += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(vals)+1, len(vals)+2)
 	vals = append(vals, limit, offset)
 
-	rows, err := s.db.QueryContext(ctx, q, vals...)
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, vals...)
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return d, err
@@ -70,8 +90,8 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 	defer rows.Close()
 
 	// scan the rows
-	entity := &userEntity{}
-	users, err := entity.scanRows(rows)
+	entity := s.newEntity()
+	users, err := entity.scanRows(rows, projections)
 	if err != nil {
 		return d, err
 	}
@@ -91,20 +111,106 @@ func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any)
 	return d, nil
 }
 
+// ReadByIDs reads the entities of the ids in one query
+// only the columns of the fieldset of the context are selected
+func (s *storage) ReadByIDs(ctx context.Context, ids []string, args ...any) ([]user.User, error) {
+	d := make([]user.User, 0, len(ids))
+
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
+
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE id = ANY($1)", strings.Join(projections, ", "), tableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, ids)
+	if err != nil {
+		err := errorext.BuildDBError(err)
+		return d, err
+	}
+
+	defer rows.Close()
+
+	// scan the rows
+	entity := s.newEntity()
+	users, err := entity.scanRows(rows, projections)
+	if err != nil {
+		return d, err
+	}
+
+	// convert postgres entity to domain entity
+	for _, u := range users {
+		d = append(d, user.User{
+			ID:         u.Id,
+			Name:       u.Name,
+			Address:    &u.Address.String,
+			IsArchived: u.IsArchived,
+			CreatedAt:  u.CreatedAt,
+			UpdatedAt:  u.UpdatedAt,
+		})
+	}
+
+	return d, nil
+}
+
+// Count counts the entities matching the args
+func (s *storage) Count(ctx context.Context, args ...any) (int64, error) {
+	var n int64
+
+	q := fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)
+	vals := make([]any, 0)
+
+	if len(args) > 0 && args[0] != nil {
+		q += " WHERE is_archived = $1"
+		vals = append(vals, args[0].(bool))
+	}
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, vals...).Scan(&n)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+// Estimate returns the estimated number of entities, see sqlext.Estimate
+func (s *storage) Estimate(ctx context.Context) (int64, error) {
+	n, err := sqlext.Estimate(ctx, sqlext.Conn(ctx, s.db), tableName)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+func (s *storage) Stream(ctx context.Context, args ...any) iter.Seq2[user.User, error] {
+	q := fmt.Sprintf("SELECT id, name, address, is_archived, created_at, updated_at FROM %s", tableName)
+	vals := make([]any, 0)
+
+	if len(args) > 0 && args[0] != nil {
+		q += " WHERE is_archived = $1"
+		vals = append(vals, args[0].(bool))
+	}
+
+	// stable order so the export can be compared
+	q += " ORDER BY created_at, id"
+
+	return sqlext.Stream(ctx, s.db, q, constant.StreamBatchSize, s.newEntity().scanUser, vals...)
+}
+
+// ReadOne reads the entity of the id
+// only the columns of the fieldset of the context are selected
 func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (user.User, error) {
-	projections := []string{"id", "name", "description", "is_archived", "created_at", "updated_at"}
+	projections := fieldset.FromContext(ctx).Columns(columns, fieldColumns, "id")
 
 	q := sqlext.BuildSelectQuery(tableName, projections, []string{"id"}, "LIMIT $2")
 
-	row := s.db.QueryRowContext(ctx, q, id, 1)
+	row := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, id, 1)
 	err := row.Err()
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return user.User{}, err
 	}
 
-	entity := &userEntity{}
-	err = entity.scanRow(row)
+	entity := s.newEntity()
+	err = entity.scanRow(row, projections)
 	if err != nil {
 		return user.User{}, err
 	}
@@ -121,9 +227,11 @@ func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (user.Use
 }
 
 func (s *storage) Update(ctx context.Context, id string, payload user.UpdateDTO, args ...any) (int64, error) {
-	q := sqlext.BuildUpdateQuery(tableName, []string{"name", "description", "updated_at"}, []string{"id"}, "")
+	q := sqlext.BuildUpdateQuery(tableName, []string{"name", "address", "updated_at"}, []string{"id"}, "")
+
+	address := sqlext.NewEncryptedString(s.enc, AddressColumn, &id, payload.Address)
 
-	res, err := s.db.ExecContext(ctx, q, payload.Name, payload.Address, payload.UpdatedAt, id)
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, payload.Name, address, payload.UpdatedAt, id)
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return -1, err
@@ -135,7 +243,19 @@ func (s *storage) Update(ctx context.Context, id string, payload user.UpdateDTO,
 func (s *storage) Delete(ctx context.Context, id string, args ...any) (int64, error) {
 	q := sqlext.BuildUpdateQuery(tableName, []string{"is_archived", "updated_at"}, []string{"id"}, "")
 
-	res, err := s.db.ExecContext(ctx, q, true, args[0].(int64), id)
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, true, args[0].(int64), id)
+	if err != nil {
+		err := errorext.BuildDBError(err)
+		return -1, err
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) Purge(ctx context.Context, id string, args ...any) (int64, error) {
+	q := sqlext.BuildDeleteQuery(tableName, []string{"id"}, "")
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, id)
 	if err != nil {
 		err := errorext.BuildDBError(err)
 		return -1, err
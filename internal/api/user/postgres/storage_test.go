@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"database/sql/driver"
 	"regexp"
 	"testing"
 	"time"
@@ -12,8 +13,56 @@ import (
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/postgres"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
 )
 
+// newKeyRing returns a key ring of a test key
+func newKeyRing(t *testing.T) *envelope.KeyRing {
+	t.Helper()
+
+	keys, err := envelope.NewKeyRing("test", map[string][]byte{"test": make([]byte, constant.EncryptionKeyBytes)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+// rowID matches any id arg and stores it to id
+type rowID struct{ id *string }
+
+// Match implements the sqlmock.Argument interface
+func (a rowID) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	*a.id = s
+
+	return ok && s != ""
+}
+
+// encryptedAddress matches the encrypted address arg
+// of the plaintext of the row of the id
+type encryptedAddress struct {
+	keys      *envelope.KeyRing
+	id        *string
+	plaintext *string
+}
+
+// Match implements the sqlmock.Argument interface
+func (a encryptedAddress) Match(v driver.Value) bool {
+	if a.plaintext == nil {
+		return v == nil
+	}
+
+	s, ok := v.(string)
+	if !ok || !a.keys.IsEncrypted(s) {
+		return false
+	}
+
+	b, err := a.keys.Decrypt(s, postgres.AddressColumn.AAD(*a.id))
+
+	return err == nil && string(b) == *a.plaintext
+}
+
 func TestStorage(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -22,7 +71,9 @@ func TestStorage(t *testing.T) {
 		defer db.Close()
 	})
 
-	s := postgres.NewStorage(db)
+	keys := newKeyRing(t)
+
+	s := postgres.NewStorage(db, keys)
 
 	// inserted ids stored for later use
 	// var insertedIDs [2]string
@@ -68,10 +119,12 @@ func TestStorage(t *testing.T) {
 		}
 
 		for _, tc := range tests {
+			var rowIDArg string
+
 			mock.ExpectQuery(regexp.QuoteMeta(
-				`INSERT INTO users (name, address, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id`,
+				`INSERT INTO users (id, name, address, created_at, updated_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
 			)).
-				WithArgs(tc.dto.Name, tc.dto.Address, tc.dto.CreatedAt, tc.dto.UpdatedAt).
+				WithArgs(rowID{&rowIDArg}, tc.dto.Name, encryptedAddress{keys, &rowIDArg, tc.dto.Address}, tc.dto.CreatedAt, tc.dto.UpdatedAt).
 				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(id))
 
 			gotID, err := s.Create(context.Background(), tc.dto)
@@ -226,7 +279,7 @@ func TestStorage(t *testing.T) {
 				mock.ExpectExec(regexp.QuoteMeta(
 					"UPDATE users SET name = $1, address = $2, updated_at = $3 WHERE id = $4",
 				)).
-					WithArgs(tc.dto.Name, tc.dto.Address, tc.dto.UpdatedAt, ids[i]).
+					WithArgs(tc.dto.Name, encryptedAddress{keys, &ids[i], tc.dto.Address}, tc.dto.UpdatedAt, ids[i]).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 
 				rowsAffected, err := s.Update(context.Background(), ids[i], tc.dto)
@@ -289,7 +342,8 @@ func TestStorage_Create(t *testing.T) {
 		t.Fatalf("error opening stub db: %v", err)
 	}
 	defer db.Close()
-	storage := postgres.NewStorage(db)
+	keys := newKeyRing(t)
+	storage := postgres.NewStorage(db, keys)
 
 	addr := "dummy address"
 
@@ -300,9 +354,11 @@ func TestStorage_Create(t *testing.T) {
 		UpdatedAt: 1234567890,
 	}
 
+	var rowIDArg string
+
 	query := "INSERT INTO users"
 	mock.ExpectQuery(query).
-		WithArgs(dto.Name, dto.Address, dto.CreatedAt, dto.UpdatedAt).
+		WithArgs(rowID{&rowIDArg}, dto.Name, encryptedAddress{keys, &rowIDArg, dto.Address}, dto.CreatedAt, dto.UpdatedAt).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1"))
 
 	id, err := storage.Create(context.Background(), dto)
@@ -313,3 +369,30 @@ func TestStorage_Create(t *testing.T) {
 		t.Errorf("expected id '1', got '%s'", id)
 	}
 }
+
+func TestStorage_ReadOneCopiedAddress(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening stub db: %v", err)
+	}
+	defer db.Close()
+	keys := newKeyRing(t)
+	storage := postgres.NewStorage(db, keys)
+
+	id, otherID := uuid.NewString(), uuid.NewString()
+
+	// the address of another user copied to the row
+	address, err := keys.Encrypt([]byte("dummy address"), postgres.AddressColumn.AAD(otherID))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, name, address, is_archived, created_at, updated_at FROM users WHERE id = $1 LIMIT $2")).
+		WithArgs(id, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "address", "is_archived", "created_at", "updated_at"}).
+			AddRow(id, "John", address, false, 1234567890, 1234567890))
+
+	if _, err := storage.ReadOne(context.Background(), id); err == nil {
+		t.Error("expected the address of another row to fail to decrypt")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/postgres"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
 )
 
 // Provider contains and initializes the components of the package
@@ -14,9 +15,9 @@ type Provider struct {
 	Repository user.Repository
 }
 
-// New initializes a Provider
-func New(db *sql.DB) Provider {
-	r := postgres.NewStorage(db)
-	u := service.NewService(r)
+// New initializes a Provider, enc encrypts the personal data
+func New(db *sql.DB, enc sqlext.Encryptor, opts ...service.Option) Provider {
+	r := postgres.NewStorage(db, enc)
+	u := service.NewService(r, opts...)
 	return Provider{UseCase: u, Repository: r}
 }
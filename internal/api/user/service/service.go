@@ -3,13 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"iter"
 	"net/http"
 	"time"
 
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
-	"github.com/tanveerprottoy/backend-structure-go/pkg/util"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
 )
 
 // service implements the use case for user
@@ -17,11 +20,40 @@ import (
 // repository to perform db operations
 type service struct {
 	repository user.Repository
+	// authorizer authorizes the callers, nil disables the checks
+	authorizer rbac.Authorizer
+}
+
+// Option configures the service
+type Option func(*service)
+
+// WithAuthorizer checks the users permissions of the callers, an own grant
+// applies to the user of the caller itself
+func WithAuthorizer(a rbac.Authorizer) Option {
+	return func(s *service) {
+		s.authorizer = a
+	}
 }
 
 // NewService initializes a new Service
-func NewService(r user.Repository) *service {
-	return &service{repository: r}
+func NewService(r user.Repository, opts ...Option) *service {
+	s := &service{repository: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// authorize checks the permission of the caller of ctx
+// a user is the owner of itself, owner is empty for the
+// collection
+func (s *service) authorize(ctx context.Context, permission, owner string) error {
+	if s.authorizer == nil {
+		return nil
+	}
+
+	return s.authorizer.Authorize(ctx, permission, owner)
 }
 
 // readOneInternal fetches one entity from db
@@ -36,6 +68,11 @@ func (s *service) readOneInternal(ctx context.Context, id string) (user.User, er
 
 // create defines the business logic for create post request
 func (s *service) Create(ctx context.Context, payload user.CreateDTO) (user.User, error) {
+	err := s.authorize(ctx, role.PermissionUsersWrite, "")
+	if err != nil {
+		return user.User{}, err
+	}
+
 	// build entity
 	n := time.Now().Unix()
 
@@ -57,7 +94,12 @@ func (s *service) Create(ctx context.Context, payload user.CreateDTO) (user.User
 }
 
 func (s *service) ReadMany(ctx context.Context, limit, page int, args ...any) ([]user.User, error) {
-	offset := util.CalculateOffset(limit, page)
+	err := s.authorize(ctx, role.PermissionUsersRead, "")
+	if err != nil {
+		return nil, err
+	}
+
+	offset := pagination.Page{Limit: limit, Number: max(page, 1)}.Offset()
 
 	d, err := s.repository.ReadMany(ctx, limit, offset, args...)
 	if err != nil {
@@ -67,7 +109,53 @@ func (s *service) ReadMany(ctx context.Context, limit, page int, args ...any) ([
 	return d, nil
 }
 
+// Count returns the total of the entities matching the args
+func (s *service) Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error) {
+	err := s.authorize(ctx, role.PermissionUsersRead, "")
+	if err != nil {
+		return pagination.Total{}, err
+	}
+
+	t, err := policy.Total(
+		ctx,
+		func(ctx context.Context) (int64, error) { return s.repository.Count(ctx, args...) },
+		s.repository.Estimate,
+	)
+	if err != nil {
+		return t, errorext.BuildCustomError(err)
+	}
+
+	return t, nil
+}
+
+// Stream reads all the entities matching the args
+// the errors are converted to custom errors
+func (s *service) Stream(ctx context.Context, args ...any) iter.Seq2[user.User, error] {
+	return func(yield func(user.User, error) bool) {
+		if err := s.authorize(ctx, role.PermissionUsersRead, ""); err != nil {
+			yield(user.User{}, err)
+			return
+		}
+
+		for e, err := range s.repository.Stream(ctx, args...) {
+			if err != nil {
+				yield(e, errorext.BuildCustomError(err))
+				return
+			}
+
+			if !yield(e, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (s *service) ReadOne(ctx context.Context, id string) (user.User, error) {
+	err := s.authorize(ctx, role.PermissionUsersRead, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
@@ -76,7 +164,34 @@ func (s *service) ReadOne(ctx context.Context, id string) (user.User, error) {
 	return e, nil
 }
 
+// ReadByIDs reads the entities of the ids, an empty ids
+// is answered without calling the repository, the caller
+// needs the read permission on each of the ids
+func (s *service) ReadByIDs(ctx context.Context, ids []string) ([]user.User, error) {
+	for _, id := range ids {
+		if err := s.authorize(ctx, role.PermissionUsersRead, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ids) == 0 {
+		return []user.User{}, nil
+	}
+
+	d, err := s.repository.ReadByIDs(ctx, ids)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
 func (s *service) Update(ctx context.Context, id string, payload user.UpdateDTO) (user.User, error) {
+	err := s.authorize(ctx, role.PermissionUsersWrite, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
@@ -103,6 +218,11 @@ func (s *service) Update(ctx context.Context, id string, payload user.UpdateDTO)
 }
 
 func (s *service) Delete(ctx context.Context, id string) (user.User, error) {
+	err := s.authorize(ctx, role.PermissionUsersDelete, id)
+	if err != nil {
+		return user.User{}, err
+	}
+
 	e, err := s.readOneInternal(ctx, id)
 	if err != nil {
 		return e, err
@@ -122,3 +242,29 @@ func (s *service) Delete(ctx context.Context, id string) (user.User, error) {
 
 	return e, errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
 }
+
+// Purge removes the entity for good, its credentials, sessions,
+// identities, api keys and roles are removed by the cascades of
+// the database, Delete archives it
+func (s *service) Purge(ctx context.Context, id string) error {
+	err := s.authorize(ctx, role.PermissionUsersPurge, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.readOneInternal(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := s.repository.Purge(ctx, id)
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	if rowCount > 0 {
+		return nil
+	}
+
+	return errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
+}
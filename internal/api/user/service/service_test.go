@@ -2,12 +2,17 @@ package service_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/role"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/mock"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/user/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
 )
 
 func TestService(t *testing.T) {
@@ -238,3 +243,28 @@ func TestService(t *testing.T) {
 		}
 	})
 }
+
+func TestServicePurge(t *testing.T) {
+	s := service.NewService(mock.NewMemoryStorage(), service.WithAuthorizer(rbac.NewPolicy(role.Grants(role.Defaults))))
+
+	admin := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "admin-1", Method: auth.MethodJWT, Roles: []string{role.Admin}})
+
+	c, err := s.Create(admin, user.CreateDTO{Name: "name 1"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// the owner can't purge itself, the admins purge the users
+	owner := auth.WithPrincipal(context.Background(), auth.Principal{Subject: c.ID, Method: auth.MethodJWT, Roles: []string{role.Customer}})
+	if err := s.Purge(owner, c.ID); err == nil || errorext.ParseCustomError(err).Code() != http.StatusForbidden {
+		t.Fatalf("expected 403 for the owner, got %v", err)
+	}
+
+	if err := s.Purge(admin, c.ID); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	if _, err := s.ReadOne(admin, c.ID); err == nil {
+		t.Error("expected the purged entity to be removed")
+	}
+}
@@ -2,16 +2,35 @@ package user
 
 import (
 	"context"
+	"iter"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
 )
 
 type UseCase interface {
 	Create(ctx context.Context, payload CreateDTO) (User, error)
 
+	// ReadMany reads a page of the entities, limit is
+	// the effective limit of the pagination policy
 	ReadMany(ctx context.Context, limit, page int, args ...any) ([]User, error)
 
+	// Count returns the total of the entities matching the args
+	// with the count mode of the policy
+	Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error)
+
+	Stream(ctx context.Context, args ...any) iter.Seq2[User, error]
+
 	ReadOne(ctx context.Context, id string) (User, error)
 
+	// ReadByIDs reads the entities of the ids, used to batch
+	// the reads of the graphql queries, see dataloader
+	ReadByIDs(ctx context.Context, ids []string) ([]User, error)
+
 	Update(ctx context.Context, id string, payload UpdateDTO) (User, error)
 
 	Delete(ctx context.Context, id string) (User, error)
+
+	// Purge removes the entity for good with its credentials,
+	// sessions, identities, api keys and roles
+	Purge(ctx context.Context, id string) error
 }
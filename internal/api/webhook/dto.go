@@ -0,0 +1,17 @@
+package webhook
+
+type CreateDTO struct {
+	URL       string
+	Events    []string
+	Secret    string
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+type UpdateDTO struct {
+	URL    string
+	Events []string
+	// Enabled re-enables a disabled subscription
+	Enabled   bool
+	UpdatedAt int64
+}
@@ -0,0 +1,230 @@
+package mock
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// MemoryStorage is a mock storage
+// can be used to mock the repository
+// for service testing, it is safe for
+// the concurrent use of the dispatcher
+type MemoryStorage struct {
+	mu         sync.Mutex
+	seq        int
+	subs       map[string]*webhook.Subscription
+	deliveries []*webhook.Delivery
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{subs: make(map[string]*webhook.Subscription)}
+}
+
+func (s *MemoryStorage) nextID() string {
+	s.seq++
+	return strconv.Itoa(s.seq)
+}
+
+func (s *MemoryStorage) Create(ctx context.Context, payload webhook.CreateDTO, args ...any) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID()
+	s.subs[id] = &webhook.Subscription{
+		ID:        id,
+		URL:       payload.URL,
+		Events:    payload.Events,
+		Secret:    payload.Secret,
+		Enabled:   true,
+		CreatedAt: payload.CreatedAt,
+		UpdatedAt: payload.UpdatedAt,
+	}
+
+	return id, nil
+}
+
+func (s *MemoryStorage) ReadMany(ctx context.Context, limit, offset int, args ...any) ([]webhook.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entities := make([]webhook.Subscription, 0, len(s.subs))
+	for _, v := range s.subs {
+		entities = append(entities, *v)
+	}
+
+	return entities, nil
+}
+
+func (s *MemoryStorage) Count(ctx context.Context, args ...any) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.subs)), nil
+}
+
+func (s *MemoryStorage) ReadOne(ctx context.Context, id string, args ...any) (webhook.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.subs[id]; ok {
+		return *e, nil
+	}
+
+	return webhook.Subscription{}, errorext.BuildDBError(sql.ErrNoRows)
+}
+
+func (s *MemoryStorage) Update(ctx context.Context, id string, payload webhook.UpdateDTO, args ...any) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.subs[id]
+	if !ok {
+		return 0, nil
+	}
+
+	if payload.Enabled && !e.Enabled {
+		e.Failures = 0
+	}
+
+	e.URL = payload.URL
+	e.Events = payload.Events
+	e.Enabled = payload.Enabled
+	e.UpdatedAt = payload.UpdatedAt
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, id string, args ...any) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.subs[id]; !ok {
+		return 0, nil
+	}
+
+	delete(s.subs, id)
+	s.deliveries = slices.DeleteFunc(s.deliveries, func(d *webhook.Delivery) bool { return d.SubscriptionID == id })
+
+	return 1, nil
+}
+
+func (s *MemoryStorage) ReadSubscribed(ctx context.Context, eventType string) ([]webhook.Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var subs []webhook.Subscription
+	for _, v := range s.subs {
+		if v.Enabled && v.Subscribes(eventType) {
+			subs = append(subs, *v)
+		}
+	}
+
+	return subs, nil
+}
+
+func (s *MemoryStorage) RecordResult(ctx context.Context, id string, success bool, disableAfter int, now int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.subs[id]
+	if !ok {
+		return false, nil
+	}
+
+	e.UpdatedAt = now
+
+	if success {
+		e.Failures = 0
+		return false, nil
+	}
+
+	e.Failures++
+	if e.Enabled && e.Failures >= disableAfter {
+		e.Enabled = false
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *MemoryStorage) CreateDelivery(ctx context.Context, d webhook.Delivery) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d.ID = s.nextID()
+	s.deliveries = append(s.deliveries, &d)
+
+	return d.ID, nil
+}
+
+// ReadDeliveries reads the deliveries in the order of creation
+func (s *MemoryStorage) ReadDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d := make([]webhook.Delivery, 0)
+	for _, v := range s.deliveries {
+		if v.SubscriptionID == subscriptionID {
+			d = append(d, *v)
+		}
+	}
+
+	return d, nil
+}
+
+func (s *MemoryStorage) CountDeliveries(ctx context.Context, subscriptionID string) (int64, error) {
+	d, _ := s.ReadDeliveries(ctx, subscriptionID, 0, 0)
+	return int64(len(d)), nil
+}
+
+func (s *MemoryStorage) ReadDelivery(ctx context.Context, subscriptionID, id string) (webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, v := range s.deliveries {
+		if v.ID == id && v.SubscriptionID == subscriptionID {
+			return *v, nil
+		}
+	}
+
+	return webhook.Delivery{}, errorext.BuildDBError(sql.ErrNoRows)
+}
+
+func (s *MemoryStorage) ClaimDue(ctx context.Context, now, leaseUntil int64, limit int) ([]webhook.Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var d []webhook.Delivery
+	for _, v := range s.deliveries {
+		if len(d) == limit {
+			break
+		}
+
+		if v.Status == webhook.StatusPending && v.NextAttemptAt <= now {
+			v.NextAttemptAt = leaseUntil
+			d = append(d, *v)
+		}
+	}
+
+	return d, nil
+}
+
+func (s *MemoryStorage) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, v := range s.deliveries {
+		if v.ID == d.ID {
+			s.deliveries[i] = &d
+			return nil
+		}
+	}
+
+	return errorext.BuildDBError(sql.ErrNoRows)
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"database/sql"
+	"log"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+// columns contains the columns of the subscription in the select order
+var columns = []string{"id", "url", "events", "secret", "enabled", "failures", "created_at", "updated_at"}
+
+// deliveryColumns contains the columns of the delivery in the select order
+var deliveryColumns = []string{"id", "subscription_id", "event", "payload", "status", "attempts", "response_status", "error", "next_attempt_at", "created_at", "updated_at"}
+
+// scanner is implemented by sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// a package private entity clone of the domain entity
+// events is a jsonb array
+type subscriptionEntity struct {
+	id        string
+	url       string
+	events    sqlext.JsonStringArray
+	secret    string
+	enabled   bool
+	failures  int
+	createdAt int64
+	updatedAt int64
+}
+
+func scanSubscription(s scanner) (webhook.Subscription, error) {
+	var e subscriptionEntity
+	if err := s.Scan(&e.id, &e.url, &e.events, &e.secret, &e.enabled, &e.failures, &e.createdAt, &e.updatedAt); err != nil {
+		log.Println("error: ", err)
+		return webhook.Subscription{}, errorext.BuildDBError(err)
+	}
+
+	return webhook.Subscription{
+		ID:        e.id,
+		URL:       e.url,
+		Events:    e.events,
+		Secret:    e.secret,
+		Enabled:   e.enabled,
+		Failures:  e.failures,
+		CreatedAt: e.createdAt,
+		UpdatedAt: e.updatedAt,
+	}, nil
+}
+
+func scanDelivery(s scanner) (webhook.Delivery, error) {
+	var (
+		d      webhook.Delivery
+		status string
+	)
+
+	err := s.Scan(&d.ID, &d.SubscriptionID, &d.Event, &d.Payload, &status, &d.Attempts, &d.ResponseStatus, &d.Error, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		log.Println("error: ", err)
+		return d, errorext.BuildDBError(err)
+	}
+
+	d.Status = webhook.DeliveryStatus(status)
+
+	return d, nil
+}
+
+// scanAll scans the rows with scan
+func scanAll[T any](rows *sql.Rows, scan func(scanner) (T, error)) ([]T, error) {
+	d := make([]T, 0)
+
+	for rows.Next() {
+		e, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		d = append(d, e)
+	}
+
+	// Check for errors from iterating over rows.
+	if err := rows.Err(); err != nil {
+		log.Println("error: ", err)
+		return nil, errorext.BuildDBError(err)
+	}
+
+	return d, nil
+}
@@ -0,0 +1,234 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+const (
+	tableName         = "webhook_subscriptions"
+	deliveryTableName = "webhook_deliveries"
+)
+
+// storage implements the storage interface
+// the queries run in the transaction of the context if any, see sqlext.Conn
+type storage struct {
+	db *sql.DB
+}
+
+func NewStorage(db *sql.DB) *storage {
+	return &storage{db: db}
+}
+
+func (s *storage) Create(ctx context.Context, payload webhook.CreateDTO, args ...any) (string, error) {
+	var lastID string
+
+	events, err := json.Marshal(payload.Events)
+	if err != nil {
+		return lastID, err
+	}
+
+	q := sqlext.BuildInsertQuery(tableName, []string{"url", "events", "secret", "created_at", "updated_at"}, "RETURNING id")
+
+	err = sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, payload.URL, events, payload.Secret, payload.CreatedAt, payload.UpdatedAt).Scan(&lastID)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return lastID, errorext.BuildDBError(err)
+	}
+
+	return lastID, nil
+}
+
+func (s *storage) ReadMany(ctx context.Context, limit, offset int, args ...any) ([]webhook.Subscription, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s ORDER BY created_at, id LIMIT $1 OFFSET $2", strings.Join(columns, ", "), tableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, limit, offset)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	return scanAll(rows, scanSubscription)
+}
+
+func (s *storage) Count(ctx context.Context, args ...any) (int64, error) {
+	var n int64
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", tableName)).Scan(&n)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+func (s *storage) ReadOne(ctx context.Context, id string, args ...any) (webhook.Subscription, error) {
+	q := sqlext.BuildSelectQuery(tableName, columns, []string{"id"}, "")
+
+	return scanSubscription(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, id))
+}
+
+// Update updates the entity, enabling a disabled entity resets its failures
+func (s *storage) Update(ctx context.Context, id string, payload webhook.UpdateDTO, args ...any) (int64, error) {
+	events, err := json.Marshal(payload.Events)
+	if err != nil {
+		return -1, err
+	}
+
+	q := fmt.Sprintf(
+		"UPDATE %s SET url = $1, events = $2, failures = CASE WHEN $3 AND NOT enabled THEN 0 ELSE failures END, enabled = $3, updated_at = $4 WHERE id = $5",
+		tableName,
+	)
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, payload.URL, events, payload.Enabled, payload.UpdatedAt, id)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+// Delete removes the entity, its deliveries are removed by the foreign key
+func (s *storage) Delete(ctx context.Context, id string, args ...any) (int64, error) {
+	q := sqlext.BuildDeleteQuery(tableName, []string{"id"}, "")
+
+	res, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, id)
+	if err != nil {
+		return -1, errorext.BuildDBError(err)
+	}
+
+	return sqlext.GetRowsAffected(res), nil
+}
+
+func (s *storage) ReadSubscribed(ctx context.Context, eventType string) ([]webhook.Subscription, error) {
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE enabled AND events @> jsonb_build_array($1::text)", strings.Join(columns, ", "), tableName)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, eventType)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	return scanAll(rows, scanSubscription)
+}
+
+func (s *storage) RecordResult(ctx context.Context, id string, success bool, disableAfter int, now int64) (bool, error) {
+	var (
+		disabled bool
+		q        string
+		args     []any
+	)
+
+	if success {
+		q = fmt.Sprintf("UPDATE %s SET failures = 0, updated_at = $1 WHERE id = $2 RETURNING false", tableName)
+		args = []any{now, id}
+	} else {
+		// the old enabled is read in the where clause of the row
+		q = fmt.Sprintf(
+			"UPDATE %s SET failures = failures + 1, enabled = enabled AND failures + 1 < $1, updated_at = $2 WHERE id = $3 RETURNING (NOT enabled AND failures = $1)",
+			tableName,
+		)
+		args = []any{disableAfter, now, id}
+	}
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, args...).Scan(&disabled)
+	if err != nil {
+		return false, errorext.BuildDBError(err)
+	}
+
+	return disabled, nil
+}
+
+func (s *storage) CreateDelivery(ctx context.Context, d webhook.Delivery) (string, error) {
+	var lastID string
+
+	q := sqlext.BuildInsertQuery(deliveryTableName, []string{"subscription_id", "event", "payload", "status", "next_attempt_at", "created_at", "updated_at"}, "RETURNING id")
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, d.SubscriptionID, d.Event, d.Payload, string(d.Status), d.NextAttemptAt, d.CreatedAt, d.UpdatedAt).Scan(&lastID)
+	if err != nil {
+		log.Printf("err: %v", err)
+		return lastID, errorext.BuildDBError(err)
+	}
+
+	return lastID, nil
+}
+
+// ReadDeliveries reads the deliveries, the latest first
+func (s *storage) ReadDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]webhook.Delivery, error) {
+	q := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE subscription_id = $1 ORDER BY created_at DESC, id LIMIT $2 OFFSET $3",
+		strings.Join(deliveryColumns, ", "),
+		deliveryTableName,
+	)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	return scanAll(rows, scanDelivery)
+}
+
+func (s *storage) CountDeliveries(ctx context.Context, subscriptionID string) (int64, error) {
+	var n int64
+
+	q := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE subscription_id = $1", deliveryTableName)
+
+	err := sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, subscriptionID).Scan(&n)
+	if err != nil {
+		return n, errorext.BuildDBError(err)
+	}
+
+	return n, nil
+}
+
+func (s *storage) ReadDelivery(ctx context.Context, subscriptionID, id string) (webhook.Delivery, error) {
+	q := sqlext.BuildSelectQuery(deliveryTableName, deliveryColumns, []string{"subscription_id", "id"}, "")
+
+	return scanDelivery(sqlext.Conn(ctx, s.db).QueryRowContext(ctx, q, subscriptionID, id))
+}
+
+// ClaimDue leases the due deliveries, the locked rows are
+// skipped so that the dispatchers claim different deliveries
+func (s *storage) ClaimDue(ctx context.Context, now, leaseUntil int64, limit int) ([]webhook.Delivery, error) {
+	q := fmt.Sprintf(
+		`UPDATE %[1]s SET next_attempt_at = $2 WHERE id IN (
+			SELECT id FROM %[1]s WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED
+		) RETURNING %[2]s`,
+		deliveryTableName,
+		strings.Join(deliveryColumns, ", "),
+	)
+
+	rows, err := sqlext.Conn(ctx, s.db).QueryContext(ctx, q, now, leaseUntil, limit)
+	if err != nil {
+		return nil, errorext.BuildDBError(err)
+	}
+
+	defer rows.Close()
+
+	return scanAll(rows, scanDelivery)
+}
+
+func (s *storage) UpdateDelivery(ctx context.Context, d webhook.Delivery) error {
+	q := sqlext.BuildUpdateQuery(deliveryTableName, []string{"status", "attempts", "response_status", "error", "next_attempt_at", "updated_at"}, []string{"id"}, "")
+
+	_, err := sqlext.Conn(ctx, s.db).ExecContext(ctx, q, string(d.Status), d.Attempts, d.ResponseStatus, d.Error, d.NextAttemptAt, d.UpdatedAt, d.ID)
+	if err != nil {
+		return errorext.BuildDBError(err)
+	}
+
+	return nil
+}
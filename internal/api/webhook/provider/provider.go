@@ -0,0 +1,27 @@
+package provider
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+)
+
+// Provider contains and initializes the components of the package
+type Provider struct {
+	UseCase    webhook.UseCase
+	Repository webhook.Repository
+	Dispatcher *service.Dispatcher
+}
+
+// New initializes a Provider
+func New(db *sql.DB) Provider {
+	r := postgres.NewStorage(db)
+	u := service.NewService(r)
+	c := httpext.NewCustomClient(httpext.Config{Timeout: constant.WebhookTimeout * time.Second})
+	return Provider{UseCase: u, Repository: r, Dispatcher: service.NewDispatcher(r, c)}
+}
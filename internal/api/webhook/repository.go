@@ -0,0 +1,48 @@
+package webhook
+
+import "context"
+
+// Repository defines the data persistance logic that needs to be implemented
+type Repository interface {
+	Create(ctx context.Context, payload CreateDTO, args ...any) (string, error)
+
+	ReadMany(ctx context.Context, limit, offset int, args ...any) ([]Subscription, error)
+
+	// Count counts the entities matching the args
+	Count(ctx context.Context, args ...any) (int64, error)
+
+	ReadOne(ctx context.Context, id string, args ...any) (Subscription, error)
+
+	// Update updates the entity, the failures are reset
+	// when the entity is enabled
+	Update(ctx context.Context, id string, payload UpdateDTO, args ...any) (int64, error)
+
+	Delete(ctx context.Context, id string, args ...any) (int64, error)
+
+	// ReadSubscribed reads the enabled subscriptions of the event type
+	ReadSubscribed(ctx context.Context, eventType string) ([]Subscription, error)
+
+	// RecordResult stores the result of an attempt of the subscription
+	// a success resets the failures, a failure increments them and
+	// disables the subscription once they reach disableAfter
+	// disabled reports if the subscription was disabled by this call
+	RecordResult(ctx context.Context, id string, success bool, disableAfter int, now int64) (disabled bool, err error)
+
+	CreateDelivery(ctx context.Context, d Delivery) (string, error)
+
+	ReadDeliveries(ctx context.Context, subscriptionID string, limit, offset int) ([]Delivery, error)
+
+	CountDeliveries(ctx context.Context, subscriptionID string) (int64, error)
+
+	ReadDelivery(ctx context.Context, subscriptionID, id string) (Delivery, error)
+
+	// ClaimDue claims at most limit pending deliveries due at now
+	// their next attempt is moved to leaseUntil so that they are not
+	// claimed again while they are sent, a delivery of a crashed
+	// dispatcher is retried once its lease expires
+	ClaimDue(ctx context.Context, now, leaseUntil int64, limit int) ([]Delivery, error)
+
+	// UpdateDelivery stores the status, the attempts, the result
+	// and the next attempt of the delivery
+	UpdateDelivery(ctx context.Context, d Delivery) error
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/signature"
+)
+
+// maxErrorLen limits the error stored of an attempt
+const maxErrorLen = 512
+
+// DispatcherOption configures a Dispatcher
+type DispatcherOption func(*Dispatcher)
+
+// WithBackoff sets the delay before the attempt following
+// the given number of attempts
+func WithBackoff(f func(attempts int) time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.backoff = f
+	}
+}
+
+// WithMaxAttempts sets the attempts after which a delivery fails
+func WithMaxAttempts(n int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.maxAttempts = n
+	}
+}
+
+// WithDisableAfter sets the consecutive failed attempts
+// after which a subscription is disabled
+func WithDisableAfter(n int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.disableAfter = n
+	}
+}
+
+// WithClock sets the time source, used by the tests
+func WithClock(now func() time.Time) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.now = now
+	}
+}
+
+// Dispatcher sends the due deliveries to the subscriptions
+// the deliveries are claimed from the repository so that
+// several instances can dispatch at the same time
+type Dispatcher struct {
+	repository   webhook.Repository
+	client       httpext.Client
+	backoff      func(attempts int) time.Duration
+	maxAttempts  int
+	disableAfter int
+	now          func() time.Time
+}
+
+// NewDispatcher initializes a Dispatcher
+func NewDispatcher(r webhook.Repository, c httpext.Client, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		repository:   r,
+		client:       c,
+		backoff:      Backoff,
+		maxAttempts:  constant.WebhookMaxAttempts,
+		disableAfter: constant.WebhookDisableAfter,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Backoff is the default delay before the next attempt, the base
+// delay is doubled on every attempt up to the maximum
+func Backoff(attempts int) time.Duration {
+	base := constant.WebhookBackoffBase * time.Second
+	maxDelay := constant.WebhookBackoffMax * time.Second
+
+	if attempts < 1 {
+		return base
+	}
+
+	// avoid the overflow of the shift
+	if attempts > 20 {
+		return maxDelay
+	}
+
+	return min(base<<(attempts-1), maxDelay)
+}
+
+// Run dispatches the due deliveries every interval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if _, err := d.Dispatch(ctx); err != nil {
+				log.Printf("webhook dispatch returned error: %v", err)
+			}
+		}
+	}
+}
+
+// Dispatch claims the due deliveries and sends them
+// it returns the number of deliveries attempted
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	now := d.now()
+	lease := now.Add(2 * constant.WebhookTimeout * time.Second)
+
+	deliveries, err := d.repository.ClaimDue(ctx, now.Unix(), lease.Unix(), constant.WebhookClaimSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, constant.WebhookConcurrency)
+	)
+
+	for _, del := range deliveries {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			if err := d.attempt(ctx, del); err != nil {
+				log.Printf("webhook delivery %s returned error: %v", del.ID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery and records the result
+func (d *Dispatcher) attempt(ctx context.Context, del webhook.Delivery) error {
+	sub, err := d.repository.ReadOne(ctx, del.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+
+	if !sub.Enabled {
+		del.Status = webhook.StatusFailed
+		del.Error = constant.WebhookDisabled
+		del.UpdatedAt = now.Unix()
+		return d.repository.UpdateDelivery(ctx, del)
+	}
+
+	code, sendErr := d.send(ctx, sub, del, now)
+
+	del.Attempts++
+	del.ResponseStatus = code
+	del.Error = ""
+	del.UpdatedAt = d.now().Unix()
+
+	success := sendErr == nil
+	switch {
+	case success:
+		del.Status = webhook.StatusSucceeded
+	case del.Attempts >= d.maxAttempts:
+		del.Status = webhook.StatusFailed
+	default:
+		del.Status = webhook.StatusPending
+		del.NextAttemptAt = now.Add(d.backoff(del.Attempts)).Unix()
+	}
+
+	if sendErr != nil {
+		del.Error = truncate(sendErr.Error(), maxErrorLen)
+	}
+
+	if err := d.repository.UpdateDelivery(ctx, del); err != nil {
+		return err
+	}
+
+	disabled, err := d.repository.RecordResult(ctx, sub.ID, success, d.disableAfter, del.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if disabled {
+		log.Printf("webhook subscription %s disabled after %d consecutive failures", sub.ID, d.disableAfter)
+	}
+
+	return nil
+}
+
+// send posts the signed payload, a non 2xx status is an error
+func (d *Dispatcher) send(ctx context.Context, sub webhook.Subscription, del webhook.Delivery, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, constant.WebhookTimeout*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	ts := now.Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(constant.HeaderUserAgent, constant.WebhookUserAgent)
+	req.Header.Set(constant.HeaderWebhookId, del.ID)
+	req.Header.Set(constant.HeaderWebhookEvent, del.Event)
+	req.Header.Set(constant.HeaderWebhookTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(constant.HeaderWebhookSignature, signature.Header(sub.Secret, ts, del.Payload))
+
+	// the attempts are retried by the dispatcher
+	res, err := d.client.Do(req, false)
+	if err != nil {
+		return 0, err
+	}
+
+	// drain the body so the connection is reused
+	io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, &statusError{code: res.StatusCode}
+	}
+
+	return res.StatusCode, nil
+}
+
+// statusError is the error of a non 2xx response
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return "the endpoint responded with " + strconv.Itoa(e.code) + " " + http.StatusText(e.code)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	return s[:n]
+}
@@ -0,0 +1,286 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/signature"
+)
+
+// receiver is a webhook endpoint answering with status
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	rc := &receiver{status: status}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, r)
+		rc.bodies = append(rc.bodies, b)
+		status := rc.status
+		rc.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return rc, srv
+}
+
+func (rc *receiver) setStatus(status int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.status = status
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.requests)
+}
+
+// clock is a settable time source
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// setup creates a subscription of the receiver and enqueues an event
+// the deliveries are due at the time of the clock
+func setup(t *testing.T, url string, opts ...service.DispatcherOption) (*mock.MemoryStorage, *service.Dispatcher, *clock, webhook.Subscription) {
+	t.Helper()
+
+	r := mock.NewMemoryStorage()
+	s := service.NewService(r)
+	c := &clock{now: time.Now()}
+
+	sub, err := s.Create(context.Background(), webhook.CreateDTO{URL: url, Events: []string{product.EventCreated}})
+	if err != nil {
+		t.Fatalf("create returned error: %v", err)
+	}
+
+	err = s.Enqueue(context.Background(), product.EventCreated, map[string]string{"id": "p1"})
+	if err != nil {
+		t.Fatalf("enqueue returned error: %v", err)
+	}
+
+	client := httpext.NewCustomClient(httpext.Config{Timeout: 5 * time.Second})
+	opts = append([]service.DispatcherOption{service.WithClock(c.Now)}, opts...)
+
+	return r, service.NewDispatcher(r, client, opts...), c, sub
+}
+
+func deliveries(t *testing.T, r *mock.MemoryStorage, subscriptionID string) []webhook.Delivery {
+	t.Helper()
+
+	d, err := r.ReadDeliveries(context.Background(), subscriptionID, 10, 0)
+	if err != nil {
+		t.Fatalf("read deliveries returned error: %v", err)
+	}
+
+	return d
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("signed delivery", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusNoContent)
+		r, d, c, sub := setup(t, srv.URL)
+
+		n, err := d.Dispatch(context.Background())
+		if err != nil || n != 1 {
+			t.Fatalf("expected 1 delivery, got %d, %v", n, err)
+		}
+
+		if rc.count() != 1 {
+			t.Fatalf("expected 1 request, got %d", rc.count())
+		}
+
+		req, body := rc.requests[0], rc.bodies[0]
+
+		err = signature.Verify(sub.Secret, req.Header.Get(constant.HeaderWebhookSignature), body, c.Now(), 5*time.Minute)
+		if err != nil {
+			t.Errorf("expected a valid signature, got %v", err)
+		}
+
+		if req.Header.Get(constant.HeaderWebhookEvent) != product.EventCreated {
+			t.Errorf("expected event header %s, got %s", product.EventCreated, req.Header.Get(constant.HeaderWebhookEvent))
+		}
+
+		var p struct {
+			ID   string            `json:"id"`
+			Type string            `json:"type"`
+			Data map[string]string `json:"data"`
+		}
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Fatalf("unmarshal payload returned error: %v", err)
+		}
+
+		if p.ID == "" || p.Type != product.EventCreated || p.Data["id"] != "p1" {
+			t.Errorf("unexpected payload %s", body)
+		}
+
+		del := deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusSucceeded || del.Attempts != 1 || del.ResponseStatus != http.StatusNoContent {
+			t.Errorf("unexpected delivery %+v", del)
+		}
+
+		if req.Header.Get(constant.HeaderWebhookId) != del.ID {
+			t.Errorf("expected id header %s, got %s", del.ID, req.Header.Get(constant.HeaderWebhookId))
+		}
+
+		// nothing is due after the success
+		if n, _ := d.Dispatch(context.Background()); n != 0 {
+			t.Errorf("expected no delivery, got %d", n)
+		}
+	})
+
+	t.Run("backoff and recovery", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusInternalServerError)
+		backoff := func(attempts int) time.Duration { return time.Duration(attempts) * time.Minute }
+		r, d, c, sub := setup(t, srv.URL, service.WithBackoff(backoff))
+
+		d.Dispatch(context.Background())
+
+		del := deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusPending || del.Attempts != 1 || del.ResponseStatus != http.StatusInternalServerError || del.Error == "" {
+			t.Fatalf("unexpected delivery %+v", del)
+		}
+
+		if want := c.Now().Add(time.Minute).Unix(); del.NextAttemptAt != want {
+			t.Errorf("expected next attempt at %d, got %d", want, del.NextAttemptAt)
+		}
+
+		// not due before the backoff
+		if n, _ := d.Dispatch(context.Background()); n != 0 {
+			t.Errorf("expected no delivery before the backoff, got %d", n)
+		}
+
+		rc.setStatus(http.StatusOK)
+		c.advance(time.Minute)
+
+		if n, _ := d.Dispatch(context.Background()); n != 1 {
+			t.Fatalf("expected 1 delivery after the backoff, got %d", n)
+		}
+
+		del = deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusSucceeded || del.Attempts != 2 || del.Error != "" {
+			t.Errorf("unexpected delivery %+v", del)
+		}
+
+		s, _ := r.ReadOne(context.Background(), sub.ID)
+		if s.Failures != 0 {
+			t.Errorf("expected the failures to be reset, got %d", s.Failures)
+		}
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusBadGateway)
+		r, d, c, sub := setup(t, srv.URL, service.WithMaxAttempts(3), service.WithBackoff(func(int) time.Duration { return time.Second }))
+
+		for range 5 {
+			d.Dispatch(context.Background())
+			c.advance(time.Second)
+		}
+
+		if rc.count() != 3 {
+			t.Errorf("expected 3 attempts, got %d", rc.count())
+		}
+
+		del := deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusFailed || del.Attempts != 3 {
+			t.Errorf("unexpected delivery %+v", del)
+		}
+	})
+
+	t.Run("disable after failures", func(t *testing.T) {
+		rc, srv := newReceiver(t, http.StatusGone)
+		r, d, c, sub := setup(t, srv.URL, service.WithDisableAfter(2), service.WithBackoff(func(int) time.Duration { return time.Second }))
+
+		for range 4 {
+			d.Dispatch(context.Background())
+			c.advance(time.Second)
+		}
+
+		s, _ := r.ReadOne(context.Background(), sub.ID)
+		if s.Enabled || s.Failures != 2 {
+			t.Errorf("expected the subscription to be disabled after 2 failures, got %+v", s)
+		}
+
+		// the pending delivery of the disabled subscription fails without a request
+		if rc.count() != 2 {
+			t.Errorf("expected 2 requests, got %d", rc.count())
+		}
+
+		del := deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusFailed || del.Error != constant.WebhookDisabled {
+			t.Errorf("unexpected delivery %+v", del)
+		}
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		_, srv := newReceiver(t, http.StatusOK)
+		url := srv.URL
+		srv.Close()
+
+		r, d, _, sub := setup(t, url)
+		d.Dispatch(context.Background())
+
+		del := deliveries(t, r, sub.ID)[0]
+		if del.Status != webhook.StatusPending || del.ResponseStatus != 0 || del.Error == "" {
+			t.Errorf("unexpected delivery %+v", del)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{attempts: 1, expected: constant.WebhookBackoffBase * time.Second},
+		{attempts: 2, expected: 2 * constant.WebhookBackoffBase * time.Second},
+		{attempts: 3, expected: 4 * constant.WebhookBackoffBase * time.Second},
+		{attempts: 100, expected: constant.WebhookBackoffMax * time.Second},
+	}
+
+	for _, tc := range tests {
+		if d := service.Backoff(tc.attempts); d != tc.expected {
+			t.Errorf("attempts %d: expected %v, got %v", tc.attempts, tc.expected, d)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+// service implements the use case of the webhook
+// service contains the business logic as well as calls to the
+// repository to perform db operations
+type service struct {
+	repository webhook.Repository
+}
+
+// NewService initializes a new Service
+func NewService(r webhook.Repository) *service {
+	return &service{repository: r}
+}
+
+// payload is the body of a delivery
+type payload struct {
+	// ID identifies the event, it is the same for all the
+	// subscriptions and the redeliveries
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt int64  `json:"createdAt"`
+	Data      any    `json:"data"`
+}
+
+// validate checks the url and the event types of a subscription
+func validate(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || !u.IsAbs() || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.InvalidWebhookURL))
+	}
+
+	for _, e := range events {
+		if !slices.Contains(webhook.EventTypes, e) {
+			return errorext.NewCustomError(http.StatusBadRequest, fmt.Errorf("%s: %s", constant.UnknownEventType, e))
+		}
+	}
+
+	return nil
+}
+
+// newSecret generates a signing secret
+func newSecret() (string, error) {
+	b := make([]byte, constant.WebhookSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return constant.WebhookSecretPrefix + hex.EncodeToString(b), nil
+}
+
+// readOneInternal fetches one entity from db
+func (s *service) readOneInternal(ctx context.Context, id string) (webhook.Subscription, error) {
+	e, err := s.repository.ReadOne(ctx, id)
+	if err != nil {
+		return e, errorext.BuildCustomError(err)
+	}
+
+	return e, nil
+}
+
+func (s *service) Create(ctx context.Context, payload webhook.CreateDTO) (webhook.Subscription, error) {
+	if err := validate(payload.URL, payload.Events); err != nil {
+		return webhook.Subscription{}, err
+	}
+
+	if payload.Secret == "" {
+		secret, err := newSecret()
+		if err != nil {
+			return webhook.Subscription{}, errorext.BuildCustomError(err)
+		}
+
+		payload.Secret = secret
+	}
+
+	n := time.Now().Unix()
+
+	payload.CreatedAt = n
+	payload.UpdatedAt = n
+
+	id, err := s.repository.Create(ctx, payload)
+	if err != nil {
+		return webhook.Subscription{}, errorext.BuildCustomError(err)
+	}
+
+	return webhook.Subscription{
+		ID:        id,
+		URL:       payload.URL,
+		Events:    payload.Events,
+		Secret:    payload.Secret,
+		Enabled:   true,
+		CreatedAt: payload.CreatedAt,
+		UpdatedAt: payload.UpdatedAt,
+	}, nil
+}
+
+func (s *service) ReadMany(ctx context.Context, limit, page int, args ...any) ([]webhook.Subscription, error) {
+	offset := pagination.Page{Limit: limit, Number: max(page, 1)}.Offset()
+
+	d, err := s.repository.ReadMany(ctx, limit, offset, args...)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+// Count returns the total of the entities matching the args
+// the subscriptions are always counted exactly
+func (s *service) Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error) {
+	n, err := s.repository.Count(ctx, args...)
+	if err != nil {
+		return pagination.Total{}, errorext.BuildCustomError(err)
+	}
+
+	return pagination.Total{Items: n}, nil
+}
+
+func (s *service) ReadOne(ctx context.Context, id string) (webhook.Subscription, error) {
+	return s.readOneInternal(ctx, id)
+}
+
+func (s *service) Update(ctx context.Context, id string, payload webhook.UpdateDTO) (webhook.Subscription, error) {
+	if err := validate(payload.URL, payload.Events); err != nil {
+		return webhook.Subscription{}, err
+	}
+
+	e, err := s.readOneInternal(ctx, id)
+	if err != nil {
+		return e, err
+	}
+
+	payload.UpdatedAt = time.Now().Unix()
+
+	rowCount, err := s.repository.Update(ctx, id, payload)
+	if err != nil {
+		return e, errorext.BuildCustomError(err)
+	}
+
+	if rowCount == 0 {
+		return e, errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
+	}
+
+	if payload.Enabled && !e.Enabled {
+		e.Failures = 0
+	}
+
+	e.URL = payload.URL
+	e.Events = payload.Events
+	e.Enabled = payload.Enabled
+	e.UpdatedAt = payload.UpdatedAt
+
+	return e, nil
+}
+
+// Delete removes the subscription and its delivery log
+func (s *service) Delete(ctx context.Context, id string) (webhook.Subscription, error) {
+	e, err := s.readOneInternal(ctx, id)
+	if err != nil {
+		return e, err
+	}
+
+	rowCount, err := s.repository.Delete(ctx, id)
+	if err != nil {
+		return e, errorext.BuildCustomError(err)
+	}
+
+	if rowCount == 0 {
+		return e, errorext.NewCustomError(http.StatusBadRequest, errors.New(constant.GenericFailMessage))
+	}
+
+	return e, nil
+}
+
+func (s *service) ReadDeliveries(ctx context.Context, subscriptionID string, limit, page int) ([]webhook.Delivery, error) {
+	// not found if the subscription does not exist
+	if _, err := s.readOneInternal(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	offset := pagination.Page{Limit: limit, Number: max(page, 1)}.Offset()
+
+	d, err := s.repository.ReadDeliveries(ctx, subscriptionID, limit, offset)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return d, nil
+}
+
+func (s *service) CountDeliveries(ctx context.Context, subscriptionID string) (pagination.Total, error) {
+	n, err := s.repository.CountDeliveries(ctx, subscriptionID)
+	if err != nil {
+		return pagination.Total{}, errorext.BuildCustomError(err)
+	}
+
+	return pagination.Total{Items: n}, nil
+}
+
+// Redeliver enqueues a copy of the delivery due now, the copy
+// keeps the payload so the receiver can deduplicate by the event id
+func (s *service) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (webhook.Delivery, error) {
+	sub, err := s.readOneInternal(ctx, subscriptionID)
+	if err != nil {
+		return webhook.Delivery{}, err
+	}
+
+	if !sub.Enabled {
+		return webhook.Delivery{}, errorext.NewCustomError(http.StatusConflict, errors.New(constant.WebhookDisabled))
+	}
+
+	d, err := s.repository.ReadDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	return s.createDelivery(ctx, sub.ID, d.Event, d.Payload)
+}
+
+// Enqueue creates the deliveries of the event, it runs in the
+// context of the publisher so the deliveries are created in its
+// transaction if any, they are sent once it is committed
+func (s *service) Enqueue(ctx context.Context, eventType string, data any) error {
+	subs, err := s.repository.ReadSubscribed(ctx, eventType)
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: time.Now().Unix(),
+		Data:      data,
+	})
+	if err != nil {
+		return errorext.BuildCustomError(err)
+	}
+
+	for _, sub := range subs {
+		if _, err := s.createDelivery(ctx, sub.ID, eventType, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// createDelivery creates a pending delivery due now
+func (s *service) createDelivery(ctx context.Context, subscriptionID, eventType string, body []byte) (webhook.Delivery, error) {
+	n := time.Now().Unix()
+
+	d := webhook.Delivery{
+		SubscriptionID: subscriptionID,
+		Event:          eventType,
+		Payload:        body,
+		Status:         webhook.StatusPending,
+		NextAttemptAt:  n,
+		CreatedAt:      n,
+		UpdatedAt:      n,
+	}
+
+	id, err := s.repository.CreateDelivery(ctx, d)
+	if err != nil {
+		return d, errorext.BuildCustomError(err)
+	}
+
+	d.ID = id
+
+	return d, nil
+}
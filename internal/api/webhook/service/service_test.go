@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/mock"
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/webhook/service"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+func TestService(t *testing.T) {
+	r := mock.NewMemoryStorage()
+	s := service.NewService(r)
+	ctx := context.Background()
+
+	t.Run("Create", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			dto    webhook.CreateDTO
+			code   int
+			secret string
+		}{
+			{name: "generated secret", dto: webhook.CreateDTO{URL: "https://example.com/hook", Events: []string{product.EventCreated}}},
+			{name: "given secret", dto: webhook.CreateDTO{URL: "http://example.com/hook", Events: []string{product.EventDeleted}, Secret: "0123456789abcdef"}, secret: "0123456789abcdef"},
+			{name: "relative url", dto: webhook.CreateDTO{URL: "/hook", Events: []string{product.EventCreated}}, code: http.StatusBadRequest},
+			{name: "unsupported scheme", dto: webhook.CreateDTO{URL: "ftp://example.com", Events: []string{product.EventCreated}}, code: http.StatusBadRequest},
+			{name: "unknown event", dto: webhook.CreateDTO{URL: "https://example.com/hook", Events: []string{"user.created"}}, code: http.StatusBadRequest},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				e, err := s.Create(ctx, tc.dto)
+				if tc.code != 0 {
+					if err == nil || errorext.ParseCustomError(err).Code() != tc.code {
+						t.Fatalf("expected %d, got %v", tc.code, err)
+					}
+
+					return
+				}
+
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				if !e.Enabled || e.ID == "" {
+					t.Errorf("unexpected subscription %+v", e)
+				}
+
+				if tc.secret != "" && e.Secret != tc.secret {
+					t.Errorf("expected secret %s, got %s", tc.secret, e.Secret)
+				}
+
+				if tc.secret == "" && !strings.HasPrefix(e.Secret, constant.WebhookSecretPrefix) {
+					t.Errorf("expected a generated secret, got %s", e.Secret)
+				}
+			})
+		}
+	})
+
+	t.Run("Enqueue and Redeliver", func(t *testing.T) {
+		// only the first subscription receives the created events
+		if err := s.Enqueue(ctx, product.EventCreated, map[string]string{"id": "p1"}); err != nil {
+			t.Fatalf("enqueue returned error: %v", err)
+		}
+
+		subs, _ := r.ReadSubscribed(ctx, product.EventCreated)
+		if len(subs) != 1 {
+			t.Fatalf("expected 1 subscription, got %d", len(subs))
+		}
+
+		d, err := s.ReadDeliveries(ctx, subs[0].ID, 10, 1)
+		if err != nil || len(d) != 1 || d[0].Status != webhook.StatusPending {
+			t.Fatalf("expected 1 pending delivery, got %+v, %v", d, err)
+		}
+
+		re, err := s.Redeliver(ctx, subs[0].ID, d[0].ID)
+		if err != nil {
+			t.Fatalf("redeliver returned error: %v", err)
+		}
+
+		if re.ID == d[0].ID || string(re.Payload) != string(d[0].Payload) {
+			t.Errorf("expected a new delivery of the payload, got %+v", re)
+		}
+
+		total, _ := s.CountDeliveries(ctx, subs[0].ID)
+		if total.Items != 2 {
+			t.Errorf("expected 2 deliveries, got %d", total.Items)
+		}
+
+		// a disabled subscription is not redelivered to
+		_, err = s.Update(ctx, subs[0].ID, webhook.UpdateDTO{URL: subs[0].URL, Events: subs[0].Events})
+		if err != nil {
+			t.Fatalf("update returned error: %v", err)
+		}
+
+		_, err = s.Redeliver(ctx, subs[0].ID, d[0].ID)
+		if err == nil || errorext.ParseCustomError(err).Code() != http.StatusConflict {
+			t.Errorf("expected 409, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+type UseCase interface {
+	// Create creates the subscription, a secret is generated if
+	// the payload has none, it is only returned by Create
+	Create(ctx context.Context, payload CreateDTO) (Subscription, error)
+
+	// ReadMany reads a page of the entities, limit is
+	// the effective limit of the pagination policy
+	ReadMany(ctx context.Context, limit, page int, args ...any) ([]Subscription, error)
+
+	// Count returns the total of the entities matching the args
+	Count(ctx context.Context, policy pagination.Policy, args ...any) (pagination.Total, error)
+
+	ReadOne(ctx context.Context, id string) (Subscription, error)
+
+	Update(ctx context.Context, id string, payload UpdateDTO) (Subscription, error)
+
+	Delete(ctx context.Context, id string) (Subscription, error)
+
+	// ReadDeliveries reads a page of the delivery log of the subscription
+	ReadDeliveries(ctx context.Context, subscriptionID string, limit, page int) ([]Delivery, error)
+
+	// CountDeliveries returns the total of the deliveries of the subscription
+	CountDeliveries(ctx context.Context, subscriptionID string) (pagination.Total, error)
+
+	// Redeliver enqueues a new delivery of the payload of a delivery
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (Delivery, error)
+
+	// Enqueue creates a pending delivery of the event for each
+	// enabled subscription of its type, data is the payload data
+	Enqueue(ctx context.Context, eventType string, data any) error
+}
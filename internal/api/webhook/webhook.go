@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"slices"
+
+	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
+)
+
+// EventTypes contains the event types the subscriptions can subscribe to
+var EventTypes = []string{product.EventCreated, product.EventUpdated, product.EventDeleted, product.EventPurged}
+
+// Subscription is an endpoint notified of the events of its types
+type Subscription struct {
+	ID     string
+	URL    string
+	Events []string
+	// Secret signs the payloads, see signature.Header
+	Secret  string
+	Enabled bool
+	// Failures is the number of consecutive failed attempts
+	Failures  int
+	CreatedAt int64
+	UpdatedAt int64
+}
+
+// Subscribes reports if the subscription receives the event type
+func (s Subscription) Subscribes(eventType string) bool {
+	return slices.Contains(s.Events, eventType)
+}
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	// StatusPending is waiting for its next attempt
+	StatusPending DeliveryStatus = "pending"
+	// StatusSucceeded was acknowledged with a 2xx response
+	StatusSucceeded DeliveryStatus = "succeeded"
+	// StatusFailed exhausted its attempts or its subscription was disabled
+	StatusFailed DeliveryStatus = "failed"
+)
+
+// Delivery is the notification of an event to a subscription
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	Event          string
+	Payload        []byte
+	Status         DeliveryStatus
+	Attempts       int
+	// ResponseStatus is the status of the last attempt, zero
+	// if the endpoint could not be reached
+	ResponseStatus int
+	Error          string
+	NextAttemptAt  int64
+	CreatedAt      int64
+	UpdatedAt      int64
+}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// APIKey is a generated api key, "<prefix>_<secret>", the prefix
+// is stored in clear and shown in the listings, only a salted
+// hash of the secret is stored, see HashAPIKeySecret
+type APIKey struct {
+	Key    string
+	Prefix string
+	Salt   []byte
+	Hash   []byte
+}
+
+// NewAPIKey generates a random api key, ex: bsk_1a2b3c4d5e6f_<64 hex>
+func NewAPIKey() (APIKey, error) {
+	b := make([]byte, constant.APIKeyIDBytes+constant.APIKeySecretBytes+constant.APIKeySaltBytes)
+	if _, err := rand.Read(b); err != nil {
+		return APIKey{}, err
+	}
+
+	prefix := constant.APIKeyPrefix + "_" + hex.EncodeToString(b[:constant.APIKeyIDBytes])
+	secret := hex.EncodeToString(b[constant.APIKeyIDBytes : constant.APIKeyIDBytes+constant.APIKeySecretBytes])
+	salt := b[constant.APIKeyIDBytes+constant.APIKeySecretBytes:]
+
+	return APIKey{
+		Key:    prefix + "_" + secret,
+		Prefix: prefix,
+		Salt:   salt,
+		Hash:   HashAPIKeySecret(secret, salt),
+	}, nil
+}
+
+// ParseAPIKey splits the key into its prefix and its secret
+// ok is false if the key is not of the format of NewAPIKey
+func ParseAPIKey(key string) (prefix, secret string, ok bool) {
+	rest, found := strings.CutPrefix(key, constant.APIKeyPrefix+"_")
+	if !found {
+		return "", "", false
+	}
+
+	id, secret, found := strings.Cut(rest, "_")
+	if !found || len(id) != 2*constant.APIKeyIDBytes || len(secret) != 2*constant.APIKeySecretBytes {
+		return "", "", false
+	}
+
+	return constant.APIKeyPrefix + "_" + id, secret, true
+}
+
+// HashAPIKeySecret returns the SHA-256 hash of the salted secret
+// the secrets are random so a fast hash is enough, the salt keeps
+// the equal secrets of a leaked table apart
+func HashAPIKeySecret(secret string, salt []byte) []byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write([]byte(secret))
+
+	return h.Sum(nil)
+}
+
+// VerifyAPIKeySecret reports if the secret matches the hash
+// in constant time
+func VerifyAPIKeySecret(secret string, salt, hash []byte) bool {
+	return subtle.ConstantTimeCompare(HashAPIKeySecret(secret, salt), hash) == 1
+}
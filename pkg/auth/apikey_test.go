@@ -0,0 +1,57 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+func TestAPIKey(t *testing.T) {
+	k, err := auth.NewAPIKey()
+	if err != nil {
+		t.Fatalf("new api key: %v", err)
+	}
+
+	prefix, secret, ok := auth.ParseAPIKey(k.Key)
+	if !ok || prefix != k.Prefix || !strings.HasPrefix(k.Key, "bsk_") {
+		t.Fatalf("unexpected parse %q %q %v of %s", prefix, secret, ok, k.Key)
+	}
+
+	if !auth.VerifyAPIKeySecret(secret, k.Salt, k.Hash) {
+		t.Error("expected the secret to verify")
+	}
+
+	other, err := auth.NewAPIKey()
+	if err != nil {
+		t.Fatalf("new api key: %v", err)
+	}
+
+	_, otherSecret, _ := auth.ParseAPIKey(other.Key)
+
+	if auth.VerifyAPIKeySecret(otherSecret, k.Salt, k.Hash) {
+		t.Error("expected another secret to fail")
+	}
+
+	if string(auth.HashAPIKeySecret(secret, other.Salt)) == string(k.Hash) {
+		t.Error("expected the salt to change the hash")
+	}
+
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "other prefix", key: "sk_" + strings.TrimPrefix(k.Key, "bsk_")},
+		{name: "missing secret", key: k.Prefix},
+		{name: "short secret", key: k.Key[:len(k.Key)-1]},
+		{name: "empty", key: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, ok := auth.ParseAPIKey(tc.key); ok {
+				t.Errorf("expected %q to be invalid", tc.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+var ErrUnknownKey = errors.New(constant.UnknownSigningKey)
+
+// KeySet provides the keys verifying the signatures of the tokens
+type KeySet interface {
+	// Key returns the key of the key id for the algorithm of the token
+	Key(ctx context.Context, kid, alg string) (any, error)
+}
+
+// HMACKey is the KeySet of a shared HS256 secret
+type HMACKey []byte
+
+func (k HMACKey) Key(ctx context.Context, kid, alg string) (any, error) {
+	if alg != AlgHS256 {
+		return nil, ErrUnknownKey
+	}
+
+	return []byte(k), nil
+}
+
+// KeySets looks the key up in the sets in order
+type KeySets []KeySet
+
+func (s KeySets) Key(ctx context.Context, kid, alg string) (any, error) {
+	for _, ks := range s {
+		k, err := ks.Key(ctx, kid, alg)
+		if errors.Is(err, ErrUnknownKey) {
+			continue
+		}
+
+		return k, err
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// JWKSOption configures a JWKS
+type JWKSOption func(*JWKS)
+
+// WithTTL sets the time the keys are cached for
+func WithTTL(d time.Duration) JWKSOption {
+	return func(s *JWKS) {
+		s.ttl = d
+	}
+}
+
+// WithMinRefreshWait sets the minimum time between the
+// fetches caused by the unknown key ids
+func WithMinRefreshWait(d time.Duration) JWKSOption {
+	return func(s *JWKS) {
+		s.minWait = d
+	}
+}
+
+// WithHTTPClient sets the client fetching the key set of a url
+func WithHTTPClient(c *http.Client) JWKSOption {
+	return func(s *JWKS) {
+		s.client = c
+	}
+}
+
+// JWKS is the KeySet of a JSON Web Key Set document, RFC 7517, read
+// from a file or a url, the keys are cached for the ttl and a token
+// signed by an unknown key id fetches the set again so that the
+// rotated keys are found, at most once per the min refresh wait
+// the RSA and the P-256 EC signing keys are used, the others skipped
+type JWKS struct {
+	fetch   func(ctx context.Context) ([]byte, error)
+	client  *http.Client
+	ttl     time.Duration
+	minWait time.Duration
+	now     func() time.Time
+
+	// mu guards the keys, it's not held during a fetch
+	// so that the readers keep using the cached keys
+	mu          sync.RWMutex
+	keys        []jwk
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	// fetching is the running fetch, the concurrent callers share it
+	fetching *fetchCall
+}
+
+// fetchCall is a fetch of the key set, err is set once done is closed
+type fetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+func newJWKS(opts []JWKSOption) *JWKS {
+	s := &JWKS{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		ttl:     constant.JWKSCacheTTL * time.Minute,
+		minWait: constant.JWKSMinRefreshWait * time.Second,
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// NewJWKSFile initializes a JWKS of the file at path
+// the file is read again after the ttl to pick up a rotation
+func NewJWKSFile(path string, opts ...JWKSOption) *JWKS {
+	s := newJWKS(opts)
+	s.fetch = func(ctx context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+
+	return s
+}
+
+// NewJWKSURL initializes a JWKS of the document served at url
+func NewJWKSURL(url string, opts ...JWKSOption) *JWKS {
+	s := newJWKS(opts)
+	s.fetch = func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := s.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch jwks: unexpected status %d", res.StatusCode)
+		}
+
+		return io.ReadAll(io.LimitReader(res.Body, constant.JWKSMaxBytes))
+	}
+
+	return s
+}
+
+// Key returns the key of the kid, a token without a kid
+// uses the only key of the set matching its algorithm
+// the expired keys are used while they are fetched again
+func (s *JWKS) Key(ctx context.Context, kid, alg string) (any, error) {
+	now := s.now()
+
+	s.mu.RLock()
+	cached := s.keys != nil
+	expired := now.Sub(s.fetchedAt) >= s.ttl
+	s.mu.RUnlock()
+
+	switch {
+	case !cached:
+		if err := s.wait(ctx, s.refresh(ctx, now)); err != nil {
+			return nil, err
+		}
+	case expired:
+		// the stale keys are used until a fetch succeeds
+		s.refresh(ctx, now)
+	}
+
+	k, ok, lastAttempt := s.lookup(kid, alg)
+	if !ok && now.Sub(lastAttempt) >= s.minWait {
+		if err := s.wait(ctx, s.refresh(ctx, now)); err != nil {
+			return nil, err
+		}
+
+		k, ok, _ = s.lookup(kid, alg)
+	}
+
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return k.key, nil
+}
+
+// refresh starts a fetch of the key set unless one is running
+// the fetch outlives the cancellation of ctx as it's shared
+func (s *JWKS) refresh(ctx context.Context, now time.Time) *fetchCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fetching != nil {
+		return s.fetching
+	}
+
+	c := &fetchCall{done: make(chan struct{})}
+	s.fetching = c
+	s.lastAttempt = now
+
+	go func() {
+		defer close(c.done)
+
+		b, err := s.fetch(context.WithoutCancel(ctx))
+
+		var keys []jwk
+		if err == nil {
+			keys, err = parseJWKS(b)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.fetching = nil
+		c.err = err
+
+		if err != nil {
+			log.Printf("jwks refresh returned error: %v", err)
+			return
+		}
+
+		s.keys = keys
+		s.fetchedAt = now
+	}()
+
+	return c
+}
+
+// wait returns the error of the fetch once it's done
+func (s *JWKS) wait(ctx context.Context, c *fetchCall) error {
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// lookup returns the key of the kid usable with alg
+// and the time of the last fetch attempt
+func (s *JWKS) lookup(kid, alg string) (jwk, bool, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var found []jwk
+	for _, k := range s.keys {
+		if !k.usableWith(alg) {
+			continue
+		}
+
+		if kid == "" || k.Kid == kid {
+			found = append(found, k)
+		}
+	}
+
+	if len(found) != 1 {
+		return jwk{}, false, s.lastAttempt
+	}
+
+	return found[0], true, s.lastAttempt
+}
+
+// jwk is a JSON Web Key of a key set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+
+	key any
+}
+
+// usableWith reports if the key verifies the signatures of alg
+func (k jwk) usableWith(alg string) bool {
+	if k.Alg != "" && k.Alg != alg {
+		return false
+	}
+
+	switch k.key.(type) {
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256
+	}
+
+	return false
+}
+
+// parseJWKS parses the signing keys of the document
+// an invalid key is skipped so that the others are used
+func parseJWKS(b []byte) ([]jwk, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make([]jwk, 0, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			log.Printf("jwks skipped the key %q: %v", k.Kid, err)
+			continue
+		}
+
+		if key == nil {
+			continue
+		}
+
+		k.key = key
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+// publicKey returns the public key of the jwk, nil
+// if the type of the key is not supported
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid rsa exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		if x.BitLen() > 256 || y.BitLen() > 256 {
+			return nil, errors.New("invalid ec point")
+		}
+
+		// ecdh validates that the point is on the curve
+		point := make([]byte, 65)
+		point[0] = 4
+		x.FillBytes(point[1:33])
+		y.FillBytes(point[33:])
+
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	}
+
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,196 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+func TestJWKS(t *testing.T) {
+	key1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	var (
+		fetches atomic.Int32
+		keys    atomic.Value
+	)
+	keys.Store([]map[string]any{jwkOf("k1", key1)})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"keys": keys.Load()})
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	t.Run("caches the keys", func(t *testing.T) {
+		fetches.Store(0)
+		s := auth.NewJWKSURL(srv.URL)
+
+		for range 3 {
+			if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if n := fetches.Load(); n != 1 {
+			t.Errorf("fetches = %d, want 1", n)
+		}
+
+		if _, err := s.Key(ctx, "k1", auth.AlgRS256); !errors.Is(err, auth.ErrUnknownKey) {
+			t.Errorf("Key() of another algorithm error = %v, want ErrUnknownKey", err)
+		}
+	})
+
+	t.Run("fetches the rotated keys", func(t *testing.T) {
+		fetches.Store(0)
+		keys.Store([]map[string]any{jwkOf("k1", key1)})
+		s := auth.NewJWKSURL(srv.URL, auth.WithMinRefreshWait(0))
+
+		if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+			t.Fatal(err)
+		}
+
+		keys.Store([]map[string]any{jwkOf("k1", key1), jwkOf("k2", key2)})
+
+		k, err := s.Key(ctx, "k2", auth.AlgES256)
+		if err != nil {
+			t.Fatalf("Key() of the rotated key error = %v", err)
+		}
+
+		if !k.(*ecdsa.PublicKey).Equal(&key2.PublicKey) {
+			t.Error("Key() returned another key")
+		}
+
+		if n := fetches.Load(); n != 2 {
+			t.Errorf("fetches = %d, want 2", n)
+		}
+	})
+
+	t.Run("limits the fetches of the unknown kids", func(t *testing.T) {
+		fetches.Store(0)
+		s := auth.NewJWKSURL(srv.URL, auth.WithMinRefreshWait(time.Hour))
+
+		for range 3 {
+			if _, err := s.Key(ctx, "unknown", auth.AlgES256); !errors.Is(err, auth.ErrUnknownKey) {
+				t.Fatalf("Key() error = %v, want ErrUnknownKey", err)
+			}
+		}
+
+		if n := fetches.Load(); n != 1 {
+			t.Errorf("fetches = %d, want 1", n)
+		}
+	})
+
+	t.Run("keeps the stale keys", func(t *testing.T) {
+		var fail atomic.Bool
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if fail.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{jwkOf("k1", key1)}})
+		}))
+		defer srv.Close()
+
+		s := auth.NewJWKSURL(srv.URL, auth.WithTTL(0))
+		if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+			t.Fatal(err)
+		}
+
+		fail.Store(true)
+
+		if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+			t.Errorf("Key() with the stale keys error = %v", err)
+		}
+	})
+
+	t.Run("reads the cached keys during a fetch", func(t *testing.T) {
+		var (
+			fetched atomic.Int32
+			release = make(chan struct{})
+		)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// the fetches after the first wait for the release
+			if fetched.Add(1) > 1 {
+				<-release
+			}
+
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{jwkOf("k1", key1)}})
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		s := auth.NewJWKSURL(srv.URL, auth.WithMinRefreshWait(0))
+		if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+			t.Fatal(err)
+		}
+
+		// an unknown kid fetches the set again
+		go s.Key(ctx, "unknown", auth.AlgES256)
+
+		for fetched.Load() < 2 {
+			time.Sleep(time.Millisecond)
+		}
+
+		done := make(chan error)
+		go func() {
+			_, err := s.Key(ctx, "k1", auth.AlgES256)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("Key() during a fetch error = %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Key() of a cached key waited for the fetch")
+		}
+	})
+
+	t.Run("skips the invalid keys", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]any{
+				{"kty": "EC", "kid": "bad", "crv": "P-256", "x": "AQ", "y": "AQ"},
+				{"kty": "RSA", "kid": "empty", "n": "", "e": "AQAB"},
+				jwkOf("k1", key1),
+			}})
+		}))
+		defer srv.Close()
+
+		s := auth.NewJWKSURL(srv.URL)
+
+		if _, err := s.Key(ctx, "k1", auth.AlgES256); err != nil {
+			t.Errorf("Key() of the valid key error = %v", err)
+		}
+
+		if _, err := s.Key(ctx, "bad", auth.AlgES256); !errors.Is(err, auth.ErrUnknownKey) {
+			t.Errorf("Key() of the invalid point error = %v, want ErrUnknownKey", err)
+		}
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"keys": {}}`))
+		}))
+		defer srv.Close()
+
+		if _, err := auth.NewJWKSURL(srv.URL).Key(ctx, "", auth.AlgES256); err == nil || errors.Is(err, auth.ErrUnknownKey) {
+			t.Errorf("Key() error = %v, want the parse error", err)
+		}
+	})
+}
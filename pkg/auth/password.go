@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrInvalidHash = errors.New("the password hash is invalid")
+
+// PasswordParams contains the argon2id parameters of the password hashes
+type PasswordParams struct {
+	// Memory is in KiB
+	Memory  uint32
+	Time    uint32
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultPasswordParams returns the parameters recommended
+// by OWASP for argon2id
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{
+		Memory:  constant.PasswordMemory,
+		Time:    constant.PasswordTime,
+		Threads: constant.PasswordThreads,
+		SaltLen: constant.PasswordSaltBytes,
+		KeyLen:  constant.PasswordKeyBytes,
+	}
+}
+
+// ParsePasswordParams parses the comma separated parameters
+// ex: "m=19456,t=2,p=1", the missing ones are the defaults
+func ParsePasswordParams(s string) (PasswordParams, error) {
+	p := DefaultPasswordParams()
+	if s == "" {
+		return p, nil
+	}
+
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+		if !ok {
+			return p, fmt.Errorf("invalid password parameter %q", kv)
+		}
+
+		n, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || n == 0 {
+			return p, fmt.Errorf("invalid password parameter %q", kv)
+		}
+
+		switch k {
+		case "m":
+			p.Memory = uint32(n)
+		case "t":
+			p.Time = uint32(n)
+		case "p":
+			if n > 255 {
+				return p, fmt.Errorf("invalid password parameter %q", kv)
+			}
+
+			p.Threads = uint8(n)
+		default:
+			return p, fmt.Errorf("unknown password parameter %q", k)
+		}
+	}
+
+	return p, nil
+}
+
+// PasswordHasher hashes the passwords with argon2id in the
+// PHC string format, $argon2id$v=19$m=..,t=..,p=..$salt$key
+// the hashes keep their parameters so that they are verified
+// after the parameters are changed
+type PasswordHasher struct {
+	params PasswordParams
+}
+
+// NewPasswordHasher initializes a PasswordHasher of the parameters
+func NewPasswordHasher(p PasswordParams) *PasswordHasher {
+	return &PasswordHasher{params: p}
+}
+
+// Hash hashes the password with a random salt
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Time, h.params.Memory, h.params.Threads, h.params.KeyLen)
+
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Time,
+		h.params.Threads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports if the password matches the hash, rehash is true
+// if the hash was made with other parameters than the current ones,
+// the caller stores a new hash of the password then
+func (h *PasswordHasher) Verify(password, hash string) (ok, rehash bool, err error) {
+	p, salt, key, err := decodeHash(hash)
+	if err != nil {
+		return false, false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	rehash = p.Memory != h.params.Memory ||
+		p.Time != h.params.Time ||
+		p.Threads != h.params.Threads ||
+		uint32(len(salt)) != h.params.SaltLen ||
+		uint32(len(key)) != h.params.KeyLen
+
+	return true, rehash, nil
+}
+
+// decodeHash parses the PHC string of a hash
+func decodeHash(hash string) (PasswordParams, []byte, []byte, error) {
+	var p PasswordParams
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads)
+	if err != nil || p.Time == 0 || p.Threads == 0 {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	p.SaltLen = uint32(len(salt))
+	p.KeyLen = uint32(len(key))
+
+	return p, salt, key, nil
+}
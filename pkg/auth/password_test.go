@@ -0,0 +1,102 @@
+package auth_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+// cheap parameters keep the tests fast
+var testParams = auth.PasswordParams{Memory: 64, Time: 1, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+func TestPasswordHasher(t *testing.T) {
+	h := auth.NewPasswordHasher(testParams)
+
+	hash, err := h.Hash("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected hash %s", hash)
+	}
+
+	other, _ := h.Hash("correct horse")
+	if other == hash {
+		t.Error("expected a random salt")
+	}
+
+	stronger := testParams
+	stronger.Time = 2
+
+	tests := []struct {
+		name     string
+		hasher   *auth.PasswordHasher
+		password string
+		hash     string
+		ok       bool
+		rehash   bool
+		err      error
+	}{
+		{name: "match", hasher: h, password: "correct horse", hash: hash, ok: true},
+		{name: "mismatch", hasher: h, password: "battery staple", hash: hash},
+		{name: "changed params", hasher: auth.NewPasswordHasher(stronger), password: "correct horse", hash: hash, ok: true, rehash: true},
+		{name: "changed params mismatch", hasher: auth.NewPasswordHasher(stronger), password: "battery staple", hash: hash},
+		{name: "other algorithm", hasher: h, password: "correct horse", hash: "$2a$10$abcdefghijklmnopqrstuv", err: auth.ErrInvalidHash},
+		{name: "zero threads", hasher: h, password: "correct horse", hash: "$argon2id$v=19$m=64,t=1,p=0$c2FsdA$a2V5", err: auth.ErrInvalidHash},
+		{name: "other version", hasher: h, password: "correct horse", hash: strings.Replace(hash, "v=19", "v=16", 1), err: auth.ErrInvalidHash},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, rehash, err := tc.hasher.Verify(tc.password, tc.hash)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+
+			if ok != tc.ok || rehash != tc.rehash {
+				t.Errorf("expected ok %t rehash %t, got %t %t", tc.ok, tc.rehash, ok, rehash)
+			}
+		})
+	}
+}
+
+func TestParsePasswordParams(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  auth.PasswordParams
+		err   bool
+	}{
+		{name: "empty", input: "", want: auth.DefaultPasswordParams()},
+		{name: "all", input: "m=65536, t=3, p=4", want: auth.PasswordParams{Memory: 65536, Time: 3, Threads: 4, SaltLen: 16, KeyLen: 32}},
+		{name: "partial", input: "t=4", want: auth.PasswordParams{Memory: 19 * 1024, Time: 4, Threads: 1, SaltLen: 16, KeyLen: 32}},
+		{name: "unknown", input: "x=1", err: true},
+		{name: "zero", input: "t=0", err: true},
+		{name: "threads overflow", input: "p=256", err: true},
+		{name: "malformed", input: "m", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := auth.ParsePasswordParams(tc.input)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", got)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
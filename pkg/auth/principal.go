@@ -0,0 +1,81 @@
+// package auth authenticates the callers of the api, it verifies
+// the bearer tokens and carries the authenticated principal in
+// the context of the request for the handlers and the use cases
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/typesext"
+)
+
+// the authentication methods of a principal
+const (
+	MethodJWT        = "jwt"
+	MethodAPIKey     = "apikey"
+	MethodClientCert = "mtls"
+)
+
+const principalKey typesext.ContextKey = "principal"
+
+var ErrUnauthenticated = errors.New(constant.MissingCredentials)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller, ex: the id of a user
+	Subject string
+	// Method is how the caller was authenticated, ex: MethodJWT
+	Method    string
+	Issuer    string
+	Audience  []string
+	Scopes    []string
+	Roles     []string
+	ExpiresAt time.Time
+	// Claims contains all the claims of the token
+	Claims map[string]any
+}
+
+// HasScope reports if the principal was granted the scope
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// HasRole reports if the principal has the role
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// WithPrincipal returns a copy of ctx carrying the principal
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey, p)
+}
+
+// PrincipalFrom returns the principal of ctx
+// ok is false for the anonymous requests
+func PrincipalFrom(ctx context.Context) (p Principal, ok bool) {
+	p, ok = ctx.Value(principalKey).(Principal)
+	return p, ok
+}
+
+// FromRequest returns the principal of the request, see PrincipalFrom
+func FromRequest(r *http.Request) (Principal, bool) {
+	return PrincipalFrom(r.Context())
+}
+
+// RequirePrincipal returns the principal of ctx, the use cases
+// which need a caller use it, an anonymous ctx fails with a
+// 401 errorext.CustomError
+func RequirePrincipal(ctx context.Context) (Principal, error) {
+	p, ok := PrincipalFrom(ctx)
+	if !ok {
+		return p, errorext.NewCustomError(http.StatusUnauthorized, ErrUnauthenticated)
+	}
+
+	return p, nil
+}
@@ -0,0 +1,24 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+func TestRequirePrincipal(t *testing.T) {
+	_, err := auth.RequirePrincipal(context.Background())
+	if code := errorext.ParseCustomError(err).Code(); err == nil || code != http.StatusUnauthorized {
+		t.Errorf("RequirePrincipal() of an anonymous ctx error = %v, code %d, want 401", err, code)
+	}
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1"})
+
+	p, err := auth.RequirePrincipal(ctx)
+	if err != nil || p.Subject != "user-1" {
+		t.Errorf("RequirePrincipal() = %+v, %v", p, err)
+	}
+}
@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// SignerOption configures a Signer
+type SignerOption func(*Signer)
+
+// WithTokenIssuer sets the iss claim of the tokens
+func WithTokenIssuer(issuer string) SignerOption {
+	return func(s *Signer) {
+		s.issuer = issuer
+	}
+}
+
+// WithTokenAudience sets the aud claim of the tokens
+func WithTokenAudience(audience ...string) SignerOption {
+	return func(s *Signer) {
+		s.audience = audience
+	}
+}
+
+// WithTokenTTL sets the lifetime of the tokens
+func WithTokenTTL(d time.Duration) SignerOption {
+	return func(s *Signer) {
+		s.ttl = d
+	}
+}
+
+// WithSignerTimeFunc sets the clock of the iat and exp claims
+func WithSignerTimeFunc(now func() time.Time) SignerOption {
+	return func(s *Signer) {
+		s.now = now
+	}
+}
+
+// Claims contains the claims of an access token
+// besides the registered ones
+type Claims struct {
+	Subject string
+	Scopes  []string
+	Roles   []string
+}
+
+// Signer issues the HS256 access tokens of the
+// authenticated users, a Verifier of the same
+// secret verifies them
+type Signer struct {
+	key      []byte
+	issuer   string
+	audience []string
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewSigner initializes a Signer of the secret, the tokens
+// expire after constant.AccessTokenTTL by default
+func NewSigner(secret []byte, opts ...SignerOption) *Signer {
+	s := &Signer{
+		key: secret,
+		ttl: constant.AccessTokenTTL * time.Minute,
+		now: time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// TTL returns the lifetime of the tokens
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Sign issues a token of the claims, it returns
+// the token and its expiry
+func (s *Signer) Sign(c Claims) (string, time.Time, error) {
+	now := s.now()
+	exp := now.Add(s.ttl)
+
+	claims := jwt.MapClaims{
+		"sub": c.Subject,
+		"iat": now.Unix(),
+		"exp": exp.Unix(),
+	}
+
+	if s.issuer != "" {
+		claims["iss"] = s.issuer
+	}
+
+	switch len(s.audience) {
+	case 0:
+	case 1:
+		claims["aud"] = s.audience[0]
+	default:
+		claims["aud"] = s.audience
+	}
+
+	if len(c.Scopes) > 0 {
+		claims["scp"] = c.Scopes
+	}
+
+	if len(c.Roles) > 0 {
+		claims["roles"] = c.Roles
+	}
+
+	t, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.key)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return t, exp, nil
+}
+
+// NewOpaqueToken returns a random url safe token and its hash
+// only the hash is stored, see HashToken
+func NewOpaqueToken() (string, []byte, error) {
+	b := make([]byte, constant.OpaqueTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+
+	t := base64.RawURLEncoding.EncodeToString(b)
+
+	return t, HashToken(t), nil
+}
+
+// HashToken returns the SHA-256 hash of an opaque token
+// the tokens are random so they need no salt
+func HashToken(token string) []byte {
+	h := sha256.Sum256([]byte(token))
+	return h[:]
+}
@@ -0,0 +1,83 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+func TestSigner(t *testing.T) {
+	clock := func() time.Time { return now }
+
+	s := auth.NewSigner(
+		secret,
+		auth.WithTokenIssuer("https://issuer.test"),
+		auth.WithTokenAudience("api"),
+		auth.WithTokenTTL(5*time.Minute),
+		auth.WithSignerTimeFunc(clock),
+	)
+
+	token, exp, err := s.Sign(auth.Claims{Subject: "user-1", Roles: []string{"admin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !exp.Equal(now.Add(5 * time.Minute)) {
+		t.Errorf("expected expiry %v, got %v", now.Add(5*time.Minute), exp)
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		opts []auth.VerifierOption
+		ok   bool
+	}{
+		{name: "verified", at: now, opts: []auth.VerifierOption{auth.WithIssuer("https://issuer.test"), auth.WithAudience("api")}, ok: true},
+		{name: "expired", at: now.Add(10 * time.Minute)},
+		{name: "other audience", at: now, opts: []auth.VerifierOption{auth.WithAudience("other")}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			at := tc.at
+			opts := append([]auth.VerifierOption{auth.WithTimeFunc(func() time.Time { return at })}, tc.opts...)
+
+			p, err := auth.NewVerifier(auth.HMACKey(secret), opts...).Verify(context.Background(), token)
+			if !tc.ok {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if p.Subject != "user-1" || !slices.Equal(p.Roles, []string{"admin"}) {
+				t.Errorf("unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestOpaqueToken(t *testing.T) {
+	a, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _, _ := auth.NewOpaqueToken()
+	if a == b {
+		t.Error("expected random tokens")
+	}
+
+	if !bytes.Equal(hash, auth.HashToken(a)) {
+		t.Error("expected the hash of the token")
+	}
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// the signing algorithms of the tokens
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+)
+
+var ErrInvalidToken = errors.New(constant.InvalidToken)
+
+// VerifierOption configures a Verifier
+type VerifierOption func(*verifierConfig)
+
+type verifierConfig struct {
+	issuer     string
+	audience   []string
+	skew       time.Duration
+	algorithms []string
+	now        func() time.Time
+}
+
+// WithIssuer requires the iss claim to be the issuer
+func WithIssuer(issuer string) VerifierOption {
+	return func(c *verifierConfig) {
+		c.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain one of the audiences
+func WithAudience(audience ...string) VerifierOption {
+	return func(c *verifierConfig) {
+		c.audience = audience
+	}
+}
+
+// WithClockSkew sets the tolerance of the exp, nbf and iat claims
+func WithClockSkew(d time.Duration) VerifierOption {
+	return func(c *verifierConfig) {
+		c.skew = d
+	}
+}
+
+// WithAlgorithms restricts the accepted signing algorithms
+func WithAlgorithms(algorithms ...string) VerifierOption {
+	return func(c *verifierConfig) {
+		c.algorithms = algorithms
+	}
+}
+
+// WithTimeFunc sets the clock of the time based claims
+func WithTimeFunc(now func() time.Time) VerifierOption {
+	return func(c *verifierConfig) {
+		c.now = now
+	}
+}
+
+// Verifier verifies the signed JWTs and converts their
+// claims to the principal of the caller
+type Verifier struct {
+	keys   KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier initializes a Verifier of the keys, HS256, RS256
+// and ES256 are accepted by default, the exp claim is required
+func NewVerifier(keys KeySet, opts ...VerifierOption) *Verifier {
+	c := verifierConfig{
+		skew:       constant.JWTClockSkew * time.Second,
+		algorithms: []string{AlgHS256, AlgRS256, AlgES256},
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	popts := []jwt.ParserOption{
+		jwt.WithValidMethods(c.algorithms),
+		jwt.WithLeeway(c.skew),
+		jwt.WithTimeFunc(c.now),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+
+	if c.issuer != "" {
+		popts = append(popts, jwt.WithIssuer(c.issuer))
+	}
+
+	if len(c.audience) > 0 {
+		popts = append(popts, jwt.WithAudience(c.audience...))
+	}
+
+	return &Verifier{keys: keys, parser: jwt.NewParser(popts...)}
+}
+
+// Verify verifies the signature and the claims of the token
+// the errors wrap ErrInvalidToken
+func (v *Verifier) Verify(ctx context.Context, token string) (Principal, error) {
+	claims := jwt.MapClaims{}
+
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid, t.Method.Alg())
+	})
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return principalOf(claims)
+}
+
+// principalOf converts the claims to a principal, the scopes are
+// the space separated scope claim or the scp array and the roles
+// are the roles array
+func principalOf(claims jwt.MapClaims) (Principal, error) {
+	p := Principal{Method: MethodJWT, Claims: claims}
+
+	var err error
+
+	p.Subject, err = claims.GetSubject()
+	if err != nil || p.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: sub claim required", ErrInvalidToken)
+	}
+
+	p.Issuer, _ = claims.GetIssuer()
+	p.Audience, _ = claims.GetAudience()
+
+	if exp, _ := claims.GetExpirationTime(); exp != nil {
+		p.ExpiresAt = exp.Time
+	}
+
+	if s, ok := claims["scope"].(string); ok {
+		p.Scopes = strings.Fields(s)
+	} else {
+		p.Scopes = stringsClaim(claims["scp"])
+	}
+
+	p.Roles = stringsClaim(claims["roles"])
+
+	return p, nil
+}
+
+// stringsClaim returns the strings of an array or a string claim
+func stringsClaim(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			if e, ok := e.(string); ok {
+				s = append(s, e)
+			}
+		}
+
+		return s
+	}
+
+	return nil
+}
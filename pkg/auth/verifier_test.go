@@ -0,0 +1,199 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+)
+
+var (
+	secret = []byte("0123456789abcdef0123456789abcdef")
+	now    = time.Unix(1_700_000_000, 0)
+)
+
+func sign(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	s, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func claims(extra jwt.MapClaims) jwt.MapClaims {
+	c := jwt.MapClaims{
+		"sub": "user-1",
+		"iss": "https://issuer.test",
+		"aud": "api",
+		"exp": now.Add(time.Minute).Unix(),
+		"iat": now.Unix(),
+	}
+
+	for k, v := range extra {
+		if v == nil {
+			delete(c, k)
+			continue
+		}
+
+		c[k] = v
+	}
+
+	return c
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// jwkOf returns the public jwk of the key
+func jwkOf(kid string, key any) map[string]any {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return map[string]any{"kty": "RSA", "kid": kid, "use": "sig", "n": b64(k.N.Bytes()), "e": b64(big.NewInt(int64(k.E)).Bytes())}
+	case *ecdsa.PrivateKey:
+		return map[string]any{"kty": "EC", "kid": kid, "crv": "P-256", "x": b64(k.X.FillBytes(make([]byte, 32))), "y": b64(k.Y.FillBytes(make([]byte, 32)))}
+	}
+
+	return nil
+}
+
+func writeJWKS(t *testing.T, path string, keys ...map[string]any) {
+	t.Helper()
+
+	b, err := json.Marshal(map[string]any{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, jwkOf("rsa-1", rsaKey), jwkOf("ec-1", ecKey))
+
+	keys := auth.KeySets{auth.HMACKey(secret), auth.NewJWKSFile(path)}
+	v := auth.NewVerifier(
+		keys,
+		auth.WithIssuer("https://issuer.test"),
+		auth.WithAudience("api"),
+		auth.WithClockSkew(30*time.Second),
+		auth.WithTimeFunc(func() time.Time { return now }),
+	)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{name: "HS256", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(nil)), valid: true},
+		{name: "RS256 of the jwks", token: sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", claims(nil)), valid: true},
+		{name: "ES256 of the jwks", token: sign(t, jwt.SigningMethodES256, ecKey, "ec-1", claims(nil)), valid: true},
+		{name: "ES256 without a kid", token: sign(t, jwt.SigningMethodES256, ecKey, "", claims(nil)), valid: true},
+		{name: "expired within the skew", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": now.Add(-10 * time.Second).Unix()})), valid: true},
+		{name: "expired", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": now.Add(-time.Minute).Unix()}))},
+		{name: "exp required", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"exp": nil}))},
+		{name: "not before", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"nbf": now.Add(time.Minute).Unix()}))},
+		{name: "not before within the skew", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"nbf": now.Add(10 * time.Second).Unix()})), valid: true},
+		{name: "issuer", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"iss": "https://other.test"}))},
+		{name: "audience", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"aud": []string{"other"}}))},
+		{name: "one of the audiences", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"aud": []string{"other", "api"}})), valid: true},
+		{name: "subject required", token: sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{"sub": nil}))},
+		{name: "wrong secret", token: sign(t, jwt.SigningMethodHS256, []byte("another secret of thirty two byte"), "", claims(nil))},
+		{name: "unknown kid", token: sign(t, jwt.SigningMethodRS256, rsaKey, "rsa-2", claims(nil))},
+		{name: "unsupported algorithm", token: sign(t, jwt.SigningMethodHS512, secret, "", claims(nil))},
+		{name: "none algorithm", token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims(nil))},
+		{name: "malformed", token: "a.b.c"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.Verify(context.Background(), tt.token)
+			if !tt.valid {
+				if !errors.Is(err, auth.ErrInvalidToken) {
+					t.Fatalf("Verify() error = %v, want ErrInvalidToken", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+
+			if p.Subject != "user-1" || p.Method != auth.MethodJWT || p.Issuer != "https://issuer.test" {
+				t.Errorf("Verify() = %+v", p)
+			}
+		})
+	}
+
+	t.Run("claims of the principal", func(t *testing.T) {
+		token := sign(t, jwt.SigningMethodHS256, secret, "", claims(jwt.MapClaims{
+			"scope": "products:read products:write",
+			"roles": []string{"staff"},
+		}))
+
+		p, err := v.Verify(context.Background(), token)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(p.Scopes, []string{"products:read", "products:write"}) || !p.HasScope("products:write") {
+			t.Errorf("Scopes = %v", p.Scopes)
+		}
+
+		if !p.HasRole("staff") || p.HasRole("admin") {
+			t.Errorf("Roles = %v", p.Roles)
+		}
+
+		if !p.ExpiresAt.Equal(now.Add(time.Minute)) {
+			t.Errorf("ExpiresAt = %v", p.ExpiresAt)
+		}
+	})
+
+	t.Run("algorithm of the key", func(t *testing.T) {
+		// an HS256 token signed with the public key of the jwks
+		// must not verify with it
+		v := auth.NewVerifier(auth.NewJWKSFile(path), auth.WithTimeFunc(func() time.Time { return now }))
+
+		pub := []byte(jwkOf("rsa-1", rsaKey)["n"].(string))
+		token := sign(t, jwt.SigningMethodHS256, pub, "rsa-1", claims(nil))
+
+		if _, err := v.Verify(context.Background(), token); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("Verify() error = %v, want ErrInvalidToken", err)
+		}
+	})
+}
@@ -0,0 +1,352 @@
+// package batch serves many api requests in one round trip
+// the sub requests are dispatched in process through the router
+// so they pass the same middlewares as the direct requests
+package batch
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+// methods contains the methods a sub request can use
+var methods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Request is a sub request of a batch
+type Request struct {
+	// ID identifies the request in the batch
+	ID      string            `json:"id" validate:"required"`
+	Method  string            `json:"method" validate:"required"`
+	Path    string            `json:"path" validate:"required"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// DependsOn contains the ids of the requests which must succeed
+	// before this one is dispatched
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// Batch is the body of a batch request
+type Batch struct {
+	// Atomic dispatches the requests one by one in one
+	// transaction which is rolled back if any of them fails
+	Atomic   bool      `json:"atomic"`
+	Requests []Request `json:"requests" validate:"required"`
+}
+
+// Response is the response of a sub request
+type Response struct {
+	ID      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Result is the response of a batch request
+// the responses are in the order of the requests
+type Result struct {
+	Atomic bool `json:"atomic"`
+	// Committed reports if the transaction of an atomic batch
+	// was committed, the writes are rolled back otherwise
+	Committed *bool      `json:"committed,omitempty"`
+	Responses []Response `json:"responses"`
+}
+
+type Option func(*Executor)
+
+// WithDB enables the atomic mode
+// the storages must run their queries with sqlext.Conn
+func WithDB(db *sql.DB) Option {
+	return func(e *Executor) {
+		e.db = db
+	}
+}
+
+// WithMaxSize sets the maximum number of requests of a batch
+func WithMaxSize(n int) Option {
+	return func(e *Executor) {
+		e.maxSize = n
+	}
+}
+
+// WithConcurrency sets the number of requests dispatched at the same time
+func WithConcurrency(n int) Option {
+	return func(e *Executor) {
+		e.concurrency = n
+	}
+}
+
+// Executor dispatches the requests of a batch to the handler
+type Executor struct {
+	handler http.Handler
+	// pattern is the path of the batch route
+	// the nested batches are rejected
+	pattern     string
+	db          *sql.DB
+	maxSize     int
+	concurrency int
+}
+
+// New initializes an Executor
+// handler is the router the sub requests are dispatched to
+func New(handler http.Handler, pattern string, opts ...Option) *Executor {
+	e := &Executor{
+		handler:     handler,
+		pattern:     pattern,
+		maxSize:     constant.BatchMaxSize,
+		concurrency: constant.BatchConcurrency,
+	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// Handler serves the batch request
+// it responds with 200 and the response of every sub request
+// the request is rejected with 400 if the batch is invalid
+func (e *Executor) Handler(w http.ResponseWriter, r *http.Request) {
+	var b Batch
+	err := httpext.DecodeJSON(w, r, &b, httpext.WithMaxBytes(constant.BatchMaxBytes))
+	if err != nil {
+		respondError(w, httpext.BodyErrorCode(err), err)
+		return
+	}
+
+	order, err := e.plan(b)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var res Result
+	if b.Atomic {
+		res, err = e.executeAtomic(r, b, order)
+	} else {
+		res = e.execute(r, b, order)
+	}
+
+	if err != nil {
+		log.Printf("batch execute returned error: %v", err)
+		respondError(w, http.StatusInternalServerError, errors.New(constant.InternalServerError))
+		return
+	}
+
+	_, err = response.Respond(w, http.StatusOK, response.NewResponse(res))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// plan validates the batch and returns the order of the requests
+// a request comes after the requests it depends on
+func (e *Executor) plan(b Batch) ([]int, error) {
+	switch {
+	case len(b.Requests) == 0:
+		return nil, errors.New(constant.BatchEmpty)
+	case len(b.Requests) > e.maxSize:
+		return nil, fmt.Errorf("%s: %d", constant.BatchTooLarge, e.maxSize)
+	case b.Atomic && e.db == nil:
+		return nil, errors.New(constant.BatchAtomicUnsupported)
+	}
+
+	index := make(map[string]int, len(b.Requests))
+	for i, req := range b.Requests {
+		if err := e.validate(req); err != nil {
+			return nil, err
+		}
+
+		if _, ok := index[req.ID]; ok {
+			return nil, fmt.Errorf("%s: %s", constant.BatchDuplicateID, req.ID)
+		}
+
+		index[req.ID] = i
+	}
+
+	// the number of unresolved dependencies of every request
+	pending := make([]int, len(b.Requests))
+	dependents := make([][]int, len(b.Requests))
+
+	for i, req := range b.Requests {
+		for _, dep := range req.DependsOn {
+			j, ok := index[dep]
+			if !ok {
+				return nil, fmt.Errorf("%s: %s", constant.BatchUnknownDependency, dep)
+			}
+
+			pending[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	// kahn's algorithm, the independent requests keep their order
+	order := make([]int, 0, len(b.Requests))
+	for i := range b.Requests {
+		if pending[i] == 0 {
+			order = append(order, i)
+		}
+	}
+
+	for k := 0; k < len(order); k++ {
+		for _, i := range dependents[order[k]] {
+			pending[i]--
+			if pending[i] == 0 {
+				order = append(order, i)
+			}
+		}
+	}
+
+	if len(order) != len(b.Requests) {
+		return nil, errors.New(constant.BatchDependencyCycle)
+	}
+
+	return order, nil
+}
+
+// validate checks a sub request
+func (e *Executor) validate(req Request) error {
+	if req.ID == "" || !slices.Contains(methods, req.Method) {
+		return fmt.Errorf("%s: %s", constant.BatchInvalidRequest, req.ID)
+	}
+
+	u, err := url.Parse(req.Path)
+	if err != nil || u.IsAbs() || u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return fmt.Errorf("%s: %s", constant.BatchInvalidRequest, req.ID)
+	}
+
+	if strings.HasPrefix(u.Path, e.pattern) {
+		return fmt.Errorf("%s: %s", constant.BatchInvalidRequest, req.ID)
+	}
+
+	return nil
+}
+
+// execute dispatches the requests concurrently
+// a request waits for the requests it depends on
+func (e *Executor) execute(r *http.Request, b Batch, order []int) Result {
+	res := Result{Responses: make([]Response, len(b.Requests))}
+
+	index := make(map[string]int, len(b.Requests))
+	done := make([]chan struct{}, len(b.Requests))
+	for i, req := range b.Requests {
+		index[req.ID] = i
+		done[i] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, e.concurrency)
+
+	var wg sync.WaitGroup
+
+	// the semaphore is acquired after the dependencies are
+	// done so it is never held by a waiting request
+	for _, i := range order {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+
+			req := b.Requests[i]
+
+			for _, dep := range req.DependsOn {
+				j := index[dep]
+				<-done[j]
+
+				if failed(res.Responses[j]) {
+					res.Responses[i] = failedDependency(req.ID)
+					return
+				}
+			}
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			res.Responses[i] = e.dispatch(r.Context(), r, req, false)
+		}()
+	}
+
+	wg.Wait()
+
+	return res
+}
+
+// executeAtomic dispatches the requests one by one in a transaction
+// the first failure rolls back the transaction and
+// the remaining requests are not dispatched
+func (e *Executor) executeAtomic(r *http.Request, b Batch, order []int) (Result, error) {
+	res := Result{Atomic: true, Responses: make([]Response, len(b.Requests))}
+
+	tx, err := e.db.BeginTx(r.Context(), nil)
+	if err != nil {
+		return res, err
+	}
+
+	ctx := sqlext.WithTx(r.Context(), tx)
+
+	ok := true
+
+	for _, i := range order {
+		req := b.Requests[i]
+
+		if !ok {
+			res.Responses[i] = failedDependency(req.ID)
+			continue
+		}
+
+		res.Responses[i] = e.dispatch(ctx, r, req, true)
+		ok = !failed(res.Responses[i])
+	}
+
+	if !ok {
+		if err := tx.Rollback(); err != nil {
+			log.Printf("batch rollback returned error: %v", err)
+		}
+
+		res.Committed = &ok
+		return res, nil
+	}
+
+	// the AfterCommit functions of the requests run after the commit
+	if err := sqlext.Commit(ctx); err != nil {
+		return res, err
+	}
+
+	res.Committed = &ok
+
+	return res, nil
+}
+
+// failed reports if the response is an error
+func failed(res Response) bool {
+	return res.Status >= http.StatusBadRequest
+}
+
+// failedDependency is the response of a request
+// which was not dispatched because of a failure
+func failedDependency(id string) Response {
+	body, _ := json.Marshal(response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(constant.FailedDependency)}))
+
+	return Response{
+		ID:      id,
+		Status:  http.StatusFailedDependency,
+		Headers: map[string]string{"Content-Type": "application/json"},
+		Body:    body,
+	}
+}
+
+func respondError(w http.ResponseWriter, code int, err error) {
+	response.RespondError(w, code, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+}
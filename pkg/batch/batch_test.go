@@ -0,0 +1,220 @@
+package batch_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/batch"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+type result struct {
+	Data batch.Result `json:"data"`
+}
+
+// newRouter builds a router with the batch route and test routes
+// /items/{id} echoes the id, /fail responds with 400 and
+// /tx responds with 200 if the request runs in a transaction
+func newRouter(opts ...batch.Option) (*chi.Mux, *[]string) {
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls = append(calls, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	r.Get("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"` + chi.URLParam(r, "id") + `","auth":"` + r.Header.Get("Authorization") + `"}`))
+	})
+	r.Post("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	r.Get("/text", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("plain"))
+	})
+	r.Post("/tx", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := sqlext.TxFromContext(r.Context()); !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	r.Post("/batch", batch.New(r, "/batch", opts...).Handler)
+
+	return r, &calls
+}
+
+func do(t *testing.T, h http.Handler, body string) (int, batch.Result) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var res result
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+
+	return w.Code, res.Data
+}
+
+func TestBatch(t *testing.T) {
+	r, calls := newRouter()
+
+	code, res := do(t, r, `{"requests":[
+		{"id":"c","method":"GET","path":"/items/3","dependsOn":["b"]},
+		{"id":"a","method":"GET","path":"/items/1","headers":{"Authorization":"Bearer other"}},
+		{"id":"b","method":"POST","path":"/fail"},
+		{"id":"d","method":"GET","path":"/text"},
+		{"id":"e","method":"GET","path":"/missing"}
+	]}`)
+	if code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	expected := []struct {
+		id     string
+		status int
+		body   string
+	}{
+		{id: "c", status: http.StatusFailedDependency},
+		{id: "a", status: http.StatusOK, body: `{"id":"1","auth":"Bearer other"}`},
+		{id: "b", status: http.StatusBadRequest},
+		{id: "d", status: http.StatusOK, body: `"plain"`},
+		{id: "e", status: http.StatusNotFound},
+	}
+
+	for i, e := range expected {
+		got := res.Responses[i]
+		if got.ID != e.id || got.Status != e.status {
+			t.Errorf("expected %s %d, got %s %d", e.id, e.status, got.ID, got.Status)
+		}
+
+		if e.body != "" && string(got.Body) != e.body {
+			t.Errorf("expected body %s, got %s", e.body, got.Body)
+		}
+	}
+
+	for _, c := range *calls {
+		if c == "GET /items/3" {
+			t.Errorf("the request of a failed dependency must not be dispatched")
+		}
+	}
+}
+
+func TestBatchHeaders(t *testing.T) {
+	r, _ := newRouter()
+
+	_, res := do(t, r, `{"requests":[{"id":"a","method":"GET","path":"/items/1"}]}`)
+
+	if string(res.Responses[0].Body) != `{"id":"1","auth":"Bearer token"}` {
+		t.Errorf("expected the batch headers, got %s", res.Responses[0].Body)
+	}
+}
+
+func TestBatchInvalid(t *testing.T) {
+	r, _ := newRouter(batch.WithMaxSize(2))
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "empty", body: `{"requests":[]}`},
+		{name: "too large", body: `{"requests":[{"id":"a","method":"GET","path":"/items/1"},{"id":"b","method":"GET","path":"/items/1"},{"id":"c","method":"GET","path":"/items/1"}]}`},
+		{name: "duplicate id", body: `{"requests":[{"id":"a","method":"GET","path":"/items/1"},{"id":"a","method":"GET","path":"/items/2"}]}`},
+		{name: "unknown dependency", body: `{"requests":[{"id":"a","method":"GET","path":"/items/1","dependsOn":["x"]}]}`},
+		{name: "cycle", body: `{"requests":[{"id":"a","method":"GET","path":"/items/1","dependsOn":["b"]},{"id":"b","method":"GET","path":"/items/2","dependsOn":["a"]}]}`},
+		{name: "method", body: `{"requests":[{"id":"a","method":"TRACE","path":"/items/1"}]}`},
+		{name: "absolute url", body: `{"requests":[{"id":"a","method":"GET","path":"http://example.com/items/1"}]}`},
+		{name: "nested", body: `{"requests":[{"id":"a","method":"POST","path":"/batch"}]}`},
+		{name: "atomic unsupported", body: `{"atomic":true,"requests":[{"id":"a","method":"POST","path":"/tx"}]}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if code, _ := do(t, r, tc.body); code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", code)
+			}
+		})
+	}
+}
+
+func TestBatchAtomic(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		r, _ := newRouter(batch.WithDB(db))
+
+		_, res := do(t, r, `{"atomic":true,"requests":[{"id":"a","method":"POST","path":"/tx"},{"id":"b","method":"POST","path":"/tx"}]}`)
+
+		if res.Committed == nil || !*res.Committed {
+			t.Errorf("expected committed, got %+v", res)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		r, calls := newRouter(batch.WithDB(db))
+
+		_, res := do(t, r, `{"atomic":true,"requests":[{"id":"a","method":"POST","path":"/tx"},{"id":"b","method":"POST","path":"/fail"},{"id":"c","method":"POST","path":"/tx"}]}`)
+
+		if res.Committed == nil || *res.Committed {
+			t.Errorf("expected not committed, got %+v", res)
+		}
+
+		if res.Responses[2].Status != http.StatusFailedDependency {
+			t.Errorf("expected 424, got %d", res.Responses[2].Status)
+		}
+
+		// the batch request and two sub requests
+		if len(*calls) != 3 {
+			t.Errorf("expected the requests after the failure to be skipped, got %v", *calls)
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// dispatch serves the sub request with the handler
+// the headers of the batch request apply to every sub request,
+// ex: Authorization, the headers of the sub request override them
+// the Idempotency-Key header is dropped in the atomic mode
+// as a stored response could outlive a rolled back write
+func (e *Executor) dispatch(ctx context.Context, parent *http.Request, req Request, atomic bool) (res Response) {
+	// the sub request is routed from the root
+	ctx = context.WithValue(ctx, chi.RouteCtxKey, nil)
+
+	var body io.Reader = http.NoBody
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+
+	sub, err := http.NewRequestWithContext(ctx, req.Method, req.Path, body)
+	if err != nil {
+		return errorResponse(req.ID, http.StatusBadRequest, err)
+	}
+
+	sub.Header = parent.Header.Clone()
+	sub.Header.Del("Content-Length")
+
+	for k, v := range req.Headers {
+		sub.Header.Set(k, v)
+	}
+
+	if atomic {
+		sub.Header.Del(constant.HeaderIdempotencyKey)
+	}
+
+	sub.Host = parent.Host
+	sub.RemoteAddr = parent.RemoteAddr
+
+	rec := newRecorder()
+
+	defer func() {
+		// a handler can abort its response, ex: a failed stream
+		if p := recover(); p != nil {
+			log.Printf("batch request %s panicked: %v", req.ID, p)
+			res = errorResponse(req.ID, http.StatusInternalServerError, errors.New(constant.InternalServerError))
+		}
+	}()
+
+	e.handler.ServeHTTP(rec, sub)
+
+	return rec.response(req.ID)
+}
+
+func errorResponse(id string, code int, err error) Response {
+	rec := newRecorder()
+	respondError(rec, code, err)
+
+	return rec.response(id)
+}
+
+// recorder captures the response of a sub request
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRecorder() *recorder {
+	return &recorder{header: make(http.Header)}
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.body.Write(b)
+}
+
+// Flush is a no-op, the response is sent with the batch
+func (r *recorder) Flush() {}
+
+// response converts the captured response
+// a body which is not JSON is encoded as a JSON string
+func (r *recorder) response(id string) Response {
+	res := Response{ID: id, Status: r.status}
+	if res.Status == 0 {
+		res.Status = http.StatusOK
+	}
+
+	if len(r.header) > 0 {
+		res.Headers = make(map[string]string, len(r.header))
+		for k, v := range r.header {
+			res.Headers[k] = strings.Join(v, ", ")
+		}
+	}
+
+	switch b := r.body.Bytes(); {
+	case len(b) == 0:
+	case json.Valid(b):
+		res.Body = bytes.TrimSpace(b)
+	default:
+		res.Body, _ = json.Marshal(string(b))
+	}
+
+	return res
+}
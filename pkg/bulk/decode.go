@@ -0,0 +1,268 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"path/filepath"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+var ErrInvalidMapping = errors.New(constant.InvalidMapping)
+
+// Row is a decoded record of an upload
+// Number is the 1 based position of the record, the CSV header
+// and the blank NDJSON lines are not counted
+// Err is set if the record can not be decoded
+type Row[T any] struct {
+	Number int
+	Value  T
+	Err    error
+}
+
+// Mapping maps the column names of the upload to the field names
+// the unmapped columns keep their name, "-" drops the column
+type Mapping map[string]string
+
+// ParseMapping parses the source:field pairs of s
+// ex: title:name,details:description
+func ParseMapping(s string) (Mapping, error) {
+	m := Mapping{}
+	if s == "" {
+		return m, nil
+	}
+
+	for _, p := range strings.Split(s, ",") {
+		src, field, ok := strings.Cut(p, ":")
+		src, field = strings.TrimSpace(src), strings.TrimSpace(field)
+		if !ok || src == "" || field == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMapping, p)
+		}
+
+		m[src] = field
+	}
+
+	return m, nil
+}
+
+// field returns the field name of the column
+// an empty name means the column is dropped
+// mapped is true if the column is in the mapping
+func (m Mapping) field(column string) (name string, mapped bool) {
+	f, ok := m[column]
+	if !ok {
+		return column, false
+	}
+
+	if f == "-" {
+		return "", true
+	}
+
+	return f, true
+}
+
+// fields maps the columns to the field names
+// a mapped column takes precedence over an unmapped
+// column of the same name, the other one is dropped
+func (m Mapping) fields(columns []string) []string {
+	fields := make([]string, len(columns))
+	taken := make(map[string]bool)
+
+	for i, c := range columns {
+		if f, mapped := m.field(c); mapped {
+			fields[i] = f
+			taken[f] = true
+		}
+	}
+
+	for i, c := range columns {
+		if f, mapped := m.field(c); !mapped && !taken[f] {
+			fields[i] = f
+		}
+	}
+
+	return fields
+}
+
+// DetectFormat selects the format from the format query parameter,
+// the media type or the file extension of the upload, in that order
+func DetectFormat(param, contentType, filename string) (response.StreamFormat, error) {
+	if param != "" {
+		switch f := response.StreamFormat(strings.ToLower(param)); f {
+		case response.FormatCSV, response.FormatNDJSON:
+			return f, nil
+		default:
+			return "", fmt.Errorf("%w: %s", response.ErrUnsupportedFormat, param)
+		}
+	}
+
+	if t, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch t {
+		case "text/csv":
+			return response.FormatCSV, nil
+		case "application/x-ndjson", "application/jsonl":
+			return response.FormatNDJSON, nil
+		}
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return response.FormatCSV, nil
+	case ".ndjson", ".jsonl":
+		return response.FormatNDJSON, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", response.ErrUnsupportedFormat, contentType)
+}
+
+// Decode yields the records of r decoded into T
+// the CSV values are decoded as JSON strings so T must use
+// string fields for them
+// a record which can not be decoded is yielded with Row.Err set,
+// an error of the reader is yielded once and ends the iteration
+func Decode[T any](r io.Reader, format response.StreamFormat, mapping Mapping) iter.Seq2[Row[T], error] {
+	if format == response.FormatCSV {
+		return decodeCSV[T](r, mapping)
+	}
+
+	return decodeNDJSON[T](r, mapping)
+}
+
+func decodeCSV[T any](r io.Reader, mapping Mapping) iter.Seq2[Row[T], error] {
+	return func(yield func(Row[T], error) bool) {
+		cr := csv.NewReader(r)
+
+		header, err := cr.Read()
+		if err != nil {
+			// an empty upload has no rows
+			if err != io.EOF {
+				yield(Row[T]{}, err)
+			}
+			return
+		}
+
+		columns := make([]string, len(header))
+		for i, h := range header {
+			// spreadsheet exports can start with a byte order mark
+			if i == 0 {
+				h = strings.TrimPrefix(h, "\ufeff")
+			}
+
+			columns[i] = strings.TrimSpace(h)
+		}
+
+		fields := mapping.fields(columns)
+
+		for n := 1; ; n++ {
+			rec, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+
+			row := Row[T]{Number: n}
+
+			var pe *csv.ParseError
+			switch {
+			case errors.As(err, &pe):
+				// the reader continues with the next record
+				row.Err = pe.Err
+			case err != nil:
+				yield(row, err)
+				return
+			default:
+				row.Err = decodeRecord(fields, rec, &row.Value)
+			}
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+	}
+}
+
+// decodeRecord decodes the non empty values of rec into v
+func decodeRecord[T any](fields, rec []string, v *T) error {
+	m := make(map[string]string, len(fields))
+	for i, f := range fields {
+		if f != "" && rec[i] != "" {
+			m[f] = rec[i]
+		}
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+func decodeNDJSON[T any](r io.Reader, mapping Mapping) iter.Seq2[Row[T], error] {
+	return func(yield func(Row[T], error) bool) {
+		s := bufio.NewScanner(r)
+		s.Buffer(make([]byte, 0, 64*1024), constant.ImportMaxLineBytes)
+
+		n := 0
+
+		for s.Scan() {
+			line := bytes.TrimSpace(s.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+
+			n++
+
+			row := Row[T]{Number: n}
+			row.Err = decodeLine(line, mapping, &row.Value)
+
+			if !yield(row, nil) {
+				return
+			}
+		}
+
+		if err := s.Err(); err != nil {
+			yield(Row[T]{}, err)
+		}
+	}
+}
+
+// decodeLine decodes the JSON object of line into v
+// the keys are renamed by the mapping first
+func decodeLine[T any](line []byte, mapping Mapping, v *T) error {
+	if len(mapping) == 0 {
+		return json.Unmarshal(line, v)
+	}
+
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(line, &m); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	mapped := make(map[string]json.RawMessage, len(m))
+	for i, f := range mapping.fields(keys) {
+		if f != "" {
+			mapped[f] = m[keys[i]]
+		}
+	}
+
+	b, err := json.Marshal(mapped)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,143 @@
+package bulk_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/bulk"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+type item struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   response.StreamFormat
+		mapping  string
+		body     string
+		expected []string
+		invalid  []int
+	}{
+		{
+			name:     "csv",
+			format:   response.FormatCSV,
+			body:     "\ufeffname,description\na,x\n\"b,c\",\n",
+			expected: []string{"a", "b,c"},
+		},
+		{
+			name:     "csv mapping",
+			format:   response.FormatCSV,
+			mapping:  "title:name,sku:-",
+			body:     "title,sku\na,1\n",
+			expected: []string{"a"},
+		},
+		{
+			name:     "csv field count",
+			format:   response.FormatCSV,
+			body:     "name,description\na,x,y\nb,x\n",
+			expected: []string{"", "b"},
+			invalid:  []int{1},
+		},
+		{
+			name:     "csv empty",
+			format:   response.FormatCSV,
+			body:     "",
+			expected: nil,
+		},
+		{
+			name:     "ndjson",
+			format:   response.FormatNDJSON,
+			body:     "{\"name\":\"a\"}\n\n{\"name\":1}\n{\"name\":\"c\"}",
+			expected: []string{"a", "", "c"},
+			invalid:  []int{2},
+		},
+		{
+			name:     "ndjson mapping",
+			format:   response.FormatNDJSON,
+			mapping:  "title:name",
+			body:     "{\"title\":\"a\",\"name\":\"ignored\"}\n",
+			expected: []string{"a"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := bulk.ParseMapping(tc.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var (
+				got     []string
+				invalid []int
+			)
+
+			for row, err := range bulk.Decode[item](strings.NewReader(tc.body), tc.format, m) {
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if row.Number != len(got)+1 {
+					t.Errorf("expected row %d, got %d", len(got)+1, row.Number)
+				}
+
+				if row.Err != nil {
+					invalid = append(invalid, row.Number)
+				}
+
+				got = append(got, row.Value.Name)
+			}
+
+			if strings.Join(got, "|") != strings.Join(tc.expected, "|") || len(got) != len(tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+
+			if len(invalid) != len(tc.invalid) {
+				t.Errorf("expected invalid rows %v, got %v", tc.invalid, invalid)
+			}
+		})
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	if _, err := bulk.ParseMapping("title"); !errors.Is(err, bulk.ErrInvalidMapping) {
+		t.Errorf("expected ErrInvalidMapping, got %v", err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		param       string
+		contentType string
+		filename    string
+		expected    response.StreamFormat
+		err         bool
+	}{
+		{name: "param", param: "NDJSON", contentType: "text/csv", expected: response.FormatNDJSON},
+		{name: "media type", contentType: "text/csv; charset=utf-8", expected: response.FormatCSV},
+		{name: "extension", contentType: "application/octet-stream", filename: "products.jsonl", expected: response.FormatNDJSON},
+		{name: "unsupported", contentType: "application/json", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := bulk.DetectFormat(tc.param, tc.contentType, tc.filename)
+			if tc.err {
+				if !errors.Is(err, response.ErrUnsupportedFormat) {
+					t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+				}
+				return
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package bulk
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Status is the state of a job
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCanceled  Status = "canceled"
+)
+
+// RowStatus is the outcome of a row
+type RowStatus string
+
+const (
+	RowCreated RowStatus = "created"
+	RowUpdated RowStatus = "updated"
+	// RowValid is the outcome of a valid row in a dry run
+	RowValid   RowStatus = "valid"
+	RowInvalid RowStatus = "invalid"
+	RowFailed  RowStatus = "failed"
+)
+
+// ReportCSVHeader is the header of the CSV report
+var ReportCSVHeader = []string{"row", "status", "id", "errors"}
+
+// RowResult is the report entry of a row
+type RowResult struct {
+	Row    int       `json:"row"`
+	Status RowStatus `json:"status"`
+	ID     string    `json:"id,omitempty"`
+	Errors []string  `json:"errors,omitempty"`
+}
+
+func (r RowResult) CSVRecord() []string {
+	return []string{strconv.Itoa(r.Row), string(r.Status), r.ID, strings.Join(r.Errors, "; ")}
+}
+
+// Invalid builds the result of a row which failed the validation
+func Invalid(errs ...error) RowResult {
+	return RowResult{Status: RowInvalid, Errors: messages(errs)}
+}
+
+// Failed builds the result of a valid row which could not be stored
+func Failed(err error) RowResult {
+	return RowResult{Status: RowFailed, Errors: messages([]error{err})}
+}
+
+func messages(errs []error) []string {
+	m := make([]string, len(errs))
+	for i, err := range errs {
+		m[i] = err.Error()
+	}
+
+	return m
+}
+
+// Summary counts the rows by their outcome
+type Summary struct {
+	Rows    int `json:"rows"`
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+	Failed  int `json:"failed"`
+}
+
+func (s *Summary) add(status RowStatus) {
+	s.Rows++
+
+	switch status {
+	case RowCreated:
+		s.Created++
+	case RowUpdated:
+		s.Updated++
+	case RowValid:
+		s.Valid++
+	case RowInvalid:
+		s.Invalid++
+	case RowFailed:
+		s.Failed++
+	}
+}
+
+// Source is the upload of a job
+type Source struct {
+	// Body is closed when the job ends
+	Body io.ReadCloser
+	// Size is the length of Body in bytes, -1 if unknown
+	// it is used for the progress
+	Size    int64
+	Format  response.StreamFormat
+	Mapping Mapping
+}
+
+// Process handles a decoded row and returns its result
+// RowResult.Row is set by the job
+type Process[T any] func(ctx context.Context, v T) RowResult
+
+// Job is an import of an upload
+type Job struct {
+	id        string
+	dryRun    bool
+	size      int64
+	createdAt int64
+	done      chan struct{}
+
+	// read counts the bytes of the upload read so far
+	read atomic.Int64
+
+	mu         sync.Mutex
+	status     Status
+	summary    Summary
+	results    []RowResult
+	err        error
+	finishedAt int64
+}
+
+// JobView is the state of a job as it is polled
+type JobView struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	DryRun bool   `json:"dryRun"`
+	// Progress is the percentage of the upload processed,
+	// it is omitted if the size of the upload is unknown
+	Progress   *int    `json:"progress,omitempty"`
+	Summary    Summary `json:"summary"`
+	Error      string  `json:"error,omitempty"`
+	CreatedAt  int64   `json:"createdAt"`
+	FinishedAt int64   `json:"finishedAt,omitempty"`
+}
+
+func newJob(size int64, dryRun bool) *Job {
+	return &Job{
+		id:        uuid.NewString(),
+		dryRun:    dryRun,
+		size:      size,
+		createdAt: time.Now().Unix(),
+		done:      make(chan struct{}),
+		status:    StatusPending,
+	}
+}
+
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Done is closed when the job ends
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Err returns the error which ended the job
+func (j *Job) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.err
+}
+
+// Finished reports if the job ended
+func (j *Job) Finished() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Results returns the report of the rows processed so far
+func (j *Job) Results() []RowResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.results[:len(j.results):len(j.results)]
+}
+
+func (j *Job) View() JobView {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	v := JobView{
+		ID:         j.id,
+		Status:     j.status,
+		DryRun:     j.dryRun,
+		Summary:    j.summary,
+		CreatedAt:  j.createdAt,
+		FinishedAt: j.finishedAt,
+	}
+
+	if j.err != nil {
+		v.Error = j.err.Error()
+	}
+
+	if j.size > 0 {
+		p := int(min(j.read.Load()*100/j.size, 100))
+		if j.status == StatusCompleted {
+			p = 100
+		}
+
+		v.Progress = &p
+	}
+
+	return v
+}
+
+func (j *Job) setStatus(s Status) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.status = s
+}
+
+func (j *Job) record(r RowResult) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.summary.add(r.Status)
+	j.results = append(j.results, r)
+}
+
+func (j *Job) finish(s Status, err error) {
+	j.mu.Lock()
+	j.status = s
+	j.err = err
+	j.finishedAt = time.Now().Unix()
+	j.mu.Unlock()
+
+	close(j.done)
+}
+
+// expired reports if the job ended before t
+func (j *Job) expired(t int64) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.finishedAt != 0 && j.finishedAt < t
+}
+
+// countingReader counts the bytes read from r
+type countingReader struct {
+	r io.Reader
+	n *atomic.Int64
+}
+
+func (c countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+// Jobs runs the jobs in the background and keeps
+// them in memory so they can be polled
+// the jobs are local to the instance
+type Jobs struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	// sem limits the jobs processed at the same time
+	sem chan struct{}
+	ttl time.Duration
+
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewJobs initializes the jobs
+// concurrency is the number of jobs processed at the same time
+// the finished jobs are removed after ttl
+func NewJobs(concurrency int, ttl time.Duration) *Jobs {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Jobs{
+		ctx:    ctx,
+		cancel: cancel,
+		sem:    make(chan struct{}, concurrency),
+		ttl:    ttl,
+		jobs:   make(map[string]*Job),
+	}
+}
+
+// Get returns the job of the id
+func (j *Jobs) Get(id string) (*Job, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	job, ok := j.jobs[id]
+	return job, ok
+}
+
+// add stores the job and removes the expired ones
+func (j *Jobs) add(job *Job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	t := time.Now().Add(-j.ttl).Unix()
+	for id, v := range j.jobs {
+		if v.expired(t) {
+			delete(j.jobs, id)
+		}
+	}
+
+	j.jobs[job.id] = job
+}
+
+// Stop cancels the running jobs and waits for them to end
+func (j *Jobs) Stop(ctx context.Context) error {
+	j.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		j.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run starts a job which decodes the rows of src and
+// passes the decoded ones to process
+// the rows which can not be decoded are reported as invalid
+func Run[T any](jobs *Jobs, src Source, dryRun bool, process Process[T]) *Job {
+	job := newJob(src.Size, dryRun)
+	jobs.add(job)
+
+	jobs.wg.Add(1)
+
+	go func() {
+		defer jobs.wg.Done()
+		defer src.Body.Close()
+
+		run(jobs.ctx, jobs.sem, job, src, process)
+	}()
+
+	return job
+}
+
+func run[T any](ctx context.Context, sem chan struct{}, job *Job, src Source, process Process[T]) {
+	select {
+	case sem <- struct{}{}:
+		defer func() { <-sem }()
+	case <-ctx.Done():
+		job.finish(StatusCanceled, ctx.Err())
+		return
+	}
+
+	job.setStatus(StatusRunning)
+
+	r := countingReader{r: src.Body, n: &job.read}
+
+	for row, err := range Decode[T](r, src.Format, src.Mapping) {
+		if err != nil {
+			job.finish(StatusFailed, err)
+			return
+		}
+
+		if err := ctx.Err(); err != nil {
+			job.finish(StatusCanceled, err)
+			return
+		}
+
+		var res RowResult
+		if row.Err != nil {
+			res = Invalid(row.Err)
+		} else {
+			res = process(ctx, row.Value)
+		}
+
+		res.Row = row.Number
+		job.record(res)
+	}
+
+	job.finish(StatusCompleted, nil)
+}
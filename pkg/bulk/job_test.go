@@ -0,0 +1,82 @@
+package bulk_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/bulk"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+func process(ctx context.Context, v item) bulk.RowResult {
+	switch v.Name {
+	case "":
+		return bulk.Invalid(errors.New("name is required"))
+	case "fail":
+		return bulk.Failed(errors.New("boom"))
+	case "old":
+		return bulk.RowResult{Status: bulk.RowUpdated, ID: v.Name}
+	default:
+		return bulk.RowResult{Status: bulk.RowCreated, ID: v.Name}
+	}
+}
+
+func TestRun(t *testing.T) {
+	jobs := bulk.NewJobs(1, time.Hour)
+	defer jobs.Stop(context.Background())
+
+	body := "name\nnew\nold\n\"\"\nfail\n\"bad\n"
+	src := bulk.Source{
+		Body:   io.NopCloser(strings.NewReader(body)),
+		Size:   int64(len(body)),
+		Format: response.FormatCSV,
+	}
+
+	job := bulk.Run(jobs, src, false, process)
+	<-job.Done()
+
+	if got, ok := jobs.Get(job.ID()); !ok || got != job {
+		t.Fatalf("expected the job to be stored")
+	}
+
+	v := job.View()
+	if v.Status != bulk.StatusCompleted || v.Progress == nil || *v.Progress != 100 {
+		t.Fatalf("unexpected view %+v", v)
+	}
+
+	expected := bulk.Summary{Rows: 5, Created: 1, Updated: 1, Invalid: 2, Failed: 1}
+	if v.Summary != expected {
+		t.Errorf("expected summary %+v, got %+v", expected, v.Summary)
+	}
+
+	results := job.Results()
+	for i, r := range results {
+		if r.Row != i+1 {
+			t.Errorf("expected row %d, got %d", i+1, r.Row)
+		}
+	}
+
+	if rec := results[2].CSVRecord(); strings.Join(rec, ",") != "3,invalid,,name is required" {
+		t.Errorf("unexpected csv record %q", rec)
+	}
+}
+
+func TestJobsStop(t *testing.T) {
+	jobs := bulk.NewJobs(1, time.Hour)
+
+	if err := jobs.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	src := bulk.Source{Body: io.NopCloser(strings.NewReader("name\na\n")), Size: -1, Format: response.FormatCSV}
+	job := bulk.Run(jobs, src, false, process)
+	<-job.Done()
+
+	if v := job.View(); v.Status != bulk.StatusCanceled || v.Progress != nil {
+		t.Errorf("expected canceled job without progress, got %+v", v)
+	}
+}
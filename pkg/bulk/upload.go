@@ -0,0 +1,95 @@
+package bulk
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+var ErrMissingFile = errors.New(constant.MissingImportFile)
+
+// Upload is the file of an import request
+type Upload struct {
+	Body        io.Reader
+	ContentType string
+	Filename    string
+}
+
+// ReadUpload returns the upload of r
+// a multipart/form-data request must carry the file in the
+// constant.ImportFormField part, any other request is the raw file
+// the multipart parts are read as a stream so the upload is not buffered
+func ReadUpload(r *http.Request) (Upload, error) {
+	t, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if t != "multipart/form-data" {
+		return Upload{Body: r.Body, ContentType: r.Header.Get("Content-Type")}, nil
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return Upload{}, err
+	}
+
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			return Upload{}, ErrMissingFile
+		}
+
+		if err != nil {
+			return Upload{}, err
+		}
+
+		if p.FormName() == constant.ImportFormField {
+			return Upload{Body: p, ContentType: p.Header.Get("Content-Type"), Filename: p.FileName()}, nil
+		}
+	}
+}
+
+// Spool copies r to a temporary file so the job can outlive the request
+// the file is removed when it is closed
+func Spool(r io.Reader) (io.ReadCloser, int64, error) {
+	f, err := os.CreateTemp("", "import-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s := &spooled{File: f}
+
+	n, err := io.Copy(f, r)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		s.Close()
+		return nil, 0, err
+	}
+
+	return s, n, nil
+}
+
+// spooled is a temporary file removed on close
+type spooled struct {
+	*os.File
+}
+
+func (s *spooled) Close() error {
+	err := s.File.Close()
+	if rmErr := os.Remove(s.Name()); err == nil {
+		err = rmErr
+	}
+
+	return err
+}
+
+// IsTooLarge reports if err is caused by an
+// upload exceeding the http.MaxBytesReader limit
+func IsTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
@@ -8,20 +8,225 @@ const DBDriverName = "pgx"
 const ServerReadTimeout = 30
 const ServerReadHeaderTimeout = 10
 const ServerWriteTimeout = 30
+const GRPCShutdownTimeout = 30 // in seconds, the calls still running after it are cancelled
+const TLSReloadInterval = 30   // in seconds, between the checks of the changed certificate files
 
 // request
 const RequestTimeout = 120
+const RequestBodyMaxBytes = 1 << 20 // JSON body limit, see httpext.DecodeJSON
+
+// pagination
+const PageDefaultLimit = 10         // items per page without the limit parameter
+const PageMaxLimit = 100            // larger limits are reduced to it
+const PageEstimateThreshold = 10000 // estimated totals above it are not counted exactly
+
+// streaming
+const StreamBatchSize = 500   // rows per cursor fetch
+const StreamFlushRows = 100   // rows per response flush
+const StreamWriteTimeout = 30 // in seconds, extended on every flush
+
+// batch
+const BatchMaxSize = 20       // sub requests per batch
+const BatchMaxBytes = 1 << 20 // batch request body limit
+const BatchConcurrency = 4    // sub requests dispatched at the same time
+
+// bulk import
+const ImportMaxBytes = 50 << 20    // upload size limit
+const ImportSyncMaxBytes = 1 << 20 // larger uploads run as asynchronous jobs
+const ImportMaxLineBytes = 1 << 20 // NDJSON line size limit
+const ImportReadTimeout = 300      // in seconds, read deadline of the upload
+const ImportConcurrency = 2        // jobs processed at the same time
+const ImportJobTTL = 60            // in minutes, finished jobs are kept for polling
+const ImportFormField = "file"     // multipart part of the upload
+
+// webhooks
+const WebhookTimeout = 10          // in seconds, per delivery attempt
+const WebhookPollInterval = 2      // in seconds, between the claims of the due deliveries
+const WebhookClaimSize = 20        // deliveries claimed per poll
+const WebhookConcurrency = 4       // deliveries sent at the same time
+const WebhookMaxAttempts = 8       // a delivery fails after it
+const WebhookBackoffBase = 30      // in seconds, doubled on every attempt
+const WebhookBackoffMax = 6 * 3600 // in seconds
+const WebhookDisableAfter = 20     // consecutive failed attempts disabling a subscription
+const WebhookSecretBytes = 32      // random bytes of a generated secret
+const WebhookSecretPrefix = "whsec_"
+
+// change feed
+const FeedBufferSize = 1000     // events kept for the resume with Last-Event-ID
+const FeedSubscriberBuffer = 64 // events queued per connection, a slower connection is dropped
+const FeedHeartbeat = 15        // in seconds, between the keep alive messages
+const FeedWriteTimeout = 10     // in seconds, write deadline of a message
+
+// graphql
+const GraphQLMaxDepth = 8         // nesting of the selections of an operation
+const GraphQLMaxComplexity = 1000 // cost of an operation, see package complexity
+const GraphQLListSize = 20        // cost multiplier of a list without a page limit
+const GraphQLBatchWait = 2        // in milliseconds, the loaders collect the ids during it
+const GraphQLMaxBatch = 100       // ids read per batch of a loader
+
+// authentication
+const JWTClockSkew = 30       // in seconds, tolerance of the exp, nbf and iat claims
+const JWKSCacheTTL = 10       // in minutes, a key set is fetched again after it
+const JWKSMinRefreshWait = 30 // in seconds, between the fetches of an unknown key id
+const JWKSMaxBytes = 1 << 20  // key set document limit
+
+// credentials
+const PasswordMemory = 19 * 1024 // in KiB, argon2id memory
+const PasswordTime = 2           // argon2id iterations
+const PasswordThreads = 1        // argon2id parallelism
+const PasswordSaltBytes = 16     // random bytes of a salt
+const PasswordKeyBytes = 32      // bytes of a derived key
+const PasswordMinLength = 8      // shorter passwords are rejected
+const PasswordMaxLength = 128    // longer passwords are rejected
+const AccessTokenTTL = 15        // in minutes
+const QueryTokenTTL = 15         // in minutes, the longest remaining lifetime of a token of the query
+const RefreshTokenTTL = 30 * 24  // in hours, a rotated token gets a new lifetime
+const OpaqueTokenBytes = 32      // random bytes of a refresh token
+
+// login lockout, the failed logins are counted per account and per client
+// address, after the free failures the next login waits a delay doubling
+// with every failure, the threshold locks the key for the lockout duration
+const LockoutAccountFree = 3       // failures of an account without a delay
+const LockoutAccountThreshold = 10 // failures locking an account
+const LockoutIPFree = 10           // failures of a client address without a delay
+const LockoutIPThreshold = 100     // failures locking a client address
+const LockoutDelay = 1             // in seconds, the first delay
+const LockoutMaxDelay = 60         // in seconds
+const LockoutDuration = 15         // in minutes
+const LockoutWindow = 60           // in minutes, the failures are counted again after it without a failure
+const LockoutCleanupInterval = 10  // in minutes, between the removals of the lockouts not counted anymore
+const LockoutReserveTries = 3      // reservations of a login attempt raced by the concurrent attempts before it is delayed
+
+// api keys
+const APIKeyPrefix = "bsk"        // marks the keys, ex: in the secret scanners
+const APIKeyIDBytes = 6           // random bytes of the visible part of a key
+const APIKeySecretBytes = 32      // random bytes of the secret part of a key
+const APIKeySaltBytes = 16        // random bytes of the salt of a key hash
+const APIKeyLastUsedInterval = 60 // in seconds, the last use is stored at most once per it
+const APIKeyMaxScopes = 32        // scopes of a key
+
+// openid connect
+const OIDCStateTTL = 10      // in minutes, the login must be completed in it
+const OIDCRandomBytes = 32   // random bytes of a state, a nonce and a code verifier
+const OIDCHTTPTimeout = 10   // in seconds, of the requests to a provider
+const OIDCMaxBytes = 1 << 20 // discovery and token response limit
+const OIDCStateCookie = "oidc_state"
+
+// field encryption
+const EncryptionKeyBytes = 32  // AES-256 key encryption and data keys
+const ReencryptBatchSize = 500 // rows read per batch of the re-encryption
+
+// rate limits
+const RateLimitShards = 64         // locks of the memory store, the keys are spread over them
+const RateLimitCleanupInterval = 5 // in minutes, between the removals of the expired states
+const RateLimitRequests = 300      // default requests per period
+const RateLimitPeriod = 60         // in seconds, default period
+const RateLimitAuthRequests = 10   // requests per period of the auth routes per client
+const RateLimitIPRequests = 600    // requests per period per client address before the authentication
 
 // cors configs
+const CORSMaxAge = 600 // in seconds, preflight results are cached for it
 var AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 var AllowedHeaders = []string{"*"}
+var ExposedHeaders = []string{HeaderApiVersion, HeaderDeprecation, HeaderSunset, HeaderLink, HeaderIdempotentReplayed, HeaderETag, HeaderLastModified, HeaderRateLimitLimit, HeaderRateLimitRemaining, HeaderRateLimitReset, HeaderRetryAfter}
 var AllowCredentials = false
 
 // api url patterns
 const ApiPattern string = "/api"
 const V1 = "/v1"
+const V2 = "/v2"
 const ProductsPattern = "/products"
 const UsersPattern = "/users"
+const ExportSuffix = ":export"
+const ImportSuffix = ":import"
+const ReportPattern = "/report"
+const OpenAPIPattern = "/openapi.json"
+const DocsPattern = "/docs"
+const VersionsPattern = "/versions"
+const BatchPattern = "/batch"
+const WebhooksPattern = "/webhooks"
+const DeliveriesPattern = "/deliveries"
+const RedeliverPattern = "/redeliver"
+const PurgePattern = "/purge"
+const EventsPattern = "/events"
+const GraphQLPattern = "/graphql"
+const AuthPattern = "/auth"
+const SignupPattern = "/signup"
+const LoginPattern = "/login"
+const RefreshPattern = "/refresh"
+const LogoutPattern = "/logout"
+const RolesPattern = "/roles"
+const APIKeysPattern = "/api-keys"
+const RotatePattern = "/rotate"
+const OIDCPattern = "/oidc"
+const CallbackPattern = "/callback"
+const LinkPattern = "/link"
+const LockoutsPattern = "/lockouts"
+
+// versioning headers
+const HeaderAcceptVersion = "Accept-Version"
+const HeaderApiVersion = "Api-Version"
+const HeaderDeprecation = "Deprecation"
+const HeaderSunset = "Sunset"
+const HeaderLink = "Link"
+const HeaderVary = "Vary"
+
+// conditional request and cache headers
+const HeaderETag = "ETag"
+const HeaderLastModified = "Last-Modified"
+const HeaderIfNoneMatch = "If-None-Match"
+const HeaderIfModifiedSince = "If-Modified-Since"
+const HeaderCacheControl = "Cache-Control"
+
+// default cache policies of the read routes
+// no-cache lets the clients store the response and revalidate with the ETag
+const CacheControlReadOne = "private, no-cache"
+const CacheControlReadMany = "private, no-cache"
+
+// idempotency
+const HeaderIdempotencyKey = "Idempotency-Key"
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+const IdempotencyCleanupInterval = 10 // in minutes
+
+// webhook request headers
+const HeaderWebhookId = "Webhook-Id"
+const HeaderWebhookEvent = "Webhook-Event"
+const HeaderWebhookTimestamp = "Webhook-Timestamp"
+const HeaderWebhookSignature = "Webhook-Signature"
+const HeaderUserAgent = "User-Agent"
+
+// authentication headers
+const HeaderAuthorization = "Authorization"
+const HeaderWWWAuthenticate = "WWW-Authenticate"
+const AuthSchemeBearer = "Bearer"
+const AuthSchemeAPIKey = "ApiKey"
+
+// change feed headers
+const HeaderLastEventId = "Last-Event-ID"
+const HeaderXAccelBuffering = "X-Accel-Buffering"
+const WebhookUserAgent = "backend-structure-go-webhooks/1"
+
+// rate limit headers
+const HeaderRateLimitLimit = "RateLimit-Limit"
+const HeaderRateLimitRemaining = "RateLimit-Remaining"
+const HeaderRateLimitReset = "RateLimit-Reset"
+const HeaderRetryAfter = "Retry-After"
+const HeaderForwardedFor = "X-Forwarded-For"
+
+// security headers
+const HeaderStrictTransportSecurity = "Strict-Transport-Security"
+const HeaderContentSecurityPolicy = "Content-Security-Policy"
+const HeaderContentTypeOptions = "X-Content-Type-Options"
+const HeaderReferrerPolicy = "Referrer-Policy"
+const HeaderFrameOptions = "X-Frame-Options"
+
+// default security headers, the api only serves documents
+// which load nothing and can't be framed
+const StrictTransportSecurity = "max-age=31536000; includeSubDomains"
+const ContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+const ContentTypeOptions = "nosniff"
+const ReferrerPolicy = "no-referrer"
+const FrameOptions = "DENY"
 
 const InternalServerError = "internal server error"
 const BadRequest = "bad request"
@@ -30,6 +235,66 @@ const GenericFailMessage = "failed to perform the operation"
 const InvalidQueryParam = "the query parameter supplied is invalid"
 const MissingRequiredPathParam = "missing required path parameter id"
 const InvalidRequestBody = "the request body is invalid"
+const EmptyRequestBody = "the request body is empty"
+const TruncatedRequestBody = "the request body is truncated"
+const RequestBodyTooLarge = "the request body exceeds the size limit"
+const TrailingRequestBody = "the request body has data after the JSON value"
+const UnknownField = "the request body has an unknown field"
+const UnsupportedFormat = "the requested format is not supported"
+const UnsupportedVersion = "the requested api version is not supported"
+const InvalidIdempotencyKey = "the idempotency key is invalid"
+const IdempotencyKeyReused = "the idempotency key was used with a different request"
+const IdempotencyKeyInFlight = "a request with the idempotency key is in progress"
+const InvalidMapping = "the column mapping is invalid"
+const MissingImportFile = "the multipart upload has no file part"
+const ImportTooLarge = "the upload exceeds the size limit"
+const ImportJobNotFound = "the import job was not found"
+const ImportInProgress = "the import job is in progress"
+const ProductNameTaken = "a product with the name already exists"
+const BatchEmpty = "the batch has no requests"
+const BatchTooLarge = "the batch exceeds the maximum number of requests"
+const BatchInvalidRequest = "the batch request is invalid"
+const BatchDuplicateID = "the batch request id is not unique"
+const BatchUnknownDependency = "the batch request depends on an unknown request"
+const BatchDependencyCycle = "the batch requests depend on each other"
+const BatchAtomicUnsupported = "the atomic batch mode is not supported"
+const FailedDependency = "a request the request depends on failed"
+const InvalidPaginationPolicy = "the pagination policy is invalid"
+const UnknownSelectedField = "the selected field is unknown"
+const UnknownRelation = "the expanded relation is unknown"
+const UnknownEventType = "the event type is unknown"
+const InvalidWebhookURL = "the webhook url must be an absolute http or https url"
+const WebhookDisabled = "the webhook subscription is disabled"
+const InvalidLastEventId = "the last event id is invalid"
+const FeedClosed = "the change feed is closed"
+const QueryTooComplex = "the query is too complex"
+const UnknownOperation = "the operation is not found in the query"
+const QueryRequired = "query required"
+const MissingCredentials = "the credentials are missing"
+const InvalidToken = "the token is invalid"
+const QueryTokenTooLong = "the token of the query must be short lived"
+const UnknownSigningKey = "the signing key of the token is unknown"
+const InvalidCredentials = "the email or the password is invalid"
+const EmailTaken = "the email is already registered"
+const InvalidRefreshToken = "the refresh token is invalid or expired"
+const RefreshTokenReused = "the refresh token was already used, the session is revoked"
+const TokenIssuanceDisabled = "the token issuance is not configured"
+const Forbidden = "the caller is not allowed to perform the operation"
+const UserOrRoleNotFound = "the user or the role was not found"
+const InvalidAPIKey = "the api key is invalid, expired or revoked"
+const UnknownScope = "the scope is unknown"
+const APIKeyNotManageable = "the api keys can't be managed with an api key"
+const UnknownIdentityProvider = "the identity provider is unknown"
+const InvalidOIDCState = "the login state is invalid or expired"
+const OIDCLoginFailed = "the login with the identity provider failed"
+const UnverifiedEmail = "the email of the identity is not verified"
+const IdentityLinked = "the identity is already linked to a user"
+const EmailRegistered = "the email is registered with a password, log in and link the identity"
+const RateLimitExceeded = "the rate limit is exceeded, retry later"
+const LoginDelayed = "too many failed logins, retry later"
+const LoginLocked = "too many failed logins, the login is locked temporarily"
+const InvalidLockoutKey = "the lockout key must be account:<email> or ip:<address>"
+const LockoutNotFound = "the lockout was not found"
 
 const RequestTimeoutMsg string = "request timed out"
 
@@ -38,6 +303,21 @@ const ParamPage = "page"
 const ParamLimit = "limit"
 const ParamIsArchived = "isArchived"
 const ParamSortBy = "sortBy"
+const ParamFormat = "format"
+const ParamMapping = "mapping"
+const ParamDryRun = "dryRun"
+const ParamAsync = "async"
+const ParamJobId = "jobId"
+const ParamFields = "fields"
+const ParamExpand = "expand"
+const ParamDeliveryId = "deliveryId"
+const ParamTypes = "types"
+const ParamIds = "ids"
+const ParamLastEventId = "lastEventId"
+const ParamRole = "role"
+const ParamProvider = "provider"
+const ParamKey = "key"
+const ParamAccessToken = "access_token"
 
 const (
 	ErrorSingle     typesext.ErrorType = "single"
@@ -0,0 +1,149 @@
+// package dataloader batches the loads of the keys requested
+// concurrently into one call of the batch function, a loader
+// caches its results and is meant to live for one request
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("dataloader: key not found")
+
+// BatchFunc loads the values of the keys in one call
+// the keys missing from the map are not found
+type BatchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+type Option func(*options)
+
+type options struct {
+	wait     time.Duration
+	maxBatch int
+}
+
+// WithWait sets the time a batch collects the keys before it is loaded
+func WithWait(d time.Duration) Option {
+	return func(o *options) {
+		o.wait = d
+	}
+}
+
+// WithMaxBatch loads a batch as soon as it has n keys
+func WithMaxBatch(n int) Option {
+	return func(o *options) {
+		o.maxBatch = n
+	}
+}
+
+// result is the pending or loaded value of a key
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// batch contains the keys collected during the wait
+type batch[K comparable, V any] struct {
+	keys    []K
+	results map[K]*result[V]
+}
+
+// Loader loads the values of the keys in batches
+type Loader[K comparable, V any] struct {
+	fn   BatchFunc[K, V]
+	opts options
+
+	mu      sync.Mutex
+	cache   map[K]*result[V]
+	pending *batch[K, V]
+}
+
+// New initializes a Loader of the batch function
+// the default wait is a millisecond without a batch limit
+func New[K comparable, V any](fn BatchFunc[K, V], opts ...Option) *Loader[K, V] {
+	o := options{wait: time.Millisecond}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Loader[K, V]{fn: fn, opts: o, cache: make(map[K]*result[V])}
+}
+
+// Load returns the value of the key, the loads of the
+// same key share the result, ErrNotFound if it is missing
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+
+	r, ok := l.cache[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.cache[key] = r
+		l.enqueue(ctx, key, r)
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// enqueue adds the key to the pending batch, the first key
+// schedules the batch, l.mu must be held
+func (l *Loader[K, V]) enqueue(ctx context.Context, key K, r *result[V]) {
+	if l.pending == nil {
+		b := &batch[K, V]{results: make(map[K]*result[V])}
+		l.pending = b
+
+		// the batch runs with the context of its first load
+		time.AfterFunc(l.opts.wait, func() { l.dispatch(ctx, b) })
+	}
+
+	b := l.pending
+	b.keys = append(b.keys, key)
+	b.results[key] = r
+
+	if l.opts.maxBatch > 0 && len(b.keys) >= l.opts.maxBatch {
+		l.pending = nil
+		go l.load(ctx, b)
+	}
+}
+
+// dispatch loads the batch if it was not loaded on its size
+func (l *Loader[K, V]) dispatch(ctx context.Context, b *batch[K, V]) {
+	l.mu.Lock()
+
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+
+	l.pending = nil
+	l.mu.Unlock()
+
+	l.load(ctx, b)
+}
+
+// load calls the batch function and completes the results
+func (l *Loader[K, V]) load(ctx context.Context, b *batch[K, V]) {
+	values, err := l.fn(ctx, b.keys)
+
+	for k, r := range b.results {
+		switch v, ok := values[k]; {
+		case err != nil:
+			r.err = err
+		case !ok:
+			r.err = ErrNotFound
+		default:
+			r.value = v
+		}
+
+		close(r.done)
+	}
+}
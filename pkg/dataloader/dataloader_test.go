@@ -0,0 +1,130 @@
+package dataloader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/dataloader"
+)
+
+// recorder is a batch function recording its calls
+type recorder struct {
+	mu    sync.Mutex
+	calls [][]int
+	err   error
+}
+
+func (r *recorder) load(ctx context.Context, keys []int) (map[int]string, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, keys)
+	r.mu.Unlock()
+
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	m := make(map[int]string)
+	for _, k := range keys {
+		// the negative keys are missing
+		if k >= 0 {
+			m[k] = string(rune('a' + k))
+		}
+	}
+
+	return m, nil
+}
+
+// loadAll loads the keys concurrently
+func loadAll(l *dataloader.Loader[int, string], keys ...int) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	var wg sync.WaitGroup
+	for i, k := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values[i], errs[i] = l.Load(context.Background(), k)
+		}()
+	}
+
+	wg.Wait()
+
+	return values, errs
+}
+
+func TestLoader(t *testing.T) {
+	t.Run("batches concurrent loads", func(t *testing.T) {
+		r := &recorder{}
+		l := dataloader.New(r.load, dataloader.WithWait(10*time.Millisecond))
+
+		values, errs := loadAll(l, 0, 1, 2, 1)
+
+		if len(r.calls) != 1 || len(r.calls[0]) != 3 {
+			t.Fatalf("expected one call with the distinct keys, got %v", r.calls)
+		}
+
+		for i, expected := range []string{"a", "b", "c", "b"} {
+			if errs[i] != nil || values[i] != expected {
+				t.Errorf("key %d: expected %s, got %s %v", i, expected, values[i], errs[i])
+			}
+		}
+	})
+
+	t.Run("caches the results", func(t *testing.T) {
+		r := &recorder{}
+		l := dataloader.New(r.load)
+
+		loadAll(l, 0)
+		loadAll(l, 0)
+
+		if len(r.calls) != 1 {
+			t.Errorf("expected one call, got %v", r.calls)
+		}
+	})
+
+	t.Run("max batch", func(t *testing.T) {
+		r := &recorder{}
+		l := dataloader.New(r.load, dataloader.WithWait(10*time.Millisecond), dataloader.WithMaxBatch(2))
+
+		loadAll(l, 0, 1, 2, 3, 4)
+
+		if len(r.calls) != 3 {
+			t.Errorf("expected three calls, got %v", r.calls)
+		}
+	})
+
+	t.Run("missing key", func(t *testing.T) {
+		l := dataloader.New((&recorder{}).load)
+
+		if _, err := l.Load(context.Background(), -1); !errors.Is(err, dataloader.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("batch error", func(t *testing.T) {
+		boom := errors.New("boom")
+		l := dataloader.New((&recorder{err: boom}).load)
+
+		_, errs := loadAll(l, 0, 1)
+		for _, err := range errs {
+			if !errors.Is(err, boom) {
+				t.Errorf("expected the batch error, got %v", err)
+			}
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		l := dataloader.New((&recorder{}).load, dataloader.WithWait(time.Second))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		if _, err := l.Load(ctx, 0); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,241 @@
+// package envelope encrypts the values with the envelope encryption, every
+// value is encrypted by its own AES-GCM data key which is wrapped by a key
+// encryption key of the key ring, the id of the key encryption key is
+// stored with the value so that the keys can be rotated
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// prefix marks the encrypted values, the format is
+// enc:v1:<key id>:<base64 of the wrapped data key, the nonce and the ciphertext>
+const prefix = "enc:v1:"
+
+// wrappedKeyBytes is the size of the nonce, the data key and the tag
+const wrappedKeyBytes = 12 + constant.EncryptionKeyBytes + 16
+
+var (
+	ErrNotEncrypted = errors.New("the value is not encrypted")
+	ErrUnknownKey   = errors.New("the value is encrypted with an unknown key")
+	ErrDecrypt      = errors.New("the value can't be decrypted")
+)
+
+var keyIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// KeyRing contains the key encryption keys keyed by their ids, the
+// values are encrypted with the primary key and decrypted with the
+// key of their id, it is safe for the concurrent use
+type KeyRing struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyRing initializes the key ring of the 32 byte keys, AES-256
+// primary is the id of the key encrypting the new values
+func NewKeyRing(primary string, keys map[string][]byte) (*KeyRing, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("the primary key %q is not in the key ring", primary)
+	}
+
+	k := &KeyRing{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+
+	for id, key := range keys {
+		if !keyIDPattern.MatchString(id) {
+			return nil, fmt.Errorf("invalid key id %q, it must match %s", id, keyIDPattern)
+		}
+
+		if len(key) != constant.EncryptionKeyBytes {
+			return nil, fmt.Errorf("the key %q must be %d bytes", id, constant.EncryptionKeyBytes)
+		}
+
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+// ParseKeys parses the comma or new line separated id:base64 keys
+// ex: 2026-01:<base64 of 32 random bytes>, the order is kept
+func ParseKeys(s string) (ids []string, keys map[string][]byte, err error) {
+	keys = make(map[string][]byte)
+
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		v = strings.TrimSpace(v)
+		if v == "" || strings.HasPrefix(v, "#") {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, nil, errors.New("a key must be id:base64")
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decode the key %q: %w", id, err)
+		}
+
+		if _, ok := keys[id]; ok {
+			return nil, nil, fmt.Errorf("duplicate key id %q", id)
+		}
+
+		ids = append(ids, id)
+		keys[id] = key
+	}
+
+	return ids, keys, nil
+}
+
+// Primary returns the id of the key encrypting the new values
+func (k *KeyRing) Primary() string {
+	return k.primary
+}
+
+// Encrypt encrypts the plaintext with a new data key wrapped by the
+// primary key, aad is the authenticated context of the value, ex: the
+// column and the row, it must be the same to decrypt
+func (k *KeyRing) Encrypt(plaintext, aad []byte) (string, error) {
+	dataKey := make([]byte, constant.EncryptionKeyBytes)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	// the id is authenticated with the data key so
+	// that it can't be replaced by another one
+	b, err := seal(k.keys[k.primary], nil, dataKey, []byte(k.primary))
+	if err != nil {
+		return "", err
+	}
+
+	b, err = seal(data, b, plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + k.primary + ":" + base64.RawStdEncoding.EncodeToString(b), nil
+}
+
+// Decrypt decrypts the value of Encrypt with the same aad
+func (k *KeyRing) Decrypt(value string, aad []byte) ([]byte, error) {
+	id, b, err := parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	kek, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+	}
+
+	if len(b) < wrappedKeyBytes {
+		return nil, ErrDecrypt
+	}
+
+	dataKey, err := open(kek, b[:wrappedKeyBytes], []byte(id))
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return open(data, b[wrappedKeyBytes:], aad)
+}
+
+// IsEncrypted reports if the value is encrypted by a key ring
+func (k *KeyRing) IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Current reports if the value is encrypted with the primary key,
+// the other values must be encrypted again after a rotation
+func (k *KeyRing) Current(value string) bool {
+	id, _, err := parse(value)
+
+	return err == nil && id == k.primary
+}
+
+// GenerateKey returns a new base64 key encryption key
+func GenerateKey() (string, error) {
+	key := make([]byte, constant.EncryptionKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// parse returns the key id and the bytes of the value
+func parse(value string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if !ok {
+		return "", nil, ErrNotEncrypted
+	}
+
+	id, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", nil, ErrDecrypt
+	}
+
+	b, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, ErrDecrypt
+	}
+
+	return id, b, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal appends the random nonce and the ciphertext to dst
+func seal(aead cipher.AEAD, dst, plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return append(dst, aead.Seal(nonce, nonce, plaintext, aad)...), nil
+}
+
+// open decrypts the nonce and the ciphertext of seal
+func open(aead cipher.AEAD, b, aad []byte) ([]byte, error) {
+	if len(b) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrDecrypt
+	}
+
+	plaintext, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+
+	return plaintext, nil
+}
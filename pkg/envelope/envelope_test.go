@@ -0,0 +1,152 @@
+package envelope_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+)
+
+// key returns a key of the byte
+func key(b byte) []byte {
+	return bytes.Repeat([]byte{b}, constant.EncryptionKeyBytes)
+}
+
+func newKeyRing(t *testing.T, primary string, keys map[string][]byte) *envelope.KeyRing {
+	t.Helper()
+
+	k, err := envelope.NewKeyRing(primary, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+func TestKeyRing(t *testing.T) {
+	old := newKeyRing(t, "old", map[string][]byte{"old": key(1)})
+	rotated := newKeyRing(t, "new", map[string][]byte{"old": key(1), "new": key(2)})
+
+	aad := []byte("users.address")
+
+	v, err := old.Encrypt([]byte("221B Baker Street"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(v, "enc:v1:old:") || strings.Contains(v, "Baker") {
+		t.Fatalf("Encrypt() = %s", v)
+	}
+
+	other, err := old.Encrypt([]byte("221B Baker Street"), aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v == other {
+		t.Error("Encrypt() of the same plaintext returned the same value")
+	}
+
+	tests := []struct {
+		name    string
+		keys    *envelope.KeyRing
+		value   string
+		aad     []byte
+		want    string
+		wantErr error
+	}{
+		{name: "same key", keys: old, value: v, aad: aad, want: "221B Baker Street"},
+		{name: "rotated key ring", keys: rotated, value: v, aad: aad, want: "221B Baker Street"},
+		{name: "another aad", keys: old, value: v, aad: []byte("users.name"), wantErr: envelope.ErrDecrypt},
+		{name: "unknown key", keys: newKeyRing(t, "new", map[string][]byte{"new": key(2)}), value: v, aad: aad, wantErr: envelope.ErrUnknownKey},
+		{name: "replaced key id", keys: rotated, value: strings.Replace(v, ":old:", ":new:", 1), aad: aad, wantErr: envelope.ErrDecrypt},
+		{name: "truncated", keys: old, value: v[:len(v)-4], aad: aad, wantErr: envelope.ErrDecrypt},
+		{name: "plaintext", keys: old, value: "221B Baker Street", aad: aad, wantErr: envelope.ErrNotEncrypted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.keys.Decrypt(tt.value, tt.aad)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decrypt() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("Decrypt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if !old.Current(v) || rotated.Current(v) {
+		t.Error("Current() must report the values of the primary key only")
+	}
+
+	if !rotated.IsEncrypted(v) || rotated.IsEncrypted("221B Baker Street") {
+		t.Error("IsEncrypted() must report the encrypted values only")
+	}
+}
+
+func TestNewKeyRing(t *testing.T) {
+	tests := []struct {
+		name    string
+		primary string
+		keys    map[string][]byte
+		wantErr bool
+	}{
+		{name: "valid", primary: "2026-01", keys: map[string][]byte{"2026-01": key(1)}},
+		{name: "unknown primary", primary: "2026-02", keys: map[string][]byte{"2026-01": key(1)}, wantErr: true},
+		{name: "short key", primary: "a", keys: map[string][]byte{"a": key(1)[:16]}, wantErr: true},
+		{name: "invalid id", primary: "a:b", keys: map[string][]byte{"a:b": key(1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := envelope.NewKeyRing(tt.primary, tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewKeyRing() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	k, err := envelope.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    []string
+		wantErr bool
+	}{
+		{name: "comma separated", s: "b:" + k + ", a:" + k, want: []string{"b", "a"}},
+		{name: "file", s: "# keys\nb:" + k + "\n\na:" + k + "\n", want: []string{"b", "a"}},
+		{name: "no id", s: k, wantErr: true},
+		{name: "invalid base64", s: "a:%%%", wantErr: true},
+		{name: "duplicate id", s: "a:" + k + ",a:" + k, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, keys, err := envelope.ParseKeys(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseKeys() ids = %v, want %v", ids, tt.want)
+			}
+
+			for _, id := range ids {
+				if len(keys[id]) != constant.EncryptionKeyBytes {
+					t.Errorf("key %s is %d bytes", id, len(keys[id]))
+				}
+			}
+		})
+	}
+}
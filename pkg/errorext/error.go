@@ -4,7 +4,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"reflect"
+	"strings"
 
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 )
@@ -26,12 +30,20 @@ const (
 	SQLInvalidColumnReference = "42P10"
 	// unique violation
 	SQLCodeUniqueViolation = "23505"
+	// foreign_key_violation
+	SQLCodeForeignKeyViolation = "23503"
 )
 
 var ErrNotFound = errors.New(constant.NotFound)
 var ErrInternalServer = errors.New(constant.InternalServerError)
 
 func BuildCustomError(err error) error {
+	// the errors are built with NewCustomError as pointers
+	var customErrPtr *CustomError
+	if errors.As(err, &customErrPtr) {
+		return customErrPtr
+	}
+
 	var customErr CustomError
 	ok := errors.As(err, &customErr)
 	if ok {
@@ -43,6 +55,12 @@ func BuildCustomError(err error) error {
 }
 
 func ParseCustomError(err error) *CustomError {
+	// the errors are built with NewCustomError as pointers
+	var customErrPtr *CustomError
+	if errors.As(err, &customErrPtr) {
+		return customErrPtr
+	}
+
 	var customErr CustomError
 	ok := errors.As(err, &customErr)
 	if ok {
@@ -53,14 +71,56 @@ func ParseCustomError(err error) *CustomError {
 	return NewCustomError(http.StatusInternalServerError, err)
 }
 
+// ParseJSONError converts a decoding error to a message for the client
+// the syntax and type errors carry the field path and the byte offset
 func ParseJSONError(err error) error {
-	if err, ok := err.(*json.UnmarshalTypeError); ok {
-		return errors.New("invalid type for " + err.Field)
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+	)
+
+	switch {
+	case errors.As(err, &typeErr):
+		if typeErr.Field == "" {
+			return fmt.Errorf("%s: expected %s, got %s", constant.InvalidRequestBody, jsonType(typeErr.Type), typeErr.Value)
+		}
+
+		return fmt.Errorf("field %s must be %s, got %s at offset %d", typeErr.Field, jsonType(typeErr.Type), typeErr.Value, typeErr.Offset)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%s: %s at offset %d", constant.InvalidRequestBody, strings.TrimPrefix(syntaxErr.Error(), "json: "), syntaxErr.Offset)
+	case errors.Is(err, io.EOF):
+		return errors.New(constant.EmptyRequestBody)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return errors.New(constant.TruncatedRequestBody)
 	}
 
 	return errors.New(constant.InvalidRequestBody)
 }
 
+// jsonType returns the JSON type of the go type
+func jsonType(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+
+	return t.String()
+}
+
 func BuildDBError(err error) error {
 	// check if it's an sql error
 	switch err {
@@ -0,0 +1,68 @@
+// package event publishes the domain events in process
+// the handlers run in the goroutine and the context of the
+// publisher, so they join its transaction, see sqlext.Conn
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Event is a change of an entity
+type Event struct {
+	// Type is the name of the change, ex: product.created
+	Type       string
+	Data       any
+	OccurredAt time.Time
+}
+
+// New returns an event of the type occurring now
+func New(eventType string, data any) Event {
+	return Event{Type: eventType, Data: data, OccurredAt: time.Now()}
+}
+
+// Handler handles the published events
+type Handler func(ctx context.Context, e Event) error
+
+// Publisher publishes the events
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Bus dispatches the published events to the subscribed handlers
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+// NewBus initializes a Bus
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a handler of all the events
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish calls the handlers in the order of subscription
+// all the handlers are called, their errors are joined
+func (b *Bus) Publish(ctx context.Context, e Event) error {
+	b.mu.RLock()
+	handlers := b.handlers
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h(ctx, e); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,329 @@
+// package feed pushes the changes of the resources to the connected
+// clients over Server-Sent Events and WebSocket, the entries are kept
+// in a bounded buffer so that a reconnecting client resumes after the
+// last entry it received, see Broker
+package feed
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+// ErrClosed is returned once the broker is closed
+var ErrClosed = errors.New(constant.FeedClosed)
+
+// Entry is a change pushed to the clients
+type Entry struct {
+	// ID is assigned by the buffer, it increases with every entry
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+	// Subject is the id of the changed entity
+	Subject    string          `json:"subject"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt int64           `json:"occurredAt"`
+}
+
+// Buffer keeps the latest entries for the resume
+type Buffer interface {
+	// Append assigns the id of the entry and stores it
+	// see Transactional
+	Append(ctx context.Context, e Entry) (Entry, error)
+
+	// Since returns at most limit entries after the id in order
+	// complete is false if entries after the id were evicted
+	Since(ctx context.Context, id int64, limit int) (entries []Entry, complete bool, err error)
+}
+
+// Transactional is implemented by the buffers which append in the
+// transaction of the context, see sqlext.Conn, the entries of a
+// rollback are discarded, the other buffers append after the commit
+type Transactional interface {
+	Transactional()
+}
+
+// Filter selects the entries of a connection, an empty list matches all
+type Filter struct {
+	Types    []string
+	Subjects []string
+}
+
+// Match reports if the entry is selected
+func (f Filter) Match(e Entry) bool {
+	if len(f.Types) > 0 && !slices.Contains(f.Types, e.Type) {
+		return false
+	}
+
+	return len(f.Subjects) == 0 || slices.Contains(f.Subjects, e.Subject)
+}
+
+// ParseFilter returns the filter of the types and ids query parameters
+// the types must be in allowed
+func ParseFilter(r *http.Request, allowed []string) (Filter, error) {
+	var f Filter
+
+	for t := range strings.SplitSeq(httpext.GetQueryParam(r, constant.ParamTypes), ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+
+		if !slices.Contains(allowed, t) {
+			return f, fmt.Errorf("%s: %s", constant.UnknownEventType, t)
+		}
+
+		f.Types = append(f.Types, t)
+	}
+
+	for id := range strings.SplitSeq(httpext.GetQueryParam(r, constant.ParamIds), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			f.Subjects = append(f.Subjects, id)
+		}
+	}
+
+	return f, nil
+}
+
+// LastEventID returns the id of the Last-Event-ID header or of the
+// lastEventId query parameter, the browsers can't set the headers of
+// a WebSocket, ok is false if the client does not resume
+func LastEventID(r *http.Request) (id int64, ok bool, err error) {
+	v := r.Header.Get(constant.HeaderLastEventId)
+	if v == "" {
+		v = httpext.GetQueryParam(r, constant.ParamLastEventId)
+	}
+
+	if v == "" {
+		return 0, false, nil
+	}
+
+	id, err = strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, fmt.Errorf("%s: %s", constant.InvalidLastEventId, v)
+	}
+
+	return id, true, nil
+}
+
+// Subscription receives the entries of a connection
+type Subscription struct {
+	filter Filter
+	ch     chan Entry
+	// Backlog contains the buffered entries after the last event id
+	Backlog []Entry
+	// Reset is true if entries after the last event id were evicted
+	// the client must reload its state
+	Reset bool
+}
+
+// C returns the channel of the live entries, it is closed
+// when the subscription is dropped or the broker is closed
+func (s *Subscription) C() <-chan Entry {
+	return s.ch
+}
+
+// inBacklog reports if the entry was read with the backlog
+// the live entries are not in the order of the ids, the
+// transactions commit in another order than they append
+func (s *Subscription) inBacklog(e Entry) bool {
+	_, ok := slices.BinarySearchFunc(s.Backlog, e.ID, func(b Entry, id int64) int {
+		return cmp.Compare(b.ID, id)
+	})
+
+	return ok
+}
+
+// Broker fans the published entries out to the subscriptions
+// a subscription which does not keep up is dropped, its client
+// reconnects and resumes from the buffer
+type Broker struct {
+	buffer Buffer
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+	// origins are the cross origins allowed to open a WebSocket
+	origins []string
+}
+
+type Option func(*Broker)
+
+// WithOrigins allows the origins to open a WebSocket besides the
+// origin of the api, ex: https://app.example.com, a wildcard matches
+// the subdomains, ex: https://*.example.com, "*" is ignored as the
+// browsers send the cookies with the upgrades of all the origins
+func WithOrigins(origins ...string) Option {
+	return func(b *Broker) {
+		b.origins = origins
+	}
+}
+
+// NewBroker initializes a Broker
+func NewBroker(buf Buffer, opts ...Option) *Broker {
+	b := &Broker{buffer: buf, subs: make(map[*Subscription]struct{})}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// Publish stores the entry and sends it to the matching subscriptions
+// in a transaction the entry is sent once it commits, see sqlext.Commit
+// the id is 0 if the buffer appends after the commit, see Transactional
+func (b *Broker) Publish(ctx context.Context, e Entry) (Entry, error) {
+	if _, ok := b.buffer.(Transactional); !ok {
+		if _, ok := sqlext.TxFromContext(ctx); ok {
+			sqlext.AfterCommit(ctx, func() {
+				// the publisher already returned
+				_, _ = b.append(context.WithoutCancel(ctx), e)
+			})
+
+			return e, nil
+		}
+
+		return b.append(ctx, e)
+	}
+
+	if b.isClosed() {
+		return e, ErrClosed
+	}
+
+	// the lock is not held by the append as the buffer joins the
+	// transaction of the publisher, the entries of the concurrent
+	// transactions are sent in the order of their commits
+	e, err := b.buffer.Append(ctx, e)
+	if err != nil {
+		return e, err
+	}
+
+	sqlext.AfterCommit(ctx, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if !b.closed {
+			b.send(e)
+		}
+	})
+
+	return e, nil
+}
+
+// append stores the entry and sends it, the buffer is not
+// transactional, it's appended under the lock so the
+// subscriptions get the ids in order
+func (b *Broker) append(ctx context.Context, e Entry) (Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return e, ErrClosed
+	}
+
+	e, err := b.buffer.Append(ctx, e)
+	if err != nil {
+		return e, err
+	}
+
+	b.send(e)
+
+	return e, nil
+}
+
+// send sends the entry to the matching subscriptions, b.mu must be held
+func (b *Broker) send(e Entry) {
+	for s := range b.subs {
+		if !s.filter.Match(e) {
+			continue
+		}
+
+		select {
+		case s.ch <- e:
+		default:
+			b.remove(s)
+		}
+	}
+}
+
+func (b *Broker) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+// Subscribe registers a subscription of the entries matching f
+// with resume set the buffered entries after the id are its backlog
+func (b *Broker) Subscribe(ctx context.Context, f Filter, after int64, resume bool) (*Subscription, error) {
+	s := &Subscription{filter: f, ch: make(chan Entry, constant.FeedSubscriberBuffer)}
+
+	// registered before the backlog is read so no entry is
+	// missed, the live entries of the backlog are skipped
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	if !resume {
+		return s, nil
+	}
+
+	entries, complete, err := b.buffer.Since(ctx, after, constant.FeedBufferSize)
+	if err != nil {
+		b.Unsubscribe(s)
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if f.Match(e) {
+			s.Backlog = append(s.Backlog, e)
+		}
+	}
+
+	s.Reset = !complete
+
+	return s, nil
+}
+
+// Unsubscribe removes the subscription
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.remove(s)
+}
+
+func (b *Broker) remove(s *Subscription) {
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.ch)
+	}
+}
+
+// Close ends all the subscriptions and rejects the new ones
+// it must run before the server shuts down as the shutdown
+// waits for the open streams, see server.WithOnShutdown
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		b.remove(s)
+	}
+}
@@ -0,0 +1,424 @@
+package feed_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/websocket"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/feed"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+var types = []string{"product.created", "product.updated"}
+
+func publish(t *testing.T, b *feed.Broker, typ, subject string) feed.Entry {
+	t.Helper()
+
+	e, err := b.Publish(context.Background(), feed.Entry{Type: typ, Subject: subject, Data: json.RawMessage(`{}`)})
+	if err != nil {
+		t.Fatalf("publish returned error: %v", err)
+	}
+
+	return e
+}
+
+func TestMemoryBuffer(t *testing.T) {
+	b := feed.NewMemoryBuffer(3)
+	for range 5 {
+		b.Append(context.Background(), feed.Entry{Type: "t"})
+	}
+
+	tests := []struct {
+		name     string
+		after    int64
+		ids      []int64
+		complete bool
+	}{
+		{name: "retained", after: 3, ids: []int64{4, 5}, complete: true},
+		{name: "oldest retained", after: 2, ids: []int64{3, 4, 5}, complete: true},
+		{name: "evicted", after: 1, ids: []int64{3, 4, 5}, complete: false},
+		{name: "latest", after: 5, complete: true},
+		{name: "unknown", after: 9, complete: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, complete, _ := b.Since(context.Background(), tc.after, 10)
+
+			var ids []int64
+			for _, e := range entries {
+				ids = append(ids, e.ID)
+			}
+
+			if complete != tc.complete || len(ids) != len(tc.ids) {
+				t.Fatalf("expected %v %v, got %v %v", tc.ids, tc.complete, ids, complete)
+			}
+
+			for i := range ids {
+				if ids[i] != tc.ids[i] {
+					t.Fatalf("expected %v, got %v", tc.ids, ids)
+				}
+			}
+		})
+	}
+}
+
+func TestBroker(t *testing.T) {
+	t.Run("filter", func(t *testing.T) {
+		b := feed.NewBroker(feed.NewMemoryBuffer(10))
+		s, _ := b.Subscribe(context.Background(), feed.Filter{Types: []string{"product.updated"}, Subjects: []string{"1"}}, 0, false)
+
+		publish(t, b, "product.created", "1")
+		publish(t, b, "product.updated", "2")
+		e := publish(t, b, "product.updated", "1")
+
+		select {
+		case got := <-s.C():
+			if got.ID != e.ID {
+				t.Errorf("expected entry %d, got %d", e.ID, got.ID)
+			}
+		default:
+			t.Fatal("expected an entry")
+		}
+
+		if len(s.C()) != 0 {
+			t.Errorf("expected no other entry, got %d", len(s.C()))
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		b := feed.NewBroker(feed.NewMemoryBuffer(2))
+		for range 3 {
+			publish(t, b, "product.created", "1")
+		}
+
+		s, _ := b.Subscribe(context.Background(), feed.Filter{}, 2, true)
+		if s.Reset || len(s.Backlog) != 1 || s.Backlog[0].ID != 3 {
+			t.Errorf("expected the backlog [3], got %+v reset %v", s.Backlog, s.Reset)
+		}
+
+		s, _ = b.Subscribe(context.Background(), feed.Filter{}, 0, true)
+		if !s.Reset {
+			t.Error("expected a reset after the eviction")
+		}
+	})
+
+	t.Run("slow subscriber is dropped", func(t *testing.T) {
+		b := feed.NewBroker(feed.NewMemoryBuffer(10))
+		s, _ := b.Subscribe(context.Background(), feed.Filter{}, 0, false)
+
+		for range constant.FeedSubscriberBuffer + 1 {
+			publish(t, b, "product.created", "1")
+		}
+
+		n := 0
+		for range s.C() {
+			n++
+		}
+
+		if n != constant.FeedSubscriberBuffer {
+			t.Errorf("expected %d entries before the drop, got %d", constant.FeedSubscriberBuffer, n)
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		b := feed.NewBroker(feed.NewMemoryBuffer(10))
+		s, _ := b.Subscribe(context.Background(), feed.Filter{}, 0, false)
+
+		b.Close()
+
+		if _, open := <-s.C(); open {
+			t.Error("expected the subscription to be closed")
+		}
+
+		if _, err := b.Subscribe(context.Background(), feed.Filter{}, 0, false); err != feed.ErrClosed {
+			t.Errorf("expected ErrClosed, got %v", err)
+		}
+	})
+}
+
+func TestBrokerTransaction(t *testing.T) {
+	// begin returns a context carrying a transaction of the mock
+	begin := func(t *testing.T, mock sqlmock.Sqlmock, db *sql.DB) context.Context {
+		mock.ExpectBegin()
+
+		tx, err := db.BeginTx(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("begin returned error: %v", err)
+		}
+
+		return sqlext.WithTx(context.Background(), tx)
+	}
+
+	t.Run("memory buffer appends after the commit", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		b := feed.NewBroker(feed.NewMemoryBuffer(10))
+		s, _ := b.Subscribe(context.Background(), feed.Filter{}, 0, false)
+
+		ctx := begin(t, mock, db)
+		publish(t, b, "product.created", "1")
+		b.Publish(ctx, feed.Entry{Type: "product.updated", Subject: "1"})
+
+		if got := <-s.C(); got.Type != "product.created" || len(s.C()) != 0 {
+			t.Fatalf("expected only the entry outside of the transaction, got %+v", got)
+		}
+
+		mock.ExpectCommit()
+		if err := sqlext.Commit(ctx); err != nil {
+			t.Fatalf("commit returned error: %v", err)
+		}
+
+		if got := <-s.C(); got.Type != "product.updated" || got.ID != 2 {
+			t.Errorf("expected the committed entry 2, got %+v", got)
+		}
+	})
+
+	t.Run("rollback discards the entries", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		b := feed.NewBroker(feed.NewPostgresBuffer(db, 10))
+		s, _ := b.Subscribe(context.Background(), feed.Filter{}, 0, false)
+
+		ctx := begin(t, mock, db)
+
+		// the entry is appended in the transaction
+		mock.ExpectQuery("INSERT INTO feed_events").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec("DELETE FROM feed_events").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		if _, err := b.Publish(ctx, feed.Entry{Type: "product.created", Subject: "1"}); err != nil {
+			t.Fatalf("publish returned error: %v", err)
+		}
+
+		tx, _ := sqlext.TxFromContext(ctx)
+		tx.Rollback()
+
+		if len(s.C()) != 0 {
+			t.Errorf("expected no entry after the rollback, got %d", len(s.C()))
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		err   bool
+	}{
+		{name: "all", query: ""},
+		{name: "types and ids", query: "types=product.created,product.updated&ids=1,2"},
+		{name: "unknown type", query: "types=user.created", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+tc.query, nil)
+
+			_, err := feed.ParseFilter(r, types)
+			if (err != nil) != tc.err {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// serve serves the subscriptions of b like the feed handler
+func serve(b *feed.Broker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after, resume, err := feed.LastEventID(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s, err := b.Subscribe(r.Context(), feed.Filter{}, after, resume)
+		if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		if feed.IsWebSocket(r) {
+			feed.ServeWebSocket(w, r, b, s)
+			return
+		}
+
+		feed.ServeSSE(w, r, b, s)
+	})
+}
+
+func TestServeSSE(t *testing.T) {
+	b := feed.NewBroker(feed.NewMemoryBuffer(10))
+	publish(t, b, "product.created", "1")
+	publish(t, b, "product.updated", "1")
+
+	srv := httptest.NewServer(serve(b))
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set(constant.HeaderLastEventId, "1")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request returned error: %v", err)
+	}
+
+	defer res.Body.Close()
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %s", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(res.Body)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+
+		close(lines)
+	}()
+
+	// next returns the next id and event lines
+	next := func() []string {
+		var got []string
+		for {
+			select {
+			case l, ok := <-lines:
+				if !ok {
+					return got
+				}
+
+				if strings.HasPrefix(l, "id:") || strings.HasPrefix(l, "event:") {
+					got = append(got, l)
+				}
+
+				if strings.HasPrefix(l, "data:") {
+					return got
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatal("timed out waiting for an event")
+			}
+		}
+	}
+
+	// the backlog after the last event id, then the live entries
+	if got := next(); strings.Join(got, ";") != "id: 2;event: product.updated" {
+		t.Errorf("unexpected backlog event %v", got)
+	}
+
+	publish(t, b, "product.created", "2")
+
+	if got := next(); strings.Join(got, ";") != "id: 3;event: product.created" {
+		t.Errorf("unexpected live event %v", got)
+	}
+
+	// the stream ends when the broker is closed
+	b.Close()
+
+	select {
+	case _, ok := <-lines:
+		for ok {
+			_, ok = <-lines
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the stream to end")
+	}
+}
+
+func TestServeWebSocket(t *testing.T) {
+	b := feed.NewBroker(feed.NewMemoryBuffer(1))
+	publish(t, b, "product.created", "1")
+	publish(t, b, "product.created", "2")
+
+	srv := httptest.NewServer(serve(b))
+	defer srv.Close()
+
+	// the entry after 0 was evicted
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"?lastEventId=0", nil)
+	if err != nil {
+		t.Fatalf("dial returned error: %v", err)
+	}
+
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var e feed.Entry
+
+	for _, want := range []string{feed.TypeReset, "product.created"} {
+		if err := conn.ReadJSON(&e); err != nil {
+			t.Fatalf("read returned error: %v", err)
+		}
+
+		if e.Type != want {
+			t.Errorf("expected %s, got %s", want, e.Type)
+		}
+	}
+
+	publish(t, b, "product.updated", "2")
+
+	if err := conn.ReadJSON(&e); err != nil || e.Type != "product.updated" || e.ID != 3 {
+		t.Errorf("unexpected live entry %+v, %v", e, err)
+	}
+
+	b.Close()
+
+	_, _, err = conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("expected a going away close, got %v", err)
+	}
+}
+
+func TestServeWebSocketOrigin(t *testing.T) {
+	b := feed.NewBroker(feed.NewMemoryBuffer(1), feed.WithOrigins("https://app.example.com", "https://*.example.org", "*"))
+
+	srv := httptest.NewServer(serve(b))
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		origin string
+		status int
+	}{
+		{name: "no origin", status: http.StatusSwitchingProtocols},
+		{name: "same origin", origin: srv.URL, status: http.StatusSwitchingProtocols},
+		{name: "allowed", origin: "https://app.example.com", status: http.StatusSwitchingProtocols},
+		{name: "allowed subdomain", origin: "https://a.example.org", status: http.StatusSwitchingProtocols},
+		{name: "other scheme", origin: "http://app.example.com", status: http.StatusForbidden},
+		{name: "cross origin", origin: "https://evil.example.net", status: http.StatusForbidden},
+		{name: "suffix of the domain", origin: "https://evilexample.org", status: http.StatusForbidden},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			if tc.origin != "" {
+				h.Set("Origin", tc.origin)
+			}
+
+			conn, res, _ := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), h)
+			if conn != nil {
+				conn.Close()
+			}
+
+			if res == nil || res.StatusCode != tc.status {
+				t.Errorf("expected %d, got %+v", tc.status, res)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package feed
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBuffer keeps the latest entries in memory of the instance
+type MemoryBuffer struct {
+	mu      sync.RWMutex
+	entries []Entry
+	size    int
+	lastID  int64
+}
+
+// NewMemoryBuffer initializes a MemoryBuffer of size entries
+func NewMemoryBuffer(size int) *MemoryBuffer {
+	return &MemoryBuffer{entries: make([]Entry, 0, size), size: size}
+}
+
+func (b *MemoryBuffer) Append(ctx context.Context, e Entry) (Entry, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	e.ID = b.lastID
+
+	if len(b.entries) == b.size {
+		// drop the oldest entry
+		copy(b.entries, b.entries[1:])
+		b.entries = b.entries[:len(b.entries)-1]
+	}
+
+	b.entries = append(b.entries, e)
+
+	return e, nil
+}
+
+func (b *MemoryBuffer) Since(ctx context.Context, id int64, limit int) ([]Entry, bool, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	// the ids are consecutive, the entry after id is at id - oldest + 1
+	// an id after the last one was assigned before a restart
+	oldest := b.lastID - int64(len(b.entries)) + 1
+	complete := id+1 >= oldest && id <= b.lastID
+
+	start := max(id-oldest+1, 0)
+	if start >= int64(len(b.entries)) {
+		return nil, complete, nil
+	}
+
+	entries := b.entries[start:]
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return append([]Entry(nil), entries...), complete, nil
+}
@@ -0,0 +1,91 @@
+package feed
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+// PostgresBuffer keeps the latest entries in the feed_events table
+// the instances share it so a client resumes on any instance and
+// after a restart, the live entries are pushed by the instance
+// which published them
+type PostgresBuffer struct {
+	db   *sql.DB
+	size int64
+}
+
+// NewPostgresBuffer initializes a PostgresBuffer of size entries
+func NewPostgresBuffer(db *sql.DB, size int) *PostgresBuffer {
+	return &PostgresBuffer{db: db, size: int64(size)}
+}
+
+// Transactional marks the buffer as appending in the transaction
+func (b *PostgresBuffer) Transactional() {}
+
+// Append inserts the entry and removes the entries beyond the size
+// it runs in the transaction of the publisher if any, see sqlext.Conn
+func (b *PostgresBuffer) Append(ctx context.Context, e Entry) (Entry, error) {
+	conn := sqlext.Conn(ctx, b.db)
+
+	err := conn.QueryRowContext(
+		ctx,
+		"INSERT INTO feed_events (type, subject, data, occurred_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		e.Type, e.Subject, []byte(e.Data), e.OccurredAt,
+	).Scan(&e.ID)
+	if err != nil {
+		return e, err
+	}
+
+	_, err = conn.ExecContext(ctx, "DELETE FROM feed_events WHERE id <= $1", e.ID-b.size)
+	if err != nil {
+		return e, err
+	}
+
+	return e, nil
+}
+
+// Since reads the entries after the id, the ids of the rolled back
+// inserts are skipped and the ids are not in the order of the commits
+// so the completeness is approximate
+func (b *PostgresBuffer) Since(ctx context.Context, id int64, limit int) ([]Entry, bool, error) {
+	var oldest, latest int64
+
+	err := b.db.QueryRowContext(ctx, "SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM feed_events").Scan(&oldest, &latest)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows, err := b.db.QueryContext(
+		ctx,
+		"SELECT id, type, subject, data, occurred_at FROM feed_events WHERE id > $1 ORDER BY id LIMIT $2",
+		id, limit,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var (
+			e    Entry
+			data []byte
+		)
+
+		if err := rows.Scan(&e.ID, &e.Type, &e.Subject, &data, &e.OccurredAt); err != nil {
+			return nil, false, err
+		}
+
+		e.Data = data
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	return entries, id <= latest && (oldest == 0 || id+1 >= oldest), nil
+}
@@ -0,0 +1,115 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// TypeReset is the type of the message telling the client that
+// entries were evicted before it resumed, it must reload its state
+const TypeReset = "reset"
+
+// retryMillis is the reconnection delay advised to the EventSource
+const retryMillis = 3000
+
+// ServeSSE streams the subscription as Server-Sent Events until the
+// client disconnects or the subscription ends, the id of the events
+// is sent back by the EventSource in the Last-Event-ID header
+func ServeSSE(w http.ResponseWriter, r *http.Request, b *Broker, s *Subscription) {
+	defer b.Unsubscribe(s)
+
+	rc := http.NewResponseController(w)
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set(constant.HeaderCacheControl, "no-cache")
+	// disable the buffering of the proxies
+	h.Set(constant.HeaderXAccelBuffering, "no")
+	w.WriteHeader(http.StatusOK)
+
+	// write writes a message and flushes it, the write deadline
+	// of the server is extended for every message
+	write := func(f func(io.Writer) error) bool {
+		_ = rc.SetWriteDeadline(time.Now().Add(constant.FeedWriteTimeout * time.Second))
+
+		if err := f(w); err != nil {
+			return false
+		}
+
+		err := rc.Flush()
+		return err == nil || errors.Is(err, http.ErrNotSupported)
+	}
+
+	ok := write(func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "retry: %d\n\n", retryMillis)
+		return err
+	})
+	if !ok {
+		return
+	}
+
+	if s.Reset && !write(func(w io.Writer) error { return writeSSE(w, Entry{Type: TypeReset}) }) {
+		return
+	}
+
+	for _, e := range s.Backlog {
+		if !write(func(w io.Writer) error { return writeSSE(w, e) }) {
+			return
+		}
+	}
+
+	t := time.NewTicker(constant.FeedHeartbeat * time.Second)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, open := <-s.C():
+			if !open {
+				return
+			}
+
+			// already sent with the backlog
+			if s.inBacklog(e) {
+				continue
+			}
+
+			if !write(func(w io.Writer) error { return writeSSE(w, e) }) {
+				return
+			}
+		case <-t.C:
+			// a comment keeps the connection open through the proxies
+			ok := write(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			})
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// writeSSE writes the entry as an event of its type
+func writeSSE(w io.Writer, e Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if e.ID > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", e.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
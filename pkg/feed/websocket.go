@@ -0,0 +1,141 @@
+package feed
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// maxClientMessage limits the messages of the clients, they are ignored
+const maxClientMessage = 512
+
+// checkOrigin accepts the upgrades without an origin, of the origin
+// of the api and of the allowed origins, the browsers don't apply
+// the CORS policy of the router to the upgrades, see WithOrigins
+func (b *Broker) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// not a browser
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, o := range b.origins {
+		o = strings.TrimSuffix(o, "/")
+
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+
+		// https://*.example.com matches the subdomains
+		if prefix, domain, ok := strings.Cut(o, "://*."); ok && strings.EqualFold(prefix, u.Scheme) &&
+			strings.HasSuffix(strings.ToLower(u.Host), "."+strings.ToLower(domain)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsWebSocket reports if the request asks for a WebSocket upgrade
+func IsWebSocket(r *http.Request) bool {
+	return websocket.IsWebSocketUpgrade(r)
+}
+
+// ServeWebSocket upgrades the connection and sends the subscription
+// as JSON text messages until the client disconnects or the
+// subscription ends, the connection is kept alive with pings
+func ServeWebSocket(w http.ResponseWriter, r *http.Request, b *Broker, s *Subscription) {
+	defer b.Unsubscribe(s)
+
+	// the upgrader responds to a failed upgrade
+	// and to a disallowed origin with 403
+	upgrader := websocket.Upgrader{CheckOrigin: b.checkOrigin}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	defer conn.Close()
+
+	heartbeat := constant.FeedHeartbeat * time.Second
+
+	// the reads process the pongs and the close of the client
+	// a client missing two heartbeats is disconnected
+	done := make(chan struct{})
+
+	conn.SetReadLimit(maxClientMessage)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * heartbeat))
+	})
+
+	go func() {
+		defer close(done)
+
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	write := func(e Entry) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(constant.FeedWriteTimeout * time.Second))
+		return conn.WriteJSON(e) == nil
+	}
+
+	if s.Reset && !write(Entry{Type: TypeReset}) {
+		return
+	}
+
+	for _, e := range s.Backlog {
+		if !write(e) {
+			return
+		}
+	}
+
+	t := time.NewTicker(heartbeat)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case e, open := <-s.C():
+			if !open {
+				// the subscription was dropped or the broker closed
+				msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+				_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(constant.FeedWriteTimeout*time.Second))
+				return
+			}
+
+			// already sent with the backlog
+			if s.inBacklog(e) {
+				continue
+			}
+
+			if !write(e) {
+				return
+			}
+		case <-t.C:
+			err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(constant.FeedWriteTimeout*time.Second))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
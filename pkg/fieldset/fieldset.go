@@ -0,0 +1,157 @@
+// package fieldset parses the sparse fieldsets and the expanded
+// relations of the read requests, ex: ?fields=id,name&expand=owner
+package fieldset
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+)
+
+// Whitelist contains the selectable fields and the
+// expandable relations of a resource, the names are
+// the JSON names of the response entity
+type Whitelist struct {
+	Fields    []string
+	Relations []string
+}
+
+// Selection is the fields and the relations of a read request
+type Selection struct {
+	// Fields is empty if all the fields are selected
+	Fields []string
+	Expand []string
+}
+
+// Parse returns the selection of the fields and expand query parameters
+// an unknown field or relation is an error
+func (w Whitelist) Parse(r *http.Request) (Selection, error) {
+	var (
+		s   Selection
+		err error
+	)
+
+	s.Fields, err = parseList(httpext.GetQueryParam(r, constant.ParamFields), w.Fields, constant.UnknownSelectedField)
+	if err != nil {
+		return s, err
+	}
+
+	s.Expand, err = parseList(httpext.GetQueryParam(r, constant.ParamExpand), w.Relations, constant.UnknownRelation)
+	if err != nil {
+		return s, err
+	}
+
+	return s, nil
+}
+
+// parseList parses a comma separated list of allowed names
+// the empty and repeated names are dropped
+func parseList(v string, allowed []string, msg string) ([]string, error) {
+	var names []string
+
+	for name := range strings.SplitSeq(v, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(names, name) {
+			continue
+		}
+
+		if !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("%s: %s", msg, name)
+		}
+
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// Has reports if the field is selected
+func (s Selection) Has(field string) bool {
+	return len(s.Fields) == 0 || slices.Contains(s.Fields, field)
+}
+
+// Expands reports if the relation is expanded
+func (s Selection) Expands(relation string) bool {
+	return slices.Contains(s.Expand, relation)
+}
+
+// Columns returns the columns of the selected fields in the
+// order of columns, fieldColumns maps the field names to the
+// columns, the key columns are always selected
+func (s Selection) Columns(columns []string, fieldColumns map[string]string, keys ...string) []string {
+	if len(s.Fields) == 0 {
+		return columns
+	}
+
+	selected := make([]string, 0, len(columns))
+	for _, c := range columns {
+		if slices.Contains(keys, c) {
+			selected = append(selected, c)
+			continue
+		}
+
+		for _, f := range s.Fields {
+			if fieldColumns[f] == c {
+				selected = append(selected, c)
+				break
+			}
+		}
+	}
+
+	return selected
+}
+
+type selectionKey struct{}
+
+// WithContext returns a copy of ctx carrying s
+// the storages select the columns of the fields, see FromContext
+func WithContext(ctx context.Context, s Selection) context.Context {
+	return context.WithValue(ctx, selectionKey{}, s)
+}
+
+// FromContext returns the selection carried by ctx
+// all the fields are selected if there is none
+func FromContext(ctx context.Context) Selection {
+	s, _ := ctx.Value(selectionKey{}).(Selection)
+	return s
+}
+
+// Project returns the selected fields of the JSON object of v as a map,
+// v is marshalled so its MarshalJSON and the json tags apply, v is
+// returned as is if all the fields are selected or it's not an object
+func Project(v any, s Selection) any {
+	if len(s.Fields) == 0 {
+		return v
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	// the numbers are kept as is, ex: the unix times of int64
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+
+	var fields map[string]any
+	if err := d.Decode(&fields); err != nil || fields == nil {
+		return v
+	}
+
+	m := make(map[string]any, len(s.Fields))
+
+	for name, value := range fields {
+		if s.Has(name) {
+			m[name] = value
+		}
+	}
+
+	return m
+}
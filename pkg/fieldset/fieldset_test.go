@@ -0,0 +1,143 @@
+package fieldset_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/fieldset"
+)
+
+var whitelist = fieldset.Whitelist{
+	Fields:    []string{"id", "name", "isArchived"},
+	Relations: []string{"owner"},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected fieldset.Selection
+		err      bool
+	}{
+		{name: "none", query: ""},
+		{name: "fields", query: "?fields=id,%20name,,id", expected: fieldset.Selection{Fields: []string{"id", "name"}}},
+		{name: "expand", query: "?expand=owner", expected: fieldset.Selection{Expand: []string{"owner"}}},
+		{name: "unknown field", query: "?fields=id,price", err: true},
+		{name: "unknown relation", query: "?expand=category", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products"+tc.query, nil)
+
+			s, err := whitelist.Parse(r)
+			if tc.err != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+
+			if !tc.err && !reflect.DeepEqual(s, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, s)
+			}
+		})
+	}
+}
+
+func TestColumns(t *testing.T) {
+	columns := []string{"id", "name", "is_archived", "created_at"}
+	fieldColumns := map[string]string{"id": "id", "name": "name", "isArchived": "is_archived", "createdAt": "created_at"}
+
+	tests := []struct {
+		name     string
+		fields   []string
+		expected []string
+	}{
+		{name: "all", expected: columns},
+		{name: "in the column order with the key", fields: []string{"isArchived", "name"}, expected: []string{"id", "name", "is_archived"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := fieldset.FromContext(fieldset.WithContext(context.Background(), fieldset.Selection{Fields: tc.fields}))
+
+			if got := s.Columns(columns, fieldColumns, "id"); !slices.Equal(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestProject(t *testing.T) {
+	type entity struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		IsArchived bool   `json:"isArchived"`
+	}
+
+	e := &entity{ID: "1", Name: "a"}
+
+	if got := fieldset.Project(e, fieldset.Selection{}); got != e {
+		t.Errorf("expected the entity, got %v", got)
+	}
+
+	got := fieldset.Project(e, fieldset.Selection{Fields: []string{"id", "name"}})
+	expected := map[string]any{"id": "1", "name": "a"}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	t.Run("unexported field", func(t *testing.T) {
+		type entity struct {
+			ID     string `json:"id"`
+			secret string
+		}
+
+		got := fieldset.Project(entity{ID: "1", secret: "s"}, fieldset.Selection{Fields: []string{"id", "secret"}})
+		expected := map[string]any{"id": "1"}
+
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected %v, got %v", expected, got)
+		}
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		got, err := json.Marshal(fieldset.Project(marshaler{CreatedAt: 1767225600123456789}, fieldset.Selection{Fields: []string{"createdAt"}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := `{"createdAt":"1767225600123456789"}`; string(got) != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	})
+
+	t.Run("number", func(t *testing.T) {
+		type entity struct {
+			UpdatedAt int64 `json:"updatedAt"`
+		}
+
+		got, err := json.Marshal(fieldset.Project(entity{UpdatedAt: 9007199254740993}, fieldset.Selection{Fields: []string{"updatedAt"}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := `{"updatedAt":9007199254740993}`; string(got) != expected {
+			t.Errorf("expected %s, got %s", expected, got)
+		}
+	})
+}
+
+// marshaler encodes its int64 as a string
+type marshaler struct {
+	CreatedAt int64
+}
+
+func (m marshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"createdAt":"` + strconv.FormatInt(m.CreatedAt, 10) + `","internal":true}`), nil
+}
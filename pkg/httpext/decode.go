@@ -0,0 +1,120 @@
+package httpext
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+// BodyError is an error of a request body which could not be decoded
+// the handlers respond with Code
+type BodyError struct {
+	code int
+	err  error
+}
+
+func (e *BodyError) Error() string {
+	return e.err.Error()
+}
+
+func (e *BodyError) Unwrap() error {
+	return e.err
+}
+
+// Code returns the http status of the error
+func (e *BodyError) Code() int {
+	return e.code
+}
+
+// BodyErrorCode returns the http status of a DecodeJSON error
+func BodyErrorCode(err error) int {
+	var bodyErr *BodyError
+	if errors.As(err, &bodyErr) {
+		return bodyErr.code
+	}
+
+	return http.StatusInternalServerError
+}
+
+type DecodeOption func(*decodeConfig)
+
+type decodeConfig struct {
+	maxBytes      int64
+	unknownFields bool
+}
+
+// WithMaxBytes sets the maximum size of the body
+func WithMaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxBytes = n
+	}
+}
+
+// WithUnknownFields accepts the fields which are not in v
+func WithUnknownFields() DecodeOption {
+	return func(c *decodeConfig) {
+		c.unknownFields = true
+	}
+}
+
+// DecodeJSON decodes the body of r into v which must be a pointer
+// the body must be one JSON value of at most constant.RequestBodyMaxBytes
+// without unknown fields, a *BodyError is returned otherwise
+// carrying 413 for an oversized body and 400 for the others
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v any, opts ...DecodeOption) error {
+	c := decodeConfig{maxBytes: constant.RequestBodyMaxBytes}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, c.maxBytes)
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	if !c.unknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	err := dec.Decode(v)
+	if err != nil {
+		return decodeError(dec, err, c.maxBytes)
+	}
+
+	// the body must end after the value
+	_, err = dec.Token()
+	switch {
+	case err == io.EOF:
+		return nil
+	case err == nil:
+		return &BodyError{code: http.StatusBadRequest, err: fmt.Errorf("%s at offset %d", constant.TrailingRequestBody, dec.InputOffset())}
+	}
+
+	return decodeError(dec, err, c.maxBytes)
+}
+
+// decodeError converts an error of dec to a *BodyError
+func decodeError(dec *json.Decoder, err error, maxBytes int64) error {
+	var (
+		mbe           *http.MaxBytesError
+		invalidTarget *json.InvalidUnmarshalError
+	)
+
+	switch {
+	case errors.As(err, &mbe):
+		return &BodyError{code: http.StatusRequestEntityTooLarge, err: fmt.Errorf("%s of %d bytes", constant.RequestBodyTooLarge, maxBytes)}
+	case errors.As(err, &invalidTarget):
+		// a programming error, v is not a pointer
+		return err
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &BodyError{code: http.StatusBadRequest, err: fmt.Errorf("%s %s", constant.UnknownField, field)}
+	}
+
+	return &BodyError{code: http.StatusBadRequest, err: errorext.ParseJSONError(err)}
+}
@@ -0,0 +1,66 @@
+package httpext_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+)
+
+type item struct {
+	Name  string `json:"name"`
+	Stock int    `json:"stock"`
+	Tags  []struct {
+		Value string `json:"value"`
+	} `json:"tags"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		opts []httpext.DecodeOption
+		code int
+		err  string
+	}{
+		{name: "valid", body: `{"name":"a","stock":1}`},
+		{name: "trailing whitespace", body: "{\"name\":\"a\"}\n"},
+		{name: "empty", body: ``, code: http.StatusBadRequest, err: "the request body is empty"},
+		{name: "too large", body: `{"name":"abcdef"}`, opts: []httpext.DecodeOption{httpext.WithMaxBytes(8)}, code: http.StatusRequestEntityTooLarge, err: "the request body exceeds the size limit of 8 bytes"},
+		{name: "unknown field", body: `{"name":"a","price":1}`, code: http.StatusBadRequest, err: `the request body has an unknown field "price"`},
+		{name: "unknown field allowed", body: `{"name":"a","price":1}`, opts: []httpext.DecodeOption{httpext.WithUnknownFields()}},
+		{name: "trailing data", body: `{"name":"a"}{}`, code: http.StatusBadRequest, err: "the request body has data after the JSON value at offset 13"},
+		{name: "syntax", body: `{"name":}`, code: http.StatusBadRequest, err: "the request body is invalid: invalid character '}' looking for beginning of value at offset 9"},
+		{name: "truncated", body: `{"name":"a"`, code: http.StatusBadRequest, err: "the request body is truncated"},
+		{name: "type", body: `{"stock":"1"}`, code: http.StatusBadRequest, err: "field stock must be number, got string at offset 12"},
+		{name: "nested type", body: `{"tags":[{"value":1}]}`, code: http.StatusBadRequest, err: "field tags.0.value must be string, got number at offset 19"},
+		{name: "root type", body: `[]`, code: http.StatusBadRequest, err: "the request body is invalid: expected object, got array"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			var v item
+			err := httpext.DecodeJSON(w, r, &v, tc.opts...)
+			if tc.err == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				return
+			}
+
+			if err == nil || err.Error() != tc.err {
+				t.Fatalf("expected %q, got %v", tc.err, err)
+			}
+
+			if code := httpext.BodyErrorCode(err); code != tc.code {
+				t.Errorf("expected %d, got %d", tc.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Authenticator authenticates the requests of a credential scheme
+type Authenticator interface {
+	// Scheme is the scheme of the WWW-Authenticate challenge, ex: Bearer
+	Scheme() string
+	// Authenticate returns the principal of the credentials of the request
+	// ok is false if the request has no credentials of the scheme
+	Authenticate(r *http.Request) (p auth.Principal, ok bool, err error)
+}
+
+// bearerJWT authenticates the bearer tokens with the verifier
+type bearerJWT struct {
+	verifier *auth.Verifier
+}
+
+// BearerJWT returns the Authenticator of the Authorization: Bearer <jwt> requests
+func BearerJWT(v *auth.Verifier) Authenticator {
+	return bearerJWT{verifier: v}
+}
+
+func (a bearerJWT) Scheme() string {
+	return constant.AuthSchemeBearer
+}
+
+func (a bearerJWT) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	token, ok := Credentials(r, constant.AuthSchemeBearer)
+	if !ok {
+		return auth.Principal{}, false, nil
+	}
+
+	p, err := a.verifier.Verify(r.Context(), token)
+
+	return p, true, err
+}
+
+// queryJWT authenticates the tokens of the query parameter
+type queryJWT struct {
+	verifier *auth.Verifier
+	match    func(*http.Request) bool
+	maxTTL   time.Duration
+}
+
+// QueryJWT returns the Authenticator of the access_token query parameter,
+// RFC 6750 2.3, of the requests matched by match, ex: the streams, the
+// EventSource and the WebSocket of the browsers can't set the Authorization
+// header, the urls are logged so the tokens must expire within maxTTL
+func QueryJWT(v *auth.Verifier, match func(*http.Request) bool, maxTTL time.Duration) Authenticator {
+	return queryJWT{verifier: v, match: match, maxTTL: maxTTL}
+}
+
+// Scheme is empty, the challenge is the one of BearerJWT
+func (a queryJWT) Scheme() string {
+	return ""
+}
+
+func (a queryJWT) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	if !a.match(r) {
+		return auth.Principal{}, false, nil
+	}
+
+	token := r.URL.Query().Get(constant.ParamAccessToken)
+	if token == "" {
+		return auth.Principal{}, false, nil
+	}
+
+	p, err := a.verifier.Verify(r.Context(), token)
+	if err != nil {
+		return p, true, err
+	}
+
+	if p.ExpiresAt.IsZero() || time.Until(p.ExpiresAt) > a.maxTTL {
+		return auth.Principal{}, true, errors.New(constant.QueryTokenTooLong)
+	}
+
+	return p, true, nil
+}
+
+// APIKeyVerifier verifies the api keys
+type APIKeyVerifier interface {
+	// Authenticate returns the principal of the key
+	Authenticate(ctx context.Context, key string) (auth.Principal, error)
+}
+
+// apiKey authenticates the api keys with the verifier
+type apiKey struct {
+	verifier APIKeyVerifier
+}
+
+// APIKey returns the Authenticator of the Authorization: ApiKey <key> requests
+func APIKey(v APIKeyVerifier) Authenticator {
+	return apiKey{verifier: v}
+}
+
+func (a apiKey) Scheme() string {
+	return constant.AuthSchemeAPIKey
+}
+
+func (a apiKey) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	key, ok := Credentials(r, constant.AuthSchemeAPIKey)
+	if !ok {
+		return auth.Principal{}, false, nil
+	}
+
+	p, err := a.verifier.Authenticate(r.Context(), key)
+
+	return p, true, err
+}
+
+// clientCert authenticates the verified client certificates
+type clientCert struct {
+	roles map[string][]string
+}
+
+// ClientCert returns the Authenticator of the requests with a verified
+// tls client certificate, ex: the service to service calls, the
+// subject is the common name of the certificate, roles contains
+// the roles of the subjects, it has no challenge
+func ClientCert(roles map[string][]string) Authenticator {
+	return clientCert{roles: roles}
+}
+
+func (a clientCert) Scheme() string {
+	return ""
+}
+
+func (a clientCert) Authenticate(r *http.Request) (auth.Principal, bool, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return auth.Principal{}, false, nil
+	}
+
+	// the certificates are only verified with the client CAs
+	if len(r.TLS.VerifiedChains) == 0 {
+		return auth.Principal{}, true, auth.ErrUnauthenticated
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+
+	subject := cert.Subject.CommonName
+	if subject == "" {
+		subject = cert.Subject.String()
+	}
+
+	return auth.Principal{
+		Subject:   subject,
+		Method:    auth.MethodClientCert,
+		Issuer:    cert.Issuer.String(),
+		Roles:     a.roles[subject],
+		ExpiresAt: cert.NotAfter,
+		Claims: map[string]any{
+			"subject":   cert.Subject.String(),
+			"serial":    cert.SerialNumber.String(),
+			"dns_names": cert.DNSNames,
+		},
+	}, true, nil
+}
+
+// Credentials returns the credentials of the Authorization header
+// of the scheme, the scheme is case insensitive
+func Credentials(r *http.Request, scheme string) (string, bool) {
+	h := r.Header.Get(constant.HeaderAuthorization)
+
+	s, credentials, ok := strings.Cut(h, " ")
+	if !ok || !strings.EqualFold(s, scheme) {
+		return "", false
+	}
+
+	credentials = strings.TrimSpace(credentials)
+
+	return credentials, credentials != ""
+}
+
+// Authenticate authenticates the requests with the first authenticator
+// finding its credentials and stores the principal in the context, see
+// auth.PrincipalFrom, the requests without valid credentials are answered
+// with 401 unless public matches them, the public requests pass as
+// anonymous, a request already carrying a principal, ex: a batch sub
+// request, is not authenticated again
+func Authenticate(public func(*http.Request) bool, authenticators ...Authenticator) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := auth.FromRequest(r); ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			isPublic := public != nil && public(r)
+
+			for _, a := range authenticators {
+				p, ok, err := a.Authenticate(r)
+				if !ok {
+					continue
+				}
+
+				if err != nil {
+					if isPublic {
+						break
+					}
+
+					unauthorized(w, authenticators, err, true)
+					return
+				}
+
+				next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), p)))
+				return
+			}
+
+			if isPublic {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			unauthorized(w, authenticators, auth.ErrUnauthenticated, false)
+		})
+	}
+}
+
+// unauthorized responds with 401 and the challenges of the schemes
+// RFC 6750, invalid marks the rejected credentials
+func unauthorized(w http.ResponseWriter, authenticators []Authenticator, err error, invalid bool) {
+	for _, a := range authenticators {
+		if a.Scheme() == "" {
+			continue
+		}
+
+		challenge := a.Scheme() + ` realm="api"`
+		if invalid {
+			challenge += `, error="invalid_token"`
+		}
+
+		w.Header().Add(constant.HeaderWWWAuthenticate, challenge)
+	}
+
+	response.RespondError(w, http.StatusUnauthorized, response.NewErrorResponse(constant.ErrorSingle, []error{err}))
+}
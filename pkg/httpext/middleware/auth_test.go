@@ -0,0 +1,257 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+)
+
+var secret = []byte("0123456789abcdef0123456789abcdef")
+
+func token(t *testing.T, exp time.Time) string {
+	t.Helper()
+
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "exp": exp.Unix()}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return s
+}
+
+func TestAuthenticate(t *testing.T) {
+	public := func(r *http.Request) bool { return r.URL.Path == "/public" }
+	mw := middleware.Authenticate(public, middleware.BearerJWT(auth.NewVerifier(auth.HMACKey(secret))))
+
+	// the handler responds with the subject of the principal
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := auth.FromRequest(r)
+		if !ok {
+			w.Write([]byte("anonymous"))
+			return
+		}
+
+		w.Write([]byte(p.Subject))
+	}))
+
+	valid := "Bearer " + token(t, time.Now().Add(time.Minute))
+	expired := "Bearer " + token(t, time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		principal     *auth.Principal
+		code          int
+		body          string
+		challenge     string
+	}{
+		{name: "valid token", path: "/private", authorization: valid, code: http.StatusOK, body: "user-1"},
+		{name: "scheme is case insensitive", path: "/private", authorization: "bearer " + strings.TrimPrefix(valid, "Bearer "), code: http.StatusOK, body: "user-1"},
+		{name: "missing credentials", path: "/private", code: http.StatusUnauthorized, challenge: `Bearer realm="api"`},
+		{name: "other scheme", path: "/private", authorization: "Basic dXNlcjpwYXNz", code: http.StatusUnauthorized, challenge: `Bearer realm="api"`},
+		{name: "expired token", path: "/private", authorization: expired, code: http.StatusUnauthorized, challenge: `Bearer realm="api", error="invalid_token"`},
+		{name: "public anonymous", path: "/public", code: http.StatusOK, body: "anonymous"},
+		{name: "public with a token", path: "/public", authorization: valid, code: http.StatusOK, body: "user-1"},
+		{name: "public with an invalid token", path: "/public", authorization: expired, code: http.StatusOK, body: "anonymous"},
+		{name: "principal of the parent request", path: "/private", principal: &auth.Principal{Subject: "user-2"}, code: http.StatusOK, body: "user-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			if tt.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), *tt.principal))
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("code = %d, want %d, body %s", w.Code, tt.code, w.Body)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+
+			if got := w.Header().Get("WWW-Authenticate"); got != tt.challenge {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenge)
+			}
+		})
+	}
+}
+
+// keys is a fake api key verifier of one key
+type keys struct{}
+
+func (keys) Authenticate(ctx context.Context, key string) (auth.Principal, error) {
+	if key != "bsk_valid" {
+		return auth.Principal{}, errors.New("invalid key")
+	}
+
+	return auth.Principal{Subject: "client-1", Method: auth.MethodAPIKey}, nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	mw := middleware.Authenticate(nil, middleware.BearerJWT(auth.NewVerifier(auth.HMACKey(secret))), middleware.APIKey(keys{}))
+
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromRequest(r)
+		w.Write([]byte(p.Method + ":" + p.Subject))
+	}))
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          int
+		body          string
+		challenges    []string
+	}{
+		{name: "valid key", authorization: "ApiKey bsk_valid", code: http.StatusOK, body: "apikey:client-1"},
+		{name: "scheme is case insensitive", authorization: "apikey bsk_valid", code: http.StatusOK, body: "apikey:client-1"},
+		{name: "bearer token", authorization: "Bearer " + token(t, time.Now().Add(time.Minute)), code: http.StatusOK, body: "jwt:user-1"},
+		{name: "invalid key", authorization: "ApiKey bsk_other", code: http.StatusUnauthorized, challenges: []string{`Bearer realm="api", error="invalid_token"`, `ApiKey realm="api", error="invalid_token"`}},
+		{name: "missing credentials", code: http.StatusUnauthorized, challenges: []string{`Bearer realm="api"`, `ApiKey realm="api"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/private", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("code = %d, want %d, body %s", w.Code, tt.code, w.Body)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+
+			if got := w.Header().Values("WWW-Authenticate"); !slices.Equal(got, tt.challenges) {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenges)
+			}
+		})
+	}
+}
+
+func TestAuthenticateQuery(t *testing.T) {
+	v := auth.NewVerifier(auth.HMACKey(secret))
+	stream := func(r *http.Request) bool { return strings.HasSuffix(r.URL.Path, "/events") }
+	mw := middleware.Authenticate(nil, middleware.BearerJWT(v), middleware.QueryJWT(v, stream, 15*time.Minute))
+
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromRequest(r)
+		w.Write([]byte(p.Subject))
+	}))
+
+	short := token(t, time.Now().Add(time.Minute))
+	long := token(t, time.Now().Add(time.Hour))
+
+	tests := []struct {
+		name          string
+		target        string
+		authorization string
+		code          int
+		body          string
+	}{
+		{name: "token of the stream", target: "/products/events?access_token=" + short, code: http.StatusOK, body: "user-1"},
+		{name: "header of the stream", target: "/products/events", authorization: "Bearer " + short, code: http.StatusOK, body: "user-1"},
+		{name: "long lived token", target: "/products/events?access_token=" + long, code: http.StatusUnauthorized},
+		{name: "expired token", target: "/products/events?access_token=" + token(t, time.Now().Add(-time.Minute)), code: http.StatusUnauthorized},
+		{name: "token of another route", target: "/products?access_token=" + short, code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("code = %d, want %d, body %s", w.Code, tt.code, w.Body)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+		})
+	}
+}
+
+func TestAuthenticateClientCert(t *testing.T) {
+	mw := middleware.Authenticate(nil, middleware.BearerJWT(auth.NewVerifier(auth.HMACKey(secret))), middleware.ClientCert(map[string][]string{"billing": {"staff"}}))
+
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, _ := auth.FromRequest(r)
+		w.Write([]byte(p.Method + ":" + p.Subject + ":" + strings.Join(p.Roles, ",")))
+	}))
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}, SerialNumber: big.NewInt(1)}
+
+	tests := []struct {
+		name          string
+		tls           *tls.ConnectionState
+		authorization string
+		code          int
+		body          string
+		challenges    []string
+	}{
+		{name: "verified certificate", tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}, code: http.StatusOK, body: "mtls:billing:staff"},
+		{name: "bearer token first", tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}, VerifiedChains: [][]*x509.Certificate{{cert}}}, authorization: "Bearer " + token(t, time.Now().Add(time.Minute)), code: http.StatusOK, body: "jwt:user-1:"},
+		{name: "unverified certificate", tls: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}, code: http.StatusUnauthorized, challenges: []string{`Bearer realm="api", error="invalid_token"`}},
+		{name: "no certificate", tls: &tls.ConnectionState{}, code: http.StatusUnauthorized, challenges: []string{`Bearer realm="api"`}},
+		{name: "http", code: http.StatusUnauthorized, challenges: []string{`Bearer realm="api"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/private", nil)
+			r.TLS = tt.tls
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("code = %d, want %d, body %s", w.Code, tt.code, w.Body)
+			}
+
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %s, want %s", w.Body, tt.body)
+			}
+
+			if got := w.Header().Values("WWW-Authenticate"); !slices.Equal(got, tt.challenges) {
+				t.Errorf("WWW-Authenticate = %q, want %q", got, tt.challenges)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Require authorizes the requests of a route or a chi group with the
+// permission, owner returns the owner of the requested resource, ex:
+// the id of a user, nil for the resources without owner, a denied
+// request is answered with a 403 problem, RFC 9457
+func Require(a rbac.Authorizer, permission string, owner func(*http.Request) string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var o string
+			if owner != nil {
+				o = owner(r)
+			}
+
+			err := a.Authorize(r.Context(), permission, o)
+			if err != nil {
+				cerr := errorext.ParseCustomError(err)
+
+				p := response.NewProblem(cerr.Code(), cerr.Error())
+				p.Instance = r.URL.Path
+
+				_, _ = response.RespondProblem(w, p)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+func TestRequire(t *testing.T) {
+	p := rbac.NewPolicy(map[string][]rbac.Grant{"customer": {{Permission: "users:read", Own: true}}})
+
+	// the owner is the last segment of the path
+	owner := func(r *http.Request) string {
+		return r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	}
+
+	h := middleware.Require(p, "users:read", owner)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	customer := &auth.Principal{Subject: "user-1", Roles: []string{"customer"}}
+
+	tests := []struct {
+		name      string
+		path      string
+		principal *auth.Principal
+		code      int
+	}{
+		{name: "owner", path: "/users/user-1", principal: customer, code: http.StatusNoContent},
+		{name: "other user", path: "/users/user-2", principal: customer, code: http.StatusForbidden},
+		{name: "anonymous", path: "/users/user-1", code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.principal != nil {
+				r = r.WithContext(auth.WithPrincipal(r.Context(), *tc.principal))
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("expected %d, got %d", tc.code, w.Code)
+			}
+
+			if tc.code == http.StatusNoContent {
+				return
+			}
+
+			if ct := w.Header().Get("Content-Type"); ct != response.ContentTypeProblem {
+				t.Errorf("expected a problem, got %s", ct)
+			}
+
+			var problem response.Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			if problem.Status != tc.code || problem.Instance != tc.path {
+				t.Errorf("unexpected problem %+v", problem)
+			}
+		})
+	}
+}
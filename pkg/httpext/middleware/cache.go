@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// CacheControl sets the Cache-Control header of the successful
+// and not modified responses, error responses are not cached
+// an empty policy leaves the header unset
+func CacheControl(policy string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, policy: policy}, r)
+		})
+	}
+}
+
+type cacheControlWriter struct {
+	http.ResponseWriter
+	policy      string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+
+		if code < http.StatusBadRequest {
+			w.Header().Set(constant.HeaderCacheControl, w.policy)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
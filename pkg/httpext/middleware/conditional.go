@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// Conditional answers the conditional GET requests
+// the successful responses are buffered to compute a strong ETag
+// from the body unless the handler sets one
+// If-None-Match and If-Modified-Since, evaluated against the
+// Last-Modified header set by the handler, are answered with 304
+func Conditional(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &conditionalWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+
+		if cw.status == 0 {
+			cw.status = http.StatusOK
+		}
+
+		h := w.Header()
+
+		if cw.status != http.StatusOK {
+			w.WriteHeader(cw.status)
+			w.Write(cw.body.Bytes())
+			return
+		}
+
+		if h.Get(constant.HeaderETag) == "" {
+			h.Set(constant.HeaderETag, ETag(cw.body.Bytes()))
+		}
+
+		if notModified(r, h) {
+			// the representation headers are not sent with 304
+			h.Del("Content-Type")
+			h.Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.WriteHeader(cw.status)
+		w.Write(cw.body.Bytes())
+	})
+}
+
+// ETag returns the strong entity tag of the body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:]) + `"`
+}
+
+// notModified evaluates the preconditions of RFC 9110 13.2.2
+// If-Modified-Since is ignored when If-None-Match is present
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get(constant.HeaderIfNoneMatch); inm != "" {
+		return etagMatches(inm, h.Get(constant.HeaderETag))
+	}
+
+	ims := r.Header.Get(constant.HeaderIfModifiedSince)
+	lm := h.Get(constant.HeaderLastModified)
+	if ims == "" || lm == "" {
+		return false
+	}
+
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(lm)
+	if err != nil {
+		return false
+	}
+
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// etagMatches uses the weak comparison as required for If-None-Match
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	etag = strings.TrimPrefix(etag, "W/")
+
+	for _, t := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(t), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conditionalWriter buffers the response
+type conditionalWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *conditionalWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *conditionalWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
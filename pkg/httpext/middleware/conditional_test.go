@@ -0,0 +1,98 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+const body = `{"data":{"id":"1"}}`
+
+var updatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func entityHandler(status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.SetLastModified(w, updatedAt.Unix())
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	})
+}
+
+func TestConditional(t *testing.T) {
+	etag := middleware.ETag([]byte(body))
+
+	tests := []struct {
+		name     string
+		method   string
+		header   map[string]string
+		status   int
+		expected int
+	}{
+		{name: "no precondition", method: http.MethodGet, status: http.StatusOK, expected: http.StatusOK},
+		{name: "etag match", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: etag}, status: http.StatusOK, expected: http.StatusNotModified},
+		{name: "weak etag match", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: `"x", W/` + etag}, status: http.StatusOK, expected: http.StatusNotModified},
+		{name: "etag mismatch", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: `"x"`}, status: http.StatusOK, expected: http.StatusOK},
+		{name: "any etag", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: "*"}, status: http.StatusOK, expected: http.StatusNotModified},
+		{name: "not modified since", method: http.MethodGet, header: map[string]string{constant.HeaderIfModifiedSince: updatedAt.Format(http.TimeFormat)}, status: http.StatusOK, expected: http.StatusNotModified},
+		{name: "modified since", method: http.MethodGet, header: map[string]string{constant.HeaderIfModifiedSince: updatedAt.Add(-time.Hour).Format(http.TimeFormat)}, status: http.StatusOK, expected: http.StatusOK},
+		{name: "etag takes precedence", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: `"x"`, constant.HeaderIfModifiedSince: updatedAt.Format(http.TimeFormat)}, status: http.StatusOK, expected: http.StatusOK},
+		{name: "error is not conditional", method: http.MethodGet, header: map[string]string{constant.HeaderIfNoneMatch: "*"}, status: http.StatusNotFound, expected: http.StatusNotFound},
+		{name: "unsafe method", method: http.MethodPut, header: map[string]string{constant.HeaderIfNoneMatch: "*"}, status: http.StatusOK, expected: http.StatusOK},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, "/", nil)
+			for k, v := range tc.header {
+				req.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			middleware.Conditional(entityHandler(tc.status)).ServeHTTP(w, req)
+
+			if w.Code != tc.expected {
+				t.Fatalf("expected status %d, got %d", tc.expected, w.Code)
+			}
+
+			if tc.expected == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("expected empty body, got %s", w.Body.String())
+			}
+
+			if tc.expected == http.StatusOK && tc.method == http.MethodGet {
+				if w.Body.String() != body || w.Header().Get(constant.HeaderETag) != etag {
+					t.Errorf("expected body and etag, got %s %s", w.Body.String(), w.Header().Get(constant.HeaderETag))
+				}
+			}
+		})
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	const policy = "private, max-age=60"
+
+	tests := []struct {
+		name     string
+		status   int
+		expected string
+	}{
+		{name: "success", status: http.StatusOK, expected: policy},
+		{name: "not modified", status: http.StatusNotModified, expected: policy},
+		{name: "error", status: http.StatusNotFound, expected: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			middleware.CacheControl(policy)(entityHandler(tc.status)).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if got := w.Header().Get(constant.HeaderCacheControl); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-chi/cors"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// CORSPolicy contains the cross origin policy of the api
+type CORSPolicy struct {
+	// AllowedOrigins contains the origins allowed to call the api,
+	// ex: https://app.example.com, a wildcard matches the subdomains,
+	// ex: https://*.example.com, "*" allows all the origins
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders contains the response headers readable by the clients
+	ExposedHeaders []string
+	// AllowCredentials lets the clients send the cookies, it
+	// can't be combined with all the origins
+	AllowCredentials bool
+	// MaxAge is the seconds the preflight results are cached for
+	MaxAge int
+}
+
+// DefaultCORSPolicy returns the policy allowing all the origins
+// without the credentials
+func DefaultCORSPolicy() CORSPolicy {
+	return CORSPolicy{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   constant.AllowedMethods,
+		AllowedHeaders:   constant.AllowedHeaders,
+		ExposedHeaders:   constant.ExposedHeaders,
+		AllowCredentials: constant.AllowCredentials,
+		MaxAge:           constant.CORSMaxAge,
+	}
+}
+
+// Validate checks the origins of the policy
+func (p CORSPolicy) Validate() error {
+	if len(p.AllowedOrigins) == 0 {
+		return errors.New("no allowed origins")
+	}
+
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			if p.AllowCredentials {
+				// the browsers reject the credentials of a wildcard response
+				return errors.New("all the origins can't be allowed with the credentials")
+			}
+
+			continue
+		}
+
+		// the wildcard must be the leftmost label of the host
+		host := strings.Replace(o, "://*.", "://wildcard.", 1)
+		if strings.Contains(host, "*") {
+			return fmt.Errorf("invalid origin %q, a wildcard must be the first label of the host", o)
+		}
+
+		u, err := url.Parse(host)
+		if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			return fmt.Errorf("invalid origin %q, it must be scheme://host[:port]", o)
+		}
+	}
+
+	return nil
+}
+
+// CORS applies the policy to the requests, the preflights are
+// answered without reaching the routes, the responses vary
+// by the origin
+func CORS(p CORSPolicy) func(next http.Handler) http.Handler {
+	origins := make([]string, len(p.AllowedOrigins))
+	for i, o := range p.AllowedOrigins {
+		origins[i] = strings.TrimSuffix(o, "/")
+	}
+
+	return cors.Handler(cors.Options{
+		AllowedOrigins:   origins,
+		AllowedMethods:   p.AllowedMethods,
+		AllowedHeaders:   p.AllowedHeaders,
+		ExposedHeaders:   p.ExposedHeaders,
+		AllowCredentials: p.AllowCredentials,
+		MaxAge:           p.MaxAge,
+	})
+}
@@ -0,0 +1,190 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+)
+
+func TestCORSPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  middleware.CORSPolicy
+		wantErr bool
+	}{
+		{name: "all origins", policy: middleware.CORSPolicy{AllowedOrigins: []string{"*"}}},
+		{name: "origins", policy: middleware.CORSPolicy{AllowedOrigins: []string{"https://app.example.com", "http://localhost:3000"}}},
+		{name: "wildcard subdomain", policy: middleware.CORSPolicy{AllowedOrigins: []string{"https://*.example.com"}, AllowCredentials: true}},
+		{name: "no origins", policy: middleware.CORSPolicy{}, wantErr: true},
+		{name: "all origins with credentials", policy: middleware.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true}, wantErr: true},
+		{name: "wildcard in the domain", policy: middleware.CORSPolicy{AllowedOrigins: []string{"https://app.*.com"}}, wantErr: true},
+		{name: "no scheme", policy: middleware.CORSPolicy{AllowedOrigins: []string{"app.example.com"}}, wantErr: true},
+		{name: "path", policy: middleware.CORSPolicy{AllowedOrigins: []string{"https://app.example.com/login"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	// reached counts the requests reaching the routes
+	reached := 0
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached++
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	p := middleware.DefaultCORSPolicy()
+	p.AllowedOrigins = []string{"https://app.example.com", "https://*.example.org"}
+	p.AllowedHeaders = []string{"Authorization", "Content-Type"}
+	p.AllowCredentials = true
+	p.MaxAge = 300
+
+	h := middleware.CORS(p)(ok)
+
+	tests := []struct {
+		name        string
+		method      string
+		header      map[string]string
+		code        int
+		reached     bool
+		origin      string
+		credentials string
+		maxAge      string
+		allowed     string
+	}{
+		{
+			name:   "preflight",
+			method: http.MethodOptions,
+			header: map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": http.MethodPost, "Access-Control-Request-Headers": "authorization, content-type"},
+			code:   http.StatusOK, origin: "https://app.example.com", credentials: "true", maxAge: "300", allowed: "Authorization, Content-Type",
+		},
+		{
+			name:   "preflight of a subdomain",
+			method: http.MethodOptions,
+			header: map[string]string{"Origin": "https://eu.app.example.org", "Access-Control-Request-Method": http.MethodDelete},
+			code:   http.StatusOK, origin: "https://eu.app.example.org", credentials: "true", maxAge: "300",
+		},
+		{
+			name:   "preflight of an unknown origin",
+			method: http.MethodOptions,
+			header: map[string]string{"Origin": "https://example.org.evil.com", "Access-Control-Request-Method": http.MethodPost},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "preflight of a denied method",
+			method: http.MethodOptions,
+			header: map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": "TRACE"},
+			code:   http.StatusOK,
+		},
+		{
+			name:   "preflight of a denied header",
+			method: http.MethodOptions,
+			header: map[string]string{"Origin": "https://app.example.com", "Access-Control-Request-Method": http.MethodPost, "Access-Control-Request-Headers": "X-Debug"},
+			code:   http.StatusOK,
+		},
+		{
+			name:    "options without a preflight",
+			method:  http.MethodOptions,
+			header:  map[string]string{"Origin": "https://app.example.com"},
+			code:    http.StatusNoContent,
+			reached: true, origin: "https://app.example.com", credentials: "true",
+		},
+		{
+			name:    "request",
+			method:  http.MethodGet,
+			header:  map[string]string{"Origin": "https://app.example.com"},
+			code:    http.StatusNoContent,
+			reached: true, origin: "https://app.example.com", credentials: "true",
+		},
+		{
+			name:    "request of an unknown origin",
+			method:  http.MethodGet,
+			header:  map[string]string{"Origin": "https://evil.com"},
+			code:    http.StatusNoContent,
+			reached: true,
+		},
+		{
+			name:    "same origin request",
+			method:  http.MethodGet,
+			code:    http.StatusNoContent,
+			reached: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached = 0
+
+			r := httptest.NewRequest(tt.method, "/api/v1/products", nil)
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Errorf("code = %d, want %d", w.Code, tt.code)
+			}
+
+			if (reached > 0) != tt.reached {
+				t.Errorf("reached the route = %v, want %v", reached > 0, tt.reached)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.origin)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.credentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.credentials)
+			}
+
+			if got := w.Header().Get("Access-Control-Max-Age"); got != tt.maxAge {
+				t.Errorf("Access-Control-Max-Age = %q, want %q", got, tt.maxAge)
+			}
+
+			if tt.allowed != "" {
+				if got := w.Header().Get("Access-Control-Allow-Headers"); got != tt.allowed {
+					t.Errorf("Access-Control-Allow-Headers = %q, want %q", got, tt.allowed)
+				}
+			}
+
+			if got := w.Header().Values("Vary"); len(got) == 0 || got[0] != "Origin" {
+				t.Errorf("Vary = %v, want Origin", got)
+			}
+
+			exposed := w.Header().Get("Access-Control-Expose-Headers")
+			if tt.method == http.MethodGet && tt.origin != "" && exposed == "" {
+				t.Error("Access-Control-Expose-Headers is not set")
+			}
+		})
+	}
+
+	t.Run("all origins", func(t *testing.T) {
+		h := middleware.CORS(middleware.DefaultCORSPolicy())(ok)
+
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/products", nil)
+		r.Header.Set("Origin", "https://any.com")
+		r.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+		r.Header.Set("Access-Control-Request-Headers", "Idempotency-Key")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("Access-Control-Allow-Origin = %q, want *", got)
+		}
+
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Access-Control-Allow-Credentials = %q, want unset", got)
+		}
+	})
+}
@@ -49,3 +49,27 @@ func Timeout(timeout time.Duration) func(next http.Handler) http.Handler {
 		return http.HandlerFunc(fn)
 	}
 }
+
+// Unless applies the middlewares to the requests not matched by skip
+// a nil skip applies them to all the requests
+func Unless(skip func(*http.Request) bool, middlewares ...func(http.Handler) http.Handler) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		wrapped := next
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			wrapped = middlewares[i](wrapped)
+		}
+
+		if skip == nil {
+			return wrapped
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			wrapped.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// TrustedProxies contains the networks of the proxies in front of
+// the api, ex: the load balancer, their X-Forwarded-For is trusted
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses the comma separated networks
+// or addresses, ex: 10.0.0.0/8,192.168.1.10
+func ParseTrustedProxies(s string) (TrustedProxies, error) {
+	var t TrustedProxies
+
+	for _, v := range strings.Split(s, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			a, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+
+			t = append(t, netip.PrefixFrom(a.Unmap(), a.Unmap().BitLen()))
+			continue
+		}
+
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+
+		t = append(t, p.Masked())
+	}
+
+	return t, nil
+}
+
+func (t TrustedProxies) contains(a netip.Addr) bool {
+	for _, p := range t {
+		if p.Contains(a) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClientIP returns the address of the client of the request, the
+// X-Forwarded-For entries are only read when the peer is a trusted
+// proxy, the rightmost entry not of a trusted proxy is the client
+// since the entries left of it can be set by the client
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+
+	client := peer.Unmap()
+	if !t.contains(client) {
+		return client.String()
+	}
+
+	entries := strings.Split(strings.Join(r.Header.Values(constant.HeaderForwardedFor), ","), ",")
+	for i := len(entries) - 1; i >= 0; i-- {
+		a, err := netip.ParseAddr(strings.TrimSpace(entries[i]))
+		if err != nil {
+			// a malformed entry ends the trusted chain
+			break
+		}
+
+		client = a.Unmap()
+		if !t.contains(client) {
+			break
+		}
+	}
+
+	return client.String()
+}
+
+// RateLimitKey returns the key the request is limited by
+// ok is false if the key does not apply to the request
+type RateLimitKey func(r *http.Request) (key string, ok bool)
+
+// KeyByIP keys the requests by the address of the client
+func KeyByIP(proxies TrustedProxies) RateLimitKey {
+	return func(r *http.Request) (string, bool) {
+		return "ip:" + proxies.ClientIP(r), true
+	}
+}
+
+// KeyByAPIKey keys the requests authenticated with an api key by the key
+// so that the keys of a user have their own limits
+func KeyByAPIKey() RateLimitKey {
+	return func(r *http.Request) (string, bool) {
+		p, ok := auth.FromRequest(r)
+		if !ok || p.Method != auth.MethodAPIKey {
+			return "", false
+		}
+
+		kid, ok := p.Claims["kid"].(string)
+
+		return "apikey:" + kid, ok
+	}
+}
+
+// KeyByPrincipal keys the authenticated requests by the subject
+func KeyByPrincipal() RateLimitKey {
+	return func(r *http.Request) (string, bool) {
+		p, ok := auth.FromRequest(r)
+		if !ok {
+			return "", false
+		}
+
+		return "principal:" + p.Subject, true
+	}
+}
+
+// FirstKey keys the requests by the first of the keys applying to them
+func FirstKey(keys ...RateLimitKey) RateLimitKey {
+	return func(r *http.Request) (string, bool) {
+		for _, k := range keys {
+			if key, ok := k(r); ok {
+				return key, true
+			}
+		}
+
+		return "", false
+	}
+}
+
+// RateLimit limits the requests of the keys with the limiter, the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers
+// describe the quota, the most restrictive one of the limiters of
+// a route, a request over the limit is answered with a 429 problem,
+// RFC 9457, and Retry-After, a failing store lets the requests pass
+func RateLimit(l *ratelimit.Limiter, key RateLimitKey) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k, ok := key(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := l.Allow(r.Context(), k)
+			if err != nil {
+				log.Printf("rate limit %s returned error: %v", l.Name(), err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			setRateLimitHeaders(w.Header(), res)
+
+			if !res.Allowed {
+				w.Header().Set(constant.HeaderRetryAfter, strconv.FormatInt(seconds(res.RetryAfter), 10))
+
+				p := response.NewProblem(http.StatusTooManyRequests, constant.RateLimitExceeded)
+				p.Instance = r.URL.Path
+
+				_, _ = response.RespondProblem(w, p)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRateLimitHeaders sets the headers of the result unless
+// a limiter before set a lower remaining quota
+func setRateLimitHeaders(h http.Header, res ratelimit.Result) {
+	if v := h.Get(constant.HeaderRateLimitRemaining); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n <= res.Remaining {
+			return
+		}
+	}
+
+	h.Set(constant.HeaderRateLimitLimit, strconv.Itoa(res.Limit))
+	h.Set(constant.HeaderRateLimitRemaining, strconv.Itoa(res.Remaining))
+	h.Set(constant.HeaderRateLimitReset, strconv.FormatInt(seconds(res.Reset), 10))
+}
+
+// seconds rounds d up to the seconds, at least 1
+func seconds(d time.Duration) int64 {
+	return int64(math.Max(1, math.Ceil(d.Seconds())))
+}
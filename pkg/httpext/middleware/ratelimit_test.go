@@ -0,0 +1,170 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies("10.0.0.0/8, 192.168.1.10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:1234", want: "203.0.113.7"},
+		{name: "forwarded by an untrusted peer", remoteAddr: "203.0.113.7:1234", forwarded: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "forwarded by a trusted proxy", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed entries", remoteAddr: "10.0.0.1:1234", forwarded: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "proxy chain", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.1, 192.168.1.10", "10.0.0.2"}, want: "198.51.100.1"},
+		{name: "only proxies", remoteAddr: "10.0.0.1:1234", forwarded: []string{"10.0.0.3"}, want: "10.0.0.3"},
+		{name: "malformed entry", remoteAddr: "10.0.0.1:1234", forwarded: []string{"198.51.100.1, unknown"}, want: "10.0.0.1"},
+		{name: "ipv4 mapped ipv6", remoteAddr: "[::ffff:10.0.0.1]:1234", forwarded: []string{"2001:db8::1"}, want: "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+
+			if got := proxies.ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := middleware.ParseTrustedProxies("10.0.0.0/33"); err == nil {
+		t.Error("ParseTrustedProxies() of an invalid network succeeded")
+	}
+}
+
+// failingStore is a store which is down
+type failingStore struct{}
+
+func (failingStore) Update(ctx context.Context, key string, now time.Time, fn func(ratelimit.State) ratelimit.State) error {
+	return errors.New("down")
+}
+
+func (failingStore) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, errors.New("down")
+}
+
+func TestRateLimit(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	key := middleware.FirstKey(middleware.KeyByAPIKey(), middleware.KeyByPrincipal(), middleware.KeyByIP(nil))
+	limit := ratelimit.Limit{Requests: 2, Period: time.Minute}
+
+	// send sends a request of the principal from the address
+	send := func(h http.Handler, addr string, p *auth.Principal) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/products", nil)
+		r.RemoteAddr = addr + ":1234"
+
+		if p != nil {
+			r = r.WithContext(auth.WithPrincipal(r.Context(), *p))
+		}
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("limits a key", func(t *testing.T) {
+		h := middleware.RateLimit(ratelimit.New("test", ratelimit.NewMemoryStore(), ratelimit.SlidingWindow{}, limit), key)(ok)
+
+		tests := []struct {
+			name      string
+			addr      string
+			principal *auth.Principal
+			code      int
+			remaining string
+		}{
+			{name: "first", addr: "203.0.113.1", code: http.StatusOK, remaining: "1"},
+			{name: "second", addr: "203.0.113.1", code: http.StatusOK, remaining: "0"},
+			{name: "over the limit", addr: "203.0.113.1", code: http.StatusTooManyRequests, remaining: "0"},
+			{name: "other address", addr: "203.0.113.2", code: http.StatusOK, remaining: "1"},
+			{name: "principal", addr: "203.0.113.1", principal: &auth.Principal{Subject: "user-1"}, code: http.StatusOK, remaining: "1"},
+			{name: "api key of the principal", addr: "203.0.113.1", principal: &auth.Principal{Subject: "user-1", Method: auth.MethodAPIKey, Claims: map[string]any{"kid": "k1"}}, code: http.StatusOK, remaining: "1"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := send(h, tt.addr, tt.principal)
+
+				if w.Code != tt.code {
+					t.Fatalf("code = %d, want %d", w.Code, tt.code)
+				}
+
+				if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+					t.Errorf("RateLimit-Limit = %s, want 2", got)
+				}
+
+				if got := w.Header().Get("RateLimit-Remaining"); got != tt.remaining {
+					t.Errorf("RateLimit-Remaining = %s, want %s", got, tt.remaining)
+				}
+
+				if got := w.Header().Get("RateLimit-Reset"); got == "" {
+					t.Error("RateLimit-Reset is not set")
+				}
+
+				if tt.code != http.StatusTooManyRequests {
+					return
+				}
+
+				if got := w.Header().Get("Retry-After"); got == "" || got == "0" {
+					t.Errorf("Retry-After = %q, want the seconds", got)
+				}
+
+				if got := w.Header().Get("Content-Type"); got != response.ContentTypeProblem {
+					t.Errorf("Content-Type = %s, want %s", got, response.ContentTypeProblem)
+				}
+			})
+		}
+	})
+
+	t.Run("most restrictive headers", func(t *testing.T) {
+		s := ratelimit.NewMemoryStore()
+		global := middleware.RateLimit(ratelimit.New("global", s, ratelimit.TokenBucket{}, ratelimit.Limit{Requests: 100, Period: time.Minute}), key)
+		route := middleware.RateLimit(ratelimit.New("route", s, ratelimit.TokenBucket{}, limit), key)
+
+		w := send(global(route(ok)), "203.0.113.1", nil)
+
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("RateLimit-Limit = %s, want the limit of the route", got)
+		}
+
+		w = send(route(global(ok)), "203.0.113.2", nil)
+
+		if got := w.Header().Get("RateLimit-Remaining"); got != "1" {
+			t.Errorf("RateLimit-Remaining = %s, want the remaining of the route", got)
+		}
+	})
+
+	t.Run("failing store", func(t *testing.T) {
+		h := middleware.RateLimit(ratelimit.New("test", failingStore{}, ratelimit.TokenBucket{}, limit), key)(ok)
+
+		if w := send(h, "203.0.113.1", nil); w.Code != http.StatusOK {
+			t.Errorf("code = %d, want the request to pass", w.Code)
+		}
+	})
+}
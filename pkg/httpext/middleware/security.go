@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// SecurityHeaders contains the values of the security headers of the
+// responses, an empty value leaves the header as it is so that a route
+// policy only overrides the headers it sets, HeaderUnset removes it
+type SecurityHeaders struct {
+	// StrictTransportSecurity is ignored by the browsers over http
+	StrictTransportSecurity string
+	ContentSecurityPolicy   string
+	ContentTypeOptions      string
+	ReferrerPolicy          string
+	FrameOptions            string
+}
+
+// HeaderUnset removes a security header set by a previous policy
+const HeaderUnset = "-"
+
+// DefaultSecurityHeaders returns the headers of a json api
+func DefaultSecurityHeaders() SecurityHeaders {
+	return SecurityHeaders{
+		StrictTransportSecurity: constant.StrictTransportSecurity,
+		ContentSecurityPolicy:   constant.ContentSecurityPolicy,
+		ContentTypeOptions:      constant.ContentTypeOptions,
+		ReferrerPolicy:          constant.ReferrerPolicy,
+		FrameOptions:            constant.FrameOptions,
+	}
+}
+
+// Secure sets the security headers before the handler writes the
+// response, applied globally and then on a route the route values
+// replace the global ones, ex: the relaxed csp of a html page
+func Secure(s SecurityHeaders) func(next http.Handler) http.Handler {
+	headers := [][2]string{
+		{constant.HeaderStrictTransportSecurity, s.StrictTransportSecurity},
+		{constant.HeaderContentSecurityPolicy, s.ContentSecurityPolicy},
+		{constant.HeaderContentTypeOptions, s.ContentTypeOptions},
+		{constant.HeaderReferrerPolicy, s.ReferrerPolicy},
+		{constant.HeaderFrameOptions, s.FrameOptions},
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+
+			for _, v := range headers {
+				switch v[1] {
+				case "":
+				case HeaderUnset:
+					h.Del(v[0])
+				default:
+					h.Set(v[0], v[1])
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
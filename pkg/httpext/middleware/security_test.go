@@ -0,0 +1,71 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+)
+
+func TestSecure(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	global := middleware.Secure(middleware.DefaultSecurityHeaders())
+
+	tests := []struct {
+		name    string
+		handler http.Handler
+		want    map[string]string
+	}{
+		{
+			name:    "defaults",
+			handler: global(ok),
+			want: map[string]string{
+				constant.HeaderStrictTransportSecurity: constant.StrictTransportSecurity,
+				constant.HeaderContentSecurityPolicy:   constant.ContentSecurityPolicy,
+				constant.HeaderContentTypeOptions:      "nosniff",
+				constant.HeaderReferrerPolicy:          "no-referrer",
+				constant.HeaderFrameOptions:            "DENY",
+			},
+		},
+		{
+			name: "route override",
+			handler: global(middleware.Secure(middleware.SecurityHeaders{
+				ContentSecurityPolicy: "default-src 'self'",
+				FrameOptions:          middleware.HeaderUnset,
+			})(ok)),
+			want: map[string]string{
+				constant.HeaderStrictTransportSecurity: constant.StrictTransportSecurity,
+				constant.HeaderContentSecurityPolicy:   "default-src 'self'",
+				constant.HeaderContentTypeOptions:      "nosniff",
+				constant.HeaderReferrerPolicy:          "no-referrer",
+				constant.HeaderFrameOptions:            "",
+			},
+		},
+		{
+			name:    "empty values",
+			handler: middleware.Secure(middleware.SecurityHeaders{ContentTypeOptions: "nosniff"})(ok),
+			want: map[string]string{
+				constant.HeaderStrictTransportSecurity: "",
+				constant.HeaderContentTypeOptions:      "nosniff",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			for k, v := range tt.want {
+				if got := w.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+}
@@ -17,11 +17,13 @@ func GetQueryParam(r *http.Request, key string) string {
 }
 
 // ParseRequestBody parses the request body
+//
+// Deprecated: use DecodeJSON which bounds and validates the body
 func ParseRequestBody(r io.ReadCloser, v any) error {
 	// close the request body
 	defer r.Close()
 
-	err := json.NewDecoder(r).Decode(&v)
+	err := json.NewDecoder(r).Decode(v)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,319 @@
+// package idempotency makes the retries of unsafe requests safe
+// a request with the Idempotency-Key header is executed once
+// and the stored response is replayed on the retries
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+const (
+	defaultTTL   = 24 * time.Hour
+	maxKeyLength = 255
+)
+
+// Record is the stored state of a key
+type Record struct {
+	Key string
+	// Fingerprint identifies the request the key was first used with
+	Fingerprint string
+	// Completed is false while the first request is in flight
+	Completed bool
+	Status    int
+	Header    http.Header
+	Body      []byte
+	ExpiresAt time.Time
+}
+
+// Store persists the records
+type Store interface {
+	// Reserve stores an in flight record for the key if the key
+	// has no live record, otherwise the live record is returned
+	// reserved reports if the record was stored
+	Reserve(ctx context.Context, rec Record) (existing Record, reserved bool, err error)
+
+	// Complete stores the response of the reserved key
+	Complete(ctx context.Context, rec Record) error
+
+	// Release removes the reserved key so that the request can be retried
+	Release(ctx context.Context, key string) error
+
+	// DeleteExpired removes the expired records
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Scope returns the caller of the request, the keys are scoped to
+// the caller so that the callers sending the same key and body do
+// not get the stored responses of each other
+type Scope func(r *http.Request) string
+
+// ScopeByPrincipal scopes the authenticated requests by the principal,
+// the api keys by their id, and the anonymous ones by anonymous
+func ScopeByPrincipal(anonymous Scope) Scope {
+	return func(r *http.Request) string {
+		p, ok := auth.FromRequest(r)
+		if !ok {
+			return anonymous(r)
+		}
+
+		if kid, ok := p.Claims["kid"].(string); ok && p.Method == auth.MethodAPIKey {
+			return "apikey:" + kid
+		}
+
+		return "principal:" + p.Method + ":" + p.Subject
+	}
+}
+
+// ScopeByClientIP scopes the requests by the address of the client
+// resolved as the rate limits do, the X-Forwarded-For of the proxies
+// is read, see middleware.TrustedProxies, nil proxies use the peer
+func ScopeByClientIP(proxies middleware.TrustedProxies) Scope {
+	return func(r *http.Request) string {
+		return "ip:" + proxies.ClientIP(r)
+	}
+}
+
+type Option func(*Idempotency)
+
+// WithTTL sets the time the records are kept for
+func WithTTL(ttl time.Duration) Option {
+	return func(i *Idempotency) {
+		i.ttl = ttl
+	}
+}
+
+// WithMethods sets the methods the middleware applies to
+// the default is POST
+func WithMethods(methods ...string) Option {
+	return func(i *Idempotency) {
+		i.methods = methods
+	}
+}
+
+// WithScope sets the scope of the keys
+// the default is ScopeByPrincipal(ScopeByClientIP(nil))
+func WithScope(scope Scope) Option {
+	return func(i *Idempotency) {
+		i.scope = scope
+	}
+}
+
+// Idempotency provides the middleware
+type Idempotency struct {
+	store   Store
+	ttl     time.Duration
+	methods []string
+	scope   Scope
+	now     func() time.Time
+}
+
+// New initializes an Idempotency
+func New(store Store, opts ...Option) *Idempotency {
+	i := &Idempotency{
+		store:   store,
+		ttl:     defaultTTL,
+		methods: []string{http.MethodPost},
+		scope:   ScopeByPrincipal(ScopeByClientIP(nil)),
+		now:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Store returns the store of the records
+func (i *Idempotency) Store() Store {
+	return i.store
+}
+
+// Middleware executes a request with the Idempotency-Key header once
+// the retries of the caller with the same key and body get the stored
+// response, the same key of another caller is another key
+// the same key with a different body gets 409
+// a retry while the first request is in flight gets 409
+// server errors are not stored so that the request can be retried
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(constant.HeaderIdempotencyKey)
+		if key == "" || !i.applies(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			respondError(w, http.StatusBadRequest, constant.InvalidIdempotencyKey)
+			return
+		}
+
+		// the body is buffered so it is bounded like httpext.DecodeJSON
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, constant.RequestBodyMaxBytes))
+		if err != nil {
+			var mbe *http.MaxBytesError
+			if errors.As(err, &mbe) {
+				respondError(w, http.StatusRequestEntityTooLarge, constant.RequestBodyTooLarge)
+				return
+			}
+
+			respondError(w, http.StatusBadRequest, constant.InvalidRequestBody)
+			return
+		}
+
+		// restore the body for the handler
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		// the key is scoped to the caller and the route
+		rec := Record{
+			Key:         i.scope(r) + " " + r.Method + " " + r.URL.Path + " " + key,
+			Fingerprint: fingerprint(r, body),
+			ExpiresAt:   i.now().Add(i.ttl),
+		}
+
+		existing, reserved, err := i.store.Reserve(r.Context(), rec)
+		if err != nil {
+			log.Printf("idempotency reserve returned error: %v", err)
+			respondError(w, http.StatusInternalServerError, constant.InternalServerError)
+			return
+		}
+
+		if !reserved {
+			i.respondExisting(w, rec, existing)
+			return
+		}
+
+		i.execute(w, r, next, rec)
+	})
+}
+
+func (i *Idempotency) applies(method string) bool {
+	for _, m := range i.methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (i *Idempotency) respondExisting(w http.ResponseWriter, rec, existing Record) {
+	switch {
+	case existing.Fingerprint != rec.Fingerprint:
+		respondError(w, http.StatusConflict, constant.IdempotencyKeyReused)
+	case !existing.Completed:
+		respondError(w, http.StatusConflict, constant.IdempotencyKeyInFlight)
+	default:
+		replay(w, existing)
+	}
+}
+
+// execute serves the reserved request and stores its response
+func (i *Idempotency) execute(w http.ResponseWriter, r *http.Request, next http.Handler, rec Record) {
+	rw := &recorder{ResponseWriter: w}
+
+	// the store calls must run even if the request is canceled
+	ctx := context.WithoutCancel(r.Context())
+
+	completed := false
+
+	defer func() {
+		if completed {
+			return
+		}
+
+		// the handler panicked or failed, the key is released
+		if err := i.store.Release(ctx, rec.Key); err != nil {
+			log.Printf("idempotency release returned error: %v", err)
+		}
+	}()
+
+	next.ServeHTTP(rw, r)
+
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+
+	if rw.status >= http.StatusInternalServerError {
+		return
+	}
+
+	rec.Completed = true
+	rec.Status = rw.status
+	rec.Header = rw.Header().Clone()
+	rec.Body = rw.body.Bytes()
+
+	if err := i.store.Complete(ctx, rec); err != nil {
+		log.Printf("idempotency complete returned error: %v", err)
+		return
+	}
+
+	completed = true
+}
+
+// replay writes the stored response
+func replay(w http.ResponseWriter, rec Record) {
+	h := w.Header()
+	for k, v := range rec.Header {
+		h[k] = v
+	}
+
+	h.Set(constant.HeaderIdempotentReplayed, "true")
+
+	w.WriteHeader(rec.Status)
+
+	if _, err := w.Write(rec.Body); err != nil {
+		log.Printf("idempotency replay returned error: %v", err)
+	}
+}
+
+// fingerprint identifies the request by the route and the body
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func respondError(w http.ResponseWriter, code int, message string) {
+	response.RespondError(w, code, response.NewErrorResponse(constant.ErrorSingle, []error{errors.New(message)}))
+}
+
+// recorder captures the response while writing it
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	r.body.Write(b)
+
+	return r.ResponseWriter.Write(b)
+}
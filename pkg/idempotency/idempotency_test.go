@@ -0,0 +1,235 @@
+package idempotency_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+)
+
+// counter is a create handler which counts its calls
+type counter struct {
+	calls  atomic.Int32
+	status int
+	// block is closed to let the handler respond
+	block chan struct{}
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.calls.Add(1)
+
+	if c.block != nil {
+		<-c.block
+	}
+
+	b, _ := io.ReadAll(r.Body)
+
+	w.Header().Set("X-Created", "1")
+	w.WriteHeader(c.status)
+	w.Write(b)
+}
+
+func send(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	return sendAs(h, nil, key, body)
+}
+
+// sendAs sends the request as the principal, nil sends it anonymously
+func sendAs(h http.Handler, p *auth.Principal, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(constant.HeaderIdempotencyKey, key)
+	}
+
+	if p != nil {
+		req = req.WithContext(auth.WithPrincipal(req.Context(), *p))
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Run("replay", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		first := send(h, "k1", `{"name":"a"}`)
+		second := send(h, "k1", `{"name":"a"}`)
+
+		if c.calls.Load() != 1 {
+			t.Fatalf("expected 1 call, got %d", c.calls.Load())
+		}
+
+		if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+			t.Errorf("expected replay of %d %s, got %d %s", first.Code, first.Body, second.Code, second.Body)
+		}
+
+		if second.Header().Get("X-Created") != "1" || second.Header().Get(constant.HeaderIdempotentReplayed) != "true" {
+			t.Errorf("expected the stored headers, got %v", second.Header())
+		}
+	})
+
+	t.Run("different body", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		send(h, "k1", `{"name":"a"}`)
+
+		if w := send(h, "k1", `{"name":"b"}`); w.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+	})
+
+	t.Run("other callers", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		jane := &auth.Principal{Subject: "jane", Method: auth.MethodJWT}
+		john := &auth.Principal{Subject: "john", Method: auth.MethodJWT}
+		janeKey := &auth.Principal{Subject: "jane", Method: auth.MethodAPIKey, Claims: map[string]any{"kid": "k-1"}}
+
+		for _, p := range []*auth.Principal{jane, john, janeKey, nil} {
+			if w := sendAs(h, p, "k1", `{"name":"a"}`); w.Header().Get(constant.HeaderIdempotentReplayed) != "" {
+				t.Errorf("expected the request of %+v not to be replayed", p)
+			}
+		}
+
+		if c.calls.Load() != 4 {
+			t.Fatalf("expected 4 calls, got %d", c.calls.Load())
+		}
+
+		if w := sendAs(h, jane, "k1", `{"name":"a"}`); w.Header().Get(constant.HeaderIdempotentReplayed) != "true" {
+			t.Errorf("expected the retry of the same caller to be replayed")
+		}
+	})
+
+	t.Run("anonymous clients behind a proxy", func(t *testing.T) {
+		proxies, err := middleware.ParseTrustedProxies("10.0.0.0/8")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore(), idempotency.WithScope(idempotency.ScopeByPrincipal(idempotency.ScopeByClientIP(proxies)))).Middleware(c)
+
+		sendFrom := func(client string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"name":"a"}`))
+			req.RemoteAddr = "10.0.0.1:1234"
+			req.Header.Set(constant.HeaderIdempotencyKey, "k1")
+			req.Header.Set(constant.HeaderForwardedFor, client)
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			return w
+		}
+
+		// the clients share the address of the proxy, not their keys
+		sendFrom("203.0.113.1")
+		if w := sendFrom("203.0.113.2"); w.Header().Get(constant.HeaderIdempotentReplayed) != "" {
+			t.Errorf("expected the request of another client not to be replayed")
+		}
+
+		if w := sendFrom("203.0.113.1"); w.Header().Get(constant.HeaderIdempotentReplayed) != "true" {
+			t.Errorf("expected the retry of the same client to be replayed")
+		}
+
+		if c.calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", c.calls.Load())
+		}
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated, block: make(chan struct{})}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			send(h, "k1", `{"name":"a"}`)
+		}()
+
+		// wait until the first request is in the handler
+		for c.calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+
+		w := send(h, "k1", `{"name":"a"}`)
+
+		close(c.block)
+		<-done
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+	})
+
+	t.Run("server error is not stored", func(t *testing.T) {
+		c := &counter{status: http.StatusInternalServerError}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		send(h, "k1", `{"name":"a"}`)
+		send(h, "k1", `{"name":"a"}`)
+
+		if c.calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", c.calls.Load())
+		}
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore()).Middleware(c)
+
+		send(h, "", `{"name":"a"}`)
+		send(h, "", `{"name":"a"}`)
+
+		if c.calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", c.calls.Load())
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		c := &counter{status: http.StatusCreated}
+		h := idempotency.New(idempotency.NewMemoryStore(), idempotency.WithTTL(time.Nanosecond)).Middleware(c)
+
+		send(h, "k1", `{"name":"a"}`)
+		time.Sleep(time.Millisecond)
+		send(h, "k1", `{"name":"a"}`)
+
+		if c.calls.Load() != 2 {
+			t.Errorf("expected 2 calls, got %d", c.calls.Load())
+		}
+	})
+}
+
+func TestMemoryStoreDeleteExpired(t *testing.T) {
+	s := idempotency.NewMemoryStore()
+	ctx := context.Background()
+
+	s.Reserve(ctx, idempotency.Record{Key: "expired", ExpiresAt: time.Now().Add(-time.Second)})
+	s.Reserve(ctx, idempotency.Record{Key: "live", ExpiresAt: time.Now().Add(time.Hour)})
+
+	n, err := s.DeleteExpired(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 {
+		t.Errorf("expected 1 deleted, got %d", n)
+	}
+
+	if _, reserved, _ := s.Reserve(ctx, idempotency.Record{Key: "live", ExpiresAt: time.Now().Add(time.Hour)}); reserved {
+		t.Error("expected the live record to be kept")
+	}
+}
@@ -0,0 +1,69 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the records in memory
+// it's suitable for a single instance
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	now     func() time.Time
+}
+
+// NewMemoryStore initializes a MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record), now: time.Now}
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, rec Record) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; ok && s.now().Before(existing.ExpiresAt) {
+		return existing, false, nil
+	}
+
+	rec.Completed = false
+	s.records[rec.Key] = rec
+
+	return Record{}, true, nil
+}
+
+func (s *MemoryStore) Complete(ctx context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[rec.Key] = rec
+
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(ctx context.Context) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	now := s.now()
+
+	for k, rec := range s.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(s.records, k)
+			n++
+		}
+	}
+
+	return n, nil
+}
@@ -0,0 +1,117 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const tableName = "idempotency_keys"
+
+// PostgresStore keeps the records in postgres
+// it's shared by all the instances
+type PostgresStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewPostgresStore initializes a PostgresStore
+// the idempotency_keys table is defined in scripts/db/db.sql
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, now: time.Now}
+}
+
+func (s *PostgresStore) Reserve(ctx context.Context, rec Record) (Record, bool, error) {
+	// an expired record is taken over
+	q := fmt.Sprintf(`INSERT INTO %s (key, fingerprint, completed, status, expires_at)
+		VALUES ($1, $2, false, 0, $3)
+		ON CONFLICT (key) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, completed = false,
+		status = 0, header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE %s.expires_at <= $4`, tableName, tableName)
+
+	res, err := s.db.ExecContext(ctx, q, rec.Key, rec.Fingerprint, rec.ExpiresAt.Unix(), s.now().Unix())
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	if n == 1 {
+		return Record{}, true, nil
+	}
+
+	existing, err := s.read(ctx, rec.Key)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+func (s *PostgresStore) read(ctx context.Context, key string) (Record, error) {
+	q := fmt.Sprintf("SELECT key, fingerprint, completed, status, header, body, expires_at FROM %s WHERE key = $1", tableName)
+
+	var (
+		rec       Record
+		header    []byte
+		expiresAt int64
+	)
+
+	err := s.db.QueryRowContext(ctx, q, key).Scan(&rec.Key, &rec.Fingerprint, &rec.Completed, &rec.Status, &header, &rec.Body, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// released between the insert and the select
+			return Record{}, fmt.Errorf("idempotency key %s was released concurrently", key)
+		}
+
+		return Record{}, err
+	}
+
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &rec.Header); err != nil {
+			return Record{}, err
+		}
+	}
+
+	rec.ExpiresAt = time.Unix(expiresAt, 0)
+
+	return rec, nil
+}
+
+func (s *PostgresStore) Complete(ctx context.Context, rec Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+
+	q := fmt.Sprintf("UPDATE %s SET completed = true, status = $1, header = $2, body = $3 WHERE key = $4 AND fingerprint = $5", tableName)
+
+	_, err = s.db.ExecContext(ctx, q, rec.Status, header, rec.Body, rec.Key, rec.Fingerprint)
+
+	return err
+}
+
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	q := fmt.Sprintf("DELETE FROM %s WHERE key = $1 AND completed = false", tableName)
+
+	_, err := s.db.ExecContext(ctx, q, key)
+
+	return err
+}
+
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	q := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= $1", tableName)
+
+	res, err := s.db.ExecContext(ctx, q, s.now().Unix())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,73 @@
+package idempotency_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/idempotency"
+)
+
+func TestPostgresStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	s := idempotency.NewPostgresStore(db)
+	ctx := context.Background()
+	rec := idempotency.Record{Key: "POST /api/v1/users k1", Fingerprint: "fp", ExpiresAt: time.Now().Add(time.Hour)}
+
+	t.Run("Reserve", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, reserved, err := s.Reserve(ctx, rec)
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+	})
+
+	t.Run("Reserve existing", func(t *testing.T) {
+		mock.ExpectExec("INSERT INTO idempotency_keys").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery("SELECT key, fingerprint, completed, status, header, body, expires_at FROM idempotency_keys").
+			WithArgs(rec.Key).
+			WillReturnRows(sqlmock.NewRows([]string{"key", "fingerprint", "completed", "status", "header", "body", "expires_at"}).
+				AddRow(rec.Key, "fp", true, http.StatusCreated, []byte(`{"X-Created":["1"]}`), []byte(`{}`), rec.ExpiresAt.Unix()))
+
+		existing, reserved, err := s.Reserve(ctx, rec)
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+		assert.True(t, existing.Completed)
+		assert.Equal(t, http.StatusCreated, existing.Status)
+		assert.Equal(t, "1", existing.Header.Get("X-Created"))
+	})
+
+	t.Run("Complete", func(t *testing.T) {
+		mock.ExpectExec("UPDATE idempotency_keys SET completed = true").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		c := rec
+		c.Status = http.StatusCreated
+		c.Body = []byte(`{}`)
+
+		assert.NoError(t, s.Complete(ctx, c))
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM idempotency_keys WHERE key = \\$1 AND completed = false").
+			WithArgs(rec.Key).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, s.Release(ctx, rec.Key))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,46 @@
+// package module defines the building block of the application
+// a module owns the routes and the lifecycle of a resource
+// modules are registered in a Registry which mounts and runs them
+package module
+
+import (
+	"context"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+)
+
+// ID is the unique name of a module, the modules declare their ids
+// as constants so a dependency on an unknown module does not compile
+type ID string
+
+// Module is implemented by every resource of the application
+type Module interface {
+	// Name returns the unique id of the module
+	Name() ID
+
+	// Version returns the version prefix the routes are mounted under
+	// ex: constant.V1, empty string mounts directly under the api pattern
+	Version() string
+
+	// Dependencies returns the ids of the modules which
+	// must be started before this module
+	Dependencies() []ID
+
+	// Routes registers the routes of the module on the
+	// router of its version
+	Routes(r chi.Router)
+
+	// Start is called before the server starts
+	Start(ctx context.Context) error
+
+	// Stop is called on shutdown in reverse start order
+	Stop(ctx context.Context) error
+}
+
+// GRPCModule is implemented by the modules which also
+// serve their resource over grpc
+type GRPCModule interface {
+	// RegisterGRPC registers the grpc services of the module
+	RegisterGRPC(s grpc.ServiceRegistrar)
+}
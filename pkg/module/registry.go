@@ -0,0 +1,197 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+)
+
+var (
+	ErrDuplicateModule  = errors.New("module already registered")
+	ErrMissingModule    = errors.New("module dependency not registered")
+	ErrCyclicDependency = errors.New("module dependencies are cyclic")
+)
+
+// Registry contains the registered modules
+// it resolves their order from the dependencies
+type Registry struct {
+	modules []Module
+	byName  map[ID]Module
+	// started contains the modules which are started
+	// in start order, used to stop them in reverse
+	started []Module
+}
+
+// NewRegistry initializes a Registry
+func NewRegistry() *Registry {
+	return &Registry{byName: make(map[ID]Module)}
+}
+
+// Register adds the modules to the registry
+func (r *Registry) Register(modules ...Module) error {
+	for _, m := range modules {
+		if _, ok := r.byName[m.Name()]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateModule, m.Name())
+		}
+
+		r.byName[m.Name()] = m
+		r.modules = append(r.modules, m)
+	}
+
+	return nil
+}
+
+// Get returns the module by id
+func (r *Registry) Get(id ID) (Module, bool) {
+	m, ok := r.byName[id]
+	return m, ok
+}
+
+// Modules returns the modules ordered so that every module
+// comes after its dependencies, registration order is kept otherwise
+func (r *Registry) Modules() ([]Module, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[ID]int, len(r.modules))
+	sorted := make([]Module, 0, len(r.modules))
+
+	var visit func(m Module) error
+	visit = func(m Module) error {
+		switch state[m.Name()] {
+		case visiting:
+			return fmt.Errorf("%w: %s", ErrCyclicDependency, m.Name())
+		case visited:
+			return nil
+		}
+
+		state[m.Name()] = visiting
+
+		for _, dep := range m.Dependencies() {
+			d, ok := r.byName[dep]
+			if !ok {
+				return fmt.Errorf("%w: %s requires %s", ErrMissingModule, m.Name(), dep)
+			}
+
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+
+		state[m.Name()] = visited
+		sorted = append(sorted, m)
+
+		return nil
+	}
+
+	for _, m := range r.modules {
+		if err := visit(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return sorted, nil
+}
+
+// Mount mounts the routes of the modules under the pattern
+// modules of the same version share one sub router
+func (r *Registry) Mount(mux chi.Router, pattern string) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+
+	// keep the version order of the first appearance
+	versions := make([]string, 0)
+	byVersion := make(map[string][]Module)
+
+	for _, m := range modules {
+		if _, ok := byVersion[m.Version()]; !ok {
+			versions = append(versions, m.Version())
+		}
+
+		byVersion[m.Version()] = append(byVersion[m.Version()], m)
+	}
+
+	mux.Route(pattern, func(api chi.Router) {
+		for _, v := range versions {
+			mount := func(vr chi.Router) {
+				for _, m := range byVersion[v] {
+					m.Routes(vr)
+				}
+			}
+
+			if v == "" {
+				mount(api)
+				continue
+			}
+
+			api.Route(v, mount)
+		}
+	})
+
+	return nil
+}
+
+// RegisterGRPC registers the grpc services of the
+// modules implementing GRPCModule on s
+func (r *Registry) RegisterGRPC(s grpc.ServiceRegistrar) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if g, ok := m.(GRPCModule); ok {
+			g.RegisterGRPC(s)
+		}
+	}
+
+	return nil
+}
+
+// Start starts the modules in dependency order
+// if a module fails to start the started ones are stopped
+func (r *Registry) Start(ctx context.Context) error {
+	modules, err := r.Modules()
+	if err != nil {
+		return err
+	}
+
+	for _, m := range modules {
+		if err := m.Start(ctx); err != nil {
+			r.Stop(ctx)
+			return fmt.Errorf("start module %s: %w", m.Name(), err)
+		}
+
+		r.started = append(r.started, m)
+	}
+
+	return nil
+}
+
+// Stop stops the started modules in reverse start order
+// all the modules are stopped even if some fail
+func (r *Registry) Stop(ctx context.Context) error {
+	var errs []error
+
+	for i := len(r.started) - 1; i >= 0; i-- {
+		m := r.started[i]
+
+		if err := m.Stop(ctx); err != nil {
+			log.Printf("stop module %s: %v", m.Name(), err)
+			errs = append(errs, fmt.Errorf("stop module %s: %w", m.Name(), err))
+		}
+	}
+
+	r.started = nil
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,188 @@
+package module_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/module"
+	"google.golang.org/grpc"
+)
+
+// fakeModule records its lifecycle calls in the shared log
+type fakeModule struct {
+	name     string
+	version  string
+	deps     []module.ID
+	log      *[]string
+	startErr error
+}
+
+func (m *fakeModule) Name() module.ID           { return module.ID(m.name) }
+func (m *fakeModule) Version() string           { return m.version }
+func (m *fakeModule) Dependencies() []module.ID { return m.deps }
+
+func (m *fakeModule) Routes(r chi.Router) {
+	r.Get("/"+m.name, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(m.name))
+	})
+}
+
+func (m *fakeModule) Start(ctx context.Context) error {
+	*m.log = append(*m.log, "start "+m.name)
+	return m.startErr
+}
+
+func (m *fakeModule) Stop(ctx context.Context) error {
+	*m.log = append(*m.log, "stop "+m.name)
+	return nil
+}
+
+// fakeGRPCModule records the registration of its grpc service
+type fakeGRPCModule struct {
+	fakeModule
+}
+
+func (m *fakeGRPCModule) RegisterGRPC(s grpc.ServiceRegistrar) {
+	s.RegisterService(&grpc.ServiceDesc{ServiceName: m.name, HandlerType: (*any)(nil)}, m)
+}
+
+// serviceRecorder records the names of the registered services
+type serviceRecorder struct {
+	names []string
+}
+
+func (r *serviceRecorder) RegisterService(desc *grpc.ServiceDesc, impl any) {
+	r.names = append(r.names, desc.ServiceName)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Run("dependency order", func(t *testing.T) {
+		var log []string
+
+		reg := module.NewRegistry()
+		err := reg.Register(
+			&fakeModule{name: "a", deps: []module.ID{"b"}, log: &log},
+			&fakeModule{name: "b", deps: []module.ID{"c"}, log: &log},
+			&fakeModule{name: "c", log: &log},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := reg.Start(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := reg.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"start c", "start b", "start a", "stop a", "stop b", "stop c"}
+		if !reflect.DeepEqual(log, expected) {
+			t.Errorf("expected %v, got %v", expected, log)
+		}
+	})
+
+	t.Run("start failure stops started", func(t *testing.T) {
+		var log []string
+
+		reg := module.NewRegistry()
+		reg.Register(
+			&fakeModule{name: "a", log: &log},
+			&fakeModule{name: "b", log: &log, startErr: errors.New("boom")},
+		)
+
+		if err := reg.Start(context.Background()); err == nil {
+			t.Fatal("expected start error")
+		}
+
+		expected := []string{"start a", "start b", "stop a"}
+		if !reflect.DeepEqual(log, expected) {
+			t.Errorf("expected %v, got %v", expected, log)
+		}
+	})
+
+	tests := []struct {
+		name     string
+		modules  []module.Module
+		expected error
+	}{
+		{
+			name:     "duplicate",
+			modules:  []module.Module{&fakeModule{name: "a"}, &fakeModule{name: "a"}},
+			expected: module.ErrDuplicateModule,
+		},
+		{
+			name:     "missing dependency",
+			modules:  []module.Module{&fakeModule{name: "a", deps: []module.ID{"x"}}},
+			expected: module.ErrMissingModule,
+		},
+		{
+			name:     "cyclic dependency",
+			modules:  []module.Module{&fakeModule{name: "a", deps: []module.ID{"b"}}, &fakeModule{name: "b", deps: []module.ID{"a"}}},
+			expected: module.ErrCyclicDependency,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reg := module.NewRegistry()
+
+			err := reg.Register(tc.modules...)
+			if err == nil {
+				_, err = reg.Modules()
+			}
+
+			if !errors.Is(err, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, err)
+			}
+		})
+	}
+
+	t.Run("mount versions", func(t *testing.T) {
+		reg := module.NewRegistry()
+		reg.Register(
+			&fakeModule{name: "a", version: "/v1"},
+			&fakeModule{name: "b", version: "/v1"},
+			&fakeModule{name: "c"},
+		)
+
+		mux := chi.NewRouter()
+		if err := reg.Mount(mux, "/api"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, path := range []string{"/api/v1/a", "/api/v1/b", "/api/c"} {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected %s to be mounted, got status %d", path, w.Code)
+			}
+		}
+	})
+
+	t.Run("register grpc services", func(t *testing.T) {
+		reg := module.NewRegistry()
+		reg.Register(
+			&fakeGRPCModule{fakeModule{name: "a", deps: []module.ID{"c"}}},
+			&fakeModule{name: "b"},
+			&fakeGRPCModule{fakeModule{name: "c"}},
+		)
+
+		var rec serviceRecorder
+		if err := reg.RegisterGRPC(&rec); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"c", "a"}
+		if !reflect.DeepEqual(rec.names, expected) {
+			t.Errorf("expected %v, got %v", expected, rec.names)
+		}
+	})
+}
@@ -0,0 +1,285 @@
+// package oidc logs the users in with the external identity providers
+// of OpenID Connect, it implements the authorization code flow with
+// PKCE, RFC 7636, the provider endpoints are read from its discovery
+// document and the ID tokens are verified with the keys of its jwks_uri
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// the path of the discovery document relative to the issuer
+const discoveryPath = "/.well-known/openid-configuration"
+
+var ErrLoginFailed = errors.New(constant.OIDCLoginFailed)
+
+// Config is the registration of the api at a provider
+type Config struct {
+	// Name identifies the provider in the routes, ex: google
+	Name string
+	// Issuer is the url of the provider, the discovery
+	// document is served under it
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL is the callback route of the provider
+	RedirectURL string
+	// Scopes are requested besides openid, nil is email and profile
+	Scopes []string
+}
+
+// Discovery contains the fields of the discovery document
+// of a provider used by the flow
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Identity is the verified identity of an ID token
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Option configures a Provider
+type Option func(*Provider)
+
+// WithHTTPClient sets the client of the requests to the provider
+func WithHTTPClient(c *http.Client) Option {
+	return func(p *Provider) {
+		p.client = c
+	}
+}
+
+// WithTimeFunc sets the clock of the ID token claims
+func WithTimeFunc(now func() time.Time) Option {
+	return func(p *Provider) {
+		p.now = now
+	}
+}
+
+// Provider is an OpenID Connect provider, the discovery
+// document is fetched by the first login and cached, a
+// failed fetch is retried by the next login
+type Provider struct {
+	config Config
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	discovery *Discovery
+	verifier  *auth.Verifier
+}
+
+// NewProvider initializes a Provider of the config
+func NewProvider(c Config, opts ...Option) *Provider {
+	p := &Provider{
+		config: c,
+		client: &http.Client{Timeout: constant.OIDCHTTPTimeout * time.Second},
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Name returns the name of the provider
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// discover returns the discovery document of the provider
+// and the verifier of its ID tokens
+func (p *Provider) discover(ctx context.Context) (*Discovery, *auth.Verifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, p.verifier, nil
+	}
+
+	var d Discovery
+
+	err := p.getJSON(ctx, strings.TrimSuffix(p.config.Issuer, "/")+discoveryPath, &d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discover %s: %w", p.config.Name, err)
+	}
+
+	// OpenID Connect Discovery 1.0 4.3, the document
+	// must be of the configured issuer
+	if d.Issuer != p.config.Issuer {
+		return nil, nil, fmt.Errorf("discover %s: issuer %q does not match %q", p.config.Name, d.Issuer, p.config.Issuer)
+	}
+
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("discover %s: the endpoints are missing", p.config.Name)
+	}
+
+	p.discovery = &d
+	p.verifier = auth.NewVerifier(
+		auth.NewJWKSURL(d.JWKSURI, auth.WithHTTPClient(p.client)),
+		auth.WithIssuer(d.Issuer),
+		auth.WithAudience(p.config.ClientID),
+		auth.WithAlgorithms(auth.AlgRS256, auth.AlgES256),
+		auth.WithTimeFunc(p.now),
+	)
+
+	return p.discovery, p.verifier, nil
+}
+
+// getJSON decodes the json document served at u into v
+func (p *Provider) getJSON(ctx context.Context, u string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, constant.OIDCMaxBytes)).Decode(v)
+}
+
+// AuthCodeURL returns the url of the authorization endpoint
+// the user agent is redirected to for the login
+func (p *Provider) AuthCodeURL(ctx context.Context, a Authorization) (string, error) {
+	d, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.config.Scopes
+	if scopes == nil {
+		scopes = []string{"email", "profile"}
+	}
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(append([]string{"openid"}, scopes...), " ")},
+		"state":                 {a.State},
+		"nonce":                 {a.Nonce},
+		"code_challenge":        {CodeChallenge(a.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	sep := "?"
+	if strings.Contains(d.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+
+	return d.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// tokenResponse contains the fields of the token response used by the flow
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+// Exchange exchanges the code of the callback for the tokens at the
+// token endpoint and returns the verified identity of the ID token
+// the nonce and the code verifier are the ones of the authorization
+func (p *Provider) Exchange(ctx context.Context, code string, a Authorization) (Identity, error) {
+	d, v, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {a.CodeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	// client_secret_basic, RFC 6749 2.3.1
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("exchange %s: %w", p.config.Name, err)
+	}
+	defer res.Body.Close()
+
+	var t tokenResponse
+
+	err = json.NewDecoder(io.LimitReader(res.Body, constant.OIDCMaxBytes)).Decode(&t)
+	if err != nil {
+		return Identity{}, fmt.Errorf("exchange %s: %w", p.config.Name, err)
+	}
+
+	if res.StatusCode != http.StatusOK || t.IDToken == "" {
+		return Identity{}, fmt.Errorf("%w: exchange %s: status %d %s", ErrLoginFailed, p.config.Name, res.StatusCode, t.Error)
+	}
+
+	return p.verify(ctx, v, t.IDToken, a.Nonce)
+}
+
+// verify verifies the signature, the iss, aud and exp
+// claims and the nonce of the ID token
+func (p *Provider) verify(ctx context.Context, v *auth.Verifier, idToken, nonce string) (Identity, error) {
+	pr, err := v.Verify(ctx, idToken)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrLoginFailed, err)
+	}
+
+	if n, _ := pr.Claims["nonce"].(string); nonce == "" || n != nonce {
+		return Identity{}, fmt.Errorf("%w: the nonce does not match", ErrLoginFailed)
+	}
+
+	// OpenID Connect Core 1.0 3.1.3.7, azp is the
+	// client of a token of several audiences
+	if azp, ok := pr.Claims["azp"].(string); ok && azp != p.config.ClientID {
+		return Identity{}, fmt.Errorf("%w: the azp claim does not match", ErrLoginFailed)
+	}
+
+	id := Identity{Issuer: pr.Issuer, Subject: pr.Subject}
+	id.Email, _ = pr.Claims["email"].(string)
+	id.Name, _ = pr.Claims["name"].(string)
+
+	// some providers send the claim as a string
+	switch ev := pr.Claims["email_verified"].(type) {
+	case bool:
+		id.EmailVerified = ev
+	case string:
+		id.EmailVerified = ev == "true"
+	}
+
+	return id, nil
+}
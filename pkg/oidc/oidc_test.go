@@ -0,0 +1,203 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/oidc"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/oidc/oidctest"
+)
+
+const redirectURL = "https://api.example.com/api/v1/auth/oidc/test/callback"
+
+// authorize follows the authorization url of a and
+// returns the code and the state of the callback
+func authorize(t *testing.T, p *oidc.Provider, a oidc.Authorization) (string, string) {
+	t.Helper()
+
+	u, err := p.AuthCodeURL(context.Background(), a)
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+
+	c := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	res, err := c.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize status = %d, want %d", res.StatusCode, http.StatusFound)
+	}
+
+	loc, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc.Query().Get("code"), loc.Query().Get("state")
+}
+
+func TestProvider(t *testing.T) {
+	srv := oidctest.NewServer("client", "secret")
+	defer srv.Close()
+
+	ctx := context.Background()
+	config := oidc.Config{
+		Name:         "test",
+		Issuer:       srv.Issuer(),
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  redirectURL,
+	}
+	user := oidctest.User{Subject: "u1", Email: "Ada@example.com", EmailVerified: true, Name: "Ada"}
+
+	tests := []struct {
+		name string
+		// claims replace the claims of the ID token
+		claims map[string]any
+		// tamper changes the authorization of the exchange
+		tamper  func(a *oidc.Authorization)
+		wantErr bool
+	}{
+		{name: "login"},
+		{name: "string email_verified", claims: map[string]any{"email_verified": "true"}},
+		{name: "other code verifier", tamper: func(a *oidc.Authorization) { a.CodeVerifier += "x" }, wantErr: true},
+		{name: "other nonce", tamper: func(a *oidc.Authorization) { a.Nonce += "x" }, wantErr: true},
+		{name: "other audience", claims: map[string]any{"aud": "another"}, wantErr: true},
+		{name: "other azp", claims: map[string]any{"aud": []string{"client", "another"}, "azp": "another"}, wantErr: true},
+		{name: "other issuer", claims: map[string]any{"iss": "https://evil.example.com"}, wantErr: true},
+		{name: "expired", claims: map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := user
+			u.Claims = tt.claims
+			srv.SetUser(u)
+
+			p := oidc.NewProvider(config)
+
+			a, err := oidc.NewAuthorization(config.Name, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			code, state := authorize(t, p, a)
+			if state != a.State {
+				t.Fatalf("callback state = %q, want %q", state, a.State)
+			}
+
+			if tt.tamper != nil {
+				tt.tamper(&a)
+			}
+
+			id, err := p.Exchange(ctx, code, a)
+			if tt.wantErr {
+				if !errors.Is(err, oidc.ErrLoginFailed) {
+					t.Errorf("Exchange() error = %v, want ErrLoginFailed", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+
+			want := oidc.Identity{Issuer: srv.Issuer(), Subject: "u1", Email: "Ada@example.com", EmailVerified: true, Name: "Ada"}
+			if id != want {
+				t.Errorf("Exchange() = %+v, want %+v", id, want)
+			}
+		})
+	}
+
+	t.Run("code used once", func(t *testing.T) {
+		srv.SetUser(user)
+		p := oidc.NewProvider(config)
+
+		a, _ := oidc.NewAuthorization(config.Name, time.Now())
+		code, _ := authorize(t, p, a)
+
+		if _, err := p.Exchange(ctx, code, a); err != nil {
+			t.Fatalf("Exchange() error = %v", err)
+		}
+
+		if _, err := p.Exchange(ctx, code, a); !errors.Is(err, oidc.ErrLoginFailed) {
+			t.Errorf("Exchange() of a used code error = %v, want ErrLoginFailed", err)
+		}
+	})
+
+	t.Run("issuer of the discovery", func(t *testing.T) {
+		c := config
+		c.Issuer = srv.Issuer() + "/other"
+
+		a, _ := oidc.NewAuthorization(c.Name, time.Now())
+
+		if _, err := oidc.NewProvider(c).AuthCodeURL(ctx, a); err == nil {
+			t.Error("AuthCodeURL() of a provider of another issuer succeeded")
+		}
+	})
+}
+
+func TestState(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Now()
+
+	a, err := oidc.NewAuthorization("test", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := oidc.Seal(key, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      []byte
+		sealed   string
+		provider string
+		state    string
+		now      time.Time
+		wantErr  bool
+	}{
+		{name: "open", key: key, sealed: sealed, provider: "test", state: a.State, now: now},
+		{name: "other key", key: []byte("another key of the state cookies"), sealed: sealed, provider: "test", state: a.State, now: now, wantErr: true},
+		{name: "tampered", key: key, sealed: "e30" + sealed[3:], provider: "test", state: a.State, now: now, wantErr: true},
+		{name: "malformed", key: key, sealed: "state", provider: "test", state: a.State, now: now, wantErr: true},
+		{name: "other provider", key: key, sealed: sealed, provider: "other", state: a.State, now: now, wantErr: true},
+		{name: "other state", key: key, sealed: sealed, provider: "test", state: a.State + "x", now: now, wantErr: true},
+		{name: "expired", key: key, sealed: sealed, provider: "test", state: a.State, now: now.Add(time.Hour), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oidc.Open(tt.key, tt.sealed, tt.provider, tt.state, tt.now)
+			if tt.wantErr {
+				if !errors.Is(err, oidc.ErrInvalidState) {
+					t.Errorf("Open() error = %v, want ErrInvalidState", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+
+			if got != a {
+				t.Errorf("Open() = %+v, want %+v", got, a)
+			}
+		})
+	}
+}
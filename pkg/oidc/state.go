@@ -0,0 +1,114 @@
+package oidc
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+var ErrInvalidState = errors.New(constant.InvalidOIDCState)
+
+// Authorization contains the secrets of a login, they are kept by the
+// user agent in a sealed cookie between the redirect to the provider
+// and the callback, the state binds the callback to the user agent,
+// the nonce binds the ID token to the login and the code verifier
+// binds the code to the login, RFC 7636
+type Authorization struct {
+	Provider     string `json:"p"`
+	State        string `json:"s"`
+	Nonce        string `json:"n"`
+	CodeVerifier string `json:"v"`
+	// ExpiresAt is the unix time the login must be completed by
+	ExpiresAt int64 `json:"e"`
+	// LinkUser is the id of the logged in user the identity
+	// is linked to, empty for a login
+	LinkUser string `json:"u,omitempty"`
+}
+
+// NewAuthorization generates the random secrets of a login at the
+// provider which expires after constant.OIDCStateTTL from now
+func NewAuthorization(provider string, now time.Time) (Authorization, error) {
+	a := Authorization{
+		Provider:  provider,
+		ExpiresAt: now.Add(constant.OIDCStateTTL * time.Minute).Unix(),
+	}
+
+	for _, s := range []*string{&a.State, &a.Nonce, &a.CodeVerifier} {
+		b := make([]byte, constant.OIDCRandomBytes)
+		if _, err := rand.Read(b); err != nil {
+			return a, err
+		}
+
+		*s = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	return a, nil
+}
+
+// CodeChallenge returns the S256 challenge of the code verifier
+func CodeChallenge(verifier string) string {
+	h := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+// Seal encodes the authorization and its HMAC-SHA256 of key
+func Seal(key []byte, a Authorization) (string, error) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return "", err
+	}
+
+	p := base64.RawURLEncoding.EncodeToString(b)
+
+	return p + "." + base64.RawURLEncoding.EncodeToString(sign(key, p)), nil
+}
+
+// Open verifies and decodes a sealed authorization, it must be
+// of the provider and the state and not expired at now
+func Open(key []byte, sealed, provider, state string, now time.Time) (Authorization, error) {
+	var a Authorization
+
+	p, mac, ok := strings.Cut(sealed, ".")
+	if !ok {
+		return a, ErrInvalidState
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(mac)
+	if err != nil || !hmac.Equal(sig, sign(key, p)) {
+		return a, ErrInvalidState
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(p)
+	if err != nil {
+		return a, ErrInvalidState
+	}
+
+	err = json.Unmarshal(b, &a)
+	if err != nil {
+		return a, ErrInvalidState
+	}
+
+	if a.Provider != provider || a.State == "" || subtle.ConstantTimeCompare([]byte(a.State), []byte(state)) != 1 {
+		return a, ErrInvalidState
+	}
+
+	if a.ExpiresAt <= now.Unix() {
+		return a, ErrInvalidState
+	}
+
+	return a, nil
+}
+
+func sign(key []byte, payload string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(payload))
+	return m.Sum(nil)
+}
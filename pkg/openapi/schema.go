@@ -0,0 +1,308 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const componentsSchemasRef = "#/components/schemas/"
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// SchemaFor builds the schema of v and registers the named
+// struct types in the components of the document
+// struct types are referenced with $ref
+func (d *Document) SchemaFor(v any) *Schema {
+	return d.schemaForType(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaForType(t reflect.Type) *Schema {
+	// time.Time is encoded as an RFC 3339 string
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	// json.RawMessage is encoded as is, it can hold any value
+	if t == rawMessageType {
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(d.schemaForType(t.Elem()))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded as base64 string by encoding/json
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaForType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+
+		name := SchemaName(t)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			// register a placeholder first to stop recursion
+			// for self referencing types
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+
+		return &Schema{Ref: componentsSchemasRef + name}
+	default:
+		// interface and other kinds can hold anything
+		return &Schema{}
+	}
+}
+
+// structSchema builds an object schema from the json
+// and validate tags of the struct fields
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, omitempty, skip := jsonName(f)
+		if skip {
+			continue
+		}
+
+		// embedded structs without a json name are flattened
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				embedded := d.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := d.schemaForType(f.Type)
+
+		required := applyValidateTag(fs, f.Tag.Get("validate"))
+		if required || (!omitempty && f.Type.Kind() != reflect.Pointer && f.Tag.Get("validate") == "") {
+			s.Required = append(s.Required, name)
+		}
+
+		s.Properties[name] = fs
+	}
+
+	return s
+}
+
+// jsonName returns the json name of the field, whether it has
+// omitempty option and whether it's skipped by encoding/json
+func jsonName(f reflect.StructField) (string, bool, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false, true
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	parts := strings.Split(tag, ",")
+
+	omitempty := false
+	for _, o := range parts[1:] {
+		if o == "omitempty" || o == "omitzero" {
+			omitempty = true
+		}
+	}
+
+	return parts[0], omitempty, false
+}
+
+// applyValidateTag maps the go-playground/validator rules
+// to schema constraints, it returns true if the field is required
+func applyValidateTag(s *Schema, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	// constraints apply to the referenced/nullable schema
+	// for $ref there is nothing to constrain
+	target := s
+	if s.Ref != "" {
+		target = nil
+	}
+
+	required := false
+	isString := isType(s, "string")
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			setFormat(target, "email")
+		case "uuid", "uuid4":
+			setFormat(target, "uuid")
+		case "url", "uri":
+			setFormat(target, "uri")
+		case "datetime":
+			setFormat(target, "date-time")
+		case "oneof":
+			if target != nil {
+				for _, v := range strings.Fields(param) {
+					target.Enum = append(target.Enum, enumValue(s, v))
+				}
+			}
+		case "min", "gte":
+			setBound(target, isString, param, true)
+		case "max", "lte":
+			setBound(target, isString, param, false)
+		case "len":
+			setBound(target, isString, param, true)
+			setBound(target, isString, param, false)
+		case "gt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil && target != nil && !isString {
+				target.ExclusiveMinimum = &f
+			}
+		case "lt":
+			if f, err := strconv.ParseFloat(param, 64); err == nil && target != nil && !isString {
+				target.ExclusiveMaximum = &f
+			}
+		}
+	}
+
+	return required
+}
+
+func setFormat(s *Schema, format string) {
+	if s != nil {
+		s.Format = format
+	}
+}
+
+// setBound sets the lower or upper length, numeric or
+// item count bound based on the type of the schema
+func setBound(s *Schema, isString bool, param string, lower bool) {
+	if s == nil {
+		return
+	}
+
+	switch {
+	case isString:
+		if n, err := strconv.Atoi(param); err == nil {
+			if lower {
+				s.MinLength = &n
+			} else {
+				s.MaxLength = &n
+			}
+		}
+	case isType(s, "array"):
+		if n, err := strconv.Atoi(param); err == nil {
+			if lower {
+				s.MinItems = &n
+			} else {
+				s.MaxItems = &n
+			}
+		}
+	case isType(s, "integer") || isType(s, "number"):
+		if f, err := strconv.ParseFloat(param, 64); err == nil {
+			if lower {
+				s.Minimum = &f
+			} else {
+				s.Maximum = &f
+			}
+		}
+	}
+}
+
+func enumValue(s *Schema, v string) any {
+	switch {
+	case isType(s, "integer"):
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case isType(s, "number"):
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+
+	return v
+}
+
+// isType checks the schema type, it handles the
+// nullable form ["<type>", "null"] as well
+func isType(s *Schema, typ string) bool {
+	switch t := s.Type.(type) {
+	case string:
+		return t == typ
+	case []string:
+		return len(t) > 0 && t[0] == typ
+	}
+
+	return false
+}
+
+// nullable converts the schema to its nullable form
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		// a $ref can't be combined with a type list
+		return &Schema{OneOf: []*Schema{s, {Type: "null"}}}
+	}
+
+	if t, ok := s.Type.(string); ok {
+		s.Type = []string{t, "null"}
+	}
+
+	return s
+}
+
+// pkgPathRegex matches the package path qualifiers
+// of the type arguments in a generic type name
+var pkgPathRegex = regexp.MustCompile(`[\w./-]*\.`)
+
+// SchemaName builds the component name of the type
+// generic type names like Response[pkg/path.Type] are
+// converted to ResponseType
+func SchemaName(t reflect.Type) string {
+	name := t.Name()
+	if !strings.Contains(name, "[") {
+		return name
+	}
+
+	name = pkgPathRegex.ReplaceAllString(name, "")
+
+	return strings.NewReplacer("[", "", "]", "", ",", "", "*", "").Replace(name)
+}
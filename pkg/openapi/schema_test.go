@@ -0,0 +1,101 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	Name     string          `json:"name" validate:"required,min=2,max=20"`
+	Email    string          `json:"email" validate:"required,email"`
+	Age      int             `json:"age" validate:"gte=18,lte=130"`
+	Role     string          `json:"role" validate:"oneof=admin staff"`
+	Nickname *string         `json:"nickname" validate:"omitempty"`
+	Tags     []string        `json:"tags,omitempty" validate:"max=5"`
+	Seen     *time.Time      `json:"seen"`
+	Extra    json.RawMessage `json:"extra,omitempty"`
+	internal string
+}
+
+type testGeneric[T any] struct {
+	Data T `json:"data"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	d := NewDocument(Info{Title: "test", Version: "1"})
+
+	s := d.SchemaFor(testPayload{})
+	if s.Ref != componentsSchemasRef+"testPayload" {
+		t.Fatalf("expected ref to testPayload, got %q", s.Ref)
+	}
+
+	c := d.Components.Schemas["testPayload"]
+
+	if !reflect.DeepEqual(c.Required, []string{"name", "email"}) {
+		t.Errorf("expected required [name email], got %v", c.Required)
+	}
+
+	if _, ok := c.Properties["internal"]; ok {
+		t.Error("unexported field must be skipped")
+	}
+
+	tests := []struct {
+		name  string
+		check func() bool
+	}{
+		{name: "string length", check: func() bool {
+			p := c.Properties["name"]
+			return *p.MinLength == 2 && *p.MaxLength == 20
+		}},
+		{name: "email format", check: func() bool { return c.Properties["email"].Format == "email" }},
+		{name: "number bounds", check: func() bool {
+			p := c.Properties["age"]
+			return *p.Minimum == 18 && *p.Maximum == 130
+		}},
+		{name: "enum", check: func() bool {
+			return reflect.DeepEqual(c.Properties["role"].Enum, []any{"admin", "staff"})
+		}},
+		{name: "nullable pointer", check: func() bool {
+			return reflect.DeepEqual(c.Properties["nickname"].Type, []string{"string", "null"})
+		}},
+		{name: "array items", check: func() bool { return *c.Properties["tags"].MaxItems == 5 }},
+		{name: "time", check: func() bool {
+			p := c.Properties["seen"]
+			return p.Format == "date-time" && reflect.DeepEqual(p.Type, []string{"string", "null"})
+		}},
+		{name: "raw message", check: func() bool {
+			p := c.Properties["extra"]
+			return p.Type == nil && p.Format == ""
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if !tc.check() {
+				t.Errorf("unexpected schema for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestSchemaName(t *testing.T) {
+	tests := []struct {
+		name     string
+		typ      reflect.Type
+		expected string
+	}{
+		{name: "plain", typ: reflect.TypeOf(testPayload{}), expected: "testPayload"},
+		{name: "generic", typ: reflect.TypeOf(testGeneric[testPayload]{}), expected: "testGenerictestPayload"},
+		{name: "nested generic", typ: reflect.TypeOf(testGeneric[testGeneric[testPayload]]{}), expected: "testGenerictestGenerictestPayload"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SchemaName(tc.typ); got != tc.expected {
+				t.Errorf("SchemaName() = %s, expected %s", got, tc.expected)
+			}
+		})
+	}
+}
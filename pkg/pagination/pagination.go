@@ -0,0 +1,219 @@
+// package pagination resolves the page of the list requests
+// and describes it with the totals and the Link header
+package pagination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext"
+)
+
+// CountMode selects how the total of a list is computed
+type CountMode string
+
+const (
+	// CountExact counts the matching rows
+	CountExact CountMode = "exact"
+	// CountEstimated reports the planner estimate of the table
+	// when it exceeds the threshold of the policy, the smaller
+	// tables are counted exactly, the estimate ignores the filters
+	CountEstimated CountMode = "estimated"
+)
+
+// Policy is the pagination policy of a resource
+type Policy struct {
+	DefaultLimit int
+	MaxLimit     int
+	Count        CountMode
+	// EstimateThreshold is the estimate above which it is reported
+	EstimateThreshold int64
+}
+
+// DefaultPolicy returns the policy of the constants
+func DefaultPolicy() Policy {
+	return Policy{
+		DefaultLimit:      constant.PageDefaultLimit,
+		MaxLimit:          constant.PageMaxLimit,
+		Count:             CountExact,
+		EstimateThreshold: constant.PageEstimateThreshold,
+	}
+}
+
+// ParsePolicy parses a policy of the form
+// "limit=10,maxLimit=100,count=estimated,threshold=10000"
+// the missing keys keep the defaults
+func ParsePolicy(s string) (Policy, error) {
+	p := DefaultPolicy()
+
+	for pair := range strings.SplitSeq(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return p, fmt.Errorf("%s: %s", constant.InvalidPaginationPolicy, pair)
+		}
+
+		var err error
+		switch k {
+		case "limit":
+			p.DefaultLimit, err = strconv.Atoi(v)
+		case "maxLimit":
+			p.MaxLimit, err = strconv.Atoi(v)
+		case "threshold":
+			p.EstimateThreshold, err = strconv.ParseInt(v, 10, 64)
+		case "count":
+			p.Count = CountMode(v)
+		default:
+			err = errors.New("unknown key")
+		}
+
+		if err != nil {
+			return p, fmt.Errorf("%s: %s", constant.InvalidPaginationPolicy, pair)
+		}
+	}
+
+	if p.DefaultLimit < 1 || p.MaxLimit < p.DefaultLimit || (p.Count != CountExact && p.Count != CountEstimated) {
+		return p, fmt.Errorf("%s: %s", constant.InvalidPaginationPolicy, s)
+	}
+
+	return p, nil
+}
+
+// Page is the resolved page of a list request
+type Page struct {
+	// Limit is the effective number of items per page
+	Limit  int
+	Number int
+}
+
+// Offset returns the number of items before the page
+func (p Page) Offset() int {
+	return p.Limit * (p.Number - 1)
+}
+
+// Resolve returns the page of the limit and page query parameters
+// a missing or lower than one limit is the default limit and
+// a limit above the maximum is reduced, a missing or lower than
+// one page is the first page
+func (p Policy) Resolve(r *http.Request) (Page, error) {
+	var limit, number int
+
+	if v := httpext.GetQueryParam(r, constant.ParamLimit); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return p.Page(0, 0), fmt.Errorf("%s: %s", constant.InvalidQueryParam, v)
+		}
+
+		limit = n
+	}
+
+	if v := httpext.GetQueryParam(r, constant.ParamPage); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return p.Page(0, 0), fmt.Errorf("%s: %s", constant.InvalidQueryParam, v)
+		}
+
+		number = n
+	}
+
+	return p.Page(limit, number), nil
+}
+
+// Page returns the page of the limit and the page number
+// with the rules of Resolve, used by the non http callers
+func (p Policy) Page(limit, number int) Page {
+	page := Page{Limit: p.DefaultLimit, Number: max(number, 1)}
+
+	if limit >= 1 {
+		page.Limit = min(limit, p.MaxLimit)
+	}
+
+	return page
+}
+
+// Total is the number of items of a list
+type Total struct {
+	Items     int64
+	Estimated bool
+}
+
+// Total computes the total with the count mode of the policy
+// exact counts the rows and estimate returns the planner
+// estimate of the table, negative if it is not known
+func (p Policy) Total(ctx context.Context, exact, estimate func(context.Context) (int64, error)) (Total, error) {
+	if p.Count == CountEstimated {
+		n, err := estimate(ctx)
+		if err != nil {
+			return Total{}, err
+		}
+
+		if n > p.EstimateThreshold {
+			return Total{Items: n, Estimated: true}, nil
+		}
+	}
+
+	n, err := exact(ctx)
+	if err != nil {
+		return Total{}, err
+	}
+
+	return Total{Items: n}, nil
+}
+
+// Meta describes a page of a list
+type Meta struct {
+	TotalItems int64
+	TotalPages int
+	HasNext    bool
+	Estimated  bool
+}
+
+// NewMeta returns the meta of the page
+func NewMeta(page Page, total Total) Meta {
+	pages := int((total.Items + int64(page.Limit) - 1) / int64(page.Limit))
+
+	return Meta{
+		TotalItems: total.Items,
+		TotalPages: pages,
+		HasNext:    page.Number < pages,
+		Estimated:  total.Estimated,
+	}
+}
+
+// SetLinks sets the first, prev, next and last Link headers of
+// the page, RFC 8288, the links keep the other query parameters
+func SetLinks(w http.ResponseWriter, r *http.Request, page Page, meta Meta) {
+	last := max(meta.TotalPages, 1)
+
+	link := func(number int, rel string) {
+		q := r.URL.Query()
+		q.Set(constant.ParamLimit, strconv.Itoa(page.Limit))
+		q.Set(constant.ParamPage, strconv.Itoa(number))
+
+		u := *r.URL
+		u.RawQuery = q.Encode()
+
+		w.Header().Add(constant.HeaderLink, fmt.Sprintf("<%s>; rel=\"%s\"", u.RequestURI(), rel))
+	}
+
+	link(1, "first")
+
+	if page.Number > 1 {
+		link(min(page.Number-1, last), "prev")
+	}
+
+	if meta.HasNext {
+		link(page.Number+1, "next")
+	}
+
+	link(last, "last")
+}
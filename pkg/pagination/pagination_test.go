@@ -0,0 +1,202 @@
+package pagination_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
+)
+
+func TestParsePolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected pagination.Policy
+		err      bool
+	}{
+		{name: "empty", input: "", expected: pagination.DefaultPolicy()},
+		{
+			name:     "all keys",
+			input:    "limit=20, maxLimit=200,count=estimated,threshold=5",
+			expected: pagination.Policy{DefaultLimit: 20, MaxLimit: 200, Count: pagination.CountEstimated, EstimateThreshold: 5},
+		},
+		{name: "unknown key", input: "size=10", err: true},
+		{name: "not a number", input: "limit=ten", err: true},
+		{name: "unknown count", input: "count=fast", err: true},
+		{name: "default above max", input: "limit=200", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := pagination.ParsePolicy(tc.input)
+			if tc.err {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", p)
+				}
+
+				return
+			}
+
+			if err != nil || p != tc.expected {
+				t.Errorf("expected %+v, got %+v %v", tc.expected, p, err)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	policy := pagination.Policy{DefaultLimit: 10, MaxLimit: 50}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected pagination.Page
+		err      bool
+	}{
+		{name: "defaults", query: "", expected: pagination.Page{Limit: 10, Number: 1}},
+		{name: "valid", query: "?limit=25&page=3", expected: pagination.Page{Limit: 25, Number: 3}},
+		{name: "limit above max", query: "?limit=500", expected: pagination.Page{Limit: 50, Number: 1}},
+		{name: "limit below one", query: "?limit=0&page=-2", expected: pagination.Page{Limit: 10, Number: 1}},
+		{name: "invalid limit", query: "?limit=a", err: true},
+		{name: "invalid page", query: "?page=a", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products"+tc.query, nil)
+
+			page, err := policy.Resolve(r)
+			if tc.err != (err != nil) {
+				t.Fatalf("expected error %t, got %v", tc.err, err)
+			}
+
+			if !tc.err && page != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, page)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	policy := pagination.Policy{DefaultLimit: 10, MaxLimit: 50}
+
+	tests := []struct {
+		name     string
+		limit    int
+		number   int
+		expected pagination.Page
+	}{
+		{name: "defaults", expected: pagination.Page{Limit: 10, Number: 1}},
+		{name: "valid", limit: 25, number: 3, expected: pagination.Page{Limit: 25, Number: 3}},
+		{name: "limit above max", limit: 500, expected: pagination.Page{Limit: 50, Number: 1}},
+		{name: "negative", limit: -1, number: -2, expected: pagination.Page{Limit: 10, Number: 1}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if page := policy.Page(tc.limit, tc.number); page != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, page)
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	exact := func(context.Context) (int64, error) { return 42, nil }
+	estimate := func(n int64) func(context.Context) (int64, error) {
+		return func(context.Context) (int64, error) { return n, nil }
+	}
+
+	tests := []struct {
+		name     string
+		policy   pagination.Policy
+		estimate int64
+		expected pagination.Total
+	}{
+		{name: "exact", policy: pagination.Policy{Count: pagination.CountExact}, estimate: 1000, expected: pagination.Total{Items: 42}},
+		{name: "estimated", policy: pagination.Policy{Count: pagination.CountEstimated, EstimateThreshold: 100}, estimate: 1000, expected: pagination.Total{Items: 1000, Estimated: true}},
+		{name: "below threshold", policy: pagination.Policy{Count: pagination.CountEstimated, EstimateThreshold: 100}, estimate: 50, expected: pagination.Total{Items: 42}},
+		{name: "unknown estimate", policy: pagination.Policy{Count: pagination.CountEstimated}, estimate: -1, expected: pagination.Total{Items: 42}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			total, err := tc.policy.Total(context.Background(), exact, estimate(tc.estimate))
+			if err != nil || total != tc.expected {
+				t.Errorf("expected %+v, got %+v %v", tc.expected, total, err)
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		failing := func(context.Context) (int64, error) { return 0, errors.New("failed") }
+
+		if _, err := pagination.DefaultPolicy().Total(context.Background(), failing, failing); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestSetLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     pagination.Page
+		total    int64
+		expected []string
+		hasNext  bool
+	}{
+		{
+			name:    "middle",
+			page:    pagination.Page{Limit: 10, Number: 2},
+			total:   35,
+			hasNext: true,
+			expected: []string{
+				`</products?isArchived=false&limit=10&page=1>; rel="first"`,
+				`</products?isArchived=false&limit=10&page=1>; rel="prev"`,
+				`</products?isArchived=false&limit=10&page=3>; rel="next"`,
+				`</products?isArchived=false&limit=10&page=4>; rel="last"`,
+			},
+		},
+		{
+			name:  "empty",
+			page:  pagination.Page{Limit: 10, Number: 1},
+			total: 0,
+			expected: []string{
+				`</products?isArchived=false&limit=10&page=1>; rel="first"`,
+				`</products?isArchived=false&limit=10&page=1>; rel="last"`,
+			},
+		},
+		{
+			name:  "past the last page",
+			page:  pagination.Page{Limit: 10, Number: 9},
+			total: 15,
+			expected: []string{
+				`</products?isArchived=false&limit=10&page=1>; rel="first"`,
+				`</products?isArchived=false&limit=10&page=2>; rel="prev"`,
+				`</products?isArchived=false&limit=10&page=2>; rel="last"`,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/products?isArchived=false&limit=500", nil)
+			w := httptest.NewRecorder()
+
+			meta := pagination.NewMeta(tc.page, pagination.Total{Items: tc.total})
+			if meta.HasNext != tc.hasNext {
+				t.Errorf("expected hasNext %t, got %t", tc.hasNext, meta.HasNext)
+			}
+
+			pagination.SetLinks(w, r, tc.page, meta)
+
+			if got := w.Header().Values("Link"); !slices.Equal(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
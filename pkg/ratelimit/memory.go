@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// shard is a part of the keys of a MemoryStore with its own lock
+type shard struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// MemoryStore keeps the states in memory, the keys are spread
+// over constant.RateLimitShards shards so that the requests
+// of different keys rarely wait for the same lock
+// it's suitable for a single instance
+type MemoryStore struct {
+	seed   maphash.Seed
+	shards []*shard
+	now    func() time.Time
+}
+
+// NewMemoryStore initializes a MemoryStore
+func NewMemoryStore() *MemoryStore {
+	s := &MemoryStore{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*shard, constant.RateLimitShards),
+		now:    time.Now,
+	}
+
+	for i := range s.shards {
+		s.shards[i] = &shard{states: make(map[string]State)}
+	}
+
+	return s
+}
+
+func (s *MemoryStore) shard(key string) *shard {
+	return s.shards[maphash.String(s.seed, key)%uint64(len(s.shards))]
+}
+
+func (s *MemoryStore) Update(ctx context.Context, key string, now time.Time, fn func(State) State) error {
+	sh := s.shard(key)
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	st, ok := sh.states[key]
+	if !ok || st.ExpiresAt <= now.UnixNano() {
+		st = State{}
+	}
+
+	sh.states[key] = fn(st)
+
+	return nil
+}
+
+func (s *MemoryStore) DeleteExpired(ctx context.Context) (int64, error) {
+	var n int64
+
+	now := s.now().UnixNano()
+
+	for _, sh := range s.shards {
+		sh.mu.Lock()
+
+		for k, st := range sh.states {
+			if st.ExpiresAt <= now {
+				delete(sh.states, k)
+				n++
+			}
+		}
+
+		sh.mu.Unlock()
+	}
+
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+const tableName = "rate_limits"
+
+// PostgresStore keeps the states in postgres
+// it's shared by all the instances, the row of
+// a key is locked during its update
+type PostgresStore struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+// NewPostgresStore initializes a PostgresStore
+// the rate_limits table is defined in scripts/db/db.sql
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, now: time.Now}
+}
+
+func (s *PostgresStore) Update(ctx context.Context, key string, now time.Time, fn func(State) State) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.update(ctx, tx, key, now, fn)
+	if err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			log.Printf("rollback returned error: %v", rerr)
+		}
+
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// update locks the row of the key, it is inserted first
+// so that the concurrent first requests wait for each other
+func (s *PostgresStore) update(ctx context.Context, tx *sql.Tx, key string, now time.Time, fn func(State) State) error {
+	q := fmt.Sprintf("INSERT INTO %s (key, time, value, prev, expires_at) VALUES ($1, 0, 0, 0, 0) ON CONFLICT (key) DO NOTHING", tableName)
+
+	_, err := tx.ExecContext(ctx, q, key)
+	if err != nil {
+		return err
+	}
+
+	q = fmt.Sprintf("SELECT time, value, prev, expires_at FROM %s WHERE key = $1 FOR UPDATE", tableName)
+
+	var st State
+
+	err = tx.QueryRowContext(ctx, q, key).Scan(&st.Time, &st.Value, &st.Prev, &st.ExpiresAt)
+	if err != nil {
+		return err
+	}
+
+	if st.ExpiresAt <= now.UnixNano() {
+		st = State{}
+	}
+
+	st = fn(st)
+
+	q = fmt.Sprintf("UPDATE %s SET time = $1, value = $2, prev = $3, expires_at = $4 WHERE key = $5", tableName)
+
+	_, err = tx.ExecContext(ctx, q, st.Time, st.Value, st.Prev, st.ExpiresAt, key)
+
+	return err
+}
+
+func (s *PostgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	q := fmt.Sprintf("DELETE FROM %s WHERE expires_at <= $1", tableName)
+
+	res, err := s.db.ExecContext(ctx, q, s.now().UnixNano())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,75 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+)
+
+func TestPostgresStore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		db.Close()
+	})
+
+	s := ratelimit.NewPostgresStore(db)
+	ctx := context.Background()
+	now := time.Now()
+	columns := []string{"time", "value", "prev", "expires_at"}
+
+	t.Run("Update", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO rate_limits").
+			WithArgs("k").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT time, value, prev, expires_at FROM rate_limits WHERE key = \\$1 FOR UPDATE").
+			WithArgs("k").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(now.UnixNano(), 2.5, 0.0, now.Add(time.Minute).UnixNano()))
+		mock.ExpectExec("UPDATE rate_limits SET time = \\$1, value = \\$2, prev = \\$3, expires_at = \\$4 WHERE key = \\$5").
+			WithArgs(now.UnixNano(), 1.5, 0.0, now.Add(time.Minute).UnixNano(), "k").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := s.Update(ctx, "k", now, func(st ratelimit.State) ratelimit.State {
+			assert.Equal(t, 2.5, st.Value)
+			st.Value--
+			return st
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Update expired", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO rate_limits").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT time, value, prev, expires_at FROM rate_limits").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(now.Add(-time.Hour).UnixNano(), 2.5, 1.0, now.Add(-time.Minute).UnixNano()))
+		mock.ExpectExec("UPDATE rate_limits").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := s.Update(ctx, "k", now, func(st ratelimit.State) ratelimit.State {
+			assert.Equal(t, ratelimit.State{}, st)
+			return st
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		mock.ExpectExec("DELETE FROM rate_limits WHERE expires_at <= \\$1").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+
+		n, err := s.DeleteExpired(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), n)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
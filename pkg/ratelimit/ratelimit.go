@@ -0,0 +1,240 @@
+// package ratelimit limits the rate of the requests of the clients
+// with the token bucket or the sliding window algorithm, the state
+// of a key is kept in a Store, in memory for a single instance or
+// in postgres for the instances sharing the limits
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is the allowance of a key
+type Limit struct {
+	// Requests are allowed per Period
+	Requests int
+	Period   time.Duration
+	// Burst is the capacity of a token bucket, 0 is Requests
+	// the sliding window ignores it
+	Burst int
+}
+
+// ParseLimit parses a limit of the form requests/period, ex: 100/1m
+func ParseLimit(s string) (Limit, error) {
+	r, p, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, want requests/period, ex: 100/1m", s)
+	}
+
+	n, err := strconv.Atoi(r)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid requests of limit %q", s)
+	}
+
+	d, err := time.ParseDuration(p)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid period of limit %q", s)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// State is the stored state of a key, its fields are
+// interpreted by the algorithm, the zero State is a
+// key without requests
+type State struct {
+	// Time is a unix time in nanoseconds, ex: of the last refill
+	Time  int64
+	Value float64
+	Prev  float64
+	// ExpiresAt is the unix time in nanoseconds the state
+	// equals the zero State at, it can be removed then
+	ExpiresAt int64
+}
+
+// Result is the decision of a request
+type Result struct {
+	Allowed bool
+	// Limit is the quota of the key, ex: the capacity of the bucket
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is available again
+	Reset time.Duration
+	// RetryAfter is the time until a request is allowed, 0 if allowed
+	RetryAfter time.Duration
+}
+
+// Algorithm takes a request from the state of a key
+type Algorithm interface {
+	// Take returns the state after the request at now and the decision
+	Take(s State, l Limit, now time.Time) (State, Result)
+}
+
+// TokenBucket refills a bucket of Burst tokens at Requests per Period
+// a request takes a token, so the bursts are allowed up to the capacity
+// State.Time is the time of the last refill and State.Value the tokens
+type TokenBucket struct{}
+
+func (TokenBucket) Take(s State, l Limit, now time.Time) (State, Result) {
+	capacity := float64(l.Burst)
+	if l.Burst <= 0 {
+		capacity = float64(l.Requests)
+	}
+
+	// tokens per nanosecond
+	rate := float64(l.Requests) / float64(l.Period)
+	n := now.UnixNano()
+
+	tokens := capacity
+	if s.Time != 0 {
+		tokens = math.Min(capacity, s.Value+float64(n-s.Time)*rate)
+	}
+
+	res := Result{Limit: int(capacity)}
+
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration(math.Ceil((1 - tokens) / rate))
+	}
+
+	res.Remaining = int(tokens)
+	res.Reset = time.Duration(math.Ceil((capacity - tokens) / rate))
+
+	return State{Time: n, Value: tokens, ExpiresAt: n + int64(res.Reset)}, res
+}
+
+// SlidingWindow counts the requests of the fixed windows of a Period
+// and estimates the requests of the last Period weighting the count
+// of the previous window by its overlap, so a client can't double
+// its rate at the edge of two windows, Burst is ignored
+// State.Time is the start of the current window, State.Value its
+// count and State.Prev the count of the previous window
+type SlidingWindow struct{}
+
+func (SlidingWindow) Take(s State, l Limit, now time.Time) (State, Result) {
+	w := int64(l.Period)
+	n := now.UnixNano()
+	start := n - n%w
+
+	switch {
+	case s.Time == start:
+	case s.Time == start-w:
+		s = State{Time: start, Prev: s.Value}
+	default:
+		s = State{Time: start}
+	}
+
+	elapsed := n - start
+	limit := float64(l.Requests)
+	estimate := s.Prev*(1-float64(elapsed)/float64(w)) + s.Value
+
+	res := Result{Limit: l.Requests, Reset: time.Duration(w - elapsed)}
+
+	if estimate+1 <= limit {
+		s.Value++
+		res.Allowed = true
+		res.Remaining = int(limit - estimate - 1)
+	} else {
+		res.RetryAfter = retryAfter(s, limit, elapsed, w)
+	}
+
+	s.ExpiresAt = start + 2*w
+
+	return s, res
+}
+
+// retryAfter returns the time until the estimate of the sliding
+// window allows a request, the weight of the previous count
+// decreases linearly over the window
+func retryAfter(s State, limit float64, elapsed, w int64) time.Duration {
+	// the current window allows it when enough of the previous passes
+	if s.Value+1 <= limit && s.Prev > 0 {
+		t := float64(w)*(1-(limit-s.Value-1)/s.Prev) - float64(elapsed)
+		return time.Duration(math.Ceil(math.Max(t, 1)))
+	}
+
+	// in the next window the current count is the previous one
+	t := float64(w - elapsed)
+	if s.Value > 0 {
+		t += math.Max(0, float64(w)*(1-(limit-1)/s.Value))
+	}
+
+	return time.Duration(math.Ceil(t))
+}
+
+// Store persists the states of the keys
+type Store interface {
+	// Update replaces the state of the key with the state fn returns
+	// atomically, a missing or expired state is the zero State
+	Update(ctx context.Context, key string, now time.Time, fn func(State) State) error
+
+	// DeleteExpired removes the expired states
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Option configures a Limiter
+type Option func(*Limiter)
+
+// WithTimeFunc sets the clock of the limiter
+func WithTimeFunc(now func() time.Time) Option {
+	return func(l *Limiter) {
+		l.now = now
+	}
+}
+
+// Limiter limits the requests of the keys to the limit
+// the keys of a limiter are prefixed with its name so
+// that the limiters can share a store
+type Limiter struct {
+	name      string
+	store     Store
+	algorithm Algorithm
+	limit     Limit
+	now       func() time.Time
+}
+
+// New initializes a Limiter
+func New(name string, store Store, a Algorithm, l Limit, opts ...Option) *Limiter {
+	lim := &Limiter{name: name, store: store, algorithm: a, limit: l, now: time.Now}
+
+	for _, opt := range opts {
+		opt(lim)
+	}
+
+	return lim
+}
+
+// Name returns the name of the limiter
+func (l *Limiter) Name() string {
+	return l.name
+}
+
+// Limit returns the limit of the limiter
+func (l *Limiter) Limit() Limit {
+	return l.limit
+}
+
+// Store returns the store of the states
+func (l *Limiter) Store() Store {
+	return l.store
+}
+
+// Allow takes a request of the key
+func (l *Limiter) Allow(ctx context.Context, key string) (Result, error) {
+	var res Result
+
+	now := l.now()
+
+	err := l.store.Update(ctx, l.name+":"+key, now, func(s State) State {
+		s, res = l.algorithm.Take(s, l.limit, now)
+		return s
+	})
+
+	return res, err
+}
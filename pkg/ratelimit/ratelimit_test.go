@@ -0,0 +1,200 @@
+package ratelimit_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+)
+
+// clock is a settable time of the limiters
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ratelimit.Limit
+		wantErr bool
+	}{
+		{in: "100/1m", want: ratelimit.Limit{Requests: 100, Period: time.Minute}},
+		{in: " 5/1h ", want: ratelimit.Limit{Requests: 5, Period: time.Hour}},
+		{in: "100", wantErr: true},
+		{in: "0/1m", wantErr: true},
+		{in: "10/0s", wantErr: true},
+		{in: "ten/1m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ratelimit.ParseLimit(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLimit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// step is a request after the wait
+type step struct {
+	wait       time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+func TestAlgorithms(t *testing.T) {
+	// aligned to the windows of a minute
+	start := time.Unix(1_700_000_040, 0)
+
+	tests := []struct {
+		name      string
+		algorithm ratelimit.Algorithm
+		limit     ratelimit.Limit
+		steps     []step
+	}{
+		{
+			name:      "token bucket",
+			algorithm: ratelimit.TokenBucket{},
+			limit:     ratelimit.Limit{Requests: 2, Period: time.Second},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{retryAfter: 500 * time.Millisecond},
+				// a token is refilled every 500ms
+				{wait: 500 * time.Millisecond, allowed: true, remaining: 0},
+				{wait: 2 * time.Second, allowed: true, remaining: 1},
+			},
+		},
+		{
+			name:      "token bucket burst",
+			algorithm: ratelimit.TokenBucket{},
+			limit:     ratelimit.Limit{Requests: 1, Period: time.Second, Burst: 3},
+			steps: []step{
+				{allowed: true, remaining: 2},
+				{allowed: true, remaining: 1},
+				{allowed: true, remaining: 0},
+				{retryAfter: time.Second},
+			},
+		},
+		{
+			name:      "sliding window",
+			algorithm: ratelimit.SlidingWindow{},
+			limit:     ratelimit.Limit{Requests: 2, Period: time.Minute},
+			steps: []step{
+				{allowed: true, remaining: 1},
+				{wait: 30 * time.Second, allowed: true, remaining: 0},
+				// the next window starts in 30s, then the
+				// weight of the 2 requests must drop under 1/2
+				{wait: 15 * time.Second, retryAfter: 45 * time.Second},
+				// 2 * 3/4 of the previous window
+				{wait: 30 * time.Second, retryAfter: 15 * time.Second},
+				{wait: 15 * time.Second, allowed: true, remaining: 0},
+				// the previous window has no requests after 2 periods
+				{wait: 2 * time.Minute, allowed: true, remaining: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clock{now: start}
+			l := ratelimit.New("test", ratelimit.NewMemoryStore(), tt.algorithm, tt.limit, ratelimit.WithTimeFunc(c.Now))
+
+			for i, s := range tt.steps {
+				c.Add(s.wait)
+
+				res, err := l.Allow(context.Background(), "k")
+				if err != nil {
+					t.Fatalf("step %d: Allow() error = %v", i, err)
+				}
+
+				if res.Allowed != s.allowed || res.Remaining != s.remaining || res.RetryAfter != s.retryAfter {
+					t.Errorf("step %d: Allow() = %+v, want allowed %v, remaining %d, retry after %v", i, res, s.allowed, s.remaining, s.retryAfter)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	s := ratelimit.NewMemoryStore()
+	l := ratelimit.New("test", s, ratelimit.TokenBucket{}, ratelimit.Limit{Requests: 50, Period: time.Hour})
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			allowed int
+		)
+
+		for range 100 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				res, _ := l.Allow(ctx, "k")
+				if res.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		if allowed != 50 {
+			t.Errorf("allowed = %d, want 50", allowed)
+		}
+	})
+
+	t.Run("keys are separate", func(t *testing.T) {
+		for i := range 10 {
+			res, _ := l.Allow(ctx, fmt.Sprintf("k%d", i))
+			if !res.Allowed {
+				t.Errorf("Allow() of key k%d denied", i)
+			}
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		short := ratelimit.New("short", s, ratelimit.TokenBucket{}, ratelimit.Limit{Requests: 1000, Period: time.Millisecond})
+		short.Allow(ctx, "k")
+
+		time.Sleep(5 * time.Millisecond)
+
+		n, err := s.DeleteExpired(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != 1 {
+			t.Errorf("DeleteExpired() = %d, want 1", n)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+// package rbac authorizes the principals with the permissions
+// granted to their roles, a grant can be limited to the
+// resources owned by the principal
+package rbac
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+)
+
+var ErrForbidden = errors.New(constant.Forbidden)
+
+// Grant is a permission granted to a role, Own limits it
+// to the resources owned by the subject of the principal
+type Grant struct {
+	Permission string
+	Own        bool
+}
+
+// Authorizer decides if the caller of a context has a permission
+type Authorizer interface {
+	// Authorize returns nil if the principal of ctx has the permission
+	// on the resource owned by owner, owner is empty for the collections
+	// and the resources without owner, an anonymous ctx fails with a 401
+	// and a denied one with a 403 errorext.CustomError
+	Authorize(ctx context.Context, permission, owner string) error
+}
+
+// Policy evaluates the grants of the roles, it is safe
+// for the concurrent use and can be loaded again
+type Policy struct {
+	mu     sync.RWMutex
+	grants map[string][]Grant
+}
+
+// NewPolicy initializes a Policy of the grants keyed by the role
+func NewPolicy(grants map[string][]Grant) *Policy {
+	return &Policy{grants: grants}
+}
+
+// Load replaces the grants of the roles
+func (p *Policy) Load(grants map[string][]Grant) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.grants = grants
+}
+
+// Allowed reports if a role of the principal grants the permission,
+// an own grant only applies when the principal is the owner, a
+// principal of an api key is limited to the scopes of the key
+func (p *Policy) Allowed(principal auth.Principal, permission, owner string) bool {
+	if principal.Method == auth.MethodAPIKey && !principal.HasScope(permission) {
+		return false
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for _, role := range principal.Roles {
+		for _, g := range p.grants[role] {
+			if g.Permission != permission {
+				continue
+			}
+
+			if !g.Own || (owner != "" && owner == principal.Subject) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (p *Policy) Authorize(ctx context.Context, permission, owner string) error {
+	principal, err := auth.RequirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !p.Allowed(principal, permission, owner) {
+		return errorext.NewCustomError(http.StatusForbidden, ErrForbidden)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package rbac_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/rbac"
+)
+
+func TestPolicy(t *testing.T) {
+	p := rbac.NewPolicy(map[string][]rbac.Grant{
+		"admin":    {{Permission: "users:read"}, {Permission: "users:delete"}},
+		"customer": {{Permission: "users:read", Own: true}},
+	})
+
+	admin := auth.Principal{Subject: "user-1", Roles: []string{"admin"}}
+	customer := auth.Principal{Subject: "user-2", Roles: []string{"customer"}}
+	both := auth.Principal{Subject: "user-3", Roles: []string{"customer", "admin"}}
+	key := auth.Principal{Subject: "user-1", Method: auth.MethodAPIKey, Roles: []string{"admin"}, Scopes: []string{"users:read"}}
+
+	tests := []struct {
+		name       string
+		principal  auth.Principal
+		permission string
+		owner      string
+		allowed    bool
+	}{
+		{name: "granted", principal: admin, permission: "users:read", owner: "user-2", allowed: true},
+		{name: "collection", principal: admin, permission: "users:read", allowed: true},
+		{name: "not granted", principal: customer, permission: "users:delete", owner: "user-2"},
+		{name: "own resource", principal: customer, permission: "users:read", owner: "user-2", allowed: true},
+		{name: "other resource", principal: customer, permission: "users:read", owner: "user-1"},
+		{name: "own grant on a collection", principal: customer, permission: "users:read"},
+		{name: "any role", principal: both, permission: "users:delete", owner: "user-1", allowed: true},
+		{name: "api key scope", principal: key, permission: "users:read", allowed: true},
+		{name: "api key out of scope", principal: key, permission: "users:delete"},
+		{name: "unknown role", principal: auth.Principal{Subject: "user-4", Roles: []string{"guest"}}, permission: "users:read", owner: "user-4"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := p.Allowed(tc.principal, tc.permission, tc.owner); got != tc.allowed {
+				t.Errorf("expected %v, got %v", tc.allowed, got)
+			}
+		})
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		p := rbac.NewPolicy(nil)
+		if p.Allowed(admin, "users:read", "") {
+			t.Fatal("expected no grants")
+		}
+
+		p.Load(map[string][]rbac.Grant{"admin": {{Permission: "users:read"}}})
+		if !p.Allowed(admin, "users:read", "") {
+			t.Error("expected the loaded grant")
+		}
+	})
+}
+
+func TestAuthorize(t *testing.T) {
+	p := rbac.NewPolicy(map[string][]rbac.Grant{"admin": {{Permission: "users:read"}}})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code int
+	}{
+		{name: "allowed", ctx: auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1", Roles: []string{"admin"}})},
+		{name: "denied", ctx: auth.WithPrincipal(context.Background(), auth.Principal{Subject: "user-1"}), code: http.StatusForbidden},
+		{name: "anonymous", ctx: context.Background(), code: http.StatusUnauthorized},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := p.Authorize(tc.ctx, "users:read", "")
+			if tc.code == 0 {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || errorext.ParseCustomError(err).Code() != tc.code {
+				t.Errorf("expected %d, got %v", tc.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package response
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// SetLastModified sets the Last-Modified header from the
+// update time of the entity, unix seconds as the entities store it
+// zero leaves the header unset
+func SetLastModified(w http.ResponseWriter, updatedAt int64) {
+	if updatedAt <= 0 {
+		return
+	}
+
+	w.Header().Set(constant.HeaderLastModified, time.Unix(updatedAt, 0).UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,44 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// ContentTypeProblem is the media type of the problem details
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is the problem details of an error response, RFC 9457
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// NewProblem builds the problem of the status, the type is
+// about:blank so the title is the status text
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// RespondProblem responds with the problem and its status
+func RespondProblem(w http.ResponseWriter, p *Problem) (int, error) {
+	res, err := json.Marshal(p)
+	if err != nil {
+		return RespondError(w, http.StatusInternalServerError, NewErrorResponse(constant.ErrorSingle, []error{err}))
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.WriteHeader(p.Status)
+
+	return w.Write(res)
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/pagination"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/typesext"
 )
 
@@ -16,10 +17,30 @@ func NewResponse[T any](payload T) *Response[T] {
 	return &Response[T]{Data: payload}
 }
 
+// ReadManyResponse is a page of a list
+// Limit is the effective limit of the pagination policy
 type ReadManyResponse[T any] struct {
-	Items []T `json:"items"`
-	Limit int `json:"limit"`
-	Page  int `json:"page"`
+	Items      []T   `json:"items"`
+	Limit      int   `json:"limit"`
+	Page       int   `json:"page"`
+	TotalItems int64 `json:"totalItems"`
+	TotalPages int   `json:"totalPages"`
+	HasNext    bool  `json:"hasNext"`
+	// TotalEstimated reports if the totals are estimated
+	TotalEstimated bool `json:"totalEstimated,omitempty"`
+}
+
+// NewReadManyResponse builds the response of the page
+func NewReadManyResponse[T any](items []T, page pagination.Page, meta pagination.Meta) *ReadManyResponse[T] {
+	return &ReadManyResponse[T]{
+		Items:          items,
+		Limit:          page.Limit,
+		Page:           page.Number,
+		TotalItems:     meta.TotalItems,
+		TotalPages:     meta.TotalPages,
+		HasNext:        meta.HasNext,
+		TotalEstimated: meta.Estimated,
+	}
 }
 
 type Error struct {
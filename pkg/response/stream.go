@@ -0,0 +1,193 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+)
+
+// StreamFormat is the encoding of a streamed collection
+type StreamFormat string
+
+const (
+	FormatNDJSON StreamFormat = "ndjson"
+	FormatCSV    StreamFormat = "csv"
+)
+
+var ErrUnsupportedFormat = errors.New(constant.UnsupportedFormat)
+
+// contentType returns the media type of the format
+func (f StreamFormat) contentType() string {
+	if f == FormatCSV {
+		return "text/csv; charset=utf-8"
+	}
+
+	return "application/x-ndjson"
+}
+
+// CSVRecord is implemented by the entities which can be streamed as CSV
+type CSVRecord interface {
+	CSVRecord() []string
+}
+
+// NegotiateStreamFormat selects the format from the format
+// query parameter or the Accept header, NDJSON is the default
+func NegotiateStreamFormat(r *http.Request) (StreamFormat, error) {
+	if f := r.URL.Query().Get(constant.ParamFormat); f != "" {
+		switch StreamFormat(strings.ToLower(f)) {
+		case FormatNDJSON:
+			return FormatNDJSON, nil
+		case FormatCSV:
+			return FormatCSV, nil
+		default:
+			return "", fmt.Errorf("%w: %s", ErrUnsupportedFormat, f)
+		}
+	}
+
+	for _, a := range strings.Split(r.Header.Get("Accept"), ",") {
+		t, _, err := mime.ParseMediaType(strings.TrimSpace(a))
+		if err != nil {
+			continue
+		}
+
+		switch t {
+		case "text/csv":
+			return FormatCSV, nil
+		case "application/x-ndjson", "application/jsonl":
+			return FormatNDJSON, nil
+		}
+	}
+
+	return FormatNDJSON, nil
+}
+
+// Stream writes the items of seq as they are read
+// csvHeader is the first row of the CSV format
+// the response is flushed every constant.StreamFlushRows items and
+// the write deadline is extended on every flush
+// an error of the first item is returned before anything is written
+// so the caller can respond with an error, later errors abort the
+// response so the client sees a truncated body instead of a complete one
+func Stream[T any](w http.ResponseWriter, format StreamFormat, filename string, csvHeader []string, seq iter.Seq2[T, error]) error {
+	next, stop := iter.Pull2(seq)
+	defer stop()
+
+	item, err, ok := next()
+	if err != nil {
+		return err
+	}
+
+	enc := newStreamEncoder(w, format)
+
+	h := w.Header()
+	h.Set("Content-Type", format.contentType())
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+"."+string(format)))
+	// the length is unknown, it must not be buffered by proxies
+	h.Set("X-Accel-Buffering", "no")
+
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	flush := func() error {
+		// not every writer supports the deadline, ex: httptest
+		_ = rc.SetWriteDeadline(time.Now().Add(constant.StreamWriteTimeout * time.Second))
+
+		if err := enc.flush(); err != nil {
+			return err
+		}
+
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+
+		return nil
+	}
+
+	if format == FormatCSV {
+		if err := enc.writeHeader(csvHeader); err != nil {
+			abort(err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		abort(err)
+	}
+
+	for n := 1; ok; n++ {
+		if err := enc.encode(item); err != nil {
+			abort(err)
+		}
+
+		if n%constant.StreamFlushRows == 0 {
+			if err := flush(); err != nil {
+				abort(err)
+			}
+		}
+
+		item, err, ok = next()
+		if err != nil {
+			abort(err)
+		}
+	}
+
+	if err := flush(); err != nil {
+		abort(err)
+	}
+
+	return nil
+}
+
+// abort stops the response without completing it
+// the status is already sent, the client sees a truncated body
+func abort(err error) {
+	log.Printf("stream aborted: %v", err)
+	panic(http.ErrAbortHandler)
+}
+
+// streamEncoder encodes the items in the format
+type streamEncoder struct {
+	encode      func(v any) error
+	writeHeader func(header []string) error
+	flush       func() error
+}
+
+// newStreamEncoder initializes the encoder of the format
+func newStreamEncoder(w io.Writer, format StreamFormat) *streamEncoder {
+	if format == FormatCSV {
+		cw := csv.NewWriter(w)
+
+		return &streamEncoder{
+			encode: func(v any) error {
+				rec, ok := v.(CSVRecord)
+				if !ok {
+					return fmt.Errorf("%w: %T is not a CSV record", ErrUnsupportedFormat, v)
+				}
+
+				return cw.Write(rec.CSVRecord())
+			},
+			writeHeader: cw.Write,
+			flush: func() error {
+				cw.Flush()
+				return cw.Error()
+			},
+		}
+	}
+
+	je := json.NewEncoder(w)
+
+	return &streamEncoder{
+		encode:      je.Encode,
+		writeHeader: func([]string) error { return nil },
+		flush:       func() error { return nil },
+	}
+}
@@ -0,0 +1,147 @@
+package response_test
+
+import (
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+type row struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func (r row) CSVRecord() []string {
+	return []string{r.ID, r.Name}
+}
+
+func rows(items []row, err error) iter.Seq2[row, error] {
+	return func(yield func(row, error) bool) {
+		for _, v := range items {
+			if !yield(v, nil) {
+				return
+			}
+		}
+
+		if err != nil {
+			yield(row{}, err)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	items := []row{{ID: "1", Name: "a"}, {ID: "2", Name: "b,c"}}
+
+	tests := []struct {
+		name        string
+		format      response.StreamFormat
+		items       []row
+		contentType string
+		expected    string
+	}{
+		{
+			name:        "ndjson",
+			format:      response.FormatNDJSON,
+			items:       items,
+			contentType: "application/x-ndjson",
+			expected:    "{\"id\":\"1\",\"name\":\"a\"}\n{\"id\":\"2\",\"name\":\"b,c\"}\n",
+		},
+		{
+			name:        "csv",
+			format:      response.FormatCSV,
+			items:       items,
+			contentType: "text/csv; charset=utf-8",
+			expected:    "id,name\n1,a\n2,\"b,c\"\n",
+		},
+		{
+			name:        "empty csv",
+			format:      response.FormatCSV,
+			contentType: "text/csv; charset=utf-8",
+			expected:    "id,name\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			err := response.Stream(w, tc.format, "rows", []string{"id", "name"}, rows(tc.items, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Header().Get("Content-Type") != tc.contentType {
+				t.Errorf("expected content type %s, got %s", tc.contentType, w.Header().Get("Content-Type"))
+			}
+
+			if w.Body.String() != tc.expected {
+				t.Errorf("expected body %q, got %q", tc.expected, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("first error is returned", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		boom := errors.New("boom")
+
+		err := response.Stream(w, response.FormatNDJSON, "rows", nil, rows(nil, boom))
+		if !errors.Is(err, boom) {
+			t.Fatalf("expected %v, got %v", boom, err)
+		}
+
+		if w.Body.Len() != 0 {
+			t.Errorf("expected nothing written, got %q", w.Body.String())
+		}
+	})
+
+	t.Run("later error aborts", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("expected http.ErrAbortHandler panic, got %v", r)
+			}
+		}()
+
+		response.Stream(httptest.NewRecorder(), response.FormatNDJSON, "rows", nil, rows(items, errors.New("boom")))
+	})
+}
+
+func TestNegotiateStreamFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		accept   string
+		expected response.StreamFormat
+		err      bool
+	}{
+		{name: "default", url: "/", expected: response.FormatNDJSON},
+		{name: "query", url: "/?format=csv", expected: response.FormatCSV},
+		{name: "query overrides accept", url: "/?format=ndjson", accept: "text/csv", expected: response.FormatNDJSON},
+		{name: "accept", url: "/", accept: "application/json, text/csv;q=0.9", expected: response.FormatCSV},
+		{name: "unsupported", url: "/?format=xml", err: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+
+			got, err := response.NegotiateStreamFormat(r)
+			if tc.err {
+				if !errors.Is(err, response.ErrUnsupportedFormat) {
+					t.Errorf("expected ErrUnsupportedFormat, got %v", err)
+				}
+				return
+			}
+
+			if got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// redactedLogFormatter formats the log entries of the requests
+// with the values of the query parameters params redacted
+type redactedLogFormatter struct {
+	middleware.LogFormatter
+	params []string
+}
+
+// newLogger returns the request logger of chi which
+// redacts the values of the query parameters params
+func newLogger(params ...string) func(http.Handler) http.Handler {
+	return middleware.RequestLogger(redactedLogFormatter{
+		LogFormatter: &middleware.DefaultLogFormatter{Logger: log.New(os.Stdout, "", log.LstdFlags)},
+		params:       params,
+	})
+}
+
+func (f redactedLogFormatter) NewLogEntry(r *http.Request) middleware.LogEntry {
+	q := r.URL.Query()
+
+	var redacted bool
+	for _, p := range f.params {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return f.LogFormatter.NewLogEntry(r)
+	}
+
+	// the entry is formatted from a copy, the request is not changed
+	u := *r.URL
+	u.RawQuery = q.Encode()
+
+	c := *r
+	c.URL = &u
+	c.RequestURI = u.RequestURI()
+
+	return f.LogFormatter.NewLogEntry(&c)
+}
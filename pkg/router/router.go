@@ -9,45 +9,154 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 )
 
 // Router struct
 type Router struct {
-	Mux *chi.Mux
+	Mux        *chi.Mux
+	versioning *Versioning
+	// timeoutExempt matches the long running requests
+	// like streams which must not be timed out
+	timeoutExempt func(*http.Request) bool
+	// authentication authenticates the requests, nil if disabled
+	authentication func(http.Handler) http.Handler
+	// ipRateLimit limits the requests by the client
+	// address before the authentication, nil if disabled
+	ipRateLimit func(http.Handler) http.Handler
+	// rateLimit limits the requests, nil if disabled
+	rateLimit func(http.Handler) http.Handler
+	// cors applies the cross origin policy
+	cors func(http.Handler) http.Handler
+	// securityHeaders sets the security headers, nil if disabled
+	securityHeaders func(http.Handler) http.Handler
 }
 
-func NewRouter() *Router {
-	r := &Router{}
+// Option is the functional option of the router
+type Option func(*Router)
+
+// WithVersioning enables the version selection of the api routes
+func WithVersioning(v *Versioning) Option {
+	return func(r *Router) {
+		r.versioning = v
+	}
+}
+
+// WithTimeoutExempt exempts the requests matched by match from the
+// request timeouts, http.TimeoutHandler buffers the whole response
+// so streaming responses can't pass through it
+func WithTimeoutExempt(match func(*http.Request) bool) Option {
+	return func(r *Router) {
+		r.timeoutExempt = match
+	}
+}
+
+// WithAuthentication authenticates the requests with the middleware
+// it runs after the cors handler so that the preflights pass, and
+// after the versioning so that it sees the versioned paths
+func WithAuthentication(mw func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.authentication = mw
+	}
+}
+
+// WithIPRateLimit limits the requests with the middleware before the
+// authentication, so that the requests with invalid credentials are
+// limited too, it must key them by the client address since they
+// have no principal yet, a nil middleware is ignored
+func WithIPRateLimit(mw func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.ipRateLimit = mw
+	}
+}
+
+// WithRateLimit limits the authenticated requests with the middleware,
+// it runs after the authentication so that it can key them by the
+// principal, the requests rejected by the authentication don't reach
+// it, see WithIPRateLimit, a nil middleware is ignored
+func WithRateLimit(mw func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.rateLimit = mw
+	}
+}
+
+// WithCORS applies the cross origin policy with the middleware
+// instead of the default one allowing all the origins
+func WithCORS(mw func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.cors = mw
+	}
+}
+
+// WithSecurityHeaders sets the security headers of all the responses
+// with the middleware, the routes can override them, see middlewarext.Secure
+func WithSecurityHeaders(mw func(http.Handler) http.Handler) Option {
+	return func(r *Router) {
+		r.securityHeaders = mw
+	}
+}
+
+func NewRouter(opts ...Option) *Router {
+	r := &Router{cors: middlewarext.CORS(middlewarext.DefaultCORSPolicy())}
 	r.Mux = chi.NewRouter()
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
 	r.registerGlobalMiddlewares()
 	r.registerNotFoundHander()
 	return r
 }
 
 func (r *Router) registerGlobalMiddlewares() {
+	// the access tokens of the streams are not logged, see
+	// middlewarext.QueryJWT
+	r.Mux.Use(newLogger(constant.ParamAccessToken))
+
+	// the headers are set first so that all the
+	// responses carry them, the errors as well
+	if r.securityHeaders != nil {
+		r.Mux.Use(r.securityHeaders)
+	}
+
 	r.Mux.Use(
-		middleware.Logger,
 		middleware.Recoverer,
 		middlewarext.JSONContentTypeMiddleWare,
 		// timeout middlewares
-		middleware.Timeout(constant.RequestTimeout*time.Second),
-		middlewarext.TimeoutHandler(constant.RequestTimeout*time.Second),
-		cors.Handler(cors.Options{
-			// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
-			AllowedOrigins: []string{"*"},
-			// AllowedOrigins: []string{os.Getenv("ALLOWED_ORIGIN")},
-			// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-			AllowedMethods: constant.AllowedMethods,
-			// AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-			AllowedHeaders: constant.AllowedHeaders,
-			// ExposedHeaders:   []string{"Link"},
-			AllowCredentials: constant.AllowCredentials,
-			// MaxAge:           300, // Maximum value not ignored by any of major browsers
-		}),
-		// middlewarepkg.CORSEnableMiddleWareChi,
-		// middlewarepkg.CORSEnableMiddleWare,
+		middlewarext.Unless(
+			r.timeoutExempt,
+			middleware.Timeout(constant.RequestTimeout*time.Second),
+			middlewarext.TimeoutHandler(constant.RequestTimeout*time.Second),
+		),
+		r.cors,
 	)
+
+	// the version is resolved before the authentication and the
+	// routing as the unversioned paths are rewritten, so that the
+	// public routes are matched by their versioned path
+	if r.versioning != nil {
+		r.Mux.Use(r.versioning.Middleware(r.Mux))
+	}
+
+	// the client addresses are limited before the authentication
+	// so that the invalid credentials can't be tried unlimited
+	if r.ipRateLimit != nil {
+		r.Mux.Use(r.ipRateLimit)
+	}
+
+	if r.authentication != nil {
+		r.Mux.Use(r.authentication)
+	}
+
+	if r.rateLimit != nil {
+		r.Mux.Use(r.rateLimit)
+	}
+
+}
+
+// Versioning returns the versioning of the router, nil if not enabled
+func (r *Router) Versioning() *Versioning {
+	return r.versioning
 }
 
 func (r *Router) registerNotFoundHander() {
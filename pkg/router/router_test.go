@@ -0,0 +1,129 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/auth"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/httpext/middleware"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/ratelimit"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+)
+
+func TestRateLimit(t *testing.T) {
+	// authentication accepts the requests with the token of user-1
+	authentication := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer valid" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{Subject: "user-1"})))
+		})
+	}
+
+	s := ratelimit.NewMemoryStore()
+	ip := ratelimit.New("ip", s, ratelimit.SlidingWindow{}, ratelimit.Limit{Requests: 3, Period: time.Minute})
+	principal := ratelimit.New("global", s, ratelimit.SlidingWindow{}, ratelimit.Limit{Requests: 1, Period: time.Minute})
+
+	r := router.NewRouter(
+		router.WithAuthentication(authentication),
+		router.WithIPRateLimit(middleware.RateLimit(ip, middleware.KeyByIP(nil))),
+		router.WithRateLimit(middleware.RateLimit(principal, middleware.KeyByPrincipal())),
+	)
+	r.Mux.Get("/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	send := func(addr, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/items", nil)
+		req.RemoteAddr = addr + ":1234"
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		w := httptest.NewRecorder()
+		r.Mux.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	tests := []struct {
+		name  string
+		addr  string
+		token string
+		code  int
+	}{
+		{name: "invalid credentials", addr: "203.0.113.1", token: "invalid", code: http.StatusUnauthorized},
+		{name: "principal", addr: "203.0.113.1", token: "valid", code: http.StatusOK},
+		{name: "principal over its limit", addr: "203.0.113.2", token: "valid", code: http.StatusTooManyRequests},
+		{name: "third request of the address", addr: "203.0.113.1", token: "invalid", code: http.StatusUnauthorized},
+		{name: "invalid credentials over the address limit", addr: "203.0.113.1", token: "invalid", code: http.StatusTooManyRequests},
+		{name: "other address", addr: "203.0.113.3", token: "invalid", code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := send(tt.addr, tt.token); got != tt.code {
+				t.Errorf("code = %d, want %d", got, tt.code)
+			}
+		})
+	}
+}
+
+// TestVersionedAuthentication checks the authentication sees the
+// versioned path of the unversioned requests, the public routes
+// are listed by their versioned path
+func TestVersionedAuthentication(t *testing.T) {
+	v, err := router.NewVersioning(constant.ApiPattern, constant.V1, router.Version{Name: constant.V1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	login := constant.ApiPattern + constant.V1 + constant.AuthPattern + constant.LoginPattern
+
+	// authentication only serves the login without a token
+	authentication := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != login {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	r := router.NewRouter(router.WithVersioning(v), router.WithAuthentication(authentication))
+	r.Mux.Post(login, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Mux.Post(constant.ApiPattern+constant.V1+"/items", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name string
+		path string
+		code int
+	}{
+		{name: "versioned public route", path: login, code: http.StatusOK},
+		{name: "unversioned public route", path: constant.ApiPattern + constant.AuthPattern + constant.LoginPattern, code: http.StatusOK},
+		{name: "unversioned private route", path: constant.ApiPattern + "/items", code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, nil))
+
+			if w.Code != tt.code {
+				t.Errorf("code = %d, want %d", w.Code, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package router
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+)
+
+// Version describes a version of the api
+type Version struct {
+	// Name is the path prefix of the version, ex: constant.V1
+	Name string
+
+	// Deprecation is the time the version was deprecated
+	// zero value means the version is not deprecated
+	Deprecation time.Time
+
+	// Sunset is the time after which the version is removed
+	Sunset time.Time
+
+	// Link points to the migration guide or the docs of the successor
+	Link string
+}
+
+// Deprecated reports if the version is deprecated
+func (v Version) Deprecated() bool {
+	return !v.Deprecation.IsZero()
+}
+
+// VersionUsage is the usage report of a version
+type VersionUsage struct {
+	Version     string     `json:"version"`
+	Deprecated  bool       `json:"deprecated"`
+	Deprecation *time.Time `json:"deprecation,omitempty"`
+	Sunset      *time.Time `json:"sunset,omitempty"`
+	Link        string     `json:"link,omitempty"`
+	Requests    int64      `json:"requests"`
+}
+
+type versionState struct {
+	Version
+	requests atomic.Int64
+}
+
+// Versioning selects the version of the api routes
+// a request carries the version in the path, ex: /api/v1/products
+// or in the Accept-Version header for unversioned paths, ex: /api/products
+// the path takes precedence over the header, an unversioned path without
+// the header is served by the current version if it has the route, else
+// by the latest version which has it, ex: /api/users of a v1 only resource
+// responses of deprecated versions carry the
+// Deprecation, Sunset and Link headers
+type Versioning struct {
+	// pattern is the pattern the versions are mounted under
+	pattern string
+	// current is the preferred version of the unversioned
+	// requests which don't send the Accept-Version header
+	current  *versionState
+	versions []*versionState
+	byName   map[string]*versionState
+}
+
+// NewVersioning initializes a Versioning
+// current must be one of the versions
+func NewVersioning(pattern, current string, versions ...Version) (*Versioning, error) {
+	v := &Versioning{
+		pattern: pattern,
+		byName:  make(map[string]*versionState, len(versions)),
+	}
+
+	for _, ver := range versions {
+		s := &versionState{Version: ver}
+		v.versions = append(v.versions, s)
+		v.byName[ver.Name] = s
+	}
+
+	c, ok := v.byName[current]
+	if !ok {
+		return nil, fmt.Errorf("current version %s is not registered", current)
+	}
+
+	v.current = c
+
+	return v, nil
+}
+
+// Versions returns the registered versions
+func (v *Versioning) Versions() []Version {
+	versions := make([]Version, len(v.versions))
+	for i, s := range v.versions {
+		versions[i] = s.Version
+	}
+
+	return versions
+}
+
+// Usage returns the number of requests served by each version
+func (v *Versioning) Usage() []VersionUsage {
+	usage := make([]VersionUsage, len(v.versions))
+	for i, s := range v.versions {
+		u := VersionUsage{
+			Version:    strings.TrimPrefix(s.Name, "/"),
+			Deprecated: s.Deprecated(),
+			Link:       s.Link,
+			Requests:   s.requests.Load(),
+		}
+
+		if s.Deprecated() {
+			u.Deprecation = &s.Deprecation
+		}
+
+		if !s.Sunset.IsZero() {
+			u.Sunset = &s.Sunset
+		}
+
+		usage[i] = u
+	}
+
+	return usage
+}
+
+// UsageHandler serves the usage report of the versions
+func (v *Versioning) UsageHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := response.Respond(w, http.StatusOK, response.NewResponse(v.Usage()))
+	if err != nil {
+		log.Printf("response.Respond returned error: %v", err)
+	}
+}
+
+// Middleware resolves the version of the request, unversioned
+// requests are rewritten to the selected version unless
+// routes has a route for the unversioned path
+func (v *Versioning) Middleware(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rest, ok := strings.CutPrefix(r.URL.Path, v.pattern)
+			if !ok || (rest != "" && rest[0] != '/') {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// version in the path
+			if s, ok := v.byName[firstSegment(rest)]; ok {
+				v.track(w, s)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// unversioned routes like the docs are served as is
+			if routes.Match(chi.NewRouteContext(), r.Method, r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Add(constant.HeaderVary, constant.HeaderAcceptVersion)
+
+			s := v.latest(routes, r.Method, rest)
+			if h := r.Header.Get(constant.HeaderAcceptVersion); h != "" {
+				s, ok = v.lookup(h)
+				if !ok {
+					response.RespondError(w, http.StatusBadRequest, response.NewErrorResponse(constant.ErrorSingle, []error{fmt.Errorf("%s: %s", constant.UnsupportedVersion, h)}))
+					return
+				}
+			}
+
+			r.URL.Path = v.pattern + s.Name + rest
+			r.URL.RawPath = ""
+
+			v.track(w, s)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// latest returns the version serving the unversioned path, the current
+// version if it has a route for the path, else the latest registered
+// version which has one, the current version if none of them has one
+func (v *Versioning) latest(routes chi.Routes, method, rest string) *versionState {
+	if routes.Match(chi.NewRouteContext(), method, v.pattern+v.current.Name+rest) {
+		return v.current
+	}
+
+	for i := len(v.versions) - 1; i >= 0; i-- {
+		s := v.versions[i]
+		if s != v.current && routes.Match(chi.NewRouteContext(), method, v.pattern+s.Name+rest) {
+			return s
+		}
+	}
+
+	return v.current
+}
+
+// lookup finds the version of the Accept-Version header value
+// "v1", "/v1" and "1" are accepted
+func (v *Versioning) lookup(value string) (*versionState, bool) {
+	name := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), "/")
+	if !strings.HasPrefix(name, "v") {
+		name = "v" + name
+	}
+
+	s, ok := v.byName["/"+name]
+	return s, ok
+}
+
+// track counts the request and sets the version headers
+func (v *Versioning) track(w http.ResponseWriter, s *versionState) {
+	s.requests.Add(1)
+
+	h := w.Header()
+	h.Set(constant.HeaderApiVersion, strings.TrimPrefix(s.Name, "/"))
+
+	if !s.Deprecated() {
+		return
+	}
+
+	// RFC 9745
+	h.Set(constant.HeaderDeprecation, fmt.Sprintf("@%d", s.Deprecation.Unix()))
+
+	// RFC 8594
+	if !s.Sunset.IsZero() {
+		h.Set(constant.HeaderSunset, s.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if s.Link != "" {
+		h.Add(constant.HeaderLink, fmt.Sprintf("<%s>; rel=\"deprecation\"; type=\"text/html\"", s.Link))
+	}
+}
+
+// firstSegment returns the first segment of the path with the leading slash
+func firstSegment(path string) string {
+	if i := strings.IndexByte(path[min(1, len(path)):], '/'); i >= 0 {
+		return path[:i+1]
+	}
+
+	return path
+}
@@ -0,0 +1,160 @@
+package router_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/response"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/router"
+)
+
+var (
+	deprecation = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset      = time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// newVersionedRouter mounts a route which writes its version under
+// both versions, a route only under v1 and one unversioned route
+func newVersionedRouter(t *testing.T) *router.Router {
+	v, err := router.NewVersioning(
+		constant.ApiPattern,
+		constant.V2,
+		router.Version{Name: constant.V1, Deprecation: deprecation, Sunset: sunset, Link: "/api/docs"},
+		router.Version{Name: constant.V2},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := router.NewRouter(router.WithVersioning(v))
+
+	r.Mux.Route(constant.ApiPattern, func(api chi.Router) {
+		for _, name := range []string{constant.V1, constant.V2} {
+			api.Route(name, func(vr chi.Router) {
+				vr.Get("/items", func(w http.ResponseWriter, r *http.Request) {
+					w.Write([]byte(name))
+				})
+
+				if name == constant.V1 {
+					vr.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+						w.Write([]byte(name))
+					})
+				}
+			})
+		}
+
+		api.Get(constant.VersionsPattern, v.UsageHandler)
+	})
+
+	return r
+}
+
+func TestVersioning(t *testing.T) {
+	r := newVersionedRouter(t)
+
+	tests := []struct {
+		name          string
+		path          string
+		acceptVersion string
+		status        int
+		body          string
+		deprecated    bool
+	}{
+		{name: "path v1", path: "/api/v1/items", status: http.StatusOK, body: "/v1", deprecated: true},
+		{name: "path v2", path: "/api/v2/items", status: http.StatusOK, body: "/v2"},
+		{name: "path wins over header", path: "/api/v2/items", acceptVersion: "v1", status: http.StatusOK, body: "/v2"},
+		{name: "header v1", path: "/api/items", acceptVersion: "v1", status: http.StatusOK, body: "/v1", deprecated: true},
+		{name: "header without prefix", path: "/api/items", acceptVersion: "1", status: http.StatusOK, body: "/v1", deprecated: true},
+		{name: "current version", path: "/api/items", status: http.StatusOK, body: "/v2"},
+		{name: "unsupported version", path: "/api/items", acceptVersion: "v9", status: http.StatusBadRequest},
+		{name: "latest version of the resource", path: "/api/users", status: http.StatusOK, body: "/v1", deprecated: true},
+		{name: "header of a missing version", path: "/api/users", acceptVersion: "v2", status: http.StatusNotFound},
+		{name: "unknown resource", path: "/api/orders", status: http.StatusNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.acceptVersion != "" {
+				req.Header.Set(constant.HeaderAcceptVersion, tc.acceptVersion)
+			}
+
+			w := httptest.NewRecorder()
+			r.Mux.ServeHTTP(w, req)
+
+			if w.Code != tc.status {
+				t.Fatalf("expected status %d, got %d", tc.status, w.Code)
+			}
+
+			if tc.body != "" && w.Body.String() != tc.body {
+				t.Errorf("expected body %s, got %s", tc.body, w.Body.String())
+			}
+
+			if got := w.Header().Get(constant.HeaderDeprecation) != ""; got != tc.deprecated {
+				t.Errorf("expected deprecated %v, got %v", tc.deprecated, got)
+			}
+		})
+	}
+}
+
+func TestVersioningDeprecationHeaders(t *testing.T) {
+	r := newVersionedRouter(t)
+
+	w := httptest.NewRecorder()
+	r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/items", nil))
+
+	expected := map[string]string{
+		constant.HeaderApiVersion:  "v1",
+		constant.HeaderDeprecation: "@1767225600",
+		constant.HeaderSunset:      "Fri, 01 Jan 2027 00:00:00 GMT",
+		constant.HeaderLink:        `</api/docs>; rel="deprecation"; type="text/html"`,
+	}
+
+	for k, v := range expected {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("expected %s header %q, got %q", k, v, got)
+		}
+	}
+}
+
+func TestVersioningUsage(t *testing.T) {
+	r := newVersionedRouter(t)
+
+	for _, path := range []string{"/api/v1/items", "/api/v1/items", "/api/items"} {
+		r.Mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// the unversioned route is served as is and not counted
+	w := httptest.NewRecorder()
+	r.Mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/versions", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var res response.Response[[]router.VersionUsage]
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := make(map[string]int64)
+	for _, u := range res.Data {
+		requests[u.Version] = u.Requests
+	}
+
+	if requests["v1"] != 2 || requests["v2"] != 1 {
+		t.Errorf("expected v1: 2, v2: 1 requests, got %v", requests)
+	}
+}
+
+func TestNewVersioning(t *testing.T) {
+	_, err := router.NewVersioning(constant.ApiPattern, constant.V2, router.Version{Name: constant.V1})
+	if err == nil {
+		t.Error("expected error for unregistered current version")
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCServer runs a grpc server alongside the http server
+// it serves the health service and the server reflection
+type GRPCServer struct {
+	address    string
+	grpcServer *grpc.Server
+	health     *health.Server
+	// shutdownTimeout is the wait for the running calls
+	// on shutdown before they are cancelled
+	shutdownTimeout time.Duration
+}
+
+// NewGRPCServer initializes the grpc server, the services are
+// registered on the Registrar before Start
+func NewGRPCServer(address string, shutdownTimeout time.Duration, opts ...grpc.ServerOption) *GRPCServer {
+	s := &GRPCServer{
+		address:         address,
+		grpcServer:      grpc.NewServer(opts...),
+		health:          health.NewServer(),
+		shutdownTimeout: shutdownTimeout,
+	}
+
+	healthpb.RegisterHealthServer(s.grpcServer, s.health)
+	reflection.Register(s.grpcServer)
+
+	return s
+}
+
+// Registrar returns the registrar of the services
+func (s *GRPCServer) Registrar() grpc.ServiceRegistrar {
+	return s.grpcServer
+}
+
+// Start marks the registered services as serving
+// and serves until Shutdown
+func (s *GRPCServer) Start() {
+	for name := range s.grpcServer.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	lis, err := net.Listen("tcp", s.address)
+	if err != nil {
+		log.Fatalf("gRPC server listen: %v", err)
+	}
+
+	log.Println("grpc server starting")
+
+	if err := s.grpcServer.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		log.Fatalf("gRPC server Serve: %v", err)
+	}
+}
+
+// Shutdown reports the services as not serving, stops accepting
+// calls and waits for the running ones up to the shutdown timeout
+func (s *GRPCServer) Shutdown() {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.shutdownTimeout):
+		log.Printf("gRPC server shutdown timed out, stopping")
+		s.grpcServer.Stop()
+	}
+
+	log.Println("grpc server shutdown")
+}
@@ -2,11 +2,15 @@ package server
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 )
 
 type Option func(*Server)
@@ -32,12 +36,23 @@ func WithWriteTimeout(t time.Duration) Option {
 	}
 }
 
+// WithOnShutdown registers f to run when the shutdown starts
+// the long lived streams must be ended by it as the shutdown
+// waits for the active connections
+func WithOnShutdown(f func()) Option {
+	return func(srv *Server) {
+		srv.httpServer.RegisterOnShutdown(f)
+	}
+}
+
 type Server struct {
 	httpServer *http.Server
 	// empty struct consumes zero memory
 	// this channel is used to wait for idle connections to be closed
 	// before shutting down the server
 	idleConnsClosed chan struct{}
+	// tls contains the files of the tls, nil serves http
+	tls *tlsFiles
 }
 
 // NewServer initializes the server
@@ -98,10 +113,23 @@ func (s *Server) ConfigureGracefulShutdown(defferedFunc func()) {
 func (s *Server) Start() {
 	log.Println("server starting")
 
+	addr := s.httpServer.Addr
+	if addr == "" {
+		addr = ":http"
+		if s.tls != nil {
+			addr = ":https"
+		}
+	}
+
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("HTTP server listen: %v", err)
+	}
+
 	// if err == http.ErrServerClosed do nothing
-	if err := s.httpServer.ListenAndServe(); err != http.ErrServerClosed {
+	if err := s.Serve(l); err != http.ErrServerClosed {
 		// Error starting or closing listener:
-		log.Fatalf("HTTP server ListenAndServe: %v", err)
+		log.Fatalf("HTTP server Serve: %v", err)
 	}
 
 	// wait for idle connections to be closed
@@ -110,6 +138,32 @@ func (s *Server) Start() {
 	log.Println("server shutdown")
 }
 
+// Serve serves the connections of l until the shutdown, over tls
+// if configured, the certificate is reloaded while it serves
+func (s *Server) Serve(l net.Listener) error {
+	if s.tls == nil {
+		return s.httpServer.Serve(l)
+	}
+
+	if s.tls.certFile == "" || s.tls.keyFile == "" {
+		return errors.New("the client CAs need WithTLS")
+	}
+
+	reloader, err := NewCertReloader(s.tls.certFile, s.tls.keyFile, s.tls.clientCAFile)
+	if err != nil {
+		return err
+	}
+
+	s.httpServer.TLSConfig = reloader.TLSConfig(DefaultTLSConfig(), s.tls.clientAuth)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go reloader.Watch(ctx, constant.TLSReloadInterval*time.Second)
+
+	return s.httpServer.ServeTLS(l, "", "")
+}
+
 // HTTPServer returns the http server
 func (s *Server) HTTPServer() *http.Server {
 	return s.httpServer
@@ -0,0 +1,248 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// tlsFiles contains the files of the tls of the server
+type tlsFiles struct {
+	certFile string
+	keyFile  string
+	// clientCAFile contains the CAs of the client certificates
+	// empty if the clients are not authenticated with them
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+}
+
+// WithTLS serves https with the certificate and the key files, the
+// files are loaded again when they change or on SIGHUP, see CertReloader
+func WithTLS(certFile, keyFile string) Option {
+	return func(srv *Server) {
+		if srv.tls == nil {
+			srv.tls = &tlsFiles{}
+		}
+
+		srv.tls.certFile = certFile
+		srv.tls.keyFile = keyFile
+	}
+}
+
+// WithClientCA verifies the client certificates with the CAs of the
+// PEM file, mutual tls, clientAuth is tls.RequireAndVerifyClientCert
+// to reject the clients without a certificate or
+// tls.VerifyClientCertIfGiven to let them authenticate otherwise,
+// it needs WithTLS
+func WithClientCA(caFile string, clientAuth tls.ClientAuthType) Option {
+	return func(srv *Server) {
+		if srv.tls == nil {
+			srv.tls = &tlsFiles{}
+		}
+
+		srv.tls.clientCAFile = caFile
+		srv.tls.clientAuth = clientAuth
+	}
+}
+
+// DefaultTLSConfig returns the tls config of the server, TLS 1.2
+// with the forward secret AEAD cipher suites or TLS 1.3
+func DefaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// the suites of TLS 1.3 are not configurable
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256, tls.CurveP384},
+		// the config returned for the clients must list the protocols
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// certState is a loaded certificate and its client CAs
+type certState struct {
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	// modTimes contains the modification times of the files
+	// to detect the changes
+	modTimes []time.Time
+}
+
+// CertReloader serves the certificate of the files and loads it again
+// when they change, the new handshakes use the new certificate while
+// the open connections are kept, a failed reload keeps the previous one
+type CertReloader struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	// mu serializes the reloads
+	mu    sync.Mutex
+	state atomic.Pointer[certState]
+}
+
+// NewCertReloader loads the certificate of the files, clientCAFile
+// is optional, it contains the CAs of the client certificates
+func NewCertReloader(certFile, keyFile, clientCAFile string) (*CertReloader, error) {
+	c := &CertReloader{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+
+	if err := c.Reload(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// files returns the files of the reloader
+func (c *CertReloader) files() []string {
+	files := []string{c.certFile, c.keyFile}
+	if c.clientCAFile != "" {
+		files = append(files, c.clientCAFile)
+	}
+
+	return files
+}
+
+// modTimes returns the modification times of the files
+func (c *CertReloader) modTimes() ([]time.Time, error) {
+	files := c.files()
+	times := make([]time.Time, len(files))
+
+	for i, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return nil, err
+		}
+
+		times[i] = info.ModTime()
+	}
+
+	return times, nil
+}
+
+// Reload loads the files
+func (c *CertReloader) Reload() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.reload()
+}
+
+func (c *CertReloader) reload() error {
+	times, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load certificate: %w", err)
+	}
+
+	s := &certState{cert: &cert, modTimes: times}
+
+	if c.clientCAFile != "" {
+		b, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CAs: %w", err)
+		}
+
+		s.clientCAs = x509.NewCertPool()
+		if !s.clientCAs.AppendCertsFromPEM(b) {
+			return errors.New("the client CA file has no certificates")
+		}
+	}
+
+	c.state.Store(s)
+
+	return nil
+}
+
+// reloadIfChanged loads the files if one of them changed since the last load
+func (c *CertReloader) reloadIfChanged() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	times, err := c.modTimes()
+	if err != nil {
+		return err
+	}
+
+	for i, t := range c.state.Load().modTimes {
+		if !t.Equal(times[i]) {
+			return c.reload()
+		}
+	}
+
+	return nil
+}
+
+// Watch reloads the files when they change, checked every interval,
+// and on SIGHUP until ctx is done
+func (c *CertReloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := c.Reload(); err != nil {
+				log.Printf("tls reload returned error: %v", err)
+				continue
+			}
+
+			log.Println("tls certificate reloaded")
+		case <-t.C:
+			if err := c.reloadIfChanged(); err != nil {
+				log.Printf("tls reload returned error: %v", err)
+			}
+		}
+	}
+}
+
+// Certificate returns the loaded certificate
+func (c *CertReloader) Certificate() *tls.Certificate {
+	return c.state.Load().cert
+}
+
+// TLSConfig returns a copy of base serving the loaded certificate and
+// verifying the client certificates with the loaded CAs per clientAuth
+func (c *CertReloader) TLSConfig(base *tls.Config, clientAuth tls.ClientAuthType) *tls.Config {
+	config := base.Clone()
+
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		s := c.state.Load()
+
+		hello := base.Clone()
+		hello.Certificates = []tls.Certificate{*s.cert}
+
+		if s.clientCAs != nil {
+			hello.ClientCAs = s.clientCAs
+			hello.ClientAuth = clientAuth
+		}
+
+		return hello, nil
+	}
+
+	return config
+}
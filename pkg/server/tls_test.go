@@ -0,0 +1,267 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/server"
+)
+
+// certificate is a generated certificate and its key
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newCertificate generates a certificate of the common name
+// signed by parent, a nil parent generates a CA
+func newCertificate(t *testing.T, cn string, serial int64, parent *certificate, usage x509.ExtKeyUsage) *certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{usage}
+		tmpl.DNSNames = []string{cn}
+		tmpl.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &certificate{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (c *certificate) keyPEM(t *testing.T) []byte {
+	t.Helper()
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func (c *certificate) tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+
+	cert, err := tls.X509KeyPair(c.pem, c.keyPEM(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert
+}
+
+// writeFiles writes the certificate and the key files to dir
+func writeFiles(t *testing.T, dir string, c *certificate) (certFile, keyFile string) {
+	t.Helper()
+
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+
+	if err := os.WriteFile(certFile, c.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(keyFile, c.keyPEM(t), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return certFile, keyFile
+}
+
+func TestServerTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newCertificate(t, "ca", 1, nil, 0)
+	certFile, keyFile := writeFiles(t, dir, newCertificate(t, "localhost", 2, ca, x509.ExtKeyUsageServerAuth))
+
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(caFile, ca.pem, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the handler answers with the subject of the client certificate
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	srv := server.NewServer("", h, server.WithTLS(certFile, keyFile), server.WithClientCA(caFile, tls.RequireAndVerifyClientCert))
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.Serve(l)
+	}()
+
+	t.Cleanup(func() {
+		srv.HTTPServer().Shutdown(context.Background())
+
+		if err := <-errs; err != http.ErrServerClosed {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	client := newCertificate(t, "billing", 3, ca, x509.ExtKeyUsageClientAuth)
+	other := newCertificate(t, "billing", 4, newCertificate(t, "other ca", 5, nil, 0), x509.ExtKeyUsageClientAuth)
+
+	tests := []struct {
+		name    string
+		config  *tls.Config
+		want    string
+		wantErr bool
+	}{
+		{name: "client certificate", config: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tlsCertificate(t)}}, want: "billing"},
+		{name: "no client certificate", config: &tls.Config{RootCAs: roots}, wantErr: true},
+		{name: "certificate of another CA", config: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{other.tlsCertificate(t)}}, wantErr: true},
+		{name: "TLS 1.1", config: &tls.Config{RootCAs: roots, Certificates: []tls.Certificate{client.tlsCertificate(t)}, MaxVersion: tls.VersionTLS11}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &http.Client{Transport: &http.Transport{TLSClientConfig: tt.config, ForceAttemptHTTP2: true}, Timeout: 5 * time.Second}
+			defer c.CloseIdleConnections()
+
+			res, err := c.Get("https://" + l.Addr().String())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			defer res.Body.Close()
+
+			b, _ := io.ReadAll(res.Body)
+			if string(b) != tt.want {
+				t.Errorf("subject = %s, want %s", b, tt.want)
+			}
+
+			if res.ProtoMajor != 2 {
+				t.Errorf("protocol = %s, want HTTP/2", res.Proto)
+			}
+		})
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+
+	ca := newCertificate(t, "ca", 1, nil, 0)
+	certFile, keyFile := writeFiles(t, dir, newCertificate(t, "localhost", 2, ca, x509.ExtKeyUsageServerAuth))
+
+	r, err := server.NewCertReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := r.TLSConfig(server.DefaultTLSConfig(), tls.NoClientCert)
+
+	// serial returns the serial of the certificate of a new handshake
+	serial := func() int64 {
+		c, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		leaf, err := x509.ParseCertificate(c.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return leaf.SerialNumber.Int64()
+	}
+
+	if got := serial(); got != 2 {
+		t.Fatalf("serial = %d, want 2", got)
+	}
+
+	t.Run("changed files", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go r.Watch(ctx, 10*time.Millisecond)
+
+		writeFiles(t, dir, newCertificate(t, "localhost", 3, ca, x509.ExtKeyUsageServerAuth))
+
+		// the modification time must differ on the coarse file systems
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(certFile, later, later)
+		os.Chtimes(keyFile, later, later)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for serial() != 3 {
+			if time.Now().After(deadline) {
+				t.Fatal("the changed certificate was not loaded")
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("invalid files", func(t *testing.T) {
+		if err := os.WriteFile(keyFile, []byte("invalid"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.Reload(); err == nil {
+			t.Fatal("Reload() of an invalid key succeeded")
+		}
+
+		if got := serial(); got != 3 {
+			t.Errorf("serial = %d, want the previous certificate", got)
+		}
+	})
+
+	if _, err := server.NewCertReloader(certFile, keyFile, ""); err == nil {
+		t.Error("NewCertReloader() of an invalid key succeeded")
+	}
+}
@@ -0,0 +1,86 @@
+// package signature signs the webhook payloads with HMAC-SHA256
+// the signature covers the timestamp so that a captured request
+// cannot be replayed after the tolerance, the header is of the
+// form "t=<unix seconds>,v1=<hex signature>"
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scheme is the version of the signature in the header
+const scheme = "v1"
+
+var (
+	ErrInvalidHeader = errors.New("the signature header is invalid")
+	ErrExpired       = errors.New("the signature timestamp is outside the tolerance")
+	ErrMismatch      = errors.New("the signature does not match the payload")
+)
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<payload>"
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Header returns the signature header of the payload
+func Header(secret string, timestamp int64, payload []byte) string {
+	return fmt.Sprintf("t=%d,%s=%s", timestamp, scheme, Sign(secret, timestamp, payload))
+}
+
+// Verify checks the signature header of the payload, the
+// timestamp must be within tolerance of now, zero disables the check
+// the header may carry several signatures while a secret is rotated
+func Verify(secret, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var (
+		timestamp  int64
+		signatures []string
+	)
+
+	for part := range strings.SplitSeq(header, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrInvalidHeader
+		}
+
+		switch k {
+		case "t":
+			t, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return ErrInvalidHeader
+			}
+
+			timestamp = t
+		case scheme:
+			signatures = append(signatures, v)
+		}
+	}
+
+	if timestamp == 0 || len(signatures) == 0 {
+		return ErrInvalidHeader
+	}
+
+	if tolerance > 0 && now.Sub(time.Unix(timestamp, 0)).Abs() > tolerance {
+		return ErrExpired
+	}
+
+	expected := Sign(secret, timestamp, payload)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+
+	return ErrMismatch
+}
@@ -0,0 +1,45 @@
+package signature_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/signature"
+)
+
+func TestVerify(t *testing.T) {
+	const secret = "whsec_test"
+
+	payload := []byte(`{"type":"product.created"}`)
+	now := time.Unix(1700000000, 0)
+	header := signature.Header(secret, now.Unix(), payload)
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  string
+		payload []byte
+		now     time.Time
+		err     error
+	}{
+		{name: "valid", secret: secret, header: header, payload: payload, now: now},
+		{name: "within tolerance", secret: secret, header: header, payload: payload, now: now.Add(4 * time.Minute)},
+		{name: "rotated secret", secret: secret, header: fmt.Sprintf("%s,v1=%s", header, signature.Sign("old", now.Unix(), payload)), payload: payload, now: now},
+		{name: "expired", secret: secret, header: header, payload: payload, now: now.Add(6 * time.Minute), err: signature.ErrExpired},
+		{name: "wrong secret", secret: "other", header: header, payload: payload, now: now, err: signature.ErrMismatch},
+		{name: "tampered payload", secret: secret, header: header, payload: []byte(`{}`), now: now, err: signature.ErrMismatch},
+		{name: "no timestamp", secret: secret, header: "v1=abc", payload: payload, now: now, err: signature.ErrInvalidHeader},
+		{name: "malformed", secret: secret, header: "garbage", payload: payload, now: now, err: signature.ErrInvalidHeader},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := signature.Verify(tc.secret, tc.header, tc.payload, tc.now, 5*time.Minute)
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package sqlext
+
+import "context"
+
+// Estimate returns the planner estimate of the rows of the table
+// from pg_class, it is cheap but ignores any filter and is
+// negative if the table was never vacuumed or analyzed
+func Estimate(ctx context.Context, q Querier, table string) (int64, error) {
+	var n int64
+
+	err := q.QueryRowContext(ctx, "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass($1)", table).Scan(&n)
+
+	return n, err
+}
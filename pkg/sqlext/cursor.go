@@ -0,0 +1,88 @@
+package sqlext
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"iter"
+	"log"
+)
+
+const cursorName = "stream_cursor"
+
+// Stream runs the query through a server side cursor and yields
+// the scanned rows, the rows are fetched in batches of size batch
+// so the result is never held in memory
+// the cursor lives in a read only transaction which is closed when
+// the iteration stops, ctx cancellation stops the iteration with its error
+// an error is yielded once and ends the iteration
+func Stream[T any](ctx context.Context, db *sql.DB, query string, batch int, scan func(*sql.Rows) (T, error), args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		// the cursor is closed with the transaction
+		defer func() {
+			if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+				log.Printf("stream rollback returned error: %v", err)
+			}
+		}()
+
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", cursorName, query), args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		fetch := fmt.Sprintf("FETCH %d FROM %s", batch, cursorName)
+
+		for {
+			n, ok := fetchBatch(ctx, tx, fetch, scan, yield)
+			if !ok || n < batch {
+				return
+			}
+		}
+	}
+}
+
+// fetchBatch yields the rows of one fetch
+// ok is false if the iteration must stop
+func fetchBatch[T any](ctx context.Context, tx *sql.Tx, fetch string, scan func(*sql.Rows) (T, error), yield func(T, error) bool) (int, bool) {
+	var zero T
+
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		yield(zero, err)
+		return 0, false
+	}
+
+	defer rows.Close()
+
+	n := 0
+
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			yield(zero, err)
+			return n, false
+		}
+
+		n++
+
+		if !yield(v, nil) {
+			return n, false
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		yield(zero, err)
+		return n, false
+	}
+
+	return n, true
+}
@@ -0,0 +1,84 @@
+package sqlext_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+func scanName(rows *sql.Rows) (string, error) {
+	var name string
+	err := rows.Scan(&name)
+	return name, err
+}
+
+func TestStream(t *testing.T) {
+	t.Run("batches", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE stream_cursor NO SCROLL CURSOR FOR SELECT name FROM products WHERE is_archived = \\$1").
+			WithArgs(false).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FETCH 2 FROM stream_cursor").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+		mock.ExpectQuery("FETCH 2 FROM stream_cursor").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("c"))
+		mock.ExpectRollback()
+
+		var got []string
+		for name, err := range sqlext.Stream(context.Background(), db, "SELECT name FROM products WHERE is_archived = $1", 2, scanName, false) {
+			assert.NoError(t, err)
+			got = append(got, name)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c"}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("stop early", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE stream_cursor").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("FETCH 2 FROM stream_cursor").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("a").AddRow("b"))
+		mock.ExpectRollback()
+
+		for range sqlext.Stream(context.Background(), db, "SELECT name FROM products", 2, scanName) {
+			break
+		}
+
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("error", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		assert.NoError(t, err)
+		defer db.Close()
+
+		boom := errors.New("boom")
+
+		mock.ExpectBegin()
+		mock.ExpectExec("DECLARE stream_cursor").WillReturnError(boom)
+		mock.ExpectRollback()
+
+		var errs []error
+		for _, err := range sqlext.Stream(context.Background(), db, "SELECT name FROM products", 2, scanName) {
+			errs = append(errs, err)
+		}
+
+		assert.Equal(t, []error{boom}, errs)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
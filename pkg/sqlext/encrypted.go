@@ -0,0 +1,214 @@
+package sqlext
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrNoEncryptor = errors.New("the encrypted column has no encryptor")
+	ErrNoRowKey    = errors.New("the encrypted column has no row key")
+)
+
+// Encryptor encrypts the values of the encrypted columns, see envelope.KeyRing
+type Encryptor interface {
+	// Encrypt encrypts the plaintext, aad is the authenticated
+	// context, ex: the column and the row, it must be the same to decrypt
+	Encrypt(plaintext, aad []byte) (string, error)
+	Decrypt(value string, aad []byte) ([]byte, error)
+	// IsEncrypted reports if the value is encrypted
+	IsEncrypted(value string) bool
+	// Current reports if the value is encrypted with the current key
+	Current(value string) bool
+}
+
+// EncryptedColumn is a column storing the encrypted values
+type EncryptedColumn struct {
+	Table string
+	// Key is the primary key column of the table
+	Key    string
+	Column string
+}
+
+// AAD returns the authenticated context of the value of the row of
+// the primary key so that it can't be copied to another column or row
+func (c EncryptedColumn) AAD(key string) []byte {
+	return []byte(c.Table + "." + c.Column + ":" + key)
+}
+
+// EncryptedString is a nullable string of an encrypted column, it is
+// encrypted by Value and decrypted by Scan, the plaintext values stored
+// before the column was encrypted are scanned as they are
+type EncryptedString struct {
+	String string
+	Valid  bool
+	enc    Encryptor
+	column EncryptedColumn
+	// key points to the primary key of the row, the key
+	// column must be scanned before the encrypted one
+	key *string
+}
+
+// NewEncryptedString returns an EncryptedString of the column of the
+// row of the key, the scan destinations must be initialized with it
+// too, their key pointing to the scan destination of the key column
+func NewEncryptedString(enc Encryptor, c EncryptedColumn, key *string, s *string) EncryptedString {
+	e := EncryptedString{enc: enc, column: c, key: key}
+	if s != nil {
+		e.String, e.Valid = *s, true
+	}
+
+	return e
+}
+
+// WithKey returns a copy of e of the row of the key
+// ex: a scan destination of another row
+func (e EncryptedString) WithKey(key *string) EncryptedString {
+	e.key = key
+	return e
+}
+
+// aad returns the authenticated context of the row of e
+func (e EncryptedString) aad() ([]byte, error) {
+	if e.key == nil || *e.key == "" {
+		return nil, ErrNoRowKey
+	}
+
+	return e.column.AAD(*e.key), nil
+}
+
+// Value implements the driver.Valuer interface
+func (e EncryptedString) Value() (driver.Value, error) {
+	if !e.Valid {
+		return nil, nil
+	}
+
+	if e.enc == nil {
+		return nil, ErrNoEncryptor
+	}
+
+	aad, err := e.aad()
+	if err != nil {
+		return nil, err
+	}
+
+	return e.enc.Encrypt([]byte(e.String), aad)
+}
+
+// Scan implements the sql.Scanner interface
+func (e *EncryptedString) Scan(val any) error {
+	var v sql.NullString
+	if err := v.Scan(val); err != nil {
+		return err
+	}
+
+	e.String, e.Valid = "", v.Valid
+	if !v.Valid {
+		return nil
+	}
+
+	if e.enc == nil {
+		return ErrNoEncryptor
+	}
+
+	if !e.enc.IsEncrypted(v.String) {
+		e.String = v.String
+		return nil
+	}
+
+	aad, err := e.aad()
+	if err != nil {
+		return err
+	}
+
+	b, err := e.enc.Decrypt(v.String, aad)
+	if err != nil {
+		return err
+	}
+
+	e.String = string(b)
+
+	return nil
+}
+
+// Reencrypt encrypts the values of the column which are not encrypted
+// with the current key of enc again, ex: after a key rotation, and
+// the plaintext values, the rows are read in batches of the key order,
+// a row changed since it was read is skipped, it returns the number
+// of the updated rows
+func Reencrypt(ctx context.Context, db *sql.DB, enc Encryptor, c EncryptedColumn, batchSize int) (int64, error) {
+	firstQ := fmt.Sprintf("SELECT %[1]s, %[2]s FROM %[3]s WHERE %[2]s IS NOT NULL ORDER BY %[1]s LIMIT $1", c.Key, c.Column, c.Table)
+	nextQ := fmt.Sprintf("SELECT %[1]s, %[2]s FROM %[3]s WHERE %[1]s > $2 AND %[2]s IS NOT NULL ORDER BY %[1]s LIMIT $1", c.Key, c.Column, c.Table)
+	updateQ := fmt.Sprintf("UPDATE %[3]s SET %[2]s = $1 WHERE %[1]s = $2 AND %[2]s = $3", c.Key, c.Column, c.Table)
+
+	var (
+		n    int64
+		last string
+	)
+
+	type row struct{ key, value string }
+
+	for {
+		q, args := firstQ, []any{batchSize}
+		if last != "" {
+			q, args = nextQ, append(args, last)
+		}
+
+		rows, err := db.QueryContext(ctx, q, args...)
+		if err != nil {
+			return n, err
+		}
+
+		batch := make([]row, 0, batchSize)
+		for rows.Next() {
+			var r row
+			if err := rows.Scan(&r.key, &r.value); err != nil {
+				rows.Close()
+				return n, err
+			}
+
+			batch = append(batch, r)
+		}
+
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return n, err
+		}
+
+		for _, r := range batch {
+			if enc.Current(r.value) {
+				continue
+			}
+
+			plaintext := []byte(r.value)
+			if enc.IsEncrypted(r.value) {
+				plaintext, err = enc.Decrypt(r.value, c.AAD(r.key))
+				if err != nil {
+					return n, fmt.Errorf("decrypt %s %s: %w", c.Key, r.key, err)
+				}
+			}
+
+			v, err := enc.Encrypt(plaintext, c.AAD(r.key))
+			if err != nil {
+				return n, err
+			}
+
+			res, err := db.ExecContext(ctx, updateQ, v, r.key, r.value)
+			if err != nil {
+				return n, err
+			}
+
+			n += GetRowsAffected(res)
+		}
+
+		if len(batch) < batchSize {
+			return n, nil
+		}
+
+		last = batch[len(batch)-1].key
+	}
+}
@@ -0,0 +1,146 @@
+package sqlext_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+var addressColumn = sqlext.EncryptedColumn{Table: "users", Key: "id", Column: "address"}
+
+func newKeyRing(t *testing.T, primary string) *envelope.KeyRing {
+	t.Helper()
+
+	keys := map[string][]byte{"old": make([]byte, constant.EncryptionKeyBytes), "new": make([]byte, constant.EncryptionKeyBytes)}
+	keys["new"][0] = 1
+
+	k, err := envelope.NewKeyRing(primary, keys)
+	assert.NoError(t, err)
+
+	return k
+}
+
+// currentValue matches the values of the row of the key
+// encrypted with the primary key
+type currentValue struct {
+	keys *envelope.KeyRing
+	key  string
+}
+
+// Match implements the sqlmock.Argument interface
+func (a currentValue) Match(v driver.Value) bool {
+	s, ok := v.(string)
+	if !ok || !a.keys.Current(s) {
+		return false
+	}
+
+	_, err := a.keys.Decrypt(s, addressColumn.AAD(a.key))
+
+	return err == nil
+}
+
+func TestEncryptedString(t *testing.T) {
+	keys := newKeyRing(t, "new")
+	addr := "221B Baker Street"
+	id, otherID := "1", "2"
+
+	t.Run("round trip", func(t *testing.T) {
+		v, err := sqlext.NewEncryptedString(keys, addressColumn, &id, &addr).Value()
+		assert.NoError(t, err)
+		assert.True(t, keys.Current(v.(string)))
+
+		got := sqlext.NewEncryptedString(keys, addressColumn, &id, nil)
+		assert.NoError(t, got.Scan(v))
+		assert.Equal(t, addr, got.String)
+		assert.True(t, got.Valid)
+	})
+
+	t.Run("null", func(t *testing.T) {
+		v, err := sqlext.NewEncryptedString(keys, addressColumn, &id, nil).Value()
+		assert.NoError(t, err)
+		assert.Nil(t, v)
+
+		got := sqlext.NewEncryptedString(keys, addressColumn, &id, nil)
+		assert.NoError(t, got.Scan(nil))
+		assert.False(t, got.Valid)
+	})
+
+	t.Run("plaintext", func(t *testing.T) {
+		got := sqlext.NewEncryptedString(keys, addressColumn, &id, nil)
+		assert.NoError(t, got.Scan(addr))
+		assert.Equal(t, addr, got.String)
+	})
+
+	t.Run("another column", func(t *testing.T) {
+		v, err := sqlext.NewEncryptedString(keys, addressColumn, &id, &addr).Value()
+		assert.NoError(t, err)
+
+		got := sqlext.NewEncryptedString(keys, sqlext.EncryptedColumn{Table: "users", Key: "id", Column: "name"}, &id, nil)
+		assert.ErrorIs(t, got.Scan(v), envelope.ErrDecrypt)
+	})
+
+	t.Run("another row", func(t *testing.T) {
+		v, err := sqlext.NewEncryptedString(keys, addressColumn, &id, &addr).Value()
+		assert.NoError(t, err)
+
+		got := sqlext.NewEncryptedString(keys, addressColumn, &id, nil).WithKey(&otherID)
+		assert.ErrorIs(t, got.Scan(v), envelope.ErrDecrypt)
+	})
+
+	t.Run("no row key", func(t *testing.T) {
+		_, err := sqlext.NewEncryptedString(keys, addressColumn, nil, &addr).Value()
+		assert.ErrorIs(t, err, sqlext.ErrNoRowKey)
+
+		v, err := sqlext.NewEncryptedString(keys, addressColumn, &id, &addr).Value()
+		assert.NoError(t, err)
+
+		var empty string
+		got := sqlext.NewEncryptedString(keys, addressColumn, &empty, nil)
+		assert.ErrorIs(t, got.Scan(v), sqlext.ErrNoRowKey)
+	})
+
+	t.Run("no encryptor", func(t *testing.T) {
+		_, err := sqlext.NewEncryptedString(nil, addressColumn, &id, &addr).Value()
+		assert.ErrorIs(t, err, sqlext.ErrNoEncryptor)
+	})
+}
+
+func TestReencrypt(t *testing.T) {
+	old, keys := newKeyRing(t, "old"), newKeyRing(t, "new")
+
+	oldValue, err := old.Encrypt([]byte("old key"), addressColumn.AAD("3"))
+	assert.NoError(t, err)
+
+	current, err := keys.Encrypt([]byte("new key"), addressColumn.AAD("2"))
+	assert.NoError(t, err)
+
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT id, address FROM users WHERE address IS NOT NULL ORDER BY id LIMIT \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address"}).AddRow("1", "plaintext").AddRow("2", current))
+	mock.ExpectExec("UPDATE users SET address = \\$1 WHERE id = \\$2 AND address = \\$3").
+		WithArgs(currentValue{keys, "1"}, "1", "plaintext").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT id, address FROM users WHERE id > \\$2 AND address IS NOT NULL ORDER BY id LIMIT \\$1").
+		WithArgs(2, "2").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "address"}).AddRow("3", oldValue))
+	// the row changed since it was read
+	mock.ExpectExec("UPDATE users SET address = \\$1 WHERE id = \\$2 AND address = \\$3").
+		WithArgs(currentValue{keys, "3"}, "3", oldValue).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	n, err := sqlext.Reencrypt(context.Background(), db, keys, addressColumn, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
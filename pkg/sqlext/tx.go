@@ -0,0 +1,117 @@
+package sqlext
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sync"
+)
+
+// Querier is implemented by *sql.DB and *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// txState is the transaction of a context and
+// the functions to run once it commits
+type txState struct {
+	tx      *sql.Tx
+	mu      sync.Mutex
+	commits []func()
+}
+
+// WithTx returns a copy of ctx carrying tx
+// the storages run their queries in tx, see Conn
+// tx must be committed by Commit to run the AfterCommit functions
+func WithTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, &txState{tx: tx})
+}
+
+// TxFromContext returns the transaction carried by ctx
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok || s.tx == nil {
+		return nil, false
+	}
+
+	return s.tx, true
+}
+
+// AfterCommit runs fn once the transaction carried by ctx commits
+// fn never runs after a rollback, it runs right away without a transaction
+func AfterCommit(ctx context.Context, fn func()) {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok || s.tx == nil {
+		fn()
+		return
+	}
+
+	s.mu.Lock()
+	s.commits = append(s.commits, fn)
+	s.mu.Unlock()
+}
+
+// Commit commits the transaction carried by ctx
+// and runs its AfterCommit functions in order
+func Commit(ctx context.Context) error {
+	s, ok := ctx.Value(txKey{}).(*txState)
+	if !ok || s.tx == nil {
+		return errors.New("sqlext: no transaction to commit")
+	}
+
+	if err := s.tx.Commit(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	commits := s.commits
+	s.commits = nil
+	s.mu.Unlock()
+
+	for _, fn := range commits {
+		fn()
+	}
+
+	return nil
+}
+
+// Conn returns the transaction carried by ctx or db
+func Conn(ctx context.Context, db *sql.DB) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+
+	return db
+}
+
+// RunInTx runs fn in a transaction of db, the transaction is
+// committed if fn returns nil and rolled back otherwise
+// fn joins the transaction carried by ctx if any
+func RunInTx(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx = WithTx(ctx, tx)
+
+	err = fn(ctx)
+	if err != nil {
+		if rerr := tx.Rollback(); rerr != nil {
+			log.Printf("rollback returned error: %v", rerr)
+		}
+
+		return err
+	}
+
+	return Commit(ctx)
+}
@@ -0,0 +1,61 @@
+package sqlext_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/tanveerprottoy/backend-structure-go/pkg/sqlext"
+)
+
+func TestAfterCommit(t *testing.T) {
+	t.Run("without a transaction", func(t *testing.T) {
+		ran := false
+		sqlext.AfterCommit(context.Background(), func() { ran = true })
+
+		assert.True(t, ran)
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectCommit()
+
+		var ran []int
+		err := sqlext.RunInTx(context.Background(), db, func(ctx context.Context) error {
+			sqlext.AfterCommit(ctx, func() { ran = append(ran, 1) })
+			sqlext.AfterCommit(ctx, func() { ran = append(ran, 2) })
+
+			assert.Empty(t, ran)
+
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		db, mock, _ := sqlmock.New()
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		ran := false
+		err := sqlext.RunInTx(context.Background(), db, func(ctx context.Context) error {
+			sqlext.AfterCommit(ctx, func() { ran = true })
+			return errors.New("failed")
+		})
+
+		assert.Error(t, err)
+		assert.False(t, ran)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -10,12 +10,206 @@ CREATE TABLE products (
     updated_at bigint NOT NULL
 );
 
+-- name is the natural key of the bulk import upsert
+CREATE UNIQUE INDEX products_name_key ON products (name);
+
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS external_identities;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS credentials;
 DROP TABLE IF EXISTS users;
 CREATE TABLE users (
     id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
     name varchar(255) NOT NULL,
-    address varchar(255) NULL,
+    address text NULL,
     is_archived boolean NOT NULL DEFAULT false,
     created_at bigint NOT NULL,
     updated_at bigint NOT NULL
 );
+
+-- the email is stored lower case, email_verified_at is 0
+-- until the email is verified
+CREATE TABLE credentials (
+    user_id uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    email varchar(320) NOT NULL UNIQUE,
+    password_hash text NOT NULL,
+    email_verified_at bigint NOT NULL DEFAULT 0,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+-- only the hashes of the refresh tokens are stored, the tokens
+-- rotated from the token of a login share its family
+CREATE TABLE refresh_tokens (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id uuid NOT NULL,
+    token_hash bytea NOT NULL UNIQUE,
+    expires_at bigint NOT NULL,
+    revoked_at bigint NULL,
+    replaced_by uuid NULL,
+    created_at bigint NOT NULL
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+-- the identities of the users at the external openid connect
+-- providers, the email is the verified email stored lower case
+CREATE TABLE external_identities (
+    provider varchar(64) NOT NULL,
+    subject varchar(255) NOT NULL,
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email varchar(320) NOT NULL,
+    created_at bigint NOT NULL,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX external_identities_email_idx ON external_identities (email);
+
+-- the failed logins per account, the lower case email, and per client
+-- address, the key is account:<email> or ip:<address>, a row is deleted
+-- by a successful login of the account, an unlock or the cleanup of the
+-- rows which are not counted anymore
+DROP TABLE IF EXISTS login_lockouts;
+CREATE TABLE login_lockouts (
+    key varchar(512) PRIMARY KEY,
+    failures integer NOT NULL,
+    last_failure_at bigint NOT NULL,
+    locked_until bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX login_lockouts_locked_idx ON login_lockouts (locked_until) WHERE locked_until > 0;
+CREATE INDEX login_lockouts_last_failure_idx ON login_lockouts (last_failure_at);
+
+-- the api keys of the machine clients, only the prefix and a salted
+-- hash of the secret are stored, scopes is a json array of permissions
+CREATE TABLE api_keys (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    prefix varchar(32) NOT NULL UNIQUE,
+    salt bytea NOT NULL,
+    key_hash bytea NOT NULL,
+    scopes jsonb NOT NULL,
+    expires_at bigint NULL,
+    last_used_at bigint NULL,
+    revoked_at bigint NULL,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE INDEX api_keys_user_idx ON api_keys (user_id);
+
+-- the roles grant their permissions to the users, an own grant
+-- only applies to the resources of the user, ex: itself
+CREATE TABLE roles (
+    name varchar(64) PRIMARY KEY,
+    description varchar(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE role_permissions (
+    role varchar(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission varchar(64) NOT NULL,
+    own boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE user_roles (
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role varchar(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    created_at bigint NOT NULL,
+    PRIMARY KEY (user_id, role)
+);
+
+-- the seeded roles, see role.Defaults
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'manages the users and the resources'),
+    ('customer', 'reads the products'),
+    ('staff', 'edits the products');
+
+INSERT INTO role_permissions (role, permission, own) VALUES
+    ('admin', 'products:delete', false),
+    ('admin', 'products:purge', false),
+    ('admin', 'products:read', false),
+    ('admin', 'products:write', false),
+    ('admin', 'users:delete', false),
+    ('admin', 'users:manage', false),
+    ('admin', 'users:purge', false),
+    ('admin', 'users:read', false),
+    ('admin', 'users:write', false),
+    ('admin', 'webhooks:manage', false),
+    ('customer', 'products:read', false),
+    ('customer', 'users:read', true),
+    ('customer', 'users:write', true),
+    ('staff', 'products:read', false),
+    ('staff', 'products:write', false),
+    ('staff', 'users:read', true),
+    ('staff', 'users:write', true);
+
+DROP TABLE IF EXISTS idempotency_keys;
+CREATE TABLE idempotency_keys (
+    key varchar(512) PRIMARY KEY,
+    fingerprint varchar(64) NOT NULL,
+    completed boolean NOT NULL DEFAULT false,
+    status int NOT NULL DEFAULT 0,
+    header jsonb NULL,
+    body bytea NULL,
+    expires_at bigint NOT NULL
+);
+
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+CREATE TABLE webhook_subscriptions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    url varchar(2048) NOT NULL,
+    events jsonb NOT NULL,
+    secret varchar(255) NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    failures int NOT NULL DEFAULT 0,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id uuid NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event varchar(255) NOT NULL,
+    payload bytea NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts int NOT NULL DEFAULT 0,
+    response_status int NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    next_attempt_at bigint NOT NULL,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+-- the dispatcher claims the due pending deliveries
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at);
+
+-- change feed, keeps the latest events for the resume of the streams
+DROP TABLE IF EXISTS feed_events;
+CREATE TABLE feed_events (
+    id bigserial PRIMARY KEY,
+    type varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    data jsonb NOT NULL,
+    occurred_at bigint NOT NULL
+);
+
+-- rate limits shared by the instances, the state of a key is
+-- interpreted by the algorithm, the times are in nanoseconds
+DROP TABLE IF EXISTS rate_limits;
+CREATE TABLE rate_limits (
+    key varchar(512) PRIMARY KEY,
+    time bigint NOT NULL,
+    value double precision NOT NULL,
+    prev double precision NOT NULL,
+    expires_at bigint NOT NULL
+);
+
+CREATE INDEX rate_limits_expires_idx ON rate_limits (expires_at);
@@ -0,0 +1,13 @@
+-- adds the stored responses of the Idempotency-Key middleware to the
+-- databases created before it, db.sql creates it for the new ones
+-- psql -f scripts/db/migrations/001_idempotency_keys.sql
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key varchar(512) PRIMARY KEY,
+    fingerprint varchar(64) NOT NULL,
+    completed boolean NOT NULL DEFAULT false,
+    status int NOT NULL DEFAULT 0,
+    header jsonb NULL,
+    body bytea NULL,
+    expires_at bigint NOT NULL
+);
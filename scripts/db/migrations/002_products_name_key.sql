@@ -0,0 +1,16 @@
+-- adds the unique product name of the bulk import upsert to the
+-- databases created before it, db.sql creates it for the new ones
+-- run it with psql outside of a transaction, the index is built
+-- concurrently: psql -f scripts/db/migrations/002_products_name_key.sql
+
+-- the oldest product keeps a duplicate name, the other ones
+-- are renamed to <name> (<id>) so that the index can be built
+UPDATE products p
+SET name = left(p.name, 200) || ' (' || p.id::text || ')',
+    updated_at = extract(epoch FROM now())::bigint
+WHERE EXISTS (
+    SELECT 1 FROM products o
+    WHERE o.name = p.name AND (o.created_at, o.id) < (p.created_at, p.id)
+);
+
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS products_name_key ON products (name);
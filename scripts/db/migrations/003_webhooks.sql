@@ -0,0 +1,31 @@
+-- adds the webhook subscriptions and their deliveries to the
+-- databases created before them, db.sql creates them for the new ones
+-- psql -f scripts/db/migrations/003_webhooks.sql
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    url varchar(2048) NOT NULL,
+    events jsonb NOT NULL,
+    secret varchar(255) NOT NULL,
+    enabled boolean NOT NULL DEFAULT true,
+    failures int NOT NULL DEFAULT 0,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id uuid NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event varchar(255) NOT NULL,
+    payload bytea NOT NULL,
+    status varchar(16) NOT NULL DEFAULT 'pending',
+    attempts int NOT NULL DEFAULT 0,
+    response_status int NOT NULL DEFAULT 0,
+    error text NOT NULL DEFAULT '',
+    next_attempt_at bigint NOT NULL,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at);
@@ -0,0 +1,11 @@
+-- adds the buffer of the change feed shared by the instances to the
+-- databases created before it, db.sql creates it for the new ones
+-- psql -f scripts/db/migrations/004_feed_events.sql
+
+CREATE TABLE IF NOT EXISTS feed_events (
+    id bigserial PRIMARY KEY,
+    type varchar(255) NOT NULL,
+    subject varchar(255) NOT NULL,
+    data jsonb NOT NULL,
+    occurred_at bigint NOT NULL
+);
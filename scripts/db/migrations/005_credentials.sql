@@ -0,0 +1,25 @@
+-- adds the password credentials and the refresh tokens to the
+-- databases created before them, db.sql creates them for the new ones
+-- the verification of the emails is added by 009
+-- psql -f scripts/db/migrations/005_credentials.sql
+
+CREATE TABLE IF NOT EXISTS credentials (
+    user_id uuid PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    email varchar(320) NOT NULL UNIQUE,
+    password_hash text NOT NULL,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id uuid NOT NULL,
+    token_hash bytea NOT NULL UNIQUE,
+    expires_at bigint NOT NULL,
+    revoked_at bigint NULL,
+    replaced_by uuid NULL,
+    created_at bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_idx ON refresh_tokens (family_id);
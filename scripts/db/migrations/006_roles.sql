@@ -0,0 +1,50 @@
+-- adds the roles, their permissions and the roles of the users to the
+-- databases created before them, db.sql creates them for the new ones
+-- the seeded roles are added, the grants changed since are kept
+-- psql -f scripts/db/migrations/006_roles.sql
+
+CREATE TABLE IF NOT EXISTS roles (
+    name varchar(64) PRIMARY KEY,
+    description varchar(255) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role varchar(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    permission varchar(64) NOT NULL,
+    own boolean NOT NULL DEFAULT false,
+    PRIMARY KEY (role, permission)
+);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role varchar(64) NOT NULL REFERENCES roles (name) ON DELETE CASCADE,
+    created_at bigint NOT NULL,
+    PRIMARY KEY (user_id, role)
+);
+
+-- the seeded roles, see role.Defaults
+INSERT INTO roles (name, description) VALUES
+    ('admin', 'manages the users and the resources'),
+    ('customer', 'reads the products'),
+    ('staff', 'edits the products')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role, permission, own) VALUES
+    ('admin', 'products:delete', false),
+    ('admin', 'products:purge', false),
+    ('admin', 'products:read', false),
+    ('admin', 'products:write', false),
+    ('admin', 'users:delete', false),
+    ('admin', 'users:manage', false),
+    ('admin', 'users:purge', false),
+    ('admin', 'users:read', false),
+    ('admin', 'users:write', false),
+    ('admin', 'webhooks:manage', false),
+    ('customer', 'products:read', false),
+    ('customer', 'users:read', true),
+    ('customer', 'users:write', true),
+    ('staff', 'products:read', false),
+    ('staff', 'products:write', false),
+    ('staff', 'users:read', true),
+    ('staff', 'users:write', true)
+ON CONFLICT (role, permission) DO NOTHING;
@@ -0,0 +1,20 @@
+-- adds the api keys of the machine clients to the databases
+-- created before them, db.sql creates them for the new ones
+-- psql -f scripts/db/migrations/007_api_keys.sql
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name varchar(255) NOT NULL,
+    prefix varchar(32) NOT NULL UNIQUE,
+    salt bytea NOT NULL,
+    key_hash bytea NOT NULL,
+    scopes jsonb NOT NULL,
+    expires_at bigint NULL,
+    last_used_at bigint NULL,
+    revoked_at bigint NULL,
+    created_at bigint NOT NULL,
+    updated_at bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_idx ON api_keys (user_id);
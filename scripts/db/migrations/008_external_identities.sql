@@ -0,0 +1,14 @@
+-- adds the identities of the openid connect logins to the databases
+-- created before them, db.sql creates them for the new ones
+-- psql -f scripts/db/migrations/008_external_identities.sql
+
+CREATE TABLE IF NOT EXISTS external_identities (
+    provider varchar(64) NOT NULL,
+    subject varchar(255) NOT NULL,
+    user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    email varchar(320) NOT NULL,
+    created_at bigint NOT NULL,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS external_identities_email_idx ON external_identities (email);
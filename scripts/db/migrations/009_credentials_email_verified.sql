@@ -0,0 +1,7 @@
+-- adds the verification time of the emails of the credentials to
+-- the databases created before it, db.sql creates it for the new ones
+-- the existing emails are not verified so the external logins are
+-- not linked to them by the email anymore, the users link them
+-- psql -f scripts/db/migrations/009_credentials_email_verified.sql
+
+ALTER TABLE credentials ADD COLUMN IF NOT EXISTS email_verified_at bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+-- adds the rate limits shared by the instances to the databases
+-- created before them, db.sql creates them for the new ones
+-- psql -f scripts/db/migrations/010_rate_limits.sql
+
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key varchar(512) PRIMARY KEY,
+    time bigint NOT NULL,
+    value double precision NOT NULL,
+    prev double precision NOT NULL,
+    expires_at bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS rate_limits_expires_idx ON rate_limits (expires_at);
@@ -0,0 +1,7 @@
+-- widens the address of the users for the encrypted values, the
+-- envelopes are longer than the 255 characters of the plain text,
+-- db.sql creates it for the new databases, the existing addresses
+-- are encrypted by make reencrypt, see the field encryption in README.md
+-- psql -f scripts/db/migrations/011_users_address_encrypted.sql
+
+ALTER TABLE users ALTER COLUMN address TYPE text;
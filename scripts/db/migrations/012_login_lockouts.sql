@@ -0,0 +1,13 @@
+-- adds the failed logins per account and per client address to the
+-- databases created before them, db.sql creates them for the new ones
+-- psql -f scripts/db/migrations/012_login_lockouts.sql
+
+CREATE TABLE IF NOT EXISTS login_lockouts (
+    key varchar(512) PRIMARY KEY,
+    failures integer NOT NULL,
+    last_failure_at bigint NOT NULL,
+    locked_until bigint NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS login_lockouts_locked_idx ON login_lockouts (locked_until) WHERE locked_until > 0;
+CREATE INDEX IF NOT EXISTS login_lockouts_last_failure_idx ON login_lockouts (last_failure_at);
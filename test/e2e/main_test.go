@@ -93,8 +93,17 @@ func initHTTPClientProvider() {
 
 func startServer(chStart, chStop chan int) {
 	cfg := e2e.NewConfig(db)
+
+	if err := cfg.Registry().Start(context.Background()); err != nil {
+		log.Fatalf("start modules: %v", err)
+	}
+
 	s := servere2e.NewServer("localhost:"+os.Getenv("PORT"), cfg.Router().Mux)
 	s.Start(chStart, chStop)
+
+	if err := cfg.Registry().Stop(context.Background()); err != nil {
+		log.Printf("stop modules: %v", err)
+	}
 }
 
 // TestMain is the entry point for the e2e tests
@@ -63,6 +63,35 @@ func TestUser(t *testing.T) {
 		e.ID = u.Data.ID
 	})
 
+	// a retry with the same idempotency key gets the same user
+	t.Run("create idempotent", func(t *testing.T) {
+		b, err := json.Marshal(e)
+		if err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		h := http.Header{}
+		h.Set(constant.HeaderIdempotencyKey, "e2e-create-user")
+
+		ids := make([]string, 2)
+		for i := range ids {
+			u, errRes, err := httpext.Request[response.Response[user.User], response.ErrorResponse](ctx, httpClient, http.MethodPost, baseURL+constant.V1+constant.UsersPattern, h, bytes.NewReader(b), false, nil)
+			if err != nil {
+				if errRes != nil {
+					log.Println("errRes: ", errRes)
+				}
+
+				t.Fatalf("error returned: %v", err)
+			}
+
+			ids[i] = u.Data.ID
+		}
+
+		if ids[0] != ids[1] {
+			t.Errorf("expected the retry to return user %s, got %s", ids[0], ids[1])
+		}
+	})
+
 	t.Run(("readMany"), func(t *testing.T) {
 		resp, errRes, err := httpext.Request[response.Response[response.ReadManyResponse[user.User]], response.ErrorResponse](ctx, httpClient, http.MethodGet, baseURL+constant.V1+constant.UsersPattern, nil, nil, false, nil)
 		// log.Printf("readMany res: %v\n", res)
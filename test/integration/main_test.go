@@ -3,6 +3,7 @@ package integration
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
@@ -16,6 +17,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/env"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/validatorext"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
@@ -27,6 +29,7 @@ const pingTimeout = 10 // in seconds
 var (
 	db        *sql.DB
 	validater validatorext.Validater
+	keyRing   *envelope.KeyRing
 )
 
 func loadEnv() {
@@ -81,6 +84,18 @@ func initValidater() {
 	validater = validatorext.NewValidator(validator.New())
 }
 
+// initKeyRing initializes the key ring of a random key
+func initKeyRing() error {
+	key := make([]byte, constant.EncryptionKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	var err error
+	keyRing, err = envelope.NewKeyRing("test", map[string][]byte{"test": key})
+	return err
+}
+
 // TestMain is the entry point for the e2e tests
 // this function is responsible for setting up the test environment
 // and tearing it down after the tests are done
@@ -103,6 +118,11 @@ func TestMain(m *testing.M) {
 		log.Printf("err: %v", err)
 		os.Exit(1)
 	}
+
+	if err = initKeyRing(); err != nil {
+		log.Printf("err: %v", err)
+		os.Exit(1)
+	}
 	log.Println("Database initialized, connStr: ", connStr)
 	initValidater()
 	// the below statement will run the tests
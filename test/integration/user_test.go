@@ -18,7 +18,7 @@ import (
 
 func TestUser(t *testing.T) {
 	// init storage
-	r := postgres.NewStorage(db)
+	r := postgres.NewStorage(db, keyRing)
 	// init service
 	s := service.NewService(r)
 	// init handler
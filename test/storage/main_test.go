@@ -8,6 +8,7 @@ package storage
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
@@ -16,7 +17,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/tanveerprottoy/backend-structure-go/pkg/constant"
 	"github.com/tanveerprottoy/backend-structure-go/pkg/env"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/envelope"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -24,7 +27,10 @@ import (
 
 const pingTimeout = 10 // in seconds
 
-var db *sql.DB
+var (
+	db      *sql.DB
+	keyRing *envelope.KeyRing
+)
 
 func loadEnv() {
 	// as os.Getwd returns the current working directory
@@ -79,6 +85,18 @@ func initDB(ctx context.Context, connStr string) error {
 	return nil
 }
 
+// initKeyRing initializes the key ring of a random key
+func initKeyRing() error {
+	key := make([]byte, constant.EncryptionKeyBytes)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	var err error
+	keyRing, err = envelope.NewKeyRing("test", map[string][]byte{"test": key})
+	return err
+}
+
 // TestMain is the entry point for the e2e tests
 // this function is responsible for setting up the test environment
 // and tearing it down after the tests are done
@@ -106,6 +124,11 @@ func TestMain(m *testing.M) {
 		os.Exit(1)
 	}
 
+	if err = initKeyRing(); err != nil {
+		log.Printf("err: %v", err)
+		os.Exit(1)
+	}
+
 	log.Println("Database initialized, connStr: ", connStr)
 	// the below statement will run the tests
 	code := m.Run()
@@ -2,11 +2,13 @@ package storage
 
 import (
 	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product"
 	"github.com/tanveerprottoy/backend-structure-go/internal/api/product/postgres"
+	"github.com/tanveerprottoy/backend-structure-go/pkg/errorext"
 )
 
 func TestProduct(t *testing.T) {
@@ -41,6 +43,47 @@ func TestProduct(t *testing.T) {
 		id = lastID
 	})
 
+	t.Run(("duplicate name"), func(t *testing.T) {
+		_, err := s.Create(context.Background(), product.CreateDTO{Name: "Test", CreatedAt: n, UpdatedAt: n})
+		if errorext.ParseCustomError(err).Code() != http.StatusConflict {
+			t.Errorf("expected %d, got %v", http.StatusConflict, err)
+		}
+	})
+
+	t.Run(("upsert"), func(t *testing.T) {
+		dto := product.CreateDTO{
+			Name:        "Test",
+			Description: &description,
+			CreatedAt:   n,
+			UpdatedAt:   n,
+		}
+
+		// the name of the created entity
+		got, created, err := s.Upsert(context.Background(), dto)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if created || got.ID != id {
+			t.Errorf("expected update of %s, got created %t id %s", id, created, got.ID)
+		}
+
+		if got.CreatedAt == dto.CreatedAt {
+			t.Errorf("expected the created at of the stored entity, got %d", got.CreatedAt)
+		}
+
+		dto.Name = "Test Upsert"
+
+		_, created, err = s.Upsert(context.Background(), dto)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if !created {
+			t.Errorf("expected a new entity")
+		}
+	})
+
 	t.Run(("read many"), func(t *testing.T) {
 		// t.Parallel()
 		_, err := s.ReadMany(context.Background(), 10, 0)
@@ -49,6 +92,39 @@ func TestProduct(t *testing.T) {
 		}
 	})
 
+	t.Run(("count"), func(t *testing.T) {
+		n, err := s.Count(context.Background(), false)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if n < 1 {
+			t.Errorf("expected at least one entity, got %d", n)
+		}
+
+		// the table of the test was never analyzed
+		if _, err := s.Estimate(context.Background()); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run(("stream"), func(t *testing.T) {
+		var found bool
+		for p, err := range s.Stream(context.Background(), false) {
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if p.ID == id {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("expected id %s in the stream", id)
+		}
+	})
+
 	t.Run(("read one"), func(t *testing.T) {
 		// t.Parallel()
 		got, err := s.ReadOne(context.Background(), id)
@@ -11,7 +11,7 @@ import (
 
 func TestUser(t *testing.T) {
 	// init storage
-	s := postgres.NewStorage(db)
+	s := postgres.NewStorage(db, keyRing)
 	// Mock data
 	n := time.Now().Unix()
 